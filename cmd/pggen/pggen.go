@@ -83,17 +83,68 @@ func newGenCmd() *ffcli.Command {
 	postgresConn := fset.String("postgres-connection", "",
 		`optional connection string to a postgres database, like: `+
 			`"user=postgres host=localhost dbname=pggen"`)
+	postgresImages := flags.Strings(fset, "postgres-image", nil,
+		"Docker image to run Postgres from if no --postgres-connection, like "+
+			"'postgres:15' or 'postgis/postgis:15-3.3'; defaults to postgres:13; "+
+			"if repeated, generates code with the first image and fails if any "+
+			"query errors or infers different types on the other images")
+	postgresBackend := fset.String("postgres-backend", "",
+		"how to run Postgres if no --postgres-connection: 'docker', the default, "+
+			"or 'local' to use the locally installed initdb, pg_ctl, and psql binaries")
+	postgresBinDir := fset.String("postgres-bin-dir", "",
+		"directory with the Postgres binaries for --postgres-backend local, like "+
+			"'/usr/lib/postgresql/15/bin'; defaults to binaries on PATH")
 	queryGlobs := flags.Strings(fset, "query-glob", nil,
 		"generate code for all SQL files that match glob, like 'queries/**/*.sql'")
 	schemaGlobs := flags.Strings(fset, "schema-glob", nil,
 		"create schema in Postgres from all sql, sql.gz, or shell "+
 			"scripts (*.sh) that match a glob, like 'migrations/*.sql'")
+	searchPath := fset.String("search-path", "",
+		"Postgres search_path to use when inferring query types, like "+
+			"'billing, public'; defaults to the database search_path")
 	acronyms := flags.Strings(fset, "acronym", nil,
 		"lowercase acronym that should convert to all caps like 'api', "+
 			"or custom mapping like 'apis=APIs'")
 	goTypes := flags.Strings(fset, "go-type", nil,
 		"custom type mapping from Postgres to fully qualified Go type, "+
-			"like 'device_type=github.com/jschaf/pggen.DeviceType'")
+			"like 'device_type=github.com/jschaf/pggen.DeviceType'; qualify the "+
+			"Postgres type to map one schema, like 'billing.status=...', or "+
+			"map a table column, like 'public.users.id=example.com/ids.UserID'; "+
+			"use 'text=string,*string' for separate non-null and nullable "+
+			"types; a single type applies to both")
+	readQuerier := fset.Bool("read-querier", false,
+		"generate a ReadQuerier interface with only the read-only queries")
+	goTemplates := flags.Strings(fset, "go-template", nil,
+		"path to a Go template file that overrides the whole Go template or "+
+			"the named templates it defines, like 'query_method'")
+	structTags := flags.Strings(fset, "struct-tag", nil,
+		"struct tag for each field of row, param, and composite structs, in the "+
+			"form 'key[:case][,omitempty]' like 'db' or 'json:camel,omitempty'; "+
+			"case is pg, camel, pascal, or snake; replaces the default json tag")
+	jsonTypes := flags.Strings(fset, "json-type", nil,
+		"Go type for a json or jsonb composite type field, like "+
+			"'event.payload=example.com/foo.Payload'; use the json-type query "+
+			"pragma for query columns and params")
+	typeStyle := fset.String("go-type-style", "pgtype",
+		"style of Go types for Postgres types like timestamptz: 'pgtype' for "+
+			"pgtype.Timestamptz or 'go' for time.Time, time.Duration, [16]byte, "+
+			"and json.RawMessage")
+	uuidType := fset.String("uuid-type", "",
+		"with --go-type-style=go, fully qualified Go type for uuid with an "+
+			"underlying [16]byte type, like 'github.com/google/uuid.UUID'")
+	decimalType := fset.String("decimal-type", "",
+		"with --go-type-style=go, fully qualified Go type for numeric, like "+
+			"'github.com/shopspring/decimal.Decimal'")
+	nullStyle := fset.String("null-style", "default",
+		"representation of nullable values: 'default', 'pointer' for *string, "+
+			"'pgtype' for pgtype.Text, 'sql' for sql.NullString, or 'generic' "+
+			"for a generated Null[string]; array elements use pointers, like "+
+			"[]*string, except with 'pgtype', and composite fields use pointers "+
+			"with 'sql'")
+	domainStyle := fset.String("domain-style", "base",
+		"representation of Postgres domains: 'base' for the Go type of the "+
+			"base type or 'named' for a generated named type like "+
+			"'type Email string'")
 	logLvl := zap.InfoLevel
 	fset.Var(&logLvl, "log", "log level: debug, info, or error")
 	goSubCmd := &ffcli.Command{
@@ -131,40 +182,42 @@ func newGenCmd() *ffcli.Command {
 				}
 			}
 
-			// Parse two acronym formats: "--acronym api" and "--acronym oids=OIDs"
-			acros := make(map[string]string)
-			for _, acro := range *acronyms {
-				ss := strings.SplitN(acro, "=", 2)
-				word := ss[0]
-				if word != strings.ToLower(word) {
-					return fmt.Errorf("acronym %q should be lower case", word)
-				}
-				replacement := strings.ToUpper(word)
-				if len(ss) > 1 {
-					replacement = ss[1]
-				}
-				acros[word] = replacement
+			acros, err := parseAcronyms(*acronyms)
+			if err != nil {
+				return err
 			}
-
-			typeOverrides := make(map[string]string, len(*goTypes))
-			for _, typeAssoc := range *goTypes {
-				if strings.Count(typeAssoc, "=") != 1 {
-					return fmt.Errorf("--go-type must have format <pgType>=<goType>; got %s", typeAssoc)
-				}
-				ss := strings.SplitN(typeAssoc, "=", 2)
-				typeOverrides[ss[0]] = ss[1]
+			typeOverrides, err := parseGoTypes(*goTypes)
+			if err != nil {
+				return err
+			}
+			jsonFieldTypes, err := parseJSONTypes(*jsonTypes)
+			if err != nil {
+				return err
 			}
 
 			// Codegen.
 			err = pggen.Generate(pggen.GenerateOptions{
-				Language:      pggen.LangGo,
-				ConnString:    *postgresConn,
-				SchemaFiles:   schemas,
-				QueryFiles:    queries,
-				OutputDir:     outDir,
-				Acronyms:      acros,
-				TypeOverrides: typeOverrides,
-				LogLevel:      logLvl,
+				Language:        pggen.LangGo,
+				ConnString:      *postgresConn,
+				PostgresImages:  *postgresImages,
+				PostgresBackend: pggen.PostgresBackend(*postgresBackend),
+				PostgresBinDir:  *postgresBinDir,
+				SchemaFiles:     schemas,
+				SearchPath:      *searchPath,
+				QueryFiles:      queries,
+				OutputDir:       outDir,
+				Acronyms:        acros,
+				TypeOverrides:   typeOverrides,
+				ReadQuerier:     *readQuerier,
+				GoTemplates:     *goTemplates,
+				StructTags:      *structTags,
+				JSONTypes:       jsonFieldTypes,
+				TypeStyle:       *typeStyle,
+				UUIDType:        *uuidType,
+				DecimalType:     *decimalType,
+				NullStyle:       *nullStyle,
+				DomainStyle:     *domainStyle,
+				LogLevel:        logLvl,
 			})
 			if err != nil {
 				return err
@@ -180,10 +233,10 @@ func newGenCmd() *ffcli.Command {
 	}
 	cmd := &ffcli.Command{
 		Name:        "gen",
-		ShortUsage:  "pggen gen (go|<lang>) [options...]",
+		ShortUsage:  "pggen gen (go|crud|<lang>) [options...]",
 		ShortHelp:   "generates code in specific language for Postgres query files",
 		FlagSet:     nil,
-		Subcommands: []*ffcli.Command{goSubCmd},
+		Subcommands: []*ffcli.Command{goSubCmd, newGenCRUDCmd()},
 	}
 	cmd.Exec = func(ctx context.Context, args []string) error {
 		fmt.Println(ffcli.DefaultUsageFunc(cmd))
@@ -193,6 +246,215 @@ func newGenCmd() *ffcli.Command {
 	return cmd
 }
 
+func newGenCRUDCmd() *ffcli.Command {
+	fset := flag.NewFlagSet("crud", flag.ExitOnError)
+	outputDir := fset.String("output-dir", "",
+		"where to write crud.sql and generated code")
+	postgresConn := fset.String("postgres-connection", "",
+		`optional connection string to a postgres database, like: `+
+			`"user=postgres host=localhost dbname=pggen"`)
+	postgresImages := flags.Strings(fset, "postgres-image", nil,
+		"Docker image to run Postgres from if no --postgres-connection, like "+
+			"'postgres:15' or 'postgis/postgis:15-3.3'; defaults to postgres:13; "+
+			"if repeated, generates code with the first image and fails if any "+
+			"query errors or infers different types on the other images")
+	postgresBackend := fset.String("postgres-backend", "",
+		"how to run Postgres if no --postgres-connection: 'docker', the default, "+
+			"or 'local' to use the locally installed initdb, pg_ctl, and psql binaries")
+	postgresBinDir := fset.String("postgres-bin-dir", "",
+		"directory with the Postgres binaries for --postgres-backend local, like "+
+			"'/usr/lib/postgresql/15/bin'; defaults to binaries on PATH")
+	tables := flags.Strings(fset, "table", nil,
+		"generate CRUD queries for a table, like 'author' or 'public.author'")
+	queryGlobs := flags.Strings(fset, "query-glob", nil,
+		"also generate code for all SQL files that match glob, like 'queries/**/*.sql'")
+	schemaGlobs := flags.Strings(fset, "schema-glob", nil,
+		"create schema in Postgres from all sql, sql.gz, or shell "+
+			"scripts (*.sh) that match a glob, like 'migrations/*.sql'")
+	searchPath := fset.String("search-path", "",
+		"Postgres search_path to use when inferring query types, like "+
+			"'billing, public'; defaults to the database search_path")
+	acronyms := flags.Strings(fset, "acronym", nil,
+		"lowercase acronym that should convert to all caps like 'api', "+
+			"or custom mapping like 'apis=APIs'")
+	goTypes := flags.Strings(fset, "go-type", nil,
+		"custom type mapping from Postgres to fully qualified Go type, "+
+			"like 'device_type=github.com/jschaf/pggen.DeviceType'; qualify the "+
+			"Postgres type to map one schema, like 'billing.status=...', or "+
+			"map a table column, like 'public.users.id=example.com/ids.UserID'; "+
+			"use 'text=string,*string' for separate non-null and nullable "+
+			"types; a single type applies to both")
+	readQuerier := fset.Bool("read-querier", false,
+		"generate a ReadQuerier interface with only the read-only queries")
+	goTemplates := flags.Strings(fset, "go-template", nil,
+		"path to a Go template file that overrides the whole Go template or "+
+			"the named templates it defines, like 'query_method'")
+	structTags := flags.Strings(fset, "struct-tag", nil,
+		"struct tag for each field of row, param, and composite structs, in the "+
+			"form 'key[:case][,omitempty]' like 'db' or 'json:camel,omitempty'; "+
+			"case is pg, camel, pascal, or snake; replaces the default json tag")
+	jsonTypes := flags.Strings(fset, "json-type", nil,
+		"Go type for a json or jsonb composite type field, like "+
+			"'event.payload=example.com/foo.Payload'; use the json-type query "+
+			"pragma for query columns and params")
+	typeStyle := fset.String("go-type-style", "pgtype",
+		"style of Go types for Postgres types like timestamptz: 'pgtype' for "+
+			"pgtype.Timestamptz or 'go' for time.Time, time.Duration, [16]byte, "+
+			"and json.RawMessage")
+	uuidType := fset.String("uuid-type", "",
+		"with --go-type-style=go, fully qualified Go type for uuid with an "+
+			"underlying [16]byte type, like 'github.com/google/uuid.UUID'")
+	decimalType := fset.String("decimal-type", "",
+		"with --go-type-style=go, fully qualified Go type for numeric, like "+
+			"'github.com/shopspring/decimal.Decimal'")
+	nullStyle := fset.String("null-style", "default",
+		"representation of nullable values: 'default', 'pointer' for *string, "+
+			"'pgtype' for pgtype.Text, 'sql' for sql.NullString, or 'generic' "+
+			"for a generated Null[string]; array elements use pointers, like "+
+			"[]*string, except with 'pgtype', and composite fields use pointers "+
+			"with 'sql'")
+	domainStyle := fset.String("domain-style", "base",
+		"representation of Postgres domains: 'base' for the Go type of the "+
+			"base type or 'named' for a generated named type like "+
+			"'type Email string'")
+	logLvl := zap.InfoLevel
+	fset.Var(&logLvl, "log", "log level: debug, info, or error")
+	return &ffcli.Command{
+		Name:       "crud",
+		ShortUsage: "pggen gen crud --table <table>... --output-dir <dir> [--schema-glob <glob>]... [flags]",
+		ShortHelp:  "generates CRUD queries and go code for Postgres tables",
+		FlagSet:    fset,
+		LongHelp: texts.Dedent(`
+			pggen gen crud reads each --table from the Postgres catalog and writes a
+			query file, crud.sql, to --output-dir with insert, find, update, upsert,
+			and delete queries for the table that return the row as a struct named
+			after the table. pggen then generates Go code for crud.sql and any
+			--query-glob files like pggen gen go. pggen overwrites crud.sql on every
+			run, so put hand-written queries in another file.
+
+			EXAMPLES
+			  # Generate CRUD queries for the author table.
+			  pggen gen crud --schema-glob author/schema.sql --table author --output-dir author
+		`),
+		Exec: func(ctx context.Context, args []string) error {
+			// Preconditions.
+			if len(*tables) == 0 {
+				return fmt.Errorf("pggen gen crud: at least one --table must be set")
+			}
+			if *outputDir == "" {
+				return fmt.Errorf("pggen gen crud: --output-dir must be set")
+			}
+			queries, err := expandSortGlobs(*queryGlobs)
+			if err != nil {
+				return err
+			}
+			schemas, err := expandSortGlobs(*schemaGlobs)
+			if err != nil {
+				return err
+			}
+			acros, err := parseAcronyms(*acronyms)
+			if err != nil {
+				return err
+			}
+			typeOverrides, err := parseGoTypes(*goTypes)
+			if err != nil {
+				return err
+			}
+			jsonFieldTypes, err := parseJSONTypes(*jsonTypes)
+			if err != nil {
+				return err
+			}
+
+			// Codegen.
+			err = pggen.Generate(pggen.GenerateOptions{
+				Language:        pggen.LangGo,
+				ConnString:      *postgresConn,
+				PostgresImages:  *postgresImages,
+				PostgresBackend: pggen.PostgresBackend(*postgresBackend),
+				PostgresBinDir:  *postgresBinDir,
+				SchemaFiles:     schemas,
+				SearchPath:      *searchPath,
+				QueryFiles:      queries,
+				CRUDTables:      *tables,
+				OutputDir:       *outputDir,
+				Acronyms:        acros,
+				TypeOverrides:   typeOverrides,
+				ReadQuerier:     *readQuerier,
+				GoTemplates:     *goTemplates,
+				StructTags:      *structTags,
+				JSONTypes:       jsonFieldTypes,
+				TypeStyle:       *typeStyle,
+				UUIDType:        *uuidType,
+				DecimalType:     *decimalType,
+				NullStyle:       *nullStyle,
+				DomainStyle:     *domainStyle,
+				LogLevel:        logLvl,
+			})
+			if err != nil {
+				return err
+			}
+
+			tablesDesc := "tables"
+			if len(*tables) == 1 {
+				tablesDesc = "table"
+			}
+			fmt.Printf("generated CRUD queries for %d %s\n", len(*tables), tablesDesc)
+			return nil
+		},
+	}
+}
+
+// parseAcronyms parses two acronym formats: "--acronym api" and
+// "--acronym oids=OIDs".
+func parseAcronyms(acronyms []string) (map[string]string, error) {
+	acros := make(map[string]string)
+	for _, acro := range acronyms {
+		ss := strings.SplitN(acro, "=", 2)
+		word := ss[0]
+		if word != strings.ToLower(word) {
+			return nil, fmt.Errorf("acronym %q should be lower case", word)
+		}
+		replacement := strings.ToUpper(word)
+		if len(ss) > 1 {
+			replacement = ss[1]
+		}
+		acros[word] = replacement
+	}
+	return acros, nil
+}
+
+// parseJSONTypes parses Go types for json and jsonb composite fields like
+// "--json-type event.payload=example.com/foo.Payload".
+func parseJSONTypes(jsonTypes []string) (map[string]string, error) {
+	types := make(map[string]string, len(jsonTypes))
+	for _, typeAssoc := range jsonTypes {
+		ss := strings.SplitN(typeAssoc, "=", 2)
+		if len(ss) != 2 || strings.Count(ss[0], ".") != 1 || ss[1] == "" {
+			return nil, fmt.Errorf("--json-type must have format <composite>.<field>=<goType>; got %s", typeAssoc)
+		}
+		types[ss[0]] = ss[1]
+	}
+	return types, nil
+}
+
+// parseGoTypes parses custom type mappings like "--go-type int8=int" or
+// "--go-type text=string,*string".
+func parseGoTypes(goTypes []string) (map[string]string, error) {
+	typeOverrides := make(map[string]string, len(goTypes))
+	for _, typeAssoc := range goTypes {
+		if strings.Count(typeAssoc, "=") != 1 {
+			return nil, fmt.Errorf("--go-type must have format <pgType>=<goType>; got %s", typeAssoc)
+		}
+		ss := strings.SplitN(typeAssoc, "=", 2)
+		if goTypes := strings.Split(ss[1], ","); len(goTypes) > 2 ||
+			(len(goTypes) == 2 && strings.TrimSpace(goTypes[0]) == "" && strings.TrimSpace(goTypes[1]) == "") {
+			return nil, fmt.Errorf("--go-type must have format <pgType>=<goType> or <pgType>=<goType>,<nullableGoType>; got %s", typeAssoc)
+		}
+		typeOverrides[ss[0]] = ss[1]
+	}
+	return typeOverrides, nil
+}
+
 // expandSortGlobs gets the absolute paths for all files matching globs. Order
 // files lexicographically within each glob but not across all globs. The order
 // of a glob relative to other globs is important for schemas where a schema
@@ -29,6 +29,7 @@ const projDir = ".." // hardcoded
 func TestExamples(t *testing.T) {
 	tests := []struct {
 		name string
+		cmd  string // pggen gen subcommand; defaults to go
 		args []string
 	}{
 		{
@@ -58,6 +59,14 @@ func TestExamples(t *testing.T) {
 				"--go-type", "text=string",
 			},
 		},
+		{
+			name: "example/custom_template",
+			args: []string{
+				"--schema-glob", "example/custom_template/schema.sql",
+				"--query-glob", "example/custom_template/query.sql",
+				"--go-template", "example/custom_template/sql_text.gotemplate",
+			},
+		},
 		{
 			name: "example/enums",
 			args: []string{
@@ -92,6 +101,7 @@ func TestExamples(t *testing.T) {
 				"--query-glob", "example/erp/order/*.sql",
 				"--acronym", "mrr",
 				"--go-type", "tenant_id=int",
+				"--read-querier",
 			},
 		},
 		{
@@ -101,6 +111,7 @@ func TestExamples(t *testing.T) {
 				"--query-glob", "example/erp/order/*.sql",
 				"--acronym", "mrr",
 				"--go-type", "tenant_id=int",
+				"--read-querier",
 			},
 		},
 		{
@@ -190,6 +201,15 @@ func TestExamples(t *testing.T) {
 				"--query-glob", "example/domain/query.sql",
 			},
 		},
+		{
+			name: "example/crud",
+			cmd:  "crud",
+			args: []string{
+				"--schema-glob", "example/crud/schema.sql",
+				"--table", "author",
+				"--output-dir", "example/crud",
+			},
+		},
 	}
 	if *update {
 		// update only disables the assertions. Running the tests causes pggen
@@ -201,7 +221,7 @@ func TestExamples(t *testing.T) {
 	// a new database in the Postgres cluster.
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
-	docker, err := pgdocker.Start(ctx, nil, zaptest.NewLogger(t).Sugar())
+	docker, err := pgdocker.Start(ctx, "", nil, zaptest.NewLogger(t).Sugar())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -224,7 +244,11 @@ func TestExamples(t *testing.T) {
 			}
 			connStr := mainConnStr + " dbname=" + dbName
 			args := append(tt.args, "--postgres-connection", connStr)
-			runPggen(t, pggen, args...)
+			cmd := tt.cmd
+			if cmd == "" {
+				cmd = "go"
+			}
+			runPggen(t, pggen, cmd, args...)
 			if !*update {
 				assertNoDiff(t)
 			}
@@ -232,10 +256,10 @@ func TestExamples(t *testing.T) {
 	}
 }
 
-func runPggen(t *testing.T, pggen string, args ...string) string {
+func runPggen(t *testing.T, pggen string, subcmd string, args ...string) string {
 	cmd := exec.Cmd{
 		Path: pggen,
-		Args: append([]string{pggen, "gen", "go"}, args...),
+		Args: append([]string{pggen, "gen", subcmd}, args...),
 		Dir:  projDir,
 	}
 	t.Log("running pggen")
@@ -1,5 +1,5 @@
 -- FindAuthorById finds one (or zero) authors by ID.
--- name: FindAuthorByID :one
+-- name: FindAuthorByID :one route=primary
 SELECT * FROM author WHERE author_id = pggen.arg('AuthorID');
 
 -- FindAuthors finds authors by first name.
@@ -38,3 +38,10 @@ RETURNING author_id;
 INSERT INTO author (first_name, last_name, suffix)
 VALUES (pggen.arg('FirstName'), pggen.arg('LastName'), pggen.arg('Suffix'))
 RETURNING author_id, first_name, last_name, suffix;
+
+-- FindAuthorsPage finds a page of authors by first name ordered by ID.
+-- name: FindAuthorsPage :many paginate=keyset
+SELECT author_id, first_name, last_name
+FROM author
+WHERE first_name = pggen.arg('FirstName')
+ORDER BY author_id;
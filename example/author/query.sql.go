@@ -4,10 +4,14 @@ package author
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v4"
+	"time"
 )
 
 // Querier is a typesafe Go interface backed by SQL queries.
@@ -80,11 +84,25 @@ type Querier interface {
 	InsertAuthorSuffixBatch(batch genericBatch, params InsertAuthorSuffixParams)
 	// InsertAuthorSuffixScan scans the result of an executed InsertAuthorSuffixBatch query.
 	InsertAuthorSuffixScan(results pgx.BatchResults) (InsertAuthorSuffixRow, error)
+
+	// FindAuthorsPage finds a page of authors by first name ordered by ID.
+	FindAuthorsPage(ctx context.Context, params FindAuthorsPageParams) ([]FindAuthorsPageRow, error)
+	// FindAuthorsPageBatch enqueues a FindAuthorsPage query into batch to be executed
+	// later by the batch.
+	FindAuthorsPageBatch(batch genericBatch, params FindAuthorsPageParams)
+	// FindAuthorsPageScan scans the result of an executed FindAuthorsPageBatch query.
+	FindAuthorsPageScan(results pgx.BatchResults) ([]FindAuthorsPageRow, error)
+
+	// RunInTx runs fn in a transaction using a Querier bound to the
+	// transaction. See DBQuerier.RunInTx.
+	RunInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error
 }
 
 type DBQuerier struct {
-	conn  genericConn   // underlying Postgres transport to use
-	types *typeResolver // resolve types by name
+	conn    genericConn   // underlying Postgres transport to use
+	replica genericConn   // transport for read-only queries; nil unless routing
+	types   *typeResolver // resolve types by name
+	cfg     QuerierConfig // config used to create the querier
 }
 
 var _ Querier = &DBQuerier{}
@@ -130,17 +148,362 @@ type QuerierConfig struct {
 	// transitive dependencies, pggen will use the binary encoding format for
 	// the input parameter.
 	DataTypes []pgtype.DataType
+
+	// UsePreparedStatements runs queries by the statement names prepared by
+	// PrepareAllQueries instead of by SQL text. Every connection used by the
+	// querier must run PrepareAllQueries first, typically in the AfterConnect
+	// callback of pgxpool.Config.
+	//
+	// If false, the default, queries run by SQL text, which works whether or
+	// not the connection prepared the statements.
+	UsePreparedStatements bool
+
+	// PgBouncerCompat enables compatibility with PgBouncer in transaction
+	// pooling mode, which doesn't support prepared statements. Each query runs
+	// with pgx.QuerySimpleProtocol, pggen-generated transcoders always encode
+	// in the text format, UsePreparedStatements is ignored, and
+	// PrepareAllQueriesConfig doesn't prepare any statements.
+	//
+	// pgx only sends a pgx.Batch with the simple protocol if
+	// PreferSimpleProtocol is set on pgx.ConnConfig, so Batch.Send runs the
+	// queued queries one at a time with the simple protocol instead of in a
+	// single network round-trip.
+	PgBouncerCompat bool
 }
 
 // NewQuerierConfig creates a DBQuerier that implements Querier with the given
 // config. conn is typically *pgx.Conn, pgx.Tx, or *pgxpool.Pool.
 func NewQuerierConfig(conn genericConn, cfg QuerierConfig) *DBQuerier {
-	return &DBQuerier{conn: conn, types: newTypeResolver(cfg.DataTypes)}
+	if cfg.PgBouncerCompat {
+		conn = simpleProtocolConn{conn}
+	}
+	return &DBQuerier{conn: conn, types: newTypeResolver(cfg.DataTypes, cfg.PgBouncerCompat), cfg: cfg}
 }
 
 // WithTx creates a new DBQuerier that uses the transaction to run all queries.
+// The returned DBQuerier keeps the config of q. If q is a routing querier, the
+// returned DBQuerier runs read-only queries in the transaction too.
 func (q *DBQuerier) WithTx(tx pgx.Tx) (*DBQuerier, error) {
-	return &DBQuerier{conn: tx}, nil
+	var conn genericConn = tx
+	if q.cfg.PgBouncerCompat {
+		conn = simpleProtocolConn{conn}
+	}
+	return &DBQuerier{conn: conn, types: q.types, cfg: q.cfg}, nil
+}
+
+// NewRoutingQuerier creates a DBQuerier that runs read-only queries on replica
+// and all other queries on primary. pggen classifies a query as read-only if
+// the query plan doesn't modify a table or lock rows. Queries with the
+// route=primary pragma, batches, and transactions always use primary.
+func NewRoutingQuerier(primary, replica genericConn) *DBQuerier {
+	return NewRoutingQuerierConfig(primary, replica, QuerierConfig{})
+}
+
+// NewRoutingQuerierConfig creates a DBQuerier like NewRoutingQuerier with the
+// given config. The config applies to both primary and replica.
+func NewRoutingQuerierConfig(primary, replica genericConn, cfg QuerierConfig) *DBQuerier {
+	q := NewQuerierConfig(primary, cfg)
+	if cfg.PgBouncerCompat {
+		replica = simpleProtocolConn{replica}
+	}
+	q.replica = replica
+	return q
+}
+
+// readConn returns the Postgres transport for read-only queries.
+func (q *DBQuerier) readConn() genericConn {
+	if q.replica == nil {
+		return q.conn
+	}
+	return q.replica
+}
+
+// simpleProtocolConn is a genericConn that runs all queries with the simple
+// protocol, overriding pgx.ConnConfig.PreferSimpleProtocol.
+type simpleProtocolConn struct {
+	genericConn
+}
+
+func (c simpleProtocolConn) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return c.genericConn.Query(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, args...)...)
+}
+
+func (c simpleProtocolConn) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return c.genericConn.QueryRow(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, args...)...)
+}
+
+func (c simpleProtocolConn) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	return c.genericConn.Exec(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, arguments...)...)
+}
+
+// transport returns the underlying Postgres transport of q, unwrapping any
+// wrapper added by the config.
+func (q *DBQuerier) transport() genericConn {
+	if c, ok := q.conn.(simpleProtocolConn); ok {
+		return c.genericConn
+	}
+	return q.conn
+}
+
+// txBeginner is any Postgres connection transport that can begin a
+// transaction, most commonly *pgx.Conn or *pgxpool.Pool.
+type txBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// TxOptions controls how RunInTx begins and retries a transaction.
+type TxOptions struct {
+	// TxOptions are the options used to begin a top-level transaction. Ignored
+	// for nested transactions, which use a savepoint.
+	pgx.TxOptions
+
+	// MaxRetries is the maximum number of times to retry a top-level
+	// transaction that failed with a serialization failure (SQLSTATE 40001) or
+	// a deadlock (SQLSTATE 40P01). Zero disables retries.
+	MaxRetries int
+
+	// Backoff returns how long to wait before the nth retry, starting at 1. If
+	// nil, retries immediately.
+	Backoff func(retry int) time.Duration
+}
+
+// RunInTx runs fn in a transaction. fn receives a Querier bound to the
+// transaction with the same config as q. If fn returns nil, RunInTx commits
+// the transaction; otherwise, RunInTx rolls back the transaction and returns
+// the error from fn.
+//
+// If q is already bound to a transaction, like the Querier passed to fn,
+// RunInTx creates a nested transaction using a savepoint. Only the top-level
+// transaction is retried because a serialization failure aborts the entire
+// transaction.
+func (q *DBQuerier) RunInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error {
+	_, isNested := q.transport().(pgx.Tx)
+	for retry := 1; ; retry++ {
+		err := q.runInTx(ctx, opts, fn)
+		if err == nil || isNested || retry > opts.MaxRetries || !isRetryableTxErr(err) {
+			return err
+		}
+		if opts.Backoff == nil {
+			continue
+		}
+		timer := time.NewTimer(opts.Backoff(retry))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("retry tx: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// runInTx runs a single attempt of fn in a transaction.
+func (q *DBQuerier) runInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error {
+	var tx pgx.Tx
+	var err error
+	switch conn := q.transport().(type) {
+	case pgx.Tx:
+		tx, err = conn.Begin(ctx) // savepoint
+	case txBeginner:
+		tx, err = conn.BeginTx(ctx, opts.TxOptions)
+	default:
+		return fmt.Errorf("begin tx: conn of type %T cannot begin a transaction", conn)
+	}
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	txq, _ := q.WithTx(tx)
+	if err := fn(txq); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			return fmt.Errorf("rollback tx: %v; original error: %w", rbErr, err)
+		}
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// isRetryableTxErr returns true if err is a serialization failure or deadlock
+// such that retrying the entire transaction might succeed.
+func isRetryableTxErr(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+// batchSender is any Postgres connection transport that can send a batch of
+// queries, most commonly *pgx.Conn, pgx.Tx, or *pgxpool.Pool.
+type batchSender interface {
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// Batch is a typed builder for a pgx.Batch. Each query method on Batch queues
+// the query and returns a handle. After calling Send, use Result on the handle
+// to get the typed result of the query.
+type Batch struct {
+	q       *DBQuerier
+	batch   queueBatch    // *pgx.Batch, or *simpleBatch with PgBouncerCompat
+	handles []batchHandle // handles in the same order as the queued queries
+	sent    bool
+}
+
+// batchHandle scans the result of a single query queued in a Batch.
+type batchHandle interface {
+	scan(results pgx.BatchResults) error
+}
+
+var errBatchNotSent = errors.New("batch not sent")
+
+// queueBatch is a genericBatch that counts the queued queries.
+type queueBatch interface {
+	genericBatch
+	Len() int
+}
+
+// NewBatch creates a typed Batch that sends queries using the conn of q.
+func (q *DBQuerier) NewBatch() *Batch {
+	if q.cfg.PgBouncerCompat {
+		return &Batch{q: q, batch: &simpleBatch{}}
+	}
+	return &Batch{q: q, batch: &pgx.Batch{}}
+}
+
+// Send sends all queued queries with SendBatch and scans the result of each
+// query into its handle in queue order. If any query fails, Send returns a
+// BatchError with the errors of all failed queries.
+func (b *Batch) Send(ctx context.Context) error {
+	if b.sent {
+		return errors.New("send batch: batch already sent")
+	}
+	if b.batch.Len() != len(b.handles) {
+		return fmt.Errorf("send batch: %d queued queries but %d handles", b.batch.Len(), len(b.handles))
+	}
+	var results pgx.BatchResults
+	switch batch := b.batch.(type) {
+	case *simpleBatch:
+		b.sent = true
+		results = &simpleBatchResults{ctx: ctx, conn: b.q.conn, queries: batch.queries}
+	case *pgx.Batch:
+		sender, ok := b.q.transport().(batchSender)
+		if !ok {
+			return fmt.Errorf("send batch: conn of type %T cannot send a batch", b.q.transport())
+		}
+		b.sent = true
+		results = sender.SendBatch(ctx, batch)
+	default:
+		return fmt.Errorf("send batch: unknown batch type %T", b.batch)
+	}
+	var errs []error
+	for _, h := range b.handles {
+		if err := h.scan(results); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := results.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("close batch results: %w", err))
+	}
+	if len(errs) > 0 {
+		return BatchError{Errs: errs}
+	}
+	return nil
+}
+
+// BatchError is the error returned by Batch.Send with the errors of all failed
+// queries in queue order.
+type BatchError struct {
+	Errs []error
+}
+
+func (e BatchError) Error() string {
+	msg := fmt.Sprintf("send batch: %d errors", len(e.Errs))
+	for _, err := range e.Errs {
+		msg += "; " + err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the first error.
+func (e BatchError) Unwrap() error { return e.Errs[0] }
+
+// simpleBatch is a genericBatch that records the queued queries so that
+// simpleBatchResults can run them with the simple protocol.
+type simpleBatch struct {
+	queries []simpleQuery
+}
+
+type simpleQuery struct {
+	sql  string
+	args []interface{}
+}
+
+func (b *simpleBatch) Queue(query string, arguments ...interface{}) {
+	b.queries = append(b.queries, simpleQuery{sql: query, args: arguments})
+}
+
+func (b *simpleBatch) Len() int { return len(b.queries) }
+
+// simpleBatchResults is a pgx.BatchResults that runs the next query of a
+// simpleBatch on conn when reading its result. conn is a simpleProtocolConn,
+// so each query runs with the simple protocol.
+type simpleBatchResults struct {
+	ctx     context.Context
+	conn    genericConn
+	queries []simpleQuery
+	idx     int // index of the next query
+}
+
+func (r *simpleBatchResults) next() (simpleQuery, error) {
+	if r.idx == len(r.queries) {
+		return simpleQuery{}, fmt.Errorf("no result for batch query %d", r.idx)
+	}
+	q := r.queries[r.idx]
+	r.idx++
+	return q, nil
+}
+
+func (r *simpleBatchResults) Exec() (pgconn.CommandTag, error) {
+	q, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	return r.conn.Exec(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) Query() (pgx.Rows, error) {
+	q, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	return r.conn.Query(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) QueryRow() pgx.Row {
+	q, err := r.next()
+	if err != nil {
+		return errRow{err: err}
+	}
+	return r.conn.QueryRow(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) Close() error { return nil }
+
+// errRow is a pgx.Row that returns err from Scan.
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(...interface{}) error { return r.err }
+
+// chooseSQL returns the name of the prepared statement if the querier uses
+// prepared statements. Otherwise, returns the SQL text.
+func (q *DBQuerier) chooseSQL(sql, stmtName string) string {
+	if q.cfg.UsePreparedStatements && !q.cfg.PgBouncerCompat {
+		return stmtName
+	}
+	return sql
 }
 
 // preparer is any Postgres connection transport that provides a way to prepare
@@ -153,51 +516,69 @@ type preparer interface {
 // queries in querier files. Typical usage is as the AfterConnect callback
 // for pgxpool.Config
 //
-// pgx will use the prepared statement if available. Calling PrepareAllQueries
-// is an optional optimization to avoid a network round-trip the first time pgx
-// runs a query if pgx statement caching is enabled.
+// Each statement is prepared under a deterministic name derived from the query
+// name and SQL text. A querier created with
+// QuerierConfig.UsePreparedStatements runs queries by those names, avoiding a
+// network round-trip to prepare the statement the first time pgx runs a query.
+//
+// Use PrepareAllQueriesConfig with the config of the querier to skip preparing
+// statements with QuerierConfig.PgBouncerCompat.
 func PrepareAllQueries(ctx context.Context, p preparer) error {
-	if _, err := p.Prepare(ctx, findAuthorByIDSQL, findAuthorByIDSQL); err != nil {
+	if _, err := p.Prepare(ctx, findAuthorByIDStmt, findAuthorByIDSQL); err != nil {
 		return fmt.Errorf("prepare query 'FindAuthorByID': %w", err)
 	}
-	if _, err := p.Prepare(ctx, findAuthorsSQL, findAuthorsSQL); err != nil {
+	if _, err := p.Prepare(ctx, findAuthorsStmt, findAuthorsSQL); err != nil {
 		return fmt.Errorf("prepare query 'FindAuthors': %w", err)
 	}
-	if _, err := p.Prepare(ctx, findAuthorNamesSQL, findAuthorNamesSQL); err != nil {
+	if _, err := p.Prepare(ctx, findAuthorNamesStmt, findAuthorNamesSQL); err != nil {
 		return fmt.Errorf("prepare query 'FindAuthorNames': %w", err)
 	}
-	if _, err := p.Prepare(ctx, deleteAuthorsSQL, deleteAuthorsSQL); err != nil {
+	if _, err := p.Prepare(ctx, deleteAuthorsStmt, deleteAuthorsSQL); err != nil {
 		return fmt.Errorf("prepare query 'DeleteAuthors': %w", err)
 	}
-	if _, err := p.Prepare(ctx, deleteAuthorsByFirstNameSQL, deleteAuthorsByFirstNameSQL); err != nil {
+	if _, err := p.Prepare(ctx, deleteAuthorsByFirstNameStmt, deleteAuthorsByFirstNameSQL); err != nil {
 		return fmt.Errorf("prepare query 'DeleteAuthorsByFirstName': %w", err)
 	}
-	if _, err := p.Prepare(ctx, deleteAuthorsByFullNameSQL, deleteAuthorsByFullNameSQL); err != nil {
+	if _, err := p.Prepare(ctx, deleteAuthorsByFullNameStmt, deleteAuthorsByFullNameSQL); err != nil {
 		return fmt.Errorf("prepare query 'DeleteAuthorsByFullName': %w", err)
 	}
-	if _, err := p.Prepare(ctx, insertAuthorSQL, insertAuthorSQL); err != nil {
+	if _, err := p.Prepare(ctx, insertAuthorStmt, insertAuthorSQL); err != nil {
 		return fmt.Errorf("prepare query 'InsertAuthor': %w", err)
 	}
-	if _, err := p.Prepare(ctx, insertAuthorSuffixSQL, insertAuthorSuffixSQL); err != nil {
+	if _, err := p.Prepare(ctx, insertAuthorSuffixStmt, insertAuthorSuffixSQL); err != nil {
 		return fmt.Errorf("prepare query 'InsertAuthorSuffix': %w", err)
 	}
+	if _, err := p.Prepare(ctx, findAuthorsPageStmt, findAuthorsPageSQL); err != nil {
+		return fmt.Errorf("prepare query 'FindAuthorsPage': %w", err)
+	}
 	return nil
 }
 
+// PrepareAllQueriesConfig is like PrepareAllQueries but doesn't prepare any
+// statements if cfg.PgBouncerCompat is set, because PgBouncer in transaction
+// pooling mode doesn't support prepared statements.
+func PrepareAllQueriesConfig(ctx context.Context, p preparer, cfg QuerierConfig) error {
+	if cfg.PgBouncerCompat {
+		return nil
+	}
+	return PrepareAllQueries(ctx, p)
+}
+
 // typeResolver looks up the pgtype.ValueTranscoder by Postgres type name.
 type typeResolver struct {
-	connInfo *pgtype.ConnInfo // types by Postgres type name
+	connInfo   *pgtype.ConnInfo // types by Postgres type name
+	preferText bool             // if true, always encode in the text format
 }
 
-func newTypeResolver(types []pgtype.DataType) *typeResolver {
+func newTypeResolver(types []pgtype.DataType, preferText bool) *typeResolver {
 	ci := pgtype.NewConnInfo()
 	for _, typ := range types {
-		if txt, ok := typ.Value.(textPreferrer); ok && typ.OID != unknownOID {
+		if txt, ok := typ.Value.(textPreferrer); ok && typ.OID != unknownOID && !preferText {
 			typ.Value = txt.ValueTranscoder
 		}
 		ci.RegisterDataType(typ)
 	}
-	return &typeResolver{connInfo: ci}
+	return &typeResolver{connInfo: ci, preferText: preferText}
 }
 
 // findValue find the OID, and pgtype.ValueTranscoder for a Postgres type name.
@@ -206,8 +587,11 @@ func (tr *typeResolver) findValue(name string) (uint32, pgtype.ValueTranscoder,
 	if !ok {
 		return 0, nil, false
 	}
-	v := pgtype.NewValue(typ.Value)
-	return typ.OID, v.(pgtype.ValueTranscoder), true
+	v := pgtype.NewValue(typ.Value).(pgtype.ValueTranscoder)
+	if _, isText := v.(textPreferrer); tr.preferText && !isText {
+		v = textPreferrer{v, name}
+	}
+	return typ.OID, v, true
 }
 
 // setValue sets the value of a ValueTranscoder to a value that should always
@@ -221,6 +605,8 @@ func (tr *typeResolver) setValue(vt pgtype.ValueTranscoder, val interface{}) pgt
 
 const findAuthorByIDSQL = `SELECT * FROM author WHERE author_id = $1;`
 
+const findAuthorByIDStmt = "pggen_FindAuthorByID_9b41e448294f6af5"
+
 type FindAuthorByIDRow struct {
 	AuthorID  int32   `json:"author_id"`
 	FirstName string  `json:"first_name"`
@@ -231,7 +617,7 @@ type FindAuthorByIDRow struct {
 // FindAuthorByID implements Querier.FindAuthorByID.
 func (q *DBQuerier) FindAuthorByID(ctx context.Context, authorID int32) (FindAuthorByIDRow, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "FindAuthorByID")
-	row := q.conn.QueryRow(ctx, findAuthorByIDSQL, authorID)
+	row := q.conn.QueryRow(ctx, q.chooseSQL(findAuthorByIDSQL, findAuthorByIDStmt), authorID)
 	var item FindAuthorByIDRow
 	if err := row.Scan(&item.AuthorID, &item.FirstName, &item.LastName, &item.Suffix); err != nil {
 		return item, fmt.Errorf("query FindAuthorByID: %w", err)
@@ -241,7 +627,7 @@ func (q *DBQuerier) FindAuthorByID(ctx context.Context, authorID int32) (FindAut
 
 // FindAuthorByIDBatch implements Querier.FindAuthorByIDBatch.
 func (q *DBQuerier) FindAuthorByIDBatch(batch genericBatch, authorID int32) {
-	batch.Queue(findAuthorByIDSQL, authorID)
+	batch.Queue(q.chooseSQL(findAuthorByIDSQL, findAuthorByIDStmt), authorID)
 }
 
 // FindAuthorByIDScan implements Querier.FindAuthorByIDScan.
@@ -254,8 +640,40 @@ func (q *DBQuerier) FindAuthorByIDScan(results pgx.BatchResults) (FindAuthorByID
 	return item, nil
 }
 
+// FindAuthorByID queues a FindAuthorByID query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) FindAuthorByID(authorID int32) *FindAuthorByIDHandle {
+	b.q.FindAuthorByIDBatch(b.batch, authorID)
+	h := &FindAuthorByIDHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// FindAuthorByIDHandle is the result of a FindAuthorByID query queued in a Batch.
+type FindAuthorByIDHandle struct {
+	b   *Batch
+	res FindAuthorByIDRow
+	err error
+}
+
+func (h *FindAuthorByIDHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.FindAuthorByIDScan(results)
+	return h.err
+}
+
+// Result returns the result of the FindAuthorByID query. Returns an error if the
+// batch wasn't sent.
+func (h *FindAuthorByIDHandle) Result() (FindAuthorByIDRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("FindAuthorByID result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const findAuthorsSQL = `SELECT * FROM author WHERE first_name = $1;`
 
+const findAuthorsStmt = "pggen_FindAuthors_31f1ba279d72a4f5"
+
 type FindAuthorsRow struct {
 	AuthorID  int32   `json:"author_id"`
 	FirstName string  `json:"first_name"`
@@ -266,7 +684,7 @@ type FindAuthorsRow struct {
 // FindAuthors implements Querier.FindAuthors.
 func (q *DBQuerier) FindAuthors(ctx context.Context, firstName string) ([]FindAuthorsRow, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "FindAuthors")
-	rows, err := q.conn.Query(ctx, findAuthorsSQL, firstName)
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(findAuthorsSQL, findAuthorsStmt), firstName)
 	if err != nil {
 		return nil, fmt.Errorf("query FindAuthors: %w", err)
 	}
@@ -287,7 +705,7 @@ func (q *DBQuerier) FindAuthors(ctx context.Context, firstName string) ([]FindAu
 
 // FindAuthorsBatch implements Querier.FindAuthorsBatch.
 func (q *DBQuerier) FindAuthorsBatch(batch genericBatch, firstName string) {
-	batch.Queue(findAuthorsSQL, firstName)
+	batch.Queue(q.chooseSQL(findAuthorsSQL, findAuthorsStmt), firstName)
 }
 
 // FindAuthorsScan implements Querier.FindAuthorsScan.
@@ -311,8 +729,40 @@ func (q *DBQuerier) FindAuthorsScan(results pgx.BatchResults) ([]FindAuthorsRow,
 	return items, err
 }
 
+// FindAuthors queues a FindAuthors query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) FindAuthors(firstName string) *FindAuthorsHandle {
+	b.q.FindAuthorsBatch(b.batch, firstName)
+	h := &FindAuthorsHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// FindAuthorsHandle is the result of a FindAuthors query queued in a Batch.
+type FindAuthorsHandle struct {
+	b   *Batch
+	res []FindAuthorsRow
+	err error
+}
+
+func (h *FindAuthorsHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.FindAuthorsScan(results)
+	return h.err
+}
+
+// Result returns the result of the FindAuthors query. Returns an error if the
+// batch wasn't sent.
+func (h *FindAuthorsHandle) Result() ([]FindAuthorsRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("FindAuthors result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const findAuthorNamesSQL = `SELECT first_name, last_name FROM author ORDER BY author_id = $1;`
 
+const findAuthorNamesStmt = "pggen_FindAuthorNames_20c52ca8d01446b5"
+
 type FindAuthorNamesRow struct {
 	FirstName *string `json:"first_name"`
 	LastName  *string `json:"last_name"`
@@ -321,7 +771,7 @@ type FindAuthorNamesRow struct {
 // FindAuthorNames implements Querier.FindAuthorNames.
 func (q *DBQuerier) FindAuthorNames(ctx context.Context, authorID int32) ([]FindAuthorNamesRow, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "FindAuthorNames")
-	rows, err := q.conn.Query(ctx, findAuthorNamesSQL, authorID)
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(findAuthorNamesSQL, findAuthorNamesStmt), authorID)
 	if err != nil {
 		return nil, fmt.Errorf("query FindAuthorNames: %w", err)
 	}
@@ -342,7 +792,7 @@ func (q *DBQuerier) FindAuthorNames(ctx context.Context, authorID int32) ([]Find
 
 // FindAuthorNamesBatch implements Querier.FindAuthorNamesBatch.
 func (q *DBQuerier) FindAuthorNamesBatch(batch genericBatch, authorID int32) {
-	batch.Queue(findAuthorNamesSQL, authorID)
+	batch.Queue(q.chooseSQL(findAuthorNamesSQL, findAuthorNamesStmt), authorID)
 }
 
 // FindAuthorNamesScan implements Querier.FindAuthorNamesScan.
@@ -366,12 +816,44 @@ func (q *DBQuerier) FindAuthorNamesScan(results pgx.BatchResults) ([]FindAuthorN
 	return items, err
 }
 
+// FindAuthorNames queues a FindAuthorNames query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) FindAuthorNames(authorID int32) *FindAuthorNamesHandle {
+	b.q.FindAuthorNamesBatch(b.batch, authorID)
+	h := &FindAuthorNamesHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// FindAuthorNamesHandle is the result of a FindAuthorNames query queued in a Batch.
+type FindAuthorNamesHandle struct {
+	b   *Batch
+	res []FindAuthorNamesRow
+	err error
+}
+
+func (h *FindAuthorNamesHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.FindAuthorNamesScan(results)
+	return h.err
+}
+
+// Result returns the result of the FindAuthorNames query. Returns an error if the
+// batch wasn't sent.
+func (h *FindAuthorNamesHandle) Result() ([]FindAuthorNamesRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("FindAuthorNames result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const deleteAuthorsSQL = `DELETE FROM author WHERE first_name = 'joe';`
 
+const deleteAuthorsStmt = "pggen_DeleteAuthors_5aa1877b53f51e19"
+
 // DeleteAuthors implements Querier.DeleteAuthors.
 func (q *DBQuerier) DeleteAuthors(ctx context.Context) (pgconn.CommandTag, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "DeleteAuthors")
-	cmdTag, err := q.conn.Exec(ctx, deleteAuthorsSQL)
+	cmdTag, err := q.conn.Exec(ctx, q.chooseSQL(deleteAuthorsSQL, deleteAuthorsStmt))
 	if err != nil {
 		return cmdTag, fmt.Errorf("exec query DeleteAuthors: %w", err)
 	}
@@ -380,7 +862,7 @@ func (q *DBQuerier) DeleteAuthors(ctx context.Context) (pgconn.CommandTag, error
 
 // DeleteAuthorsBatch implements Querier.DeleteAuthorsBatch.
 func (q *DBQuerier) DeleteAuthorsBatch(batch genericBatch) {
-	batch.Queue(deleteAuthorsSQL)
+	batch.Queue(q.chooseSQL(deleteAuthorsSQL, deleteAuthorsStmt))
 }
 
 // DeleteAuthorsScan implements Querier.DeleteAuthorsScan.
@@ -392,12 +874,44 @@ func (q *DBQuerier) DeleteAuthorsScan(results pgx.BatchResults) (pgconn.CommandT
 	return cmdTag, err
 }
 
+// DeleteAuthors queues a DeleteAuthors query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) DeleteAuthors() *DeleteAuthorsHandle {
+	b.q.DeleteAuthorsBatch(b.batch)
+	h := &DeleteAuthorsHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// DeleteAuthorsHandle is the result of a DeleteAuthors query queued in a Batch.
+type DeleteAuthorsHandle struct {
+	b   *Batch
+	res pgconn.CommandTag
+	err error
+}
+
+func (h *DeleteAuthorsHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.DeleteAuthorsScan(results)
+	return h.err
+}
+
+// Result returns the result of the DeleteAuthors query. Returns an error if the
+// batch wasn't sent.
+func (h *DeleteAuthorsHandle) Result() (pgconn.CommandTag, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("DeleteAuthors result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const deleteAuthorsByFirstNameSQL = `DELETE FROM author WHERE first_name = $1;`
 
+const deleteAuthorsByFirstNameStmt = "pggen_DeleteAuthorsByFirstName_a1d24c8dc954fa73"
+
 // DeleteAuthorsByFirstName implements Querier.DeleteAuthorsByFirstName.
 func (q *DBQuerier) DeleteAuthorsByFirstName(ctx context.Context, firstName string) (pgconn.CommandTag, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "DeleteAuthorsByFirstName")
-	cmdTag, err := q.conn.Exec(ctx, deleteAuthorsByFirstNameSQL, firstName)
+	cmdTag, err := q.conn.Exec(ctx, q.chooseSQL(deleteAuthorsByFirstNameSQL, deleteAuthorsByFirstNameStmt), firstName)
 	if err != nil {
 		return cmdTag, fmt.Errorf("exec query DeleteAuthorsByFirstName: %w", err)
 	}
@@ -406,7 +920,7 @@ func (q *DBQuerier) DeleteAuthorsByFirstName(ctx context.Context, firstName stri
 
 // DeleteAuthorsByFirstNameBatch implements Querier.DeleteAuthorsByFirstNameBatch.
 func (q *DBQuerier) DeleteAuthorsByFirstNameBatch(batch genericBatch, firstName string) {
-	batch.Queue(deleteAuthorsByFirstNameSQL, firstName)
+	batch.Queue(q.chooseSQL(deleteAuthorsByFirstNameSQL, deleteAuthorsByFirstNameStmt), firstName)
 }
 
 // DeleteAuthorsByFirstNameScan implements Querier.DeleteAuthorsByFirstNameScan.
@@ -418,12 +932,44 @@ func (q *DBQuerier) DeleteAuthorsByFirstNameScan(results pgx.BatchResults) (pgco
 	return cmdTag, err
 }
 
+// DeleteAuthorsByFirstName queues a DeleteAuthorsByFirstName query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) DeleteAuthorsByFirstName(firstName string) *DeleteAuthorsByFirstNameHandle {
+	b.q.DeleteAuthorsByFirstNameBatch(b.batch, firstName)
+	h := &DeleteAuthorsByFirstNameHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// DeleteAuthorsByFirstNameHandle is the result of a DeleteAuthorsByFirstName query queued in a Batch.
+type DeleteAuthorsByFirstNameHandle struct {
+	b   *Batch
+	res pgconn.CommandTag
+	err error
+}
+
+func (h *DeleteAuthorsByFirstNameHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.DeleteAuthorsByFirstNameScan(results)
+	return h.err
+}
+
+// Result returns the result of the DeleteAuthorsByFirstName query. Returns an error if the
+// batch wasn't sent.
+func (h *DeleteAuthorsByFirstNameHandle) Result() (pgconn.CommandTag, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("DeleteAuthorsByFirstName result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const deleteAuthorsByFullNameSQL = `DELETE
 FROM author
 WHERE first_name = $1
   AND last_name = $2
   AND suffix = $3;`
 
+const deleteAuthorsByFullNameStmt = "pggen_DeleteAuthorsByFullName_2b18d63e3903bc4f"
+
 type DeleteAuthorsByFullNameParams struct {
 	FirstName string
 	LastName  string
@@ -433,7 +979,7 @@ type DeleteAuthorsByFullNameParams struct {
 // DeleteAuthorsByFullName implements Querier.DeleteAuthorsByFullName.
 func (q *DBQuerier) DeleteAuthorsByFullName(ctx context.Context, params DeleteAuthorsByFullNameParams) (pgconn.CommandTag, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "DeleteAuthorsByFullName")
-	cmdTag, err := q.conn.Exec(ctx, deleteAuthorsByFullNameSQL, params.FirstName, params.LastName, params.Suffix)
+	cmdTag, err := q.conn.Exec(ctx, q.chooseSQL(deleteAuthorsByFullNameSQL, deleteAuthorsByFullNameStmt), params.FirstName, params.LastName, params.Suffix)
 	if err != nil {
 		return cmdTag, fmt.Errorf("exec query DeleteAuthorsByFullName: %w", err)
 	}
@@ -442,7 +988,7 @@ func (q *DBQuerier) DeleteAuthorsByFullName(ctx context.Context, params DeleteAu
 
 // DeleteAuthorsByFullNameBatch implements Querier.DeleteAuthorsByFullNameBatch.
 func (q *DBQuerier) DeleteAuthorsByFullNameBatch(batch genericBatch, params DeleteAuthorsByFullNameParams) {
-	batch.Queue(deleteAuthorsByFullNameSQL, params.FirstName, params.LastName, params.Suffix)
+	batch.Queue(q.chooseSQL(deleteAuthorsByFullNameSQL, deleteAuthorsByFullNameStmt), params.FirstName, params.LastName, params.Suffix)
 }
 
 // DeleteAuthorsByFullNameScan implements Querier.DeleteAuthorsByFullNameScan.
@@ -454,14 +1000,46 @@ func (q *DBQuerier) DeleteAuthorsByFullNameScan(results pgx.BatchResults) (pgcon
 	return cmdTag, err
 }
 
+// DeleteAuthorsByFullName queues a DeleteAuthorsByFullName query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) DeleteAuthorsByFullName(params DeleteAuthorsByFullNameParams) *DeleteAuthorsByFullNameHandle {
+	b.q.DeleteAuthorsByFullNameBatch(b.batch, params)
+	h := &DeleteAuthorsByFullNameHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// DeleteAuthorsByFullNameHandle is the result of a DeleteAuthorsByFullName query queued in a Batch.
+type DeleteAuthorsByFullNameHandle struct {
+	b   *Batch
+	res pgconn.CommandTag
+	err error
+}
+
+func (h *DeleteAuthorsByFullNameHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.DeleteAuthorsByFullNameScan(results)
+	return h.err
+}
+
+// Result returns the result of the DeleteAuthorsByFullName query. Returns an error if the
+// batch wasn't sent.
+func (h *DeleteAuthorsByFullNameHandle) Result() (pgconn.CommandTag, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("DeleteAuthorsByFullName result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const insertAuthorSQL = `INSERT INTO author (first_name, last_name)
 VALUES ($1, $2)
 RETURNING author_id;`
 
+const insertAuthorStmt = "pggen_InsertAuthor_8dd32d8b57ac36b3"
+
 // InsertAuthor implements Querier.InsertAuthor.
 func (q *DBQuerier) InsertAuthor(ctx context.Context, firstName string, lastName string) (int32, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "InsertAuthor")
-	row := q.conn.QueryRow(ctx, insertAuthorSQL, firstName, lastName)
+	row := q.conn.QueryRow(ctx, q.chooseSQL(insertAuthorSQL, insertAuthorStmt), firstName, lastName)
 	var item int32
 	if err := row.Scan(&item); err != nil {
 		return item, fmt.Errorf("query InsertAuthor: %w", err)
@@ -471,7 +1049,7 @@ func (q *DBQuerier) InsertAuthor(ctx context.Context, firstName string, lastName
 
 // InsertAuthorBatch implements Querier.InsertAuthorBatch.
 func (q *DBQuerier) InsertAuthorBatch(batch genericBatch, firstName string, lastName string) {
-	batch.Queue(insertAuthorSQL, firstName, lastName)
+	batch.Queue(q.chooseSQL(insertAuthorSQL, insertAuthorStmt), firstName, lastName)
 }
 
 // InsertAuthorScan implements Querier.InsertAuthorScan.
@@ -484,10 +1062,42 @@ func (q *DBQuerier) InsertAuthorScan(results pgx.BatchResults) (int32, error) {
 	return item, nil
 }
 
+// InsertAuthor queues a InsertAuthor query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) InsertAuthor(firstName string, lastName string) *InsertAuthorHandle {
+	b.q.InsertAuthorBatch(b.batch, firstName, lastName)
+	h := &InsertAuthorHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// InsertAuthorHandle is the result of a InsertAuthor query queued in a Batch.
+type InsertAuthorHandle struct {
+	b   *Batch
+	res int32
+	err error
+}
+
+func (h *InsertAuthorHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.InsertAuthorScan(results)
+	return h.err
+}
+
+// Result returns the result of the InsertAuthor query. Returns an error if the
+// batch wasn't sent.
+func (h *InsertAuthorHandle) Result() (int32, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("InsertAuthor result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const insertAuthorSuffixSQL = `INSERT INTO author (first_name, last_name, suffix)
 VALUES ($1, $2, $3)
 RETURNING author_id, first_name, last_name, suffix;`
 
+const insertAuthorSuffixStmt = "pggen_InsertAuthorSuffix_be6cd8d34e2c4c0e"
+
 type InsertAuthorSuffixParams struct {
 	FirstName string
 	LastName  string
@@ -504,7 +1114,7 @@ type InsertAuthorSuffixRow struct {
 // InsertAuthorSuffix implements Querier.InsertAuthorSuffix.
 func (q *DBQuerier) InsertAuthorSuffix(ctx context.Context, params InsertAuthorSuffixParams) (InsertAuthorSuffixRow, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "InsertAuthorSuffix")
-	row := q.conn.QueryRow(ctx, insertAuthorSuffixSQL, params.FirstName, params.LastName, params.Suffix)
+	row := q.conn.QueryRow(ctx, q.chooseSQL(insertAuthorSuffixSQL, insertAuthorSuffixStmt), params.FirstName, params.LastName, params.Suffix)
 	var item InsertAuthorSuffixRow
 	if err := row.Scan(&item.AuthorID, &item.FirstName, &item.LastName, &item.Suffix); err != nil {
 		return item, fmt.Errorf("query InsertAuthorSuffix: %w", err)
@@ -514,7 +1124,7 @@ func (q *DBQuerier) InsertAuthorSuffix(ctx context.Context, params InsertAuthorS
 
 // InsertAuthorSuffixBatch implements Querier.InsertAuthorSuffixBatch.
 func (q *DBQuerier) InsertAuthorSuffixBatch(batch genericBatch, params InsertAuthorSuffixParams) {
-	batch.Queue(insertAuthorSuffixSQL, params.FirstName, params.LastName, params.Suffix)
+	batch.Queue(q.chooseSQL(insertAuthorSuffixSQL, insertAuthorSuffixStmt), params.FirstName, params.LastName, params.Suffix)
 }
 
 // InsertAuthorSuffixScan implements Querier.InsertAuthorSuffixScan.
@@ -527,6 +1137,196 @@ func (q *DBQuerier) InsertAuthorSuffixScan(results pgx.BatchResults) (InsertAuth
 	return item, nil
 }
 
+// InsertAuthorSuffix queues a InsertAuthorSuffix query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) InsertAuthorSuffix(params InsertAuthorSuffixParams) *InsertAuthorSuffixHandle {
+	b.q.InsertAuthorSuffixBatch(b.batch, params)
+	h := &InsertAuthorSuffixHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// InsertAuthorSuffixHandle is the result of a InsertAuthorSuffix query queued in a Batch.
+type InsertAuthorSuffixHandle struct {
+	b   *Batch
+	res InsertAuthorSuffixRow
+	err error
+}
+
+func (h *InsertAuthorSuffixHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.InsertAuthorSuffixScan(results)
+	return h.err
+}
+
+// Result returns the result of the InsertAuthorSuffix query. Returns an error if the
+// batch wasn't sent.
+func (h *InsertAuthorSuffixHandle) Result() (InsertAuthorSuffixRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("InsertAuthorSuffix result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
+const findAuthorsPageSQL = `SELECT * FROM (
+SELECT author_id, first_name, last_name
+FROM author
+WHERE first_name = $1
+ORDER BY author_id
+) pggen_keyset
+WHERE $2::bool IS NOT TRUE OR ("author_id") > ($3)
+ORDER BY "author_id"
+LIMIT $4;`
+
+const findAuthorsPageStmt = "pggen_FindAuthorsPage_109a1dd169e28296"
+
+type FindAuthorsPageParams struct {
+	FirstName string
+	Cursor    FindAuthorsPageCursor
+	Limit     int
+}
+
+type FindAuthorsPageRow struct {
+	AuthorID  int32  `json:"author_id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+// FindAuthorsPageCursor is an opaque cursor for keyset pagination.
+// The zero value starts at the first page. Use MarshalText to encode the
+// cursor as a URL-safe string.
+type FindAuthorsPageCursor struct {
+	isSet    bool
+	authorID int32
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (c FindAuthorsPageCursor) MarshalText() ([]byte, error) {
+	if !c.isSet {
+		return []byte{}, nil
+	}
+	keys, err := json.Marshal([]interface{}{c.authorID})
+	if err != nil {
+		return nil, fmt.Errorf("marshal FindAuthorsPageCursor: %w", err)
+	}
+	return []byte(base64.RawURLEncoding.EncodeToString(keys)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (c *FindAuthorsPageCursor) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*c = FindAuthorsPageCursor{}
+		return nil
+	}
+	keys, err := base64.RawURLEncoding.DecodeString(string(text))
+	if err != nil {
+		return fmt.Errorf("unmarshal FindAuthorsPageCursor: %w", err)
+	}
+	var raws []json.RawMessage
+	if err := json.Unmarshal(keys, &raws); err != nil {
+		return fmt.Errorf("unmarshal FindAuthorsPageCursor: %w", err)
+	}
+	if len(raws) != 1 {
+		return fmt.Errorf("unmarshal FindAuthorsPageCursor: want 1 key(s); got %d", len(raws))
+	}
+	cursor := FindAuthorsPageCursor{isSet: true}
+	if err := json.Unmarshal(raws[0], &cursor.authorID); err != nil {
+		return fmt.Errorf("unmarshal FindAuthorsPageCursor key author_id: %w", err)
+	}
+	*c = cursor
+	return nil
+}
+
+// FindAuthorsPageNextCursor returns the cursor for the page after rows.
+// Returns false if rows is empty.
+func FindAuthorsPageNextCursor(rows []FindAuthorsPageRow) (FindAuthorsPageCursor, bool) {
+	if len(rows) == 0 {
+		return FindAuthorsPageCursor{}, false
+	}
+	last := rows[len(rows)-1]
+	return FindAuthorsPageCursor{
+		isSet:    true,
+		authorID: last.AuthorID,
+	}, true
+}
+
+// FindAuthorsPage implements Querier.FindAuthorsPage.
+func (q *DBQuerier) FindAuthorsPage(ctx context.Context, params FindAuthorsPageParams) ([]FindAuthorsPageRow, error) {
+	ctx = context.WithValue(ctx, "pggen_query_name", "FindAuthorsPage")
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(findAuthorsPageSQL, findAuthorsPageStmt), params.FirstName, params.Cursor.isSet, params.Cursor.authorID, params.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("query FindAuthorsPage: %w", err)
+	}
+	defer rows.Close()
+	items := []FindAuthorsPageRow{}
+	for rows.Next() {
+		var item FindAuthorsPageRow
+		if err := rows.Scan(&item.AuthorID, &item.FirstName, &item.LastName); err != nil {
+			return nil, fmt.Errorf("scan FindAuthorsPage row: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("close FindAuthorsPage rows: %w", err)
+	}
+	return items, err
+}
+
+// FindAuthorsPageBatch implements Querier.FindAuthorsPageBatch.
+func (q *DBQuerier) FindAuthorsPageBatch(batch genericBatch, params FindAuthorsPageParams) {
+	batch.Queue(q.chooseSQL(findAuthorsPageSQL, findAuthorsPageStmt), params.FirstName, params.Cursor.isSet, params.Cursor.authorID, params.Limit)
+}
+
+// FindAuthorsPageScan implements Querier.FindAuthorsPageScan.
+func (q *DBQuerier) FindAuthorsPageScan(results pgx.BatchResults) ([]FindAuthorsPageRow, error) {
+	rows, err := results.Query()
+	if err != nil {
+		return nil, fmt.Errorf("query FindAuthorsPageBatch: %w", err)
+	}
+	defer rows.Close()
+	items := []FindAuthorsPageRow{}
+	for rows.Next() {
+		var item FindAuthorsPageRow
+		if err := rows.Scan(&item.AuthorID, &item.FirstName, &item.LastName); err != nil {
+			return nil, fmt.Errorf("scan FindAuthorsPageBatch row: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("close FindAuthorsPageBatch rows: %w", err)
+	}
+	return items, err
+}
+
+// FindAuthorsPage queues a FindAuthorsPage query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) FindAuthorsPage(params FindAuthorsPageParams) *FindAuthorsPageHandle {
+	b.q.FindAuthorsPageBatch(b.batch, params)
+	h := &FindAuthorsPageHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// FindAuthorsPageHandle is the result of a FindAuthorsPage query queued in a Batch.
+type FindAuthorsPageHandle struct {
+	b   *Batch
+	res []FindAuthorsPageRow
+	err error
+}
+
+func (h *FindAuthorsPageHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.FindAuthorsPageScan(results)
+	return h.err
+}
+
+// Result returns the result of the FindAuthorsPage query. Returns an error if the
+// batch wasn't sent.
+func (h *FindAuthorsPageHandle) Result() ([]FindAuthorsPageRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("FindAuthorsPage result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 // textPreferrer wraps a pgtype.ValueTranscoder and sets the preferred encoding
 // format to text instead binary (the default). pggen uses the text format
 // when the OID is unknownOID because the binary format requires the OID.
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"testing"
 
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 	"github.com/jschaf/pggen/internal/pgtest"
 	"github.com/stretchr/testify/assert"
@@ -113,7 +114,7 @@ func TestNewQuerier_FindAuthors_PrepareAllQueries(t *testing.T) {
 	conn, cleanup := pgtest.NewPostgresSchema(t, []string{"schema.sql"},
 		pgtest.WithGuardedStmtCache(findAuthorsSQL))
 	defer cleanup()
-	q := NewQuerier(conn)
+	q := NewQuerierConfig(conn, QuerierConfig{UsePreparedStatements: true})
 	adamsID := insertAuthor(t, q, "john", "adams")
 
 	t.Run("PrepareAllQueries", func(t *testing.T) {
@@ -299,6 +300,255 @@ func TestNewQuerier_DeleteAuthorsByFullNameBatch(t *testing.T) {
 	})
 }
 
+func TestNewQuerier_RunInTx(t *testing.T) {
+	conn, cleanup := pgtest.NewPostgresSchema(t, []string{"schema.sql"})
+	defer cleanup()
+	q := NewQuerier(conn)
+	ctx := context.Background()
+
+	t.Run("commit", func(t *testing.T) {
+		err := q.RunInTx(ctx, TxOptions{}, func(tq Querier) error {
+			_, err := tq.InsertAuthor(ctx, "john", "adams")
+			return err
+		})
+		require.NoError(t, err)
+		authors, err := q.FindAuthors(ctx, "john")
+		require.NoError(t, err)
+		assert.Len(t, authors, 1)
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		errRollback := errors.New("rollback")
+		err := q.RunInTx(ctx, TxOptions{}, func(tq Querier) error {
+			if _, err := tq.InsertAuthor(ctx, "james", "madison"); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		assert.True(t, errors.Is(err, errRollback), "expected rollback error; got %v", err)
+		authors, err := q.FindAuthors(ctx, "james")
+		require.NoError(t, err)
+		assert.Empty(t, authors)
+	})
+
+	t.Run("nested savepoint rollback", func(t *testing.T) {
+		errRollback := errors.New("rollback")
+		err := q.RunInTx(ctx, TxOptions{}, func(tq Querier) error {
+			if _, err := tq.InsertAuthor(ctx, "thomas", "jefferson"); err != nil {
+				return err
+			}
+			nestedErr := tq.RunInTx(ctx, TxOptions{}, func(nq Querier) error {
+				if _, err := nq.InsertAuthor(ctx, "thomas", "paine"); err != nil {
+					return err
+				}
+				return errRollback
+			})
+			assert.True(t, errors.Is(nestedErr, errRollback), "expected rollback error; got %v", nestedErr)
+			return nil
+		})
+		require.NoError(t, err)
+		authors, err := q.FindAuthors(ctx, "thomas")
+		require.NoError(t, err)
+		require.Len(t, authors, 1)
+		assert.Equal(t, "jefferson", authors[0].LastName)
+	})
+
+	t.Run("retry serialization failure", func(t *testing.T) {
+		attempts := 0
+		err := q.RunInTx(ctx, TxOptions{MaxRetries: 2}, func(tq Querier) error {
+			attempts++
+			if attempts < 3 {
+				return &pgconn.PgError{Code: "40001"}
+			}
+			_, err := tq.InsertAuthor(ctx, "alexander", "hamilton")
+			return err
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("retry exhausted", func(t *testing.T) {
+		attempts := 0
+		err := q.RunInTx(ctx, TxOptions{MaxRetries: 1}, func(Querier) error {
+			attempts++
+			return &pgconn.PgError{Code: "40P01"}
+		})
+		var pgErr *pgconn.PgError
+		require.True(t, errors.As(err, &pgErr), "expected *pgconn.PgError; got %v", err)
+		assert.Equal(t, 2, attempts)
+	})
+}
+
+func TestNewQuerier_FindAuthorsPage(t *testing.T) {
+	conn, cleanup := pgtest.NewPostgresSchema(t, []string{"schema.sql"})
+	defer cleanup()
+	q := NewQuerier(conn)
+	ctx := context.Background()
+	washingtonID := insertAuthor(t, q, "george", "washington")
+	_ = insertAuthor(t, q, "john", "adams")
+	carverID := insertAuthor(t, q, "george", "carver")
+	harrisonID := insertAuthor(t, q, "george", "harrison")
+
+	page1, err := q.FindAuthorsPage(ctx, FindAuthorsPageParams{FirstName: "george", Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []FindAuthorsPageRow{
+		{AuthorID: washingtonID, FirstName: "george", LastName: "washington"},
+		{AuthorID: carverID, FirstName: "george", LastName: "carver"},
+	}, page1)
+
+	cursor, ok := FindAuthorsPageNextCursor(page1)
+	require.True(t, ok, "expected next cursor for non-empty page")
+	text, err := cursor.MarshalText()
+	require.NoError(t, err)
+	var decoded FindAuthorsPageCursor
+	require.NoError(t, decoded.UnmarshalText(text))
+	assert.Equal(t, cursor, decoded)
+
+	page2, err := q.FindAuthorsPage(ctx, FindAuthorsPageParams{FirstName: "george", Cursor: decoded, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []FindAuthorsPageRow{
+		{AuthorID: harrisonID, FirstName: "george", LastName: "harrison"},
+	}, page2)
+
+	cursor, _ = FindAuthorsPageNextCursor(page2)
+	page3, err := q.FindAuthorsPage(ctx, FindAuthorsPageParams{FirstName: "george", Cursor: cursor, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []FindAuthorsPageRow{}, page3)
+	_, ok = FindAuthorsPageNextCursor(page3)
+	assert.False(t, ok, "expected no next cursor for empty page")
+
+	assert.Error(t, decoded.UnmarshalText([]byte("not a cursor")))
+}
+
+func TestNewQuerier_Batch(t *testing.T) {
+	conn, cleanup := pgtest.NewPostgresSchema(t, []string{"schema.sql"})
+	defer cleanup()
+	q := NewQuerier(conn)
+	ctx := context.Background()
+	adamsID := insertAuthor(t, q, "john", "adams")
+	insertAuthor(t, q, "george", "washington")
+
+	t.Run("Send", func(t *testing.T) {
+		b := q.NewBatch()
+		byID := b.FindAuthorByID(adamsID)
+		georges := b.FindAuthors("george")
+		deleted := b.DeleteAuthorsByFirstName("nobody")
+		require.NoError(t, b.Send(ctx))
+
+		author, err := byID.Result()
+		require.NoError(t, err)
+		assert.Equal(t, FindAuthorByIDRow{AuthorID: adamsID, FirstName: "john", LastName: "adams"}, author)
+		authors, err := georges.Result()
+		require.NoError(t, err)
+		require.Len(t, authors, 1)
+		assert.Equal(t, "washington", authors[0].LastName)
+		tag, err := deleted.Result()
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), tag.RowsAffected())
+	})
+
+	t.Run("Result before Send", func(t *testing.T) {
+		b := q.NewBatch()
+		h := b.FindAuthors("john")
+		_, err := h.Result()
+		assert.Error(t, err)
+	})
+
+	t.Run("Send twice", func(t *testing.T) {
+		b := q.NewBatch()
+		b.FindAuthors("john")
+		require.NoError(t, b.Send(ctx))
+		assert.Error(t, b.Send(ctx))
+	})
+
+	t.Run("Send returns all errors", func(t *testing.T) {
+		b := q.NewBatch()
+		missing := b.FindAuthorByID(888)
+		b.FindAuthors("john")
+		err := b.Send(ctx)
+		var batchErr BatchError
+		require.True(t, errors.As(err, &batchErr), "expected BatchError; got %v", err)
+		assert.True(t, errors.Is(err, pgx.ErrNoRows), "expected no rows error; got %v", err)
+		_, err = missing.Result()
+		assert.True(t, errors.Is(err, pgx.ErrNoRows), "expected no rows error; got %v", err)
+	})
+}
+
+func TestNewRoutingQuerier(t *testing.T) {
+	conn, cleanup := pgtest.NewPostgresSchema(t, []string{"schema.sql"})
+	defer cleanup()
+	ctx := context.Background()
+	primary := &countingConn{genericConn: conn}
+	replica := &countingConn{genericConn: conn}
+	q := NewRoutingQuerier(primary, replica)
+
+	t.Run("write uses primary", func(t *testing.T) {
+		_, err := q.InsertAuthor(ctx, "john", "adams")
+		require.NoError(t, err)
+		assert.Equal(t, 1, primary.reset())
+		assert.Equal(t, 0, replica.reset())
+	})
+
+	t.Run("read-only uses replica", func(t *testing.T) {
+		authors, err := q.FindAuthors(ctx, "john")
+		require.NoError(t, err)
+		require.Len(t, authors, 1)
+		assert.Equal(t, 0, primary.reset())
+		assert.Equal(t, 1, replica.reset())
+	})
+
+	t.Run("route=primary pragma uses primary", func(t *testing.T) {
+		authors, err := q.FindAuthors(ctx, "john")
+		require.NoError(t, err)
+		replica.reset()
+		_, err = q.FindAuthorByID(ctx, authors[0].AuthorID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, primary.reset())
+		assert.Equal(t, 0, replica.reset())
+	})
+
+	t.Run("tx uses primary", func(t *testing.T) {
+		err := q.RunInTx(ctx, TxOptions{}, func(tq Querier) error {
+			_, err := tq.FindAuthors(ctx, "john")
+			return err
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 0, replica.reset())
+	})
+}
+
+// countingConn counts the queries run on a genericConn.
+type countingConn struct {
+	genericConn
+	n int
+}
+
+func (c *countingConn) BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+	return c.genericConn.(txBeginner).BeginTx(ctx, opts)
+}
+
+func (c *countingConn) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	c.n++
+	return c.genericConn.Query(ctx, sql, args...)
+}
+
+func (c *countingConn) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	c.n++
+	return c.genericConn.QueryRow(ctx, sql, args...)
+}
+
+func (c *countingConn) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	c.n++
+	return c.genericConn.Exec(ctx, sql, args...)
+}
+
+// reset returns the number of queries run since the last reset.
+func (c *countingConn) reset() int {
+	n := c.n
+	c.n = 0
+	return n
+}
+
 func insertAuthor(t *testing.T, q *DBQuerier, first, last string) int32 {
 	t.Helper()
 	authorID, err := q.InsertAuthor(context.Background(), first, last)
@@ -4,10 +4,12 @@ package complex_params
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v4"
+	"time"
 )
 
 // Querier is a typesafe Go interface backed by SQL queries.
@@ -50,11 +52,17 @@ type Querier interface {
 	ParamNested3Batch(batch genericBatch, imageSet ProductImageSetType)
 	// ParamNested3Scan scans the result of an executed ParamNested3Batch query.
 	ParamNested3Scan(results pgx.BatchResults) (ProductImageSetType, error)
+
+	// RunInTx runs fn in a transaction using a Querier bound to the
+	// transaction. See DBQuerier.RunInTx.
+	RunInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error
 }
 
 type DBQuerier struct {
-	conn  genericConn   // underlying Postgres transport to use
-	types *typeResolver // resolve types by name
+	conn    genericConn   // underlying Postgres transport to use
+	replica genericConn   // transport for read-only queries; nil unless routing
+	types   *typeResolver // resolve types by name
+	cfg     QuerierConfig // config used to create the querier
 }
 
 var _ Querier = &DBQuerier{}
@@ -100,17 +108,362 @@ type QuerierConfig struct {
 	// transitive dependencies, pggen will use the binary encoding format for
 	// the input parameter.
 	DataTypes []pgtype.DataType
+
+	// UsePreparedStatements runs queries by the statement names prepared by
+	// PrepareAllQueries instead of by SQL text. Every connection used by the
+	// querier must run PrepareAllQueries first, typically in the AfterConnect
+	// callback of pgxpool.Config.
+	//
+	// If false, the default, queries run by SQL text, which works whether or
+	// not the connection prepared the statements.
+	UsePreparedStatements bool
+
+	// PgBouncerCompat enables compatibility with PgBouncer in transaction
+	// pooling mode, which doesn't support prepared statements. Each query runs
+	// with pgx.QuerySimpleProtocol, pggen-generated transcoders always encode
+	// in the text format, UsePreparedStatements is ignored, and
+	// PrepareAllQueriesConfig doesn't prepare any statements.
+	//
+	// pgx only sends a pgx.Batch with the simple protocol if
+	// PreferSimpleProtocol is set on pgx.ConnConfig, so Batch.Send runs the
+	// queued queries one at a time with the simple protocol instead of in a
+	// single network round-trip.
+	PgBouncerCompat bool
 }
 
 // NewQuerierConfig creates a DBQuerier that implements Querier with the given
 // config. conn is typically *pgx.Conn, pgx.Tx, or *pgxpool.Pool.
 func NewQuerierConfig(conn genericConn, cfg QuerierConfig) *DBQuerier {
-	return &DBQuerier{conn: conn, types: newTypeResolver(cfg.DataTypes)}
+	if cfg.PgBouncerCompat {
+		conn = simpleProtocolConn{conn}
+	}
+	return &DBQuerier{conn: conn, types: newTypeResolver(cfg.DataTypes, cfg.PgBouncerCompat), cfg: cfg}
 }
 
 // WithTx creates a new DBQuerier that uses the transaction to run all queries.
+// The returned DBQuerier keeps the config of q. If q is a routing querier, the
+// returned DBQuerier runs read-only queries in the transaction too.
 func (q *DBQuerier) WithTx(tx pgx.Tx) (*DBQuerier, error) {
-	return &DBQuerier{conn: tx}, nil
+	var conn genericConn = tx
+	if q.cfg.PgBouncerCompat {
+		conn = simpleProtocolConn{conn}
+	}
+	return &DBQuerier{conn: conn, types: q.types, cfg: q.cfg}, nil
+}
+
+// NewRoutingQuerier creates a DBQuerier that runs read-only queries on replica
+// and all other queries on primary. pggen classifies a query as read-only if
+// the query plan doesn't modify a table or lock rows. Queries with the
+// route=primary pragma, batches, and transactions always use primary.
+func NewRoutingQuerier(primary, replica genericConn) *DBQuerier {
+	return NewRoutingQuerierConfig(primary, replica, QuerierConfig{})
+}
+
+// NewRoutingQuerierConfig creates a DBQuerier like NewRoutingQuerier with the
+// given config. The config applies to both primary and replica.
+func NewRoutingQuerierConfig(primary, replica genericConn, cfg QuerierConfig) *DBQuerier {
+	q := NewQuerierConfig(primary, cfg)
+	if cfg.PgBouncerCompat {
+		replica = simpleProtocolConn{replica}
+	}
+	q.replica = replica
+	return q
+}
+
+// readConn returns the Postgres transport for read-only queries.
+func (q *DBQuerier) readConn() genericConn {
+	if q.replica == nil {
+		return q.conn
+	}
+	return q.replica
+}
+
+// simpleProtocolConn is a genericConn that runs all queries with the simple
+// protocol, overriding pgx.ConnConfig.PreferSimpleProtocol.
+type simpleProtocolConn struct {
+	genericConn
+}
+
+func (c simpleProtocolConn) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return c.genericConn.Query(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, args...)...)
+}
+
+func (c simpleProtocolConn) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return c.genericConn.QueryRow(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, args...)...)
+}
+
+func (c simpleProtocolConn) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	return c.genericConn.Exec(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, arguments...)...)
+}
+
+// transport returns the underlying Postgres transport of q, unwrapping any
+// wrapper added by the config.
+func (q *DBQuerier) transport() genericConn {
+	if c, ok := q.conn.(simpleProtocolConn); ok {
+		return c.genericConn
+	}
+	return q.conn
+}
+
+// txBeginner is any Postgres connection transport that can begin a
+// transaction, most commonly *pgx.Conn or *pgxpool.Pool.
+type txBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// TxOptions controls how RunInTx begins and retries a transaction.
+type TxOptions struct {
+	// TxOptions are the options used to begin a top-level transaction. Ignored
+	// for nested transactions, which use a savepoint.
+	pgx.TxOptions
+
+	// MaxRetries is the maximum number of times to retry a top-level
+	// transaction that failed with a serialization failure (SQLSTATE 40001) or
+	// a deadlock (SQLSTATE 40P01). Zero disables retries.
+	MaxRetries int
+
+	// Backoff returns how long to wait before the nth retry, starting at 1. If
+	// nil, retries immediately.
+	Backoff func(retry int) time.Duration
+}
+
+// RunInTx runs fn in a transaction. fn receives a Querier bound to the
+// transaction with the same config as q. If fn returns nil, RunInTx commits
+// the transaction; otherwise, RunInTx rolls back the transaction and returns
+// the error from fn.
+//
+// If q is already bound to a transaction, like the Querier passed to fn,
+// RunInTx creates a nested transaction using a savepoint. Only the top-level
+// transaction is retried because a serialization failure aborts the entire
+// transaction.
+func (q *DBQuerier) RunInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error {
+	_, isNested := q.transport().(pgx.Tx)
+	for retry := 1; ; retry++ {
+		err := q.runInTx(ctx, opts, fn)
+		if err == nil || isNested || retry > opts.MaxRetries || !isRetryableTxErr(err) {
+			return err
+		}
+		if opts.Backoff == nil {
+			continue
+		}
+		timer := time.NewTimer(opts.Backoff(retry))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("retry tx: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// runInTx runs a single attempt of fn in a transaction.
+func (q *DBQuerier) runInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error {
+	var tx pgx.Tx
+	var err error
+	switch conn := q.transport().(type) {
+	case pgx.Tx:
+		tx, err = conn.Begin(ctx) // savepoint
+	case txBeginner:
+		tx, err = conn.BeginTx(ctx, opts.TxOptions)
+	default:
+		return fmt.Errorf("begin tx: conn of type %T cannot begin a transaction", conn)
+	}
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	txq, _ := q.WithTx(tx)
+	if err := fn(txq); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			return fmt.Errorf("rollback tx: %v; original error: %w", rbErr, err)
+		}
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// isRetryableTxErr returns true if err is a serialization failure or deadlock
+// such that retrying the entire transaction might succeed.
+func isRetryableTxErr(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+// batchSender is any Postgres connection transport that can send a batch of
+// queries, most commonly *pgx.Conn, pgx.Tx, or *pgxpool.Pool.
+type batchSender interface {
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// Batch is a typed builder for a pgx.Batch. Each query method on Batch queues
+// the query and returns a handle. After calling Send, use Result on the handle
+// to get the typed result of the query.
+type Batch struct {
+	q       *DBQuerier
+	batch   queueBatch    // *pgx.Batch, or *simpleBatch with PgBouncerCompat
+	handles []batchHandle // handles in the same order as the queued queries
+	sent    bool
+}
+
+// batchHandle scans the result of a single query queued in a Batch.
+type batchHandle interface {
+	scan(results pgx.BatchResults) error
+}
+
+var errBatchNotSent = errors.New("batch not sent")
+
+// queueBatch is a genericBatch that counts the queued queries.
+type queueBatch interface {
+	genericBatch
+	Len() int
+}
+
+// NewBatch creates a typed Batch that sends queries using the conn of q.
+func (q *DBQuerier) NewBatch() *Batch {
+	if q.cfg.PgBouncerCompat {
+		return &Batch{q: q, batch: &simpleBatch{}}
+	}
+	return &Batch{q: q, batch: &pgx.Batch{}}
+}
+
+// Send sends all queued queries with SendBatch and scans the result of each
+// query into its handle in queue order. If any query fails, Send returns a
+// BatchError with the errors of all failed queries.
+func (b *Batch) Send(ctx context.Context) error {
+	if b.sent {
+		return errors.New("send batch: batch already sent")
+	}
+	if b.batch.Len() != len(b.handles) {
+		return fmt.Errorf("send batch: %d queued queries but %d handles", b.batch.Len(), len(b.handles))
+	}
+	var results pgx.BatchResults
+	switch batch := b.batch.(type) {
+	case *simpleBatch:
+		b.sent = true
+		results = &simpleBatchResults{ctx: ctx, conn: b.q.conn, queries: batch.queries}
+	case *pgx.Batch:
+		sender, ok := b.q.transport().(batchSender)
+		if !ok {
+			return fmt.Errorf("send batch: conn of type %T cannot send a batch", b.q.transport())
+		}
+		b.sent = true
+		results = sender.SendBatch(ctx, batch)
+	default:
+		return fmt.Errorf("send batch: unknown batch type %T", b.batch)
+	}
+	var errs []error
+	for _, h := range b.handles {
+		if err := h.scan(results); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := results.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("close batch results: %w", err))
+	}
+	if len(errs) > 0 {
+		return BatchError{Errs: errs}
+	}
+	return nil
+}
+
+// BatchError is the error returned by Batch.Send with the errors of all failed
+// queries in queue order.
+type BatchError struct {
+	Errs []error
+}
+
+func (e BatchError) Error() string {
+	msg := fmt.Sprintf("send batch: %d errors", len(e.Errs))
+	for _, err := range e.Errs {
+		msg += "; " + err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the first error.
+func (e BatchError) Unwrap() error { return e.Errs[0] }
+
+// simpleBatch is a genericBatch that records the queued queries so that
+// simpleBatchResults can run them with the simple protocol.
+type simpleBatch struct {
+	queries []simpleQuery
+}
+
+type simpleQuery struct {
+	sql  string
+	args []interface{}
+}
+
+func (b *simpleBatch) Queue(query string, arguments ...interface{}) {
+	b.queries = append(b.queries, simpleQuery{sql: query, args: arguments})
+}
+
+func (b *simpleBatch) Len() int { return len(b.queries) }
+
+// simpleBatchResults is a pgx.BatchResults that runs the next query of a
+// simpleBatch on conn when reading its result. conn is a simpleProtocolConn,
+// so each query runs with the simple protocol.
+type simpleBatchResults struct {
+	ctx     context.Context
+	conn    genericConn
+	queries []simpleQuery
+	idx     int // index of the next query
+}
+
+func (r *simpleBatchResults) next() (simpleQuery, error) {
+	if r.idx == len(r.queries) {
+		return simpleQuery{}, fmt.Errorf("no result for batch query %d", r.idx)
+	}
+	q := r.queries[r.idx]
+	r.idx++
+	return q, nil
+}
+
+func (r *simpleBatchResults) Exec() (pgconn.CommandTag, error) {
+	q, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	return r.conn.Exec(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) Query() (pgx.Rows, error) {
+	q, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	return r.conn.Query(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) QueryRow() pgx.Row {
+	q, err := r.next()
+	if err != nil {
+		return errRow{err: err}
+	}
+	return r.conn.QueryRow(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) Close() error { return nil }
+
+// errRow is a pgx.Row that returns err from Scan.
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(...interface{}) error { return r.err }
+
+// chooseSQL returns the name of the prepared statement if the querier uses
+// prepared statements. Otherwise, returns the SQL text.
+func (q *DBQuerier) chooseSQL(sql, stmtName string) string {
+	if q.cfg.UsePreparedStatements && !q.cfg.PgBouncerCompat {
+		return stmtName
+	}
+	return sql
 }
 
 // preparer is any Postgres connection transport that provides a way to prepare
@@ -123,28 +476,42 @@ type preparer interface {
 // queries in querier files. Typical usage is as the AfterConnect callback
 // for pgxpool.Config
 //
-// pgx will use the prepared statement if available. Calling PrepareAllQueries
-// is an optional optimization to avoid a network round-trip the first time pgx
-// runs a query if pgx statement caching is enabled.
+// Each statement is prepared under a deterministic name derived from the query
+// name and SQL text. A querier created with
+// QuerierConfig.UsePreparedStatements runs queries by those names, avoiding a
+// network round-trip to prepare the statement the first time pgx runs a query.
+//
+// Use PrepareAllQueriesConfig with the config of the querier to skip preparing
+// statements with QuerierConfig.PgBouncerCompat.
 func PrepareAllQueries(ctx context.Context, p preparer) error {
-	if _, err := p.Prepare(ctx, paramArrayIntSQL, paramArrayIntSQL); err != nil {
+	if _, err := p.Prepare(ctx, paramArrayIntStmt, paramArrayIntSQL); err != nil {
 		return fmt.Errorf("prepare query 'ParamArrayInt': %w", err)
 	}
-	if _, err := p.Prepare(ctx, paramNested1SQL, paramNested1SQL); err != nil {
+	if _, err := p.Prepare(ctx, paramNested1Stmt, paramNested1SQL); err != nil {
 		return fmt.Errorf("prepare query 'ParamNested1': %w", err)
 	}
-	if _, err := p.Prepare(ctx, paramNested2SQL, paramNested2SQL); err != nil {
+	if _, err := p.Prepare(ctx, paramNested2Stmt, paramNested2SQL); err != nil {
 		return fmt.Errorf("prepare query 'ParamNested2': %w", err)
 	}
-	if _, err := p.Prepare(ctx, paramNested2ArraySQL, paramNested2ArraySQL); err != nil {
+	if _, err := p.Prepare(ctx, paramNested2ArrayStmt, paramNested2ArraySQL); err != nil {
 		return fmt.Errorf("prepare query 'ParamNested2Array': %w", err)
 	}
-	if _, err := p.Prepare(ctx, paramNested3SQL, paramNested3SQL); err != nil {
+	if _, err := p.Prepare(ctx, paramNested3Stmt, paramNested3SQL); err != nil {
 		return fmt.Errorf("prepare query 'ParamNested3': %w", err)
 	}
 	return nil
 }
 
+// PrepareAllQueriesConfig is like PrepareAllQueries but doesn't prepare any
+// statements if cfg.PgBouncerCompat is set, because PgBouncer in transaction
+// pooling mode doesn't support prepared statements.
+func PrepareAllQueriesConfig(ctx context.Context, p preparer, cfg QuerierConfig) error {
+	if cfg.PgBouncerCompat {
+		return nil
+	}
+	return PrepareAllQueries(ctx, p)
+}
+
 // Dimensions represents the Postgres composite type "dimensions".
 type Dimensions struct {
 	Width  int `json:"width"`
@@ -166,18 +533,19 @@ type ProductImageType struct {
 
 // typeResolver looks up the pgtype.ValueTranscoder by Postgres type name.
 type typeResolver struct {
-	connInfo *pgtype.ConnInfo // types by Postgres type name
+	connInfo   *pgtype.ConnInfo // types by Postgres type name
+	preferText bool             // if true, always encode in the text format
 }
 
-func newTypeResolver(types []pgtype.DataType) *typeResolver {
+func newTypeResolver(types []pgtype.DataType, preferText bool) *typeResolver {
 	ci := pgtype.NewConnInfo()
 	for _, typ := range types {
-		if txt, ok := typ.Value.(textPreferrer); ok && typ.OID != unknownOID {
+		if txt, ok := typ.Value.(textPreferrer); ok && typ.OID != unknownOID && !preferText {
 			typ.Value = txt.ValueTranscoder
 		}
 		ci.RegisterDataType(typ)
 	}
-	return &typeResolver{connInfo: ci}
+	return &typeResolver{connInfo: ci, preferText: preferText}
 }
 
 // findValue find the OID, and pgtype.ValueTranscoder for a Postgres type name.
@@ -186,8 +554,11 @@ func (tr *typeResolver) findValue(name string) (uint32, pgtype.ValueTranscoder,
 	if !ok {
 		return 0, nil, false
 	}
-	v := pgtype.NewValue(typ.Value)
-	return typ.OID, v.(pgtype.ValueTranscoder), true
+	v := pgtype.NewValue(typ.Value).(pgtype.ValueTranscoder)
+	if _, isText := v.(textPreferrer); tr.preferText && !isText {
+		v = textPreferrer{v, name}
+	}
+	return typ.OID, v, true
 }
 
 // setValue sets the value of a ValueTranscoder to a value that should always
@@ -225,7 +596,7 @@ func (tr *typeResolver) newCompositeValue(name string, fields ...compositeField)
 	// Okay to ignore error because it's only thrown when the number of field
 	// names does not equal the number of ValueTranscoders.
 	typ, _ := pgtype.NewCompositeTypeValues(name, fs, vals)
-	if !isBinaryOk {
+	if !isBinaryOk || tr.preferText {
 		return textPreferrer{typ, name}
 	}
 	return typ
@@ -244,7 +615,7 @@ func (tr *typeResolver) newArrayValue(name, elemName string, defaultVal func() p
 		elemValFunc = defaultVal
 	}
 	typ := pgtype.NewArrayType(name, elemOID, elemValFunc)
-	if elemOID == unknownOID {
+	if elemOID == unknownOID || tr.preferText {
 		return textPreferrer{typ, name}
 	}
 	return typ
@@ -355,10 +726,12 @@ func (tr *typeResolver) newProductImageTypeArrayRaw(vs []ProductImageType) []int
 
 const paramArrayIntSQL = `SELECT $1::bigint[];`
 
+const paramArrayIntStmt = "pggen_ParamArrayInt_bb027cdd9e36d28d"
+
 // ParamArrayInt implements Querier.ParamArrayInt.
 func (q *DBQuerier) ParamArrayInt(ctx context.Context, ints []int) ([]int, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "ParamArrayInt")
-	row := q.conn.QueryRow(ctx, paramArrayIntSQL, ints)
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(paramArrayIntSQL, paramArrayIntStmt), ints)
 	item := []int{}
 	if err := row.Scan(&item); err != nil {
 		return item, fmt.Errorf("query ParamArrayInt: %w", err)
@@ -368,7 +741,7 @@ func (q *DBQuerier) ParamArrayInt(ctx context.Context, ints []int) ([]int, error
 
 // ParamArrayIntBatch implements Querier.ParamArrayIntBatch.
 func (q *DBQuerier) ParamArrayIntBatch(batch genericBatch, ints []int) {
-	batch.Queue(paramArrayIntSQL, ints)
+	batch.Queue(q.chooseSQL(paramArrayIntSQL, paramArrayIntStmt), ints)
 }
 
 // ParamArrayIntScan implements Querier.ParamArrayIntScan.
@@ -381,12 +754,44 @@ func (q *DBQuerier) ParamArrayIntScan(results pgx.BatchResults) ([]int, error) {
 	return item, nil
 }
 
+// ParamArrayInt queues a ParamArrayInt query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) ParamArrayInt(ints []int) *ParamArrayIntHandle {
+	b.q.ParamArrayIntBatch(b.batch, ints)
+	h := &ParamArrayIntHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// ParamArrayIntHandle is the result of a ParamArrayInt query queued in a Batch.
+type ParamArrayIntHandle struct {
+	b   *Batch
+	res []int
+	err error
+}
+
+func (h *ParamArrayIntHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.ParamArrayIntScan(results)
+	return h.err
+}
+
+// Result returns the result of the ParamArrayInt query. Returns an error if the
+// batch wasn't sent.
+func (h *ParamArrayIntHandle) Result() ([]int, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("ParamArrayInt result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const paramNested1SQL = `SELECT $1::dimensions;`
 
+const paramNested1Stmt = "pggen_ParamNested1_bbe9d793950df799"
+
 // ParamNested1 implements Querier.ParamNested1.
 func (q *DBQuerier) ParamNested1(ctx context.Context, dimensions Dimensions) (Dimensions, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "ParamNested1")
-	row := q.conn.QueryRow(ctx, paramNested1SQL, q.types.newDimensionsInit(dimensions))
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(paramNested1SQL, paramNested1Stmt), q.types.newDimensionsInit(dimensions))
 	var item Dimensions
 	dimensionsRow := q.types.newDimensions()
 	if err := row.Scan(dimensionsRow); err != nil {
@@ -400,7 +805,7 @@ func (q *DBQuerier) ParamNested1(ctx context.Context, dimensions Dimensions) (Di
 
 // ParamNested1Batch implements Querier.ParamNested1Batch.
 func (q *DBQuerier) ParamNested1Batch(batch genericBatch, dimensions Dimensions) {
-	batch.Queue(paramNested1SQL, q.types.newDimensionsInit(dimensions))
+	batch.Queue(q.chooseSQL(paramNested1SQL, paramNested1Stmt), q.types.newDimensionsInit(dimensions))
 }
 
 // ParamNested1Scan implements Querier.ParamNested1Scan.
@@ -417,12 +822,44 @@ func (q *DBQuerier) ParamNested1Scan(results pgx.BatchResults) (Dimensions, erro
 	return item, nil
 }
 
+// ParamNested1 queues a ParamNested1 query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) ParamNested1(dimensions Dimensions) *ParamNested1Handle {
+	b.q.ParamNested1Batch(b.batch, dimensions)
+	h := &ParamNested1Handle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// ParamNested1Handle is the result of a ParamNested1 query queued in a Batch.
+type ParamNested1Handle struct {
+	b   *Batch
+	res Dimensions
+	err error
+}
+
+func (h *ParamNested1Handle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.ParamNested1Scan(results)
+	return h.err
+}
+
+// Result returns the result of the ParamNested1 query. Returns an error if the
+// batch wasn't sent.
+func (h *ParamNested1Handle) Result() (Dimensions, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("ParamNested1 result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const paramNested2SQL = `SELECT $1::product_image_type;`
 
+const paramNested2Stmt = "pggen_ParamNested2_45d3da01956a9f9e"
+
 // ParamNested2 implements Querier.ParamNested2.
 func (q *DBQuerier) ParamNested2(ctx context.Context, image ProductImageType) (ProductImageType, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "ParamNested2")
-	row := q.conn.QueryRow(ctx, paramNested2SQL, q.types.newProductImageTypeInit(image))
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(paramNested2SQL, paramNested2Stmt), q.types.newProductImageTypeInit(image))
 	var item ProductImageType
 	productImageTypeRow := q.types.newProductImageType()
 	if err := row.Scan(productImageTypeRow); err != nil {
@@ -436,7 +873,7 @@ func (q *DBQuerier) ParamNested2(ctx context.Context, image ProductImageType) (P
 
 // ParamNested2Batch implements Querier.ParamNested2Batch.
 func (q *DBQuerier) ParamNested2Batch(batch genericBatch, image ProductImageType) {
-	batch.Queue(paramNested2SQL, q.types.newProductImageTypeInit(image))
+	batch.Queue(q.chooseSQL(paramNested2SQL, paramNested2Stmt), q.types.newProductImageTypeInit(image))
 }
 
 // ParamNested2Scan implements Querier.ParamNested2Scan.
@@ -453,12 +890,44 @@ func (q *DBQuerier) ParamNested2Scan(results pgx.BatchResults) (ProductImageType
 	return item, nil
 }
 
+// ParamNested2 queues a ParamNested2 query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) ParamNested2(image ProductImageType) *ParamNested2Handle {
+	b.q.ParamNested2Batch(b.batch, image)
+	h := &ParamNested2Handle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// ParamNested2Handle is the result of a ParamNested2 query queued in a Batch.
+type ParamNested2Handle struct {
+	b   *Batch
+	res ProductImageType
+	err error
+}
+
+func (h *ParamNested2Handle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.ParamNested2Scan(results)
+	return h.err
+}
+
+// Result returns the result of the ParamNested2 query. Returns an error if the
+// batch wasn't sent.
+func (h *ParamNested2Handle) Result() (ProductImageType, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("ParamNested2 result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const paramNested2ArraySQL = `SELECT $1::product_image_type[];`
 
+const paramNested2ArrayStmt = "pggen_ParamNested2Array_b28000eeceaa5a4a"
+
 // ParamNested2Array implements Querier.ParamNested2Array.
 func (q *DBQuerier) ParamNested2Array(ctx context.Context, images []ProductImageType) ([]ProductImageType, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "ParamNested2Array")
-	row := q.conn.QueryRow(ctx, paramNested2ArraySQL, q.types.newProductImageTypeArrayInit(images))
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(paramNested2ArraySQL, paramNested2ArrayStmt), q.types.newProductImageTypeArrayInit(images))
 	item := []ProductImageType{}
 	productImageTypeArray := q.types.newProductImageTypeArray()
 	if err := row.Scan(productImageTypeArray); err != nil {
@@ -472,7 +941,7 @@ func (q *DBQuerier) ParamNested2Array(ctx context.Context, images []ProductImage
 
 // ParamNested2ArrayBatch implements Querier.ParamNested2ArrayBatch.
 func (q *DBQuerier) ParamNested2ArrayBatch(batch genericBatch, images []ProductImageType) {
-	batch.Queue(paramNested2ArraySQL, q.types.newProductImageTypeArrayInit(images))
+	batch.Queue(q.chooseSQL(paramNested2ArraySQL, paramNested2ArrayStmt), q.types.newProductImageTypeArrayInit(images))
 }
 
 // ParamNested2ArrayScan implements Querier.ParamNested2ArrayScan.
@@ -489,12 +958,44 @@ func (q *DBQuerier) ParamNested2ArrayScan(results pgx.BatchResults) ([]ProductIm
 	return item, nil
 }
 
+// ParamNested2Array queues a ParamNested2Array query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) ParamNested2Array(images []ProductImageType) *ParamNested2ArrayHandle {
+	b.q.ParamNested2ArrayBatch(b.batch, images)
+	h := &ParamNested2ArrayHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// ParamNested2ArrayHandle is the result of a ParamNested2Array query queued in a Batch.
+type ParamNested2ArrayHandle struct {
+	b   *Batch
+	res []ProductImageType
+	err error
+}
+
+func (h *ParamNested2ArrayHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.ParamNested2ArrayScan(results)
+	return h.err
+}
+
+// Result returns the result of the ParamNested2Array query. Returns an error if the
+// batch wasn't sent.
+func (h *ParamNested2ArrayHandle) Result() ([]ProductImageType, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("ParamNested2Array result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const paramNested3SQL = `SELECT $1::product_image_set_type;`
 
+const paramNested3Stmt = "pggen_ParamNested3_8f8be3dffa89dd10"
+
 // ParamNested3 implements Querier.ParamNested3.
 func (q *DBQuerier) ParamNested3(ctx context.Context, imageSet ProductImageSetType) (ProductImageSetType, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "ParamNested3")
-	row := q.conn.QueryRow(ctx, paramNested3SQL, q.types.newProductImageSetTypeInit(imageSet))
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(paramNested3SQL, paramNested3Stmt), q.types.newProductImageSetTypeInit(imageSet))
 	var item ProductImageSetType
 	productImageSetTypeRow := q.types.newProductImageSetType()
 	if err := row.Scan(productImageSetTypeRow); err != nil {
@@ -508,7 +1009,7 @@ func (q *DBQuerier) ParamNested3(ctx context.Context, imageSet ProductImageSetTy
 
 // ParamNested3Batch implements Querier.ParamNested3Batch.
 func (q *DBQuerier) ParamNested3Batch(batch genericBatch, imageSet ProductImageSetType) {
-	batch.Queue(paramNested3SQL, q.types.newProductImageSetTypeInit(imageSet))
+	batch.Queue(q.chooseSQL(paramNested3SQL, paramNested3Stmt), q.types.newProductImageSetTypeInit(imageSet))
 }
 
 // ParamNested3Scan implements Querier.ParamNested3Scan.
@@ -525,6 +1026,36 @@ func (q *DBQuerier) ParamNested3Scan(results pgx.BatchResults) (ProductImageSetT
 	return item, nil
 }
 
+// ParamNested3 queues a ParamNested3 query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) ParamNested3(imageSet ProductImageSetType) *ParamNested3Handle {
+	b.q.ParamNested3Batch(b.batch, imageSet)
+	h := &ParamNested3Handle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// ParamNested3Handle is the result of a ParamNested3 query queued in a Batch.
+type ParamNested3Handle struct {
+	b   *Batch
+	res ProductImageSetType
+	err error
+}
+
+func (h *ParamNested3Handle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.ParamNested3Scan(results)
+	return h.err
+}
+
+// Result returns the result of the ParamNested3 query. Returns an error if the
+// batch wasn't sent.
+func (h *ParamNested3Handle) Result() (ProductImageSetType, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("ParamNested3 result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 // textPreferrer wraps a pgtype.ValueTranscoder and sets the preferred encoding
 // format to text instead binary (the default). pggen uses the text format
 // when the OID is unknownOID because the binary format requires the OID.
@@ -191,3 +191,58 @@ func TestNewQuerier_ParamNested3_QueryAllDataTypes(t *testing.T) {
 		assert.Equal(t, want, row)
 	})
 }
+
+func TestNewQuerier_PgBouncerCompat(t *testing.T) {
+	conn, cleanup := pgtest.NewPostgresSchema(t, []string{"schema.sql"},
+		pgtest.WithoutPreparedStatements())
+	defer cleanup()
+	ctx := context.Background()
+	q := NewQuerierConfig(conn, QuerierConfig{
+		PgBouncerCompat:       true,
+		UsePreparedStatements: true, // ignored in PgBouncer compat mode
+	})
+
+	t.Run("ParamArrayInt", func(t *testing.T) {
+		want := []int{1, 2, 3, 4}
+		row, err := q.ParamArrayInt(ctx, want)
+		require.NoError(t, err)
+		assert.Equal(t, want, row)
+	})
+
+	t.Run("ParamNested2Array", func(t *testing.T) {
+		want := []ProductImageType{
+			{Source: "src1", Dimensions: Dimensions{Width: 11, Height: 11}},
+			{Source: "src2", Dimensions: Dimensions{Width: 22, Height: 22}},
+		}
+		row, err := q.ParamNested2Array(ctx, want)
+		require.NoError(t, err)
+		assert.Equal(t, want, row)
+	})
+
+	t.Run("ParamNested3", func(t *testing.T) {
+		want := ProductImageSetType{
+			Name:      "set1",
+			OrigImage: ProductImageType{Source: "src1", Dimensions: Dimensions{Width: 11, Height: 11}},
+			Images: []ProductImageType{
+				{Source: "src1", Dimensions: Dimensions{Width: 11, Height: 11}},
+				{Source: "src2", Dimensions: Dimensions{Width: 22, Height: 22}},
+			},
+		}
+		row, err := q.ParamNested3(ctx, want)
+		require.NoError(t, err)
+		assert.Equal(t, want, row)
+	})
+
+	t.Run("RunInTx", func(t *testing.T) {
+		want := Dimensions{Width: 77, Height: 77}
+		err := q.RunInTx(ctx, TxOptions{}, func(tq Querier) error {
+			row, err := tq.ParamNested1(ctx, want)
+			if err != nil {
+				return err
+			}
+			assert.Equal(t, want, row)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+}
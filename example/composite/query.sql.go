@@ -4,10 +4,12 @@ package composite
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v4"
+	"time"
 )
 
 // Querier is a typesafe Go interface backed by SQL queries.
@@ -36,11 +38,17 @@ type Querier interface {
 	InsertScreenshotBlocksBatch(batch genericBatch, screenshotID int, body string)
 	// InsertScreenshotBlocksScan scans the result of an executed InsertScreenshotBlocksBatch query.
 	InsertScreenshotBlocksScan(results pgx.BatchResults) (InsertScreenshotBlocksRow, error)
+
+	// RunInTx runs fn in a transaction using a Querier bound to the
+	// transaction. See DBQuerier.RunInTx.
+	RunInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error
 }
 
 type DBQuerier struct {
-	conn  genericConn   // underlying Postgres transport to use
-	types *typeResolver // resolve types by name
+	conn    genericConn   // underlying Postgres transport to use
+	replica genericConn   // transport for read-only queries; nil unless routing
+	types   *typeResolver // resolve types by name
+	cfg     QuerierConfig // config used to create the querier
 }
 
 var _ Querier = &DBQuerier{}
@@ -86,17 +94,362 @@ type QuerierConfig struct {
 	// transitive dependencies, pggen will use the binary encoding format for
 	// the input parameter.
 	DataTypes []pgtype.DataType
+
+	// UsePreparedStatements runs queries by the statement names prepared by
+	// PrepareAllQueries instead of by SQL text. Every connection used by the
+	// querier must run PrepareAllQueries first, typically in the AfterConnect
+	// callback of pgxpool.Config.
+	//
+	// If false, the default, queries run by SQL text, which works whether or
+	// not the connection prepared the statements.
+	UsePreparedStatements bool
+
+	// PgBouncerCompat enables compatibility with PgBouncer in transaction
+	// pooling mode, which doesn't support prepared statements. Each query runs
+	// with pgx.QuerySimpleProtocol, pggen-generated transcoders always encode
+	// in the text format, UsePreparedStatements is ignored, and
+	// PrepareAllQueriesConfig doesn't prepare any statements.
+	//
+	// pgx only sends a pgx.Batch with the simple protocol if
+	// PreferSimpleProtocol is set on pgx.ConnConfig, so Batch.Send runs the
+	// queued queries one at a time with the simple protocol instead of in a
+	// single network round-trip.
+	PgBouncerCompat bool
 }
 
 // NewQuerierConfig creates a DBQuerier that implements Querier with the given
 // config. conn is typically *pgx.Conn, pgx.Tx, or *pgxpool.Pool.
 func NewQuerierConfig(conn genericConn, cfg QuerierConfig) *DBQuerier {
-	return &DBQuerier{conn: conn, types: newTypeResolver(cfg.DataTypes)}
+	if cfg.PgBouncerCompat {
+		conn = simpleProtocolConn{conn}
+	}
+	return &DBQuerier{conn: conn, types: newTypeResolver(cfg.DataTypes, cfg.PgBouncerCompat), cfg: cfg}
 }
 
 // WithTx creates a new DBQuerier that uses the transaction to run all queries.
+// The returned DBQuerier keeps the config of q. If q is a routing querier, the
+// returned DBQuerier runs read-only queries in the transaction too.
 func (q *DBQuerier) WithTx(tx pgx.Tx) (*DBQuerier, error) {
-	return &DBQuerier{conn: tx}, nil
+	var conn genericConn = tx
+	if q.cfg.PgBouncerCompat {
+		conn = simpleProtocolConn{conn}
+	}
+	return &DBQuerier{conn: conn, types: q.types, cfg: q.cfg}, nil
+}
+
+// NewRoutingQuerier creates a DBQuerier that runs read-only queries on replica
+// and all other queries on primary. pggen classifies a query as read-only if
+// the query plan doesn't modify a table or lock rows. Queries with the
+// route=primary pragma, batches, and transactions always use primary.
+func NewRoutingQuerier(primary, replica genericConn) *DBQuerier {
+	return NewRoutingQuerierConfig(primary, replica, QuerierConfig{})
+}
+
+// NewRoutingQuerierConfig creates a DBQuerier like NewRoutingQuerier with the
+// given config. The config applies to both primary and replica.
+func NewRoutingQuerierConfig(primary, replica genericConn, cfg QuerierConfig) *DBQuerier {
+	q := NewQuerierConfig(primary, cfg)
+	if cfg.PgBouncerCompat {
+		replica = simpleProtocolConn{replica}
+	}
+	q.replica = replica
+	return q
+}
+
+// readConn returns the Postgres transport for read-only queries.
+func (q *DBQuerier) readConn() genericConn {
+	if q.replica == nil {
+		return q.conn
+	}
+	return q.replica
+}
+
+// simpleProtocolConn is a genericConn that runs all queries with the simple
+// protocol, overriding pgx.ConnConfig.PreferSimpleProtocol.
+type simpleProtocolConn struct {
+	genericConn
+}
+
+func (c simpleProtocolConn) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return c.genericConn.Query(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, args...)...)
+}
+
+func (c simpleProtocolConn) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return c.genericConn.QueryRow(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, args...)...)
+}
+
+func (c simpleProtocolConn) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	return c.genericConn.Exec(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, arguments...)...)
+}
+
+// transport returns the underlying Postgres transport of q, unwrapping any
+// wrapper added by the config.
+func (q *DBQuerier) transport() genericConn {
+	if c, ok := q.conn.(simpleProtocolConn); ok {
+		return c.genericConn
+	}
+	return q.conn
+}
+
+// txBeginner is any Postgres connection transport that can begin a
+// transaction, most commonly *pgx.Conn or *pgxpool.Pool.
+type txBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// TxOptions controls how RunInTx begins and retries a transaction.
+type TxOptions struct {
+	// TxOptions are the options used to begin a top-level transaction. Ignored
+	// for nested transactions, which use a savepoint.
+	pgx.TxOptions
+
+	// MaxRetries is the maximum number of times to retry a top-level
+	// transaction that failed with a serialization failure (SQLSTATE 40001) or
+	// a deadlock (SQLSTATE 40P01). Zero disables retries.
+	MaxRetries int
+
+	// Backoff returns how long to wait before the nth retry, starting at 1. If
+	// nil, retries immediately.
+	Backoff func(retry int) time.Duration
+}
+
+// RunInTx runs fn in a transaction. fn receives a Querier bound to the
+// transaction with the same config as q. If fn returns nil, RunInTx commits
+// the transaction; otherwise, RunInTx rolls back the transaction and returns
+// the error from fn.
+//
+// If q is already bound to a transaction, like the Querier passed to fn,
+// RunInTx creates a nested transaction using a savepoint. Only the top-level
+// transaction is retried because a serialization failure aborts the entire
+// transaction.
+func (q *DBQuerier) RunInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error {
+	_, isNested := q.transport().(pgx.Tx)
+	for retry := 1; ; retry++ {
+		err := q.runInTx(ctx, opts, fn)
+		if err == nil || isNested || retry > opts.MaxRetries || !isRetryableTxErr(err) {
+			return err
+		}
+		if opts.Backoff == nil {
+			continue
+		}
+		timer := time.NewTimer(opts.Backoff(retry))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("retry tx: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// runInTx runs a single attempt of fn in a transaction.
+func (q *DBQuerier) runInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error {
+	var tx pgx.Tx
+	var err error
+	switch conn := q.transport().(type) {
+	case pgx.Tx:
+		tx, err = conn.Begin(ctx) // savepoint
+	case txBeginner:
+		tx, err = conn.BeginTx(ctx, opts.TxOptions)
+	default:
+		return fmt.Errorf("begin tx: conn of type %T cannot begin a transaction", conn)
+	}
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	txq, _ := q.WithTx(tx)
+	if err := fn(txq); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			return fmt.Errorf("rollback tx: %v; original error: %w", rbErr, err)
+		}
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// isRetryableTxErr returns true if err is a serialization failure or deadlock
+// such that retrying the entire transaction might succeed.
+func isRetryableTxErr(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+// batchSender is any Postgres connection transport that can send a batch of
+// queries, most commonly *pgx.Conn, pgx.Tx, or *pgxpool.Pool.
+type batchSender interface {
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// Batch is a typed builder for a pgx.Batch. Each query method on Batch queues
+// the query and returns a handle. After calling Send, use Result on the handle
+// to get the typed result of the query.
+type Batch struct {
+	q       *DBQuerier
+	batch   queueBatch    // *pgx.Batch, or *simpleBatch with PgBouncerCompat
+	handles []batchHandle // handles in the same order as the queued queries
+	sent    bool
+}
+
+// batchHandle scans the result of a single query queued in a Batch.
+type batchHandle interface {
+	scan(results pgx.BatchResults) error
+}
+
+var errBatchNotSent = errors.New("batch not sent")
+
+// queueBatch is a genericBatch that counts the queued queries.
+type queueBatch interface {
+	genericBatch
+	Len() int
+}
+
+// NewBatch creates a typed Batch that sends queries using the conn of q.
+func (q *DBQuerier) NewBatch() *Batch {
+	if q.cfg.PgBouncerCompat {
+		return &Batch{q: q, batch: &simpleBatch{}}
+	}
+	return &Batch{q: q, batch: &pgx.Batch{}}
+}
+
+// Send sends all queued queries with SendBatch and scans the result of each
+// query into its handle in queue order. If any query fails, Send returns a
+// BatchError with the errors of all failed queries.
+func (b *Batch) Send(ctx context.Context) error {
+	if b.sent {
+		return errors.New("send batch: batch already sent")
+	}
+	if b.batch.Len() != len(b.handles) {
+		return fmt.Errorf("send batch: %d queued queries but %d handles", b.batch.Len(), len(b.handles))
+	}
+	var results pgx.BatchResults
+	switch batch := b.batch.(type) {
+	case *simpleBatch:
+		b.sent = true
+		results = &simpleBatchResults{ctx: ctx, conn: b.q.conn, queries: batch.queries}
+	case *pgx.Batch:
+		sender, ok := b.q.transport().(batchSender)
+		if !ok {
+			return fmt.Errorf("send batch: conn of type %T cannot send a batch", b.q.transport())
+		}
+		b.sent = true
+		results = sender.SendBatch(ctx, batch)
+	default:
+		return fmt.Errorf("send batch: unknown batch type %T", b.batch)
+	}
+	var errs []error
+	for _, h := range b.handles {
+		if err := h.scan(results); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := results.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("close batch results: %w", err))
+	}
+	if len(errs) > 0 {
+		return BatchError{Errs: errs}
+	}
+	return nil
+}
+
+// BatchError is the error returned by Batch.Send with the errors of all failed
+// queries in queue order.
+type BatchError struct {
+	Errs []error
+}
+
+func (e BatchError) Error() string {
+	msg := fmt.Sprintf("send batch: %d errors", len(e.Errs))
+	for _, err := range e.Errs {
+		msg += "; " + err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the first error.
+func (e BatchError) Unwrap() error { return e.Errs[0] }
+
+// simpleBatch is a genericBatch that records the queued queries so that
+// simpleBatchResults can run them with the simple protocol.
+type simpleBatch struct {
+	queries []simpleQuery
+}
+
+type simpleQuery struct {
+	sql  string
+	args []interface{}
+}
+
+func (b *simpleBatch) Queue(query string, arguments ...interface{}) {
+	b.queries = append(b.queries, simpleQuery{sql: query, args: arguments})
+}
+
+func (b *simpleBatch) Len() int { return len(b.queries) }
+
+// simpleBatchResults is a pgx.BatchResults that runs the next query of a
+// simpleBatch on conn when reading its result. conn is a simpleProtocolConn,
+// so each query runs with the simple protocol.
+type simpleBatchResults struct {
+	ctx     context.Context
+	conn    genericConn
+	queries []simpleQuery
+	idx     int // index of the next query
+}
+
+func (r *simpleBatchResults) next() (simpleQuery, error) {
+	if r.idx == len(r.queries) {
+		return simpleQuery{}, fmt.Errorf("no result for batch query %d", r.idx)
+	}
+	q := r.queries[r.idx]
+	r.idx++
+	return q, nil
+}
+
+func (r *simpleBatchResults) Exec() (pgconn.CommandTag, error) {
+	q, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	return r.conn.Exec(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) Query() (pgx.Rows, error) {
+	q, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	return r.conn.Query(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) QueryRow() pgx.Row {
+	q, err := r.next()
+	if err != nil {
+		return errRow{err: err}
+	}
+	return r.conn.QueryRow(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) Close() error { return nil }
+
+// errRow is a pgx.Row that returns err from Scan.
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(...interface{}) error { return r.err }
+
+// chooseSQL returns the name of the prepared statement if the querier uses
+// prepared statements. Otherwise, returns the SQL text.
+func (q *DBQuerier) chooseSQL(sql, stmtName string) string {
+	if q.cfg.UsePreparedStatements && !q.cfg.PgBouncerCompat {
+		return stmtName
+	}
+	return sql
 }
 
 // preparer is any Postgres connection transport that provides a way to prepare
@@ -109,22 +462,36 @@ type preparer interface {
 // queries in querier files. Typical usage is as the AfterConnect callback
 // for pgxpool.Config
 //
-// pgx will use the prepared statement if available. Calling PrepareAllQueries
-// is an optional optimization to avoid a network round-trip the first time pgx
-// runs a query if pgx statement caching is enabled.
+// Each statement is prepared under a deterministic name derived from the query
+// name and SQL text. A querier created with
+// QuerierConfig.UsePreparedStatements runs queries by those names, avoiding a
+// network round-trip to prepare the statement the first time pgx runs a query.
+//
+// Use PrepareAllQueriesConfig with the config of the querier to skip preparing
+// statements with QuerierConfig.PgBouncerCompat.
 func PrepareAllQueries(ctx context.Context, p preparer) error {
-	if _, err := p.Prepare(ctx, searchScreenshotsSQL, searchScreenshotsSQL); err != nil {
+	if _, err := p.Prepare(ctx, searchScreenshotsStmt, searchScreenshotsSQL); err != nil {
 		return fmt.Errorf("prepare query 'SearchScreenshots': %w", err)
 	}
-	if _, err := p.Prepare(ctx, searchScreenshotsOneColSQL, searchScreenshotsOneColSQL); err != nil {
+	if _, err := p.Prepare(ctx, searchScreenshotsOneColStmt, searchScreenshotsOneColSQL); err != nil {
 		return fmt.Errorf("prepare query 'SearchScreenshotsOneCol': %w", err)
 	}
-	if _, err := p.Prepare(ctx, insertScreenshotBlocksSQL, insertScreenshotBlocksSQL); err != nil {
+	if _, err := p.Prepare(ctx, insertScreenshotBlocksStmt, insertScreenshotBlocksSQL); err != nil {
 		return fmt.Errorf("prepare query 'InsertScreenshotBlocks': %w", err)
 	}
 	return nil
 }
 
+// PrepareAllQueriesConfig is like PrepareAllQueries but doesn't prepare any
+// statements if cfg.PgBouncerCompat is set, because PgBouncer in transaction
+// pooling mode doesn't support prepared statements.
+func PrepareAllQueriesConfig(ctx context.Context, p preparer, cfg QuerierConfig) error {
+	if cfg.PgBouncerCompat {
+		return nil
+	}
+	return PrepareAllQueries(ctx, p)
+}
+
 // Blocks represents the Postgres composite type "blocks".
 type Blocks struct {
 	ID           int    `json:"id"`
@@ -134,18 +501,19 @@ type Blocks struct {
 
 // typeResolver looks up the pgtype.ValueTranscoder by Postgres type name.
 type typeResolver struct {
-	connInfo *pgtype.ConnInfo // types by Postgres type name
+	connInfo   *pgtype.ConnInfo // types by Postgres type name
+	preferText bool             // if true, always encode in the text format
 }
 
-func newTypeResolver(types []pgtype.DataType) *typeResolver {
+func newTypeResolver(types []pgtype.DataType, preferText bool) *typeResolver {
 	ci := pgtype.NewConnInfo()
 	for _, typ := range types {
-		if txt, ok := typ.Value.(textPreferrer); ok && typ.OID != unknownOID {
+		if txt, ok := typ.Value.(textPreferrer); ok && typ.OID != unknownOID && !preferText {
 			typ.Value = txt.ValueTranscoder
 		}
 		ci.RegisterDataType(typ)
 	}
-	return &typeResolver{connInfo: ci}
+	return &typeResolver{connInfo: ci, preferText: preferText}
 }
 
 // findValue find the OID, and pgtype.ValueTranscoder for a Postgres type name.
@@ -154,8 +522,11 @@ func (tr *typeResolver) findValue(name string) (uint32, pgtype.ValueTranscoder,
 	if !ok {
 		return 0, nil, false
 	}
-	v := pgtype.NewValue(typ.Value)
-	return typ.OID, v.(pgtype.ValueTranscoder), true
+	v := pgtype.NewValue(typ.Value).(pgtype.ValueTranscoder)
+	if _, isText := v.(textPreferrer); tr.preferText && !isText {
+		v = textPreferrer{v, name}
+	}
+	return typ.OID, v, true
 }
 
 // setValue sets the value of a ValueTranscoder to a value that should always
@@ -193,7 +564,7 @@ func (tr *typeResolver) newCompositeValue(name string, fields ...compositeField)
 	// Okay to ignore error because it's only thrown when the number of field
 	// names does not equal the number of ValueTranscoders.
 	typ, _ := pgtype.NewCompositeTypeValues(name, fs, vals)
-	if !isBinaryOk {
+	if !isBinaryOk || tr.preferText {
 		return textPreferrer{typ, name}
 	}
 	return typ
@@ -212,7 +583,7 @@ func (tr *typeResolver) newArrayValue(name, elemName string, defaultVal func() p
 		elemValFunc = defaultVal
 	}
 	typ := pgtype.NewArrayType(name, elemOID, elemValFunc)
-	if elemOID == unknownOID {
+	if elemOID == unknownOID || tr.preferText {
 		return textPreferrer{typ, name}
 	}
 	return typ
@@ -245,6 +616,8 @@ GROUP BY ss.id
 ORDER BY ss.id
 LIMIT $2 OFFSET $3;`
 
+const searchScreenshotsStmt = "pggen_SearchScreenshots_12f3c8e04d9ab6e8"
+
 type SearchScreenshotsParams struct {
 	Body   string
 	Limit  int
@@ -259,7 +632,7 @@ type SearchScreenshotsRow struct {
 // SearchScreenshots implements Querier.SearchScreenshots.
 func (q *DBQuerier) SearchScreenshots(ctx context.Context, params SearchScreenshotsParams) ([]SearchScreenshotsRow, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "SearchScreenshots")
-	rows, err := q.conn.Query(ctx, searchScreenshotsSQL, params.Body, params.Limit, params.Offset)
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(searchScreenshotsSQL, searchScreenshotsStmt), params.Body, params.Limit, params.Offset)
 	if err != nil {
 		return nil, fmt.Errorf("query SearchScreenshots: %w", err)
 	}
@@ -284,7 +657,7 @@ func (q *DBQuerier) SearchScreenshots(ctx context.Context, params SearchScreensh
 
 // SearchScreenshotsBatch implements Querier.SearchScreenshotsBatch.
 func (q *DBQuerier) SearchScreenshotsBatch(batch genericBatch, params SearchScreenshotsParams) {
-	batch.Queue(searchScreenshotsSQL, params.Body, params.Limit, params.Offset)
+	batch.Queue(q.chooseSQL(searchScreenshotsSQL, searchScreenshotsStmt), params.Body, params.Limit, params.Offset)
 }
 
 // SearchScreenshotsScan implements Querier.SearchScreenshotsScan.
@@ -312,6 +685,36 @@ func (q *DBQuerier) SearchScreenshotsScan(results pgx.BatchResults) ([]SearchScr
 	return items, err
 }
 
+// SearchScreenshots queues a SearchScreenshots query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) SearchScreenshots(params SearchScreenshotsParams) *SearchScreenshotsHandle {
+	b.q.SearchScreenshotsBatch(b.batch, params)
+	h := &SearchScreenshotsHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// SearchScreenshotsHandle is the result of a SearchScreenshots query queued in a Batch.
+type SearchScreenshotsHandle struct {
+	b   *Batch
+	res []SearchScreenshotsRow
+	err error
+}
+
+func (h *SearchScreenshotsHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.SearchScreenshotsScan(results)
+	return h.err
+}
+
+// Result returns the result of the SearchScreenshots query. Returns an error if the
+// batch wasn't sent.
+func (h *SearchScreenshotsHandle) Result() ([]SearchScreenshotsRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("SearchScreenshots result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const searchScreenshotsOneColSQL = `SELECT
   array_agg(bl) AS blocks
 FROM screenshots ss
@@ -321,6 +724,8 @@ GROUP BY ss.id
 ORDER BY ss.id
 LIMIT $2 OFFSET $3;`
 
+const searchScreenshotsOneColStmt = "pggen_SearchScreenshotsOneCol_f6c0468ed9ec9723"
+
 type SearchScreenshotsOneColParams struct {
 	Body   string
 	Limit  int
@@ -330,7 +735,7 @@ type SearchScreenshotsOneColParams struct {
 // SearchScreenshotsOneCol implements Querier.SearchScreenshotsOneCol.
 func (q *DBQuerier) SearchScreenshotsOneCol(ctx context.Context, params SearchScreenshotsOneColParams) ([][]Blocks, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "SearchScreenshotsOneCol")
-	rows, err := q.conn.Query(ctx, searchScreenshotsOneColSQL, params.Body, params.Limit, params.Offset)
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(searchScreenshotsOneColSQL, searchScreenshotsOneColStmt), params.Body, params.Limit, params.Offset)
 	if err != nil {
 		return nil, fmt.Errorf("query SearchScreenshotsOneCol: %w", err)
 	}
@@ -355,7 +760,7 @@ func (q *DBQuerier) SearchScreenshotsOneCol(ctx context.Context, params SearchSc
 
 // SearchScreenshotsOneColBatch implements Querier.SearchScreenshotsOneColBatch.
 func (q *DBQuerier) SearchScreenshotsOneColBatch(batch genericBatch, params SearchScreenshotsOneColParams) {
-	batch.Queue(searchScreenshotsOneColSQL, params.Body, params.Limit, params.Offset)
+	batch.Queue(q.chooseSQL(searchScreenshotsOneColSQL, searchScreenshotsOneColStmt), params.Body, params.Limit, params.Offset)
 }
 
 // SearchScreenshotsOneColScan implements Querier.SearchScreenshotsOneColScan.
@@ -383,6 +788,36 @@ func (q *DBQuerier) SearchScreenshotsOneColScan(results pgx.BatchResults) ([][]B
 	return items, err
 }
 
+// SearchScreenshotsOneCol queues a SearchScreenshotsOneCol query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) SearchScreenshotsOneCol(params SearchScreenshotsOneColParams) *SearchScreenshotsOneColHandle {
+	b.q.SearchScreenshotsOneColBatch(b.batch, params)
+	h := &SearchScreenshotsOneColHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// SearchScreenshotsOneColHandle is the result of a SearchScreenshotsOneCol query queued in a Batch.
+type SearchScreenshotsOneColHandle struct {
+	b   *Batch
+	res [][]Blocks
+	err error
+}
+
+func (h *SearchScreenshotsOneColHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.SearchScreenshotsOneColScan(results)
+	return h.err
+}
+
+// Result returns the result of the SearchScreenshotsOneCol query. Returns an error if the
+// batch wasn't sent.
+func (h *SearchScreenshotsOneColHandle) Result() ([][]Blocks, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("SearchScreenshotsOneCol result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const insertScreenshotBlocksSQL = `WITH screens AS (
   INSERT INTO screenshots (id) VALUES ($1)
     ON CONFLICT DO NOTHING
@@ -392,6 +827,8 @@ INTO blocks (screenshot_id, body)
 VALUES ($1, $2)
 RETURNING id, screenshot_id, body;`
 
+const insertScreenshotBlocksStmt = "pggen_InsertScreenshotBlocks_42c00eaec6b98672"
+
 type InsertScreenshotBlocksRow struct {
 	ID           int    `json:"id"`
 	ScreenshotID int    `json:"screenshot_id"`
@@ -401,7 +838,7 @@ type InsertScreenshotBlocksRow struct {
 // InsertScreenshotBlocks implements Querier.InsertScreenshotBlocks.
 func (q *DBQuerier) InsertScreenshotBlocks(ctx context.Context, screenshotID int, body string) (InsertScreenshotBlocksRow, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "InsertScreenshotBlocks")
-	row := q.conn.QueryRow(ctx, insertScreenshotBlocksSQL, screenshotID, body)
+	row := q.conn.QueryRow(ctx, q.chooseSQL(insertScreenshotBlocksSQL, insertScreenshotBlocksStmt), screenshotID, body)
 	var item InsertScreenshotBlocksRow
 	if err := row.Scan(&item.ID, &item.ScreenshotID, &item.Body); err != nil {
 		return item, fmt.Errorf("query InsertScreenshotBlocks: %w", err)
@@ -411,7 +848,7 @@ func (q *DBQuerier) InsertScreenshotBlocks(ctx context.Context, screenshotID int
 
 // InsertScreenshotBlocksBatch implements Querier.InsertScreenshotBlocksBatch.
 func (q *DBQuerier) InsertScreenshotBlocksBatch(batch genericBatch, screenshotID int, body string) {
-	batch.Queue(insertScreenshotBlocksSQL, screenshotID, body)
+	batch.Queue(q.chooseSQL(insertScreenshotBlocksSQL, insertScreenshotBlocksStmt), screenshotID, body)
 }
 
 // InsertScreenshotBlocksScan implements Querier.InsertScreenshotBlocksScan.
@@ -424,6 +861,36 @@ func (q *DBQuerier) InsertScreenshotBlocksScan(results pgx.BatchResults) (Insert
 	return item, nil
 }
 
+// InsertScreenshotBlocks queues a InsertScreenshotBlocks query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) InsertScreenshotBlocks(screenshotID int, body string) *InsertScreenshotBlocksHandle {
+	b.q.InsertScreenshotBlocksBatch(b.batch, screenshotID, body)
+	h := &InsertScreenshotBlocksHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// InsertScreenshotBlocksHandle is the result of a InsertScreenshotBlocks query queued in a Batch.
+type InsertScreenshotBlocksHandle struct {
+	b   *Batch
+	res InsertScreenshotBlocksRow
+	err error
+}
+
+func (h *InsertScreenshotBlocksHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.InsertScreenshotBlocksScan(results)
+	return h.err
+}
+
+// Result returns the result of the InsertScreenshotBlocks query. Returns an error if the
+// batch wasn't sent.
+func (h *InsertScreenshotBlocksHandle) Result() (InsertScreenshotBlocksRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("InsertScreenshotBlocks result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 // textPreferrer wraps a pgtype.ValueTranscoder and sets the preferred encoding
 // format to text instead binary (the default). pggen uses the text format
 // when the OID is unknownOID because the binary format requires the OID.
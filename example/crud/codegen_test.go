@@ -0,0 +1,43 @@
+package crud
+
+import (
+	"github.com/jschaf/pggen"
+	"github.com/jschaf/pggen/internal/pgtest"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerate_Go_Example_CRUD(t *testing.T) {
+	conn, cleanupFunc := pgtest.NewPostgresSchema(t, []string{"schema.sql"})
+	defer cleanupFunc()
+
+	tmpDir := t.TempDir()
+	err := pggen.Generate(
+		pggen.GenerateOptions{
+			ConnString: conn.Config().ConnString(),
+			CRUDTables: []string{"author"},
+			OutputDir:  tmpDir,
+			GoPackage:  "crud",
+			Language:   pggen.LangGo,
+		})
+	if err != nil {
+		t.Fatalf("Generate() example/crud: %s", err)
+	}
+
+	for _, file := range []string{"crud.sql", "crud.sql.go"} {
+		gotFile := filepath.Join(tmpDir, file)
+		assert.FileExists(t, gotFile, "Generate() should emit "+file)
+		want, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatalf("read wanted %s: %s", file, err)
+		}
+		got, err := ioutil.ReadFile(gotFile)
+		if err != nil {
+			t.Fatalf("read generated %s: %s", file, err)
+		}
+		assert.Equalf(t, string(want), string(got),
+			"Got file %s; does not match contents of %s", gotFile, file)
+	}
+}
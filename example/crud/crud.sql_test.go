@@ -0,0 +1,57 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jschaf/pggen/internal/pgtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewQuerier_CRUD(t *testing.T) {
+	conn, cleanup := pgtest.NewPostgresSchema(t, []string{"schema.sql"})
+	defer cleanup()
+	q := NewQuerier(conn)
+	ctx := context.Background()
+
+	inserted, err := q.InsertAuthor(ctx, InsertAuthorParams{FirstName: "john", LastName: "adams", Email: "john@example.com"})
+	require.NoError(t, err)
+	email := "john@example.com"
+	assert.Equal(t, Author{AuthorID: inserted.AuthorID, FirstName: "john", LastName: "adams", Email: &email}, inserted)
+
+	found, err := q.FindAuthorByAuthorID(ctx, inserted.AuthorID)
+	require.NoError(t, err)
+	assert.Equal(t, inserted, found)
+
+	byEmail, err := q.FindAuthorByEmail(ctx, email)
+	require.NoError(t, err)
+	assert.Equal(t, inserted, byEmail)
+
+	updated, err := q.UpdateAuthor(ctx, UpdateAuthorParams{
+		AuthorID: inserted.AuthorID, FirstName: "john", LastName: "quincy adams", Email: email,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "quincy adams", updated.LastName)
+
+	upserted, err := q.UpsertAuthor(ctx, UpsertAuthorParams{
+		AuthorID: inserted.AuthorID, FirstName: "johnny", LastName: "adams", Email: email,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, inserted.AuthorID, upserted.AuthorID)
+	assert.Equal(t, "johnny", upserted.FirstName)
+
+	upserted, err = q.UpsertAuthor(ctx, UpsertAuthorParams{
+		AuthorID: inserted.AuthorID + 100, FirstName: "george", LastName: "washington", Email: "george@example.com",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, inserted.AuthorID+100, upserted.AuthorID)
+
+	deleted, err := q.DeleteAuthor(ctx, inserted.AuthorID)
+	require.NoError(t, err)
+	assert.Equal(t, upserted.AuthorID-100, deleted.AuthorID)
+	_, err = q.FindAuthorByAuthorID(ctx, inserted.AuthorID)
+	assert.True(t, errors.Is(err, pgx.ErrNoRows), "expected pgx.ErrNoRows; got %v", err)
+}
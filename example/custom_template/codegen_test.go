@@ -0,0 +1,45 @@
+package custom_template
+
+import (
+	"github.com/jschaf/pggen"
+	"github.com/jschaf/pggen/internal/pgtest"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerate_Go_Example_CustomTemplate(t *testing.T) {
+	conn, cleanupFunc := pgtest.NewPostgresSchema(t, []string{"schema.sql"})
+	defer cleanupFunc()
+
+	tmpDir := t.TempDir()
+	err := pggen.Generate(
+		pggen.GenerateOptions{
+			ConnString:  conn.Config().ConnString(),
+			QueryFiles:  []string{"query.sql"},
+			OutputDir:   tmpDir,
+			GoPackage:   "custom_template",
+			Language:    pggen.LangGo,
+			GoTemplates: []string{"sql_text.gotemplate"},
+		})
+	if err != nil {
+		t.Fatalf("Generate() example/custom_template: %s", err)
+	}
+
+	wantQueriesFile := "query.sql.go"
+	gotQueriesFile := filepath.Join(tmpDir, "query.sql.go")
+	assert.FileExists(t, gotQueriesFile,
+		"Generate() should emit query.sql.go")
+	wantQueries, err := ioutil.ReadFile(wantQueriesFile)
+	if err != nil {
+		t.Fatalf("read wanted query.go.sql: %s", err)
+	}
+	gotQueries, err := ioutil.ReadFile(gotQueriesFile)
+	if err != nil {
+		t.Fatalf("read generated query.go.sql: %s", err)
+	}
+	assert.Equalf(t, string(wantQueries), string(gotQueries),
+		"Got file %s; does not match contents of %s",
+		gotQueriesFile, wantQueriesFile)
+}
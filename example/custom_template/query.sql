@@ -0,0 +1,9 @@
+-- FindAuthorByID finds one (or zero) authors by ID.
+-- name: FindAuthorByID :one
+SELECT * FROM author WHERE author_id = pggen.arg('AuthorID');
+
+-- InsertAuthor inserts an author by name and returns the ID.
+-- name: InsertAuthor :one
+INSERT INTO author (first_name)
+VALUES (pggen.arg('FirstName'))
+RETURNING author_id;
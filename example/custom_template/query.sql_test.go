@@ -0,0 +1,28 @@
+package custom_template
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jschaf/pggen/internal/pgtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewQuerier_FindAuthorByID(t *testing.T) {
+	conn, cleanup := pgtest.NewPostgresSchema(t, []string{"schema.sql"})
+	defer cleanup()
+	ctx := context.Background()
+
+	q := NewQuerier(conn)
+	authorID, err := q.InsertAuthor(ctx, "john")
+	require.NoError(t, err)
+	author, err := q.FindAuthorByID(ctx, authorID)
+	require.NoError(t, err)
+	assert.Equal(t, FindAuthorByIDRow{AuthorID: authorID, FirstName: "john"}, author)
+}
+
+func TestSQLText(t *testing.T) {
+	assert.Equal(t, "SELECT * FROM author WHERE author_id = $1;", FindAuthorByIDSQLText())
+	assert.Equal(t, insertAuthorSQL, InsertAuthorSQLText())
+}
@@ -0,0 +1,4 @@
+CREATE TABLE author (
+  author_id  serial PRIMARY KEY,
+  first_name text NOT NULL
+);
@@ -4,10 +4,12 @@ package enums
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v4"
+	"time"
 )
 
 // Querier is a typesafe Go interface backed by SQL queries.
@@ -61,11 +63,17 @@ type Querier interface {
 	EnumInsideCompositeBatch(batch genericBatch)
 	// EnumInsideCompositeScan scans the result of an executed EnumInsideCompositeBatch query.
 	EnumInsideCompositeScan(results pgx.BatchResults) (Device, error)
+
+	// RunInTx runs fn in a transaction using a Querier bound to the
+	// transaction. See DBQuerier.RunInTx.
+	RunInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error
 }
 
 type DBQuerier struct {
-	conn  genericConn   // underlying Postgres transport to use
-	types *typeResolver // resolve types by name
+	conn    genericConn   // underlying Postgres transport to use
+	replica genericConn   // transport for read-only queries; nil unless routing
+	types   *typeResolver // resolve types by name
+	cfg     QuerierConfig // config used to create the querier
 }
 
 var _ Querier = &DBQuerier{}
@@ -111,17 +119,362 @@ type QuerierConfig struct {
 	// transitive dependencies, pggen will use the binary encoding format for
 	// the input parameter.
 	DataTypes []pgtype.DataType
+
+	// UsePreparedStatements runs queries by the statement names prepared by
+	// PrepareAllQueries instead of by SQL text. Every connection used by the
+	// querier must run PrepareAllQueries first, typically in the AfterConnect
+	// callback of pgxpool.Config.
+	//
+	// If false, the default, queries run by SQL text, which works whether or
+	// not the connection prepared the statements.
+	UsePreparedStatements bool
+
+	// PgBouncerCompat enables compatibility with PgBouncer in transaction
+	// pooling mode, which doesn't support prepared statements. Each query runs
+	// with pgx.QuerySimpleProtocol, pggen-generated transcoders always encode
+	// in the text format, UsePreparedStatements is ignored, and
+	// PrepareAllQueriesConfig doesn't prepare any statements.
+	//
+	// pgx only sends a pgx.Batch with the simple protocol if
+	// PreferSimpleProtocol is set on pgx.ConnConfig, so Batch.Send runs the
+	// queued queries one at a time with the simple protocol instead of in a
+	// single network round-trip.
+	PgBouncerCompat bool
 }
 
 // NewQuerierConfig creates a DBQuerier that implements Querier with the given
 // config. conn is typically *pgx.Conn, pgx.Tx, or *pgxpool.Pool.
 func NewQuerierConfig(conn genericConn, cfg QuerierConfig) *DBQuerier {
-	return &DBQuerier{conn: conn, types: newTypeResolver(cfg.DataTypes)}
+	if cfg.PgBouncerCompat {
+		conn = simpleProtocolConn{conn}
+	}
+	return &DBQuerier{conn: conn, types: newTypeResolver(cfg.DataTypes, cfg.PgBouncerCompat), cfg: cfg}
 }
 
 // WithTx creates a new DBQuerier that uses the transaction to run all queries.
+// The returned DBQuerier keeps the config of q. If q is a routing querier, the
+// returned DBQuerier runs read-only queries in the transaction too.
 func (q *DBQuerier) WithTx(tx pgx.Tx) (*DBQuerier, error) {
-	return &DBQuerier{conn: tx}, nil
+	var conn genericConn = tx
+	if q.cfg.PgBouncerCompat {
+		conn = simpleProtocolConn{conn}
+	}
+	return &DBQuerier{conn: conn, types: q.types, cfg: q.cfg}, nil
+}
+
+// NewRoutingQuerier creates a DBQuerier that runs read-only queries on replica
+// and all other queries on primary. pggen classifies a query as read-only if
+// the query plan doesn't modify a table or lock rows. Queries with the
+// route=primary pragma, batches, and transactions always use primary.
+func NewRoutingQuerier(primary, replica genericConn) *DBQuerier {
+	return NewRoutingQuerierConfig(primary, replica, QuerierConfig{})
+}
+
+// NewRoutingQuerierConfig creates a DBQuerier like NewRoutingQuerier with the
+// given config. The config applies to both primary and replica.
+func NewRoutingQuerierConfig(primary, replica genericConn, cfg QuerierConfig) *DBQuerier {
+	q := NewQuerierConfig(primary, cfg)
+	if cfg.PgBouncerCompat {
+		replica = simpleProtocolConn{replica}
+	}
+	q.replica = replica
+	return q
+}
+
+// readConn returns the Postgres transport for read-only queries.
+func (q *DBQuerier) readConn() genericConn {
+	if q.replica == nil {
+		return q.conn
+	}
+	return q.replica
+}
+
+// simpleProtocolConn is a genericConn that runs all queries with the simple
+// protocol, overriding pgx.ConnConfig.PreferSimpleProtocol.
+type simpleProtocolConn struct {
+	genericConn
+}
+
+func (c simpleProtocolConn) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return c.genericConn.Query(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, args...)...)
+}
+
+func (c simpleProtocolConn) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return c.genericConn.QueryRow(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, args...)...)
+}
+
+func (c simpleProtocolConn) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	return c.genericConn.Exec(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, arguments...)...)
+}
+
+// transport returns the underlying Postgres transport of q, unwrapping any
+// wrapper added by the config.
+func (q *DBQuerier) transport() genericConn {
+	if c, ok := q.conn.(simpleProtocolConn); ok {
+		return c.genericConn
+	}
+	return q.conn
+}
+
+// txBeginner is any Postgres connection transport that can begin a
+// transaction, most commonly *pgx.Conn or *pgxpool.Pool.
+type txBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// TxOptions controls how RunInTx begins and retries a transaction.
+type TxOptions struct {
+	// TxOptions are the options used to begin a top-level transaction. Ignored
+	// for nested transactions, which use a savepoint.
+	pgx.TxOptions
+
+	// MaxRetries is the maximum number of times to retry a top-level
+	// transaction that failed with a serialization failure (SQLSTATE 40001) or
+	// a deadlock (SQLSTATE 40P01). Zero disables retries.
+	MaxRetries int
+
+	// Backoff returns how long to wait before the nth retry, starting at 1. If
+	// nil, retries immediately.
+	Backoff func(retry int) time.Duration
+}
+
+// RunInTx runs fn in a transaction. fn receives a Querier bound to the
+// transaction with the same config as q. If fn returns nil, RunInTx commits
+// the transaction; otherwise, RunInTx rolls back the transaction and returns
+// the error from fn.
+//
+// If q is already bound to a transaction, like the Querier passed to fn,
+// RunInTx creates a nested transaction using a savepoint. Only the top-level
+// transaction is retried because a serialization failure aborts the entire
+// transaction.
+func (q *DBQuerier) RunInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error {
+	_, isNested := q.transport().(pgx.Tx)
+	for retry := 1; ; retry++ {
+		err := q.runInTx(ctx, opts, fn)
+		if err == nil || isNested || retry > opts.MaxRetries || !isRetryableTxErr(err) {
+			return err
+		}
+		if opts.Backoff == nil {
+			continue
+		}
+		timer := time.NewTimer(opts.Backoff(retry))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("retry tx: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// runInTx runs a single attempt of fn in a transaction.
+func (q *DBQuerier) runInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error {
+	var tx pgx.Tx
+	var err error
+	switch conn := q.transport().(type) {
+	case pgx.Tx:
+		tx, err = conn.Begin(ctx) // savepoint
+	case txBeginner:
+		tx, err = conn.BeginTx(ctx, opts.TxOptions)
+	default:
+		return fmt.Errorf("begin tx: conn of type %T cannot begin a transaction", conn)
+	}
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	txq, _ := q.WithTx(tx)
+	if err := fn(txq); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			return fmt.Errorf("rollback tx: %v; original error: %w", rbErr, err)
+		}
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// isRetryableTxErr returns true if err is a serialization failure or deadlock
+// such that retrying the entire transaction might succeed.
+func isRetryableTxErr(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+// batchSender is any Postgres connection transport that can send a batch of
+// queries, most commonly *pgx.Conn, pgx.Tx, or *pgxpool.Pool.
+type batchSender interface {
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// Batch is a typed builder for a pgx.Batch. Each query method on Batch queues
+// the query and returns a handle. After calling Send, use Result on the handle
+// to get the typed result of the query.
+type Batch struct {
+	q       *DBQuerier
+	batch   queueBatch    // *pgx.Batch, or *simpleBatch with PgBouncerCompat
+	handles []batchHandle // handles in the same order as the queued queries
+	sent    bool
+}
+
+// batchHandle scans the result of a single query queued in a Batch.
+type batchHandle interface {
+	scan(results pgx.BatchResults) error
+}
+
+var errBatchNotSent = errors.New("batch not sent")
+
+// queueBatch is a genericBatch that counts the queued queries.
+type queueBatch interface {
+	genericBatch
+	Len() int
+}
+
+// NewBatch creates a typed Batch that sends queries using the conn of q.
+func (q *DBQuerier) NewBatch() *Batch {
+	if q.cfg.PgBouncerCompat {
+		return &Batch{q: q, batch: &simpleBatch{}}
+	}
+	return &Batch{q: q, batch: &pgx.Batch{}}
+}
+
+// Send sends all queued queries with SendBatch and scans the result of each
+// query into its handle in queue order. If any query fails, Send returns a
+// BatchError with the errors of all failed queries.
+func (b *Batch) Send(ctx context.Context) error {
+	if b.sent {
+		return errors.New("send batch: batch already sent")
+	}
+	if b.batch.Len() != len(b.handles) {
+		return fmt.Errorf("send batch: %d queued queries but %d handles", b.batch.Len(), len(b.handles))
+	}
+	var results pgx.BatchResults
+	switch batch := b.batch.(type) {
+	case *simpleBatch:
+		b.sent = true
+		results = &simpleBatchResults{ctx: ctx, conn: b.q.conn, queries: batch.queries}
+	case *pgx.Batch:
+		sender, ok := b.q.transport().(batchSender)
+		if !ok {
+			return fmt.Errorf("send batch: conn of type %T cannot send a batch", b.q.transport())
+		}
+		b.sent = true
+		results = sender.SendBatch(ctx, batch)
+	default:
+		return fmt.Errorf("send batch: unknown batch type %T", b.batch)
+	}
+	var errs []error
+	for _, h := range b.handles {
+		if err := h.scan(results); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := results.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("close batch results: %w", err))
+	}
+	if len(errs) > 0 {
+		return BatchError{Errs: errs}
+	}
+	return nil
+}
+
+// BatchError is the error returned by Batch.Send with the errors of all failed
+// queries in queue order.
+type BatchError struct {
+	Errs []error
+}
+
+func (e BatchError) Error() string {
+	msg := fmt.Sprintf("send batch: %d errors", len(e.Errs))
+	for _, err := range e.Errs {
+		msg += "; " + err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the first error.
+func (e BatchError) Unwrap() error { return e.Errs[0] }
+
+// simpleBatch is a genericBatch that records the queued queries so that
+// simpleBatchResults can run them with the simple protocol.
+type simpleBatch struct {
+	queries []simpleQuery
+}
+
+type simpleQuery struct {
+	sql  string
+	args []interface{}
+}
+
+func (b *simpleBatch) Queue(query string, arguments ...interface{}) {
+	b.queries = append(b.queries, simpleQuery{sql: query, args: arguments})
+}
+
+func (b *simpleBatch) Len() int { return len(b.queries) }
+
+// simpleBatchResults is a pgx.BatchResults that runs the next query of a
+// simpleBatch on conn when reading its result. conn is a simpleProtocolConn,
+// so each query runs with the simple protocol.
+type simpleBatchResults struct {
+	ctx     context.Context
+	conn    genericConn
+	queries []simpleQuery
+	idx     int // index of the next query
+}
+
+func (r *simpleBatchResults) next() (simpleQuery, error) {
+	if r.idx == len(r.queries) {
+		return simpleQuery{}, fmt.Errorf("no result for batch query %d", r.idx)
+	}
+	q := r.queries[r.idx]
+	r.idx++
+	return q, nil
+}
+
+func (r *simpleBatchResults) Exec() (pgconn.CommandTag, error) {
+	q, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	return r.conn.Exec(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) Query() (pgx.Rows, error) {
+	q, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	return r.conn.Query(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) QueryRow() pgx.Row {
+	q, err := r.next()
+	if err != nil {
+		return errRow{err: err}
+	}
+	return r.conn.QueryRow(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) Close() error { return nil }
+
+// errRow is a pgx.Row that returns err from Scan.
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(...interface{}) error { return r.err }
+
+// chooseSQL returns the name of the prepared statement if the querier uses
+// prepared statements. Otherwise, returns the SQL text.
+func (q *DBQuerier) chooseSQL(sql, stmtName string) string {
+	if q.cfg.UsePreparedStatements && !q.cfg.PgBouncerCompat {
+		return stmtName
+	}
+	return sql
 }
 
 // preparer is any Postgres connection transport that provides a way to prepare
@@ -134,31 +487,45 @@ type preparer interface {
 // queries in querier files. Typical usage is as the AfterConnect callback
 // for pgxpool.Config
 //
-// pgx will use the prepared statement if available. Calling PrepareAllQueries
-// is an optional optimization to avoid a network round-trip the first time pgx
-// runs a query if pgx statement caching is enabled.
+// Each statement is prepared under a deterministic name derived from the query
+// name and SQL text. A querier created with
+// QuerierConfig.UsePreparedStatements runs queries by those names, avoiding a
+// network round-trip to prepare the statement the first time pgx runs a query.
+//
+// Use PrepareAllQueriesConfig with the config of the querier to skip preparing
+// statements with QuerierConfig.PgBouncerCompat.
 func PrepareAllQueries(ctx context.Context, p preparer) error {
-	if _, err := p.Prepare(ctx, findAllDevicesSQL, findAllDevicesSQL); err != nil {
+	if _, err := p.Prepare(ctx, findAllDevicesStmt, findAllDevicesSQL); err != nil {
 		return fmt.Errorf("prepare query 'FindAllDevices': %w", err)
 	}
-	if _, err := p.Prepare(ctx, insertDeviceSQL, insertDeviceSQL); err != nil {
+	if _, err := p.Prepare(ctx, insertDeviceStmt, insertDeviceSQL); err != nil {
 		return fmt.Errorf("prepare query 'InsertDevice': %w", err)
 	}
-	if _, err := p.Prepare(ctx, findOneDeviceArraySQL, findOneDeviceArraySQL); err != nil {
+	if _, err := p.Prepare(ctx, findOneDeviceArrayStmt, findOneDeviceArraySQL); err != nil {
 		return fmt.Errorf("prepare query 'FindOneDeviceArray': %w", err)
 	}
-	if _, err := p.Prepare(ctx, findManyDeviceArraySQL, findManyDeviceArraySQL); err != nil {
+	if _, err := p.Prepare(ctx, findManyDeviceArrayStmt, findManyDeviceArraySQL); err != nil {
 		return fmt.Errorf("prepare query 'FindManyDeviceArray': %w", err)
 	}
-	if _, err := p.Prepare(ctx, findManyDeviceArrayWithNumSQL, findManyDeviceArrayWithNumSQL); err != nil {
+	if _, err := p.Prepare(ctx, findManyDeviceArrayWithNumStmt, findManyDeviceArrayWithNumSQL); err != nil {
 		return fmt.Errorf("prepare query 'FindManyDeviceArrayWithNum': %w", err)
 	}
-	if _, err := p.Prepare(ctx, enumInsideCompositeSQL, enumInsideCompositeSQL); err != nil {
+	if _, err := p.Prepare(ctx, enumInsideCompositeStmt, enumInsideCompositeSQL); err != nil {
 		return fmt.Errorf("prepare query 'EnumInsideComposite': %w", err)
 	}
 	return nil
 }
 
+// PrepareAllQueriesConfig is like PrepareAllQueries but doesn't prepare any
+// statements if cfg.PgBouncerCompat is set, because PgBouncer in transaction
+// pooling mode doesn't support prepared statements.
+func PrepareAllQueriesConfig(ctx context.Context, p preparer, cfg QuerierConfig) error {
+	if cfg.PgBouncerCompat {
+		return nil
+	}
+	return PrepareAllQueries(ctx, p)
+}
+
 // Device represents the Postgres composite type "device".
 type Device struct {
 	Mac  pgtype.Macaddr `json:"mac"`
@@ -195,20 +562,112 @@ const (
 
 func (d DeviceType) String() string { return string(d) }
 
+// AllDeviceTypeValues returns all labels of the Postgres enum "device_type"
+// in the Postgres sort order.
+func AllDeviceTypeValues() []DeviceType {
+	return []DeviceType{
+		DeviceTypeUndefined,
+		DeviceTypePhone,
+		DeviceTypeLaptop,
+		DeviceTypeIpad,
+		DeviceTypeDesktop,
+		DeviceTypeIot,
+	}
+}
+
+// ParseDeviceType returns the DeviceType for a label of the Postgres enum
+// "device_type". Returns an error if s isn't a label.
+func ParseDeviceType(s string) (DeviceType, error) {
+	if val := DeviceType(s); val.Valid() {
+		return val, nil
+	}
+	return "", fmt.Errorf("unknown DeviceType label %q", s)
+}
+
+// Valid returns true if d is a label of the Postgres enum "device_type".
+func (d DeviceType) Valid() bool { return d.ordinal() >= 0 }
+
+// ordinal returns the position of d in the Postgres sort order of the
+// enum "device_type", or -1 if d isn't a label.
+func (d DeviceType) ordinal() int {
+	switch d {
+	case DeviceTypeUndefined:
+		return 0
+	case DeviceTypePhone:
+		return 1
+	case DeviceTypeLaptop:
+		return 2
+	case DeviceTypeIpad:
+		return 3
+	case DeviceTypeDesktop:
+		return 4
+	case DeviceTypeIot:
+		return 5
+	default:
+		return -1
+	}
+}
+
+// Compare returns -1 if d sorts before other, 0 if they're equal, and +1
+// if d sorts after other, using the Postgres sort order of the enum
+// "device_type". Invalid values sort before all labels.
+func (d DeviceType) Compare(other DeviceType) int {
+	ord, otherOrd := d.ordinal(), other.ordinal()
+	switch {
+	case ord < otherOrd:
+		return -1
+	case ord > otherOrd:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Less returns true if d sorts before other using the Postgres sort
+// order of the enum "device_type".
+func (d DeviceType) Less(other DeviceType) bool { return d.Compare(other) < 0 }
+
+// MarshalText implements encoding.TextMarshaler. The zero value, like from a
+// NULL column, marshals as empty text. Returns an error for other values that
+// aren't a label of the Postgres enum "device_type".
+func (d DeviceType) MarshalText() ([]byte, error) {
+	if d != "" && !d.Valid() {
+		return nil, fmt.Errorf("marshal DeviceType: unknown label %q", string(d))
+	}
+	return []byte(d), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Empty text unmarshals to
+// the zero value. Returns an error for other text that isn't a label of the
+// Postgres enum "device_type".
+func (d *DeviceType) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*d = ""
+		return nil
+	}
+	val, err := ParseDeviceType(string(text))
+	if err != nil {
+		return fmt.Errorf("unmarshal DeviceType: %w", err)
+	}
+	*d = val
+	return nil
+}
+
 // typeResolver looks up the pgtype.ValueTranscoder by Postgres type name.
 type typeResolver struct {
-	connInfo *pgtype.ConnInfo // types by Postgres type name
+	connInfo   *pgtype.ConnInfo // types by Postgres type name
+	preferText bool             // if true, always encode in the text format
 }
 
-func newTypeResolver(types []pgtype.DataType) *typeResolver {
+func newTypeResolver(types []pgtype.DataType, preferText bool) *typeResolver {
 	ci := pgtype.NewConnInfo()
 	for _, typ := range types {
-		if txt, ok := typ.Value.(textPreferrer); ok && typ.OID != unknownOID {
+		if txt, ok := typ.Value.(textPreferrer); ok && typ.OID != unknownOID && !preferText {
 			typ.Value = txt.ValueTranscoder
 		}
 		ci.RegisterDataType(typ)
 	}
-	return &typeResolver{connInfo: ci}
+	return &typeResolver{connInfo: ci, preferText: preferText}
 }
 
 // findValue find the OID, and pgtype.ValueTranscoder for a Postgres type name.
@@ -217,8 +676,11 @@ func (tr *typeResolver) findValue(name string) (uint32, pgtype.ValueTranscoder,
 	if !ok {
 		return 0, nil, false
 	}
-	v := pgtype.NewValue(typ.Value)
-	return typ.OID, v.(pgtype.ValueTranscoder), true
+	v := pgtype.NewValue(typ.Value).(pgtype.ValueTranscoder)
+	if _, isText := v.(textPreferrer); tr.preferText && !isText {
+		v = textPreferrer{v, name}
+	}
+	return typ.OID, v, true
 }
 
 // setValue sets the value of a ValueTranscoder to a value that should always
@@ -256,7 +718,7 @@ func (tr *typeResolver) newCompositeValue(name string, fields ...compositeField)
 	// Okay to ignore error because it's only thrown when the number of field
 	// names does not equal the number of ValueTranscoders.
 	typ, _ := pgtype.NewCompositeTypeValues(name, fs, vals)
-	if !isBinaryOk {
+	if !isBinaryOk || tr.preferText {
 		return textPreferrer{typ, name}
 	}
 	return typ
@@ -275,7 +737,7 @@ func (tr *typeResolver) newArrayValue(name, elemName string, defaultVal func() p
 		elemValFunc = defaultVal
 	}
 	typ := pgtype.NewArrayType(name, elemOID, elemValFunc)
-	if elemOID == unknownOID {
+	if elemOID == unknownOID || tr.preferText {
 		return textPreferrer{typ, name}
 	}
 	return typ
@@ -300,6 +762,8 @@ func (tr *typeResolver) newDeviceTypeArray() pgtype.ValueTranscoder {
 const findAllDevicesSQL = `SELECT mac, type
 FROM device;`
 
+const findAllDevicesStmt = "pggen_FindAllDevices_74fe8af2835c132c"
+
 type FindAllDevicesRow struct {
 	Mac  pgtype.Macaddr `json:"mac"`
 	Type DeviceType     `json:"type"`
@@ -308,7 +772,7 @@ type FindAllDevicesRow struct {
 // FindAllDevices implements Querier.FindAllDevices.
 func (q *DBQuerier) FindAllDevices(ctx context.Context) ([]FindAllDevicesRow, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "FindAllDevices")
-	rows, err := q.conn.Query(ctx, findAllDevicesSQL)
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(findAllDevicesSQL, findAllDevicesStmt))
 	if err != nil {
 		return nil, fmt.Errorf("query FindAllDevices: %w", err)
 	}
@@ -329,7 +793,7 @@ func (q *DBQuerier) FindAllDevices(ctx context.Context) ([]FindAllDevicesRow, er
 
 // FindAllDevicesBatch implements Querier.FindAllDevicesBatch.
 func (q *DBQuerier) FindAllDevicesBatch(batch genericBatch) {
-	batch.Queue(findAllDevicesSQL)
+	batch.Queue(q.chooseSQL(findAllDevicesSQL, findAllDevicesStmt))
 }
 
 // FindAllDevicesScan implements Querier.FindAllDevicesScan.
@@ -353,13 +817,45 @@ func (q *DBQuerier) FindAllDevicesScan(results pgx.BatchResults) ([]FindAllDevic
 	return items, err
 }
 
+// FindAllDevices queues a FindAllDevices query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) FindAllDevices() *FindAllDevicesHandle {
+	b.q.FindAllDevicesBatch(b.batch)
+	h := &FindAllDevicesHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// FindAllDevicesHandle is the result of a FindAllDevices query queued in a Batch.
+type FindAllDevicesHandle struct {
+	b   *Batch
+	res []FindAllDevicesRow
+	err error
+}
+
+func (h *FindAllDevicesHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.FindAllDevicesScan(results)
+	return h.err
+}
+
+// Result returns the result of the FindAllDevices query. Returns an error if the
+// batch wasn't sent.
+func (h *FindAllDevicesHandle) Result() ([]FindAllDevicesRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("FindAllDevices result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const insertDeviceSQL = `INSERT INTO device (mac, type)
 VALUES ($1, $2);`
 
+const insertDeviceStmt = "pggen_InsertDevice_811207565efb843f"
+
 // InsertDevice implements Querier.InsertDevice.
 func (q *DBQuerier) InsertDevice(ctx context.Context, mac pgtype.Macaddr, typePg DeviceType) (pgconn.CommandTag, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "InsertDevice")
-	cmdTag, err := q.conn.Exec(ctx, insertDeviceSQL, mac, typePg)
+	cmdTag, err := q.conn.Exec(ctx, q.chooseSQL(insertDeviceSQL, insertDeviceStmt), mac, typePg)
 	if err != nil {
 		return cmdTag, fmt.Errorf("exec query InsertDevice: %w", err)
 	}
@@ -368,7 +864,7 @@ func (q *DBQuerier) InsertDevice(ctx context.Context, mac pgtype.Macaddr, typePg
 
 // InsertDeviceBatch implements Querier.InsertDeviceBatch.
 func (q *DBQuerier) InsertDeviceBatch(batch genericBatch, mac pgtype.Macaddr, typePg DeviceType) {
-	batch.Queue(insertDeviceSQL, mac, typePg)
+	batch.Queue(q.chooseSQL(insertDeviceSQL, insertDeviceStmt), mac, typePg)
 }
 
 // InsertDeviceScan implements Querier.InsertDeviceScan.
@@ -380,12 +876,44 @@ func (q *DBQuerier) InsertDeviceScan(results pgx.BatchResults) (pgconn.CommandTa
 	return cmdTag, err
 }
 
+// InsertDevice queues a InsertDevice query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) InsertDevice(mac pgtype.Macaddr, typePg DeviceType) *InsertDeviceHandle {
+	b.q.InsertDeviceBatch(b.batch, mac, typePg)
+	h := &InsertDeviceHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// InsertDeviceHandle is the result of a InsertDevice query queued in a Batch.
+type InsertDeviceHandle struct {
+	b   *Batch
+	res pgconn.CommandTag
+	err error
+}
+
+func (h *InsertDeviceHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.InsertDeviceScan(results)
+	return h.err
+}
+
+// Result returns the result of the InsertDevice query. Returns an error if the
+// batch wasn't sent.
+func (h *InsertDeviceHandle) Result() (pgconn.CommandTag, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("InsertDevice result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const findOneDeviceArraySQL = `SELECT enum_range(NULL::device_type) AS device_types;`
 
+const findOneDeviceArrayStmt = "pggen_FindOneDeviceArray_7a4f8cb5646a86b1"
+
 // FindOneDeviceArray implements Querier.FindOneDeviceArray.
 func (q *DBQuerier) FindOneDeviceArray(ctx context.Context) ([]DeviceType, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "FindOneDeviceArray")
-	row := q.conn.QueryRow(ctx, findOneDeviceArraySQL)
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(findOneDeviceArraySQL, findOneDeviceArrayStmt))
 	item := []DeviceType{}
 	deviceTypesArray := q.types.newDeviceTypeArray()
 	if err := row.Scan(deviceTypesArray); err != nil {
@@ -399,7 +927,7 @@ func (q *DBQuerier) FindOneDeviceArray(ctx context.Context) ([]DeviceType, error
 
 // FindOneDeviceArrayBatch implements Querier.FindOneDeviceArrayBatch.
 func (q *DBQuerier) FindOneDeviceArrayBatch(batch genericBatch) {
-	batch.Queue(findOneDeviceArraySQL)
+	batch.Queue(q.chooseSQL(findOneDeviceArraySQL, findOneDeviceArrayStmt))
 }
 
 // FindOneDeviceArrayScan implements Querier.FindOneDeviceArrayScan.
@@ -416,14 +944,46 @@ func (q *DBQuerier) FindOneDeviceArrayScan(results pgx.BatchResults) ([]DeviceTy
 	return item, nil
 }
 
+// FindOneDeviceArray queues a FindOneDeviceArray query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) FindOneDeviceArray() *FindOneDeviceArrayHandle {
+	b.q.FindOneDeviceArrayBatch(b.batch)
+	h := &FindOneDeviceArrayHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// FindOneDeviceArrayHandle is the result of a FindOneDeviceArray query queued in a Batch.
+type FindOneDeviceArrayHandle struct {
+	b   *Batch
+	res []DeviceType
+	err error
+}
+
+func (h *FindOneDeviceArrayHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.FindOneDeviceArrayScan(results)
+	return h.err
+}
+
+// Result returns the result of the FindOneDeviceArray query. Returns an error if the
+// batch wasn't sent.
+func (h *FindOneDeviceArrayHandle) Result() ([]DeviceType, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("FindOneDeviceArray result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const findManyDeviceArraySQL = `SELECT enum_range('ipad'::device_type, 'iot'::device_type) AS device_types
 UNION ALL
 SELECT enum_range(NULL::device_type) AS device_types;`
 
+const findManyDeviceArrayStmt = "pggen_FindManyDeviceArray_022eb181a4be2eec"
+
 // FindManyDeviceArray implements Querier.FindManyDeviceArray.
 func (q *DBQuerier) FindManyDeviceArray(ctx context.Context) ([][]DeviceType, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "FindManyDeviceArray")
-	rows, err := q.conn.Query(ctx, findManyDeviceArraySQL)
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(findManyDeviceArraySQL, findManyDeviceArrayStmt))
 	if err != nil {
 		return nil, fmt.Errorf("query FindManyDeviceArray: %w", err)
 	}
@@ -448,7 +1008,7 @@ func (q *DBQuerier) FindManyDeviceArray(ctx context.Context) ([][]DeviceType, er
 
 // FindManyDeviceArrayBatch implements Querier.FindManyDeviceArrayBatch.
 func (q *DBQuerier) FindManyDeviceArrayBatch(batch genericBatch) {
-	batch.Queue(findManyDeviceArraySQL)
+	batch.Queue(q.chooseSQL(findManyDeviceArraySQL, findManyDeviceArrayStmt))
 }
 
 // FindManyDeviceArrayScan implements Querier.FindManyDeviceArrayScan.
@@ -476,10 +1036,42 @@ func (q *DBQuerier) FindManyDeviceArrayScan(results pgx.BatchResults) ([][]Devic
 	return items, err
 }
 
+// FindManyDeviceArray queues a FindManyDeviceArray query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) FindManyDeviceArray() *FindManyDeviceArrayHandle {
+	b.q.FindManyDeviceArrayBatch(b.batch)
+	h := &FindManyDeviceArrayHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// FindManyDeviceArrayHandle is the result of a FindManyDeviceArray query queued in a Batch.
+type FindManyDeviceArrayHandle struct {
+	b   *Batch
+	res [][]DeviceType
+	err error
+}
+
+func (h *FindManyDeviceArrayHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.FindManyDeviceArrayScan(results)
+	return h.err
+}
+
+// Result returns the result of the FindManyDeviceArray query. Returns an error if the
+// batch wasn't sent.
+func (h *FindManyDeviceArrayHandle) Result() ([][]DeviceType, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("FindManyDeviceArray result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const findManyDeviceArrayWithNumSQL = `SELECT 1 AS num, enum_range('ipad'::device_type, 'iot'::device_type) AS device_types
 UNION ALL
 SELECT 2 as num, enum_range(NULL::device_type) AS device_types;`
 
+const findManyDeviceArrayWithNumStmt = "pggen_FindManyDeviceArrayWithNum_c638549399f58629"
+
 type FindManyDeviceArrayWithNumRow struct {
 	Num         *int32       `json:"num"`
 	DeviceTypes []DeviceType `json:"device_types"`
@@ -488,7 +1080,7 @@ type FindManyDeviceArrayWithNumRow struct {
 // FindManyDeviceArrayWithNum implements Querier.FindManyDeviceArrayWithNum.
 func (q *DBQuerier) FindManyDeviceArrayWithNum(ctx context.Context) ([]FindManyDeviceArrayWithNumRow, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "FindManyDeviceArrayWithNum")
-	rows, err := q.conn.Query(ctx, findManyDeviceArrayWithNumSQL)
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(findManyDeviceArrayWithNumSQL, findManyDeviceArrayWithNumStmt))
 	if err != nil {
 		return nil, fmt.Errorf("query FindManyDeviceArrayWithNum: %w", err)
 	}
@@ -513,7 +1105,7 @@ func (q *DBQuerier) FindManyDeviceArrayWithNum(ctx context.Context) ([]FindManyD
 
 // FindManyDeviceArrayWithNumBatch implements Querier.FindManyDeviceArrayWithNumBatch.
 func (q *DBQuerier) FindManyDeviceArrayWithNumBatch(batch genericBatch) {
-	batch.Queue(findManyDeviceArrayWithNumSQL)
+	batch.Queue(q.chooseSQL(findManyDeviceArrayWithNumSQL, findManyDeviceArrayWithNumStmt))
 }
 
 // FindManyDeviceArrayWithNumScan implements Querier.FindManyDeviceArrayWithNumScan.
@@ -541,12 +1133,44 @@ func (q *DBQuerier) FindManyDeviceArrayWithNumScan(results pgx.BatchResults) ([]
 	return items, err
 }
 
+// FindManyDeviceArrayWithNum queues a FindManyDeviceArrayWithNum query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) FindManyDeviceArrayWithNum() *FindManyDeviceArrayWithNumHandle {
+	b.q.FindManyDeviceArrayWithNumBatch(b.batch)
+	h := &FindManyDeviceArrayWithNumHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// FindManyDeviceArrayWithNumHandle is the result of a FindManyDeviceArrayWithNum query queued in a Batch.
+type FindManyDeviceArrayWithNumHandle struct {
+	b   *Batch
+	res []FindManyDeviceArrayWithNumRow
+	err error
+}
+
+func (h *FindManyDeviceArrayWithNumHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.FindManyDeviceArrayWithNumScan(results)
+	return h.err
+}
+
+// Result returns the result of the FindManyDeviceArrayWithNum query. Returns an error if the
+// batch wasn't sent.
+func (h *FindManyDeviceArrayWithNumHandle) Result() ([]FindManyDeviceArrayWithNumRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("FindManyDeviceArrayWithNum result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const enumInsideCompositeSQL = `SELECT ROW('08:00:2b:01:02:03'::macaddr, 'phone'::device_type) ::device;`
 
+const enumInsideCompositeStmt = "pggen_EnumInsideComposite_9522df901c46ca93"
+
 // EnumInsideComposite implements Querier.EnumInsideComposite.
 func (q *DBQuerier) EnumInsideComposite(ctx context.Context) (Device, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "EnumInsideComposite")
-	row := q.conn.QueryRow(ctx, enumInsideCompositeSQL)
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(enumInsideCompositeSQL, enumInsideCompositeStmt))
 	var item Device
 	rowRow := q.types.newDevice()
 	if err := row.Scan(rowRow); err != nil {
@@ -560,7 +1184,7 @@ func (q *DBQuerier) EnumInsideComposite(ctx context.Context) (Device, error) {
 
 // EnumInsideCompositeBatch implements Querier.EnumInsideCompositeBatch.
 func (q *DBQuerier) EnumInsideCompositeBatch(batch genericBatch) {
-	batch.Queue(enumInsideCompositeSQL)
+	batch.Queue(q.chooseSQL(enumInsideCompositeSQL, enumInsideCompositeStmt))
 }
 
 // EnumInsideCompositeScan implements Querier.EnumInsideCompositeScan.
@@ -577,6 +1201,36 @@ func (q *DBQuerier) EnumInsideCompositeScan(results pgx.BatchResults) (Device, e
 	return item, nil
 }
 
+// EnumInsideComposite queues a EnumInsideComposite query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) EnumInsideComposite() *EnumInsideCompositeHandle {
+	b.q.EnumInsideCompositeBatch(b.batch)
+	h := &EnumInsideCompositeHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// EnumInsideCompositeHandle is the result of a EnumInsideComposite query queued in a Batch.
+type EnumInsideCompositeHandle struct {
+	b   *Batch
+	res Device
+	err error
+}
+
+func (h *EnumInsideCompositeHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.EnumInsideCompositeScan(results)
+	return h.err
+}
+
+// Result returns the result of the EnumInsideComposite query. Returns an error if the
+// batch wasn't sent.
+func (h *EnumInsideCompositeHandle) Result() (Device, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("EnumInsideComposite result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 // textPreferrer wraps a pgtype.ValueTranscoder and sets the preferred encoding
 // format to text instead binary (the default). pggen uses the text format
 // when the OID is unknownOID because the binary format requires the OID.
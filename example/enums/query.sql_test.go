@@ -2,6 +2,7 @@ package enums
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v4"
 	"github.com/jschaf/pggen/internal/errs"
@@ -174,6 +175,47 @@ func TestNewQuerier_EnumInsideComposite(t *testing.T) {
 	})
 }
 
+func TestDeviceType(t *testing.T) {
+	t.Run("AllDeviceTypeValues", func(t *testing.T) {
+		want := []DeviceType{
+			DeviceTypeUndefined, DeviceTypePhone, DeviceTypeLaptop,
+			DeviceTypeIpad, DeviceTypeDesktop, DeviceTypeIot,
+		}
+		assert.Equal(t, want, AllDeviceTypeValues())
+	})
+
+	t.Run("ParseDeviceType", func(t *testing.T) {
+		got, err := ParseDeviceType("ipad")
+		require.NoError(t, err)
+		assert.Equal(t, DeviceTypeIpad, got)
+		_, err = ParseDeviceType("watch")
+		assert.Error(t, err, "should reject unknown label")
+		assert.False(t, DeviceType("watch").Valid())
+	})
+
+	t.Run("Compare", func(t *testing.T) {
+		// Postgres sort order, not string order: "phone" > "laptop".
+		assert.Equal(t, -1, DeviceTypePhone.Compare(DeviceTypeLaptop))
+		assert.Equal(t, 1, DeviceTypeIot.Compare(DeviceTypeDesktop))
+		assert.Equal(t, 0, DeviceTypeIpad.Compare(DeviceTypeIpad))
+		assert.True(t, DeviceTypeUndefined.Less(DeviceTypePhone))
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		bs, err := json.Marshal([]DeviceType{DeviceTypePhone, DeviceTypeIot})
+		require.NoError(t, err)
+		assert.Equal(t, `["phone","iot"]`, string(bs))
+		_, err = json.Marshal(DeviceType("watch"))
+		assert.Error(t, err, "should reject unknown label")
+
+		var got []DeviceType
+		require.NoError(t, json.Unmarshal(bs, &got))
+		assert.Equal(t, []DeviceType{DeviceTypePhone, DeviceTypeIot}, got)
+		err = json.Unmarshal([]byte(`"watch"`), new(DeviceType))
+		assert.Error(t, err, "should reject unknown label")
+	})
+}
+
 func insertDevice(t *testing.T, q *DBQuerier, mac net.HardwareAddr, device DeviceType) {
 	t.Helper()
 	_, err := q.InsertDevice(context.Background(),
@@ -29,6 +29,7 @@ func TestGenerate_Go_Example_Order(t *testing.T) {
 			Language:      pggen.LangGo,
 			Acronyms:      map[string]string{"mrr": "MRR"},
 			TypeOverrides: map[string]string{"tenant_id": "int"},
+			ReadQuerier:   true,
 		})
 	if err != nil {
 		t.Fatalf("Generate() example/erp/order: %s", err)
@@ -4,18 +4,17 @@ package order
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v4"
+	"time"
 )
 
-// Querier is a typesafe Go interface backed by SQL queries.
-//
-// Methods ending with Batch enqueue a query to run later in a pgx.Batch. After
-// calling SendBatch on pgx.Conn, pgxpool.Pool, or pgx.Tx, use the Scan methods
-// to parse the results.
-type Querier interface {
+// CustomerQuerier is the interface for the queries in customer.sql.
+// Querier embeds CustomerQuerier.
+type CustomerQuerier interface {
 	CreateTenant(ctx context.Context, key string, name string) (CreateTenantRow, error)
 	// CreateTenantBatch enqueues a CreateTenant query into batch to be executed
 	// later by the batch.
@@ -50,6 +49,39 @@ type Querier interface {
 	InsertOrderBatch(batch genericBatch, params InsertOrderParams)
 	// InsertOrderScan scans the result of an executed InsertOrderBatch query.
 	InsertOrderScan(results pgx.BatchResults) (InsertOrderRow, error)
+}
+
+// Querier is a typesafe Go interface backed by SQL queries.
+//
+// Methods ending with Batch enqueue a query to run later in a pgx.Batch. After
+// calling SendBatch on pgx.Conn, pgxpool.Pool, or pgx.Tx, use the Scan methods
+// to parse the results.
+type Querier interface {
+	CustomerQuerier
+	PriceQuerier
+
+	// RunInTx runs fn in a transaction using a Querier bound to the
+	// transaction. See DBQuerier.RunInTx.
+	RunInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error
+}
+
+// ReadQuerier is the subset of Querier with the queries that don't modify
+// tables or lock rows, as determined by the query plan. Useful to route
+// queries to a read replica.
+type ReadQuerier interface {
+	FindOrdersByCustomer(ctx context.Context, customerID int32) ([]FindOrdersByCustomerRow, error)
+	// FindOrdersByCustomerBatch enqueues a FindOrdersByCustomer query into batch to be executed
+	// later by the batch.
+	FindOrdersByCustomerBatch(batch genericBatch, customerID int32)
+	// FindOrdersByCustomerScan scans the result of an executed FindOrdersByCustomerBatch query.
+	FindOrdersByCustomerScan(results pgx.BatchResults) ([]FindOrdersByCustomerRow, error)
+
+	FindProductsInOrder(ctx context.Context, orderID int32) ([]FindProductsInOrderRow, error)
+	// FindProductsInOrderBatch enqueues a FindProductsInOrder query into batch to be executed
+	// later by the batch.
+	FindProductsInOrderBatch(batch genericBatch, orderID int32)
+	// FindProductsInOrderScan scans the result of an executed FindProductsInOrderBatch query.
+	FindProductsInOrderScan(results pgx.BatchResults) ([]FindProductsInOrderRow, error)
 
 	FindOrdersByPrice(ctx context.Context, minTotal pgtype.Numeric) ([]FindOrdersByPriceRow, error)
 	// FindOrdersByPriceBatch enqueues a FindOrdersByPrice query into batch to be executed
@@ -66,9 +98,13 @@ type Querier interface {
 	FindOrdersMRRScan(results pgx.BatchResults) ([]FindOrdersMRRRow, error)
 }
 
+var _ ReadQuerier = &DBQuerier{}
+
 type DBQuerier struct {
-	conn  genericConn   // underlying Postgres transport to use
-	types *typeResolver // resolve types by name
+	conn    genericConn   // underlying Postgres transport to use
+	replica genericConn   // transport for read-only queries; nil unless routing
+	types   *typeResolver // resolve types by name
+	cfg     QuerierConfig // config used to create the querier
 }
 
 var _ Querier = &DBQuerier{}
@@ -114,17 +150,362 @@ type QuerierConfig struct {
 	// transitive dependencies, pggen will use the binary encoding format for
 	// the input parameter.
 	DataTypes []pgtype.DataType
+
+	// UsePreparedStatements runs queries by the statement names prepared by
+	// PrepareAllQueries instead of by SQL text. Every connection used by the
+	// querier must run PrepareAllQueries first, typically in the AfterConnect
+	// callback of pgxpool.Config.
+	//
+	// If false, the default, queries run by SQL text, which works whether or
+	// not the connection prepared the statements.
+	UsePreparedStatements bool
+
+	// PgBouncerCompat enables compatibility with PgBouncer in transaction
+	// pooling mode, which doesn't support prepared statements. Each query runs
+	// with pgx.QuerySimpleProtocol, pggen-generated transcoders always encode
+	// in the text format, UsePreparedStatements is ignored, and
+	// PrepareAllQueriesConfig doesn't prepare any statements.
+	//
+	// pgx only sends a pgx.Batch with the simple protocol if
+	// PreferSimpleProtocol is set on pgx.ConnConfig, so Batch.Send runs the
+	// queued queries one at a time with the simple protocol instead of in a
+	// single network round-trip.
+	PgBouncerCompat bool
 }
 
 // NewQuerierConfig creates a DBQuerier that implements Querier with the given
 // config. conn is typically *pgx.Conn, pgx.Tx, or *pgxpool.Pool.
 func NewQuerierConfig(conn genericConn, cfg QuerierConfig) *DBQuerier {
-	return &DBQuerier{conn: conn, types: newTypeResolver(cfg.DataTypes)}
+	if cfg.PgBouncerCompat {
+		conn = simpleProtocolConn{conn}
+	}
+	return &DBQuerier{conn: conn, types: newTypeResolver(cfg.DataTypes, cfg.PgBouncerCompat), cfg: cfg}
 }
 
 // WithTx creates a new DBQuerier that uses the transaction to run all queries.
+// The returned DBQuerier keeps the config of q. If q is a routing querier, the
+// returned DBQuerier runs read-only queries in the transaction too.
 func (q *DBQuerier) WithTx(tx pgx.Tx) (*DBQuerier, error) {
-	return &DBQuerier{conn: tx}, nil
+	var conn genericConn = tx
+	if q.cfg.PgBouncerCompat {
+		conn = simpleProtocolConn{conn}
+	}
+	return &DBQuerier{conn: conn, types: q.types, cfg: q.cfg}, nil
+}
+
+// NewRoutingQuerier creates a DBQuerier that runs read-only queries on replica
+// and all other queries on primary. pggen classifies a query as read-only if
+// the query plan doesn't modify a table or lock rows. Queries with the
+// route=primary pragma, batches, and transactions always use primary.
+func NewRoutingQuerier(primary, replica genericConn) *DBQuerier {
+	return NewRoutingQuerierConfig(primary, replica, QuerierConfig{})
+}
+
+// NewRoutingQuerierConfig creates a DBQuerier like NewRoutingQuerier with the
+// given config. The config applies to both primary and replica.
+func NewRoutingQuerierConfig(primary, replica genericConn, cfg QuerierConfig) *DBQuerier {
+	q := NewQuerierConfig(primary, cfg)
+	if cfg.PgBouncerCompat {
+		replica = simpleProtocolConn{replica}
+	}
+	q.replica = replica
+	return q
+}
+
+// readConn returns the Postgres transport for read-only queries.
+func (q *DBQuerier) readConn() genericConn {
+	if q.replica == nil {
+		return q.conn
+	}
+	return q.replica
+}
+
+// simpleProtocolConn is a genericConn that runs all queries with the simple
+// protocol, overriding pgx.ConnConfig.PreferSimpleProtocol.
+type simpleProtocolConn struct {
+	genericConn
+}
+
+func (c simpleProtocolConn) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return c.genericConn.Query(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, args...)...)
+}
+
+func (c simpleProtocolConn) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return c.genericConn.QueryRow(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, args...)...)
+}
+
+func (c simpleProtocolConn) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	return c.genericConn.Exec(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, arguments...)...)
+}
+
+// transport returns the underlying Postgres transport of q, unwrapping any
+// wrapper added by the config.
+func (q *DBQuerier) transport() genericConn {
+	if c, ok := q.conn.(simpleProtocolConn); ok {
+		return c.genericConn
+	}
+	return q.conn
+}
+
+// txBeginner is any Postgres connection transport that can begin a
+// transaction, most commonly *pgx.Conn or *pgxpool.Pool.
+type txBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// TxOptions controls how RunInTx begins and retries a transaction.
+type TxOptions struct {
+	// TxOptions are the options used to begin a top-level transaction. Ignored
+	// for nested transactions, which use a savepoint.
+	pgx.TxOptions
+
+	// MaxRetries is the maximum number of times to retry a top-level
+	// transaction that failed with a serialization failure (SQLSTATE 40001) or
+	// a deadlock (SQLSTATE 40P01). Zero disables retries.
+	MaxRetries int
+
+	// Backoff returns how long to wait before the nth retry, starting at 1. If
+	// nil, retries immediately.
+	Backoff func(retry int) time.Duration
+}
+
+// RunInTx runs fn in a transaction. fn receives a Querier bound to the
+// transaction with the same config as q. If fn returns nil, RunInTx commits
+// the transaction; otherwise, RunInTx rolls back the transaction and returns
+// the error from fn.
+//
+// If q is already bound to a transaction, like the Querier passed to fn,
+// RunInTx creates a nested transaction using a savepoint. Only the top-level
+// transaction is retried because a serialization failure aborts the entire
+// transaction.
+func (q *DBQuerier) RunInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error {
+	_, isNested := q.transport().(pgx.Tx)
+	for retry := 1; ; retry++ {
+		err := q.runInTx(ctx, opts, fn)
+		if err == nil || isNested || retry > opts.MaxRetries || !isRetryableTxErr(err) {
+			return err
+		}
+		if opts.Backoff == nil {
+			continue
+		}
+		timer := time.NewTimer(opts.Backoff(retry))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("retry tx: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// runInTx runs a single attempt of fn in a transaction.
+func (q *DBQuerier) runInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error {
+	var tx pgx.Tx
+	var err error
+	switch conn := q.transport().(type) {
+	case pgx.Tx:
+		tx, err = conn.Begin(ctx) // savepoint
+	case txBeginner:
+		tx, err = conn.BeginTx(ctx, opts.TxOptions)
+	default:
+		return fmt.Errorf("begin tx: conn of type %T cannot begin a transaction", conn)
+	}
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	txq, _ := q.WithTx(tx)
+	if err := fn(txq); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			return fmt.Errorf("rollback tx: %v; original error: %w", rbErr, err)
+		}
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// isRetryableTxErr returns true if err is a serialization failure or deadlock
+// such that retrying the entire transaction might succeed.
+func isRetryableTxErr(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+// batchSender is any Postgres connection transport that can send a batch of
+// queries, most commonly *pgx.Conn, pgx.Tx, or *pgxpool.Pool.
+type batchSender interface {
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// Batch is a typed builder for a pgx.Batch. Each query method on Batch queues
+// the query and returns a handle. After calling Send, use Result on the handle
+// to get the typed result of the query.
+type Batch struct {
+	q       *DBQuerier
+	batch   queueBatch    // *pgx.Batch, or *simpleBatch with PgBouncerCompat
+	handles []batchHandle // handles in the same order as the queued queries
+	sent    bool
+}
+
+// batchHandle scans the result of a single query queued in a Batch.
+type batchHandle interface {
+	scan(results pgx.BatchResults) error
+}
+
+var errBatchNotSent = errors.New("batch not sent")
+
+// queueBatch is a genericBatch that counts the queued queries.
+type queueBatch interface {
+	genericBatch
+	Len() int
+}
+
+// NewBatch creates a typed Batch that sends queries using the conn of q.
+func (q *DBQuerier) NewBatch() *Batch {
+	if q.cfg.PgBouncerCompat {
+		return &Batch{q: q, batch: &simpleBatch{}}
+	}
+	return &Batch{q: q, batch: &pgx.Batch{}}
+}
+
+// Send sends all queued queries with SendBatch and scans the result of each
+// query into its handle in queue order. If any query fails, Send returns a
+// BatchError with the errors of all failed queries.
+func (b *Batch) Send(ctx context.Context) error {
+	if b.sent {
+		return errors.New("send batch: batch already sent")
+	}
+	if b.batch.Len() != len(b.handles) {
+		return fmt.Errorf("send batch: %d queued queries but %d handles", b.batch.Len(), len(b.handles))
+	}
+	var results pgx.BatchResults
+	switch batch := b.batch.(type) {
+	case *simpleBatch:
+		b.sent = true
+		results = &simpleBatchResults{ctx: ctx, conn: b.q.conn, queries: batch.queries}
+	case *pgx.Batch:
+		sender, ok := b.q.transport().(batchSender)
+		if !ok {
+			return fmt.Errorf("send batch: conn of type %T cannot send a batch", b.q.transport())
+		}
+		b.sent = true
+		results = sender.SendBatch(ctx, batch)
+	default:
+		return fmt.Errorf("send batch: unknown batch type %T", b.batch)
+	}
+	var errs []error
+	for _, h := range b.handles {
+		if err := h.scan(results); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := results.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("close batch results: %w", err))
+	}
+	if len(errs) > 0 {
+		return BatchError{Errs: errs}
+	}
+	return nil
+}
+
+// BatchError is the error returned by Batch.Send with the errors of all failed
+// queries in queue order.
+type BatchError struct {
+	Errs []error
+}
+
+func (e BatchError) Error() string {
+	msg := fmt.Sprintf("send batch: %d errors", len(e.Errs))
+	for _, err := range e.Errs {
+		msg += "; " + err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the first error.
+func (e BatchError) Unwrap() error { return e.Errs[0] }
+
+// simpleBatch is a genericBatch that records the queued queries so that
+// simpleBatchResults can run them with the simple protocol.
+type simpleBatch struct {
+	queries []simpleQuery
+}
+
+type simpleQuery struct {
+	sql  string
+	args []interface{}
+}
+
+func (b *simpleBatch) Queue(query string, arguments ...interface{}) {
+	b.queries = append(b.queries, simpleQuery{sql: query, args: arguments})
+}
+
+func (b *simpleBatch) Len() int { return len(b.queries) }
+
+// simpleBatchResults is a pgx.BatchResults that runs the next query of a
+// simpleBatch on conn when reading its result. conn is a simpleProtocolConn,
+// so each query runs with the simple protocol.
+type simpleBatchResults struct {
+	ctx     context.Context
+	conn    genericConn
+	queries []simpleQuery
+	idx     int // index of the next query
+}
+
+func (r *simpleBatchResults) next() (simpleQuery, error) {
+	if r.idx == len(r.queries) {
+		return simpleQuery{}, fmt.Errorf("no result for batch query %d", r.idx)
+	}
+	q := r.queries[r.idx]
+	r.idx++
+	return q, nil
+}
+
+func (r *simpleBatchResults) Exec() (pgconn.CommandTag, error) {
+	q, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	return r.conn.Exec(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) Query() (pgx.Rows, error) {
+	q, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	return r.conn.Query(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) QueryRow() pgx.Row {
+	q, err := r.next()
+	if err != nil {
+		return errRow{err: err}
+	}
+	return r.conn.QueryRow(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) Close() error { return nil }
+
+// errRow is a pgx.Row that returns err from Scan.
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(...interface{}) error { return r.err }
+
+// chooseSQL returns the name of the prepared statement if the querier uses
+// prepared statements. Otherwise, returns the SQL text.
+func (q *DBQuerier) chooseSQL(sql, stmtName string) string {
+	if q.cfg.UsePreparedStatements && !q.cfg.PgBouncerCompat {
+		return stmtName
+	}
+	return sql
 }
 
 // preparer is any Postgres connection transport that provides a way to prepare
@@ -137,48 +518,63 @@ type preparer interface {
 // queries in querier files. Typical usage is as the AfterConnect callback
 // for pgxpool.Config
 //
-// pgx will use the prepared statement if available. Calling PrepareAllQueries
-// is an optional optimization to avoid a network round-trip the first time pgx
-// runs a query if pgx statement caching is enabled.
+// Each statement is prepared under a deterministic name derived from the query
+// name and SQL text. A querier created with
+// QuerierConfig.UsePreparedStatements runs queries by those names, avoiding a
+// network round-trip to prepare the statement the first time pgx runs a query.
+//
+// Use PrepareAllQueriesConfig with the config of the querier to skip preparing
+// statements with QuerierConfig.PgBouncerCompat.
 func PrepareAllQueries(ctx context.Context, p preparer) error {
-	if _, err := p.Prepare(ctx, createTenantSQL, createTenantSQL); err != nil {
+	if _, err := p.Prepare(ctx, createTenantStmt, createTenantSQL); err != nil {
 		return fmt.Errorf("prepare query 'CreateTenant': %w", err)
 	}
-	if _, err := p.Prepare(ctx, findOrdersByCustomerSQL, findOrdersByCustomerSQL); err != nil {
+	if _, err := p.Prepare(ctx, findOrdersByCustomerStmt, findOrdersByCustomerSQL); err != nil {
 		return fmt.Errorf("prepare query 'FindOrdersByCustomer': %w", err)
 	}
-	if _, err := p.Prepare(ctx, findProductsInOrderSQL, findProductsInOrderSQL); err != nil {
+	if _, err := p.Prepare(ctx, findProductsInOrderStmt, findProductsInOrderSQL); err != nil {
 		return fmt.Errorf("prepare query 'FindProductsInOrder': %w", err)
 	}
-	if _, err := p.Prepare(ctx, insertCustomerSQL, insertCustomerSQL); err != nil {
+	if _, err := p.Prepare(ctx, insertCustomerStmt, insertCustomerSQL); err != nil {
 		return fmt.Errorf("prepare query 'InsertCustomer': %w", err)
 	}
-	if _, err := p.Prepare(ctx, insertOrderSQL, insertOrderSQL); err != nil {
+	if _, err := p.Prepare(ctx, insertOrderStmt, insertOrderSQL); err != nil {
 		return fmt.Errorf("prepare query 'InsertOrder': %w", err)
 	}
-	if _, err := p.Prepare(ctx, findOrdersByPriceSQL, findOrdersByPriceSQL); err != nil {
+	if _, err := p.Prepare(ctx, findOrdersByPriceStmt, findOrdersByPriceSQL); err != nil {
 		return fmt.Errorf("prepare query 'FindOrdersByPrice': %w", err)
 	}
-	if _, err := p.Prepare(ctx, findOrdersMRRSQL, findOrdersMRRSQL); err != nil {
+	if _, err := p.Prepare(ctx, findOrdersMRRStmt, findOrdersMRRSQL); err != nil {
 		return fmt.Errorf("prepare query 'FindOrdersMRR': %w", err)
 	}
 	return nil
 }
 
+// PrepareAllQueriesConfig is like PrepareAllQueries but doesn't prepare any
+// statements if cfg.PgBouncerCompat is set, because PgBouncer in transaction
+// pooling mode doesn't support prepared statements.
+func PrepareAllQueriesConfig(ctx context.Context, p preparer, cfg QuerierConfig) error {
+	if cfg.PgBouncerCompat {
+		return nil
+	}
+	return PrepareAllQueries(ctx, p)
+}
+
 // typeResolver looks up the pgtype.ValueTranscoder by Postgres type name.
 type typeResolver struct {
-	connInfo *pgtype.ConnInfo // types by Postgres type name
+	connInfo   *pgtype.ConnInfo // types by Postgres type name
+	preferText bool             // if true, always encode in the text format
 }
 
-func newTypeResolver(types []pgtype.DataType) *typeResolver {
+func newTypeResolver(types []pgtype.DataType, preferText bool) *typeResolver {
 	ci := pgtype.NewConnInfo()
 	for _, typ := range types {
-		if txt, ok := typ.Value.(textPreferrer); ok && typ.OID != unknownOID {
+		if txt, ok := typ.Value.(textPreferrer); ok && typ.OID != unknownOID && !preferText {
 			typ.Value = txt.ValueTranscoder
 		}
 		ci.RegisterDataType(typ)
 	}
-	return &typeResolver{connInfo: ci}
+	return &typeResolver{connInfo: ci, preferText: preferText}
 }
 
 // findValue find the OID, and pgtype.ValueTranscoder for a Postgres type name.
@@ -187,8 +583,11 @@ func (tr *typeResolver) findValue(name string) (uint32, pgtype.ValueTranscoder,
 	if !ok {
 		return 0, nil, false
 	}
-	v := pgtype.NewValue(typ.Value)
-	return typ.OID, v.(pgtype.ValueTranscoder), true
+	v := pgtype.NewValue(typ.Value).(pgtype.ValueTranscoder)
+	if _, isText := v.(textPreferrer); tr.preferText && !isText {
+		v = textPreferrer{v, name}
+	}
+	return typ.OID, v, true
 }
 
 // setValue sets the value of a ValueTranscoder to a value that should always
@@ -204,6 +603,8 @@ const createTenantSQL = `INSERT INTO tenant (tenant_id, name)
 VALUES (base36_decode($1::text)::tenant_id, $2::text)
 RETURNING *;`
 
+const createTenantStmt = "pggen_CreateTenant_de655d30f2d758e3"
+
 type CreateTenantRow struct {
 	TenantID int     `json:"tenant_id"`
 	Rname    *string `json:"rname"`
@@ -213,7 +614,7 @@ type CreateTenantRow struct {
 // CreateTenant implements Querier.CreateTenant.
 func (q *DBQuerier) CreateTenant(ctx context.Context, key string, name string) (CreateTenantRow, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "CreateTenant")
-	row := q.conn.QueryRow(ctx, createTenantSQL, key, name)
+	row := q.conn.QueryRow(ctx, q.chooseSQL(createTenantSQL, createTenantStmt), key, name)
 	var item CreateTenantRow
 	if err := row.Scan(&item.TenantID, &item.Rname, &item.Name); err != nil {
 		return item, fmt.Errorf("query CreateTenant: %w", err)
@@ -223,7 +624,7 @@ func (q *DBQuerier) CreateTenant(ctx context.Context, key string, name string) (
 
 // CreateTenantBatch implements Querier.CreateTenantBatch.
 func (q *DBQuerier) CreateTenantBatch(batch genericBatch, key string, name string) {
-	batch.Queue(createTenantSQL, key, name)
+	batch.Queue(q.chooseSQL(createTenantSQL, createTenantStmt), key, name)
 }
 
 // CreateTenantScan implements Querier.CreateTenantScan.
@@ -236,10 +637,42 @@ func (q *DBQuerier) CreateTenantScan(results pgx.BatchResults) (CreateTenantRow,
 	return item, nil
 }
 
+// CreateTenant queues a CreateTenant query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) CreateTenant(key string, name string) *CreateTenantHandle {
+	b.q.CreateTenantBatch(b.batch, key, name)
+	h := &CreateTenantHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// CreateTenantHandle is the result of a CreateTenant query queued in a Batch.
+type CreateTenantHandle struct {
+	b   *Batch
+	res CreateTenantRow
+	err error
+}
+
+func (h *CreateTenantHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.CreateTenantScan(results)
+	return h.err
+}
+
+// Result returns the result of the CreateTenant query. Returns an error if the
+// batch wasn't sent.
+func (h *CreateTenantHandle) Result() (CreateTenantRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("CreateTenant result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const findOrdersByCustomerSQL = `SELECT *
 FROM orders
 WHERE customer_id = $1;`
 
+const findOrdersByCustomerStmt = "pggen_FindOrdersByCustomer_ad7426c2bcfff649"
+
 type FindOrdersByCustomerRow struct {
 	OrderID    int32              `json:"order_id"`
 	OrderDate  pgtype.Timestamptz `json:"order_date"`
@@ -250,7 +683,7 @@ type FindOrdersByCustomerRow struct {
 // FindOrdersByCustomer implements Querier.FindOrdersByCustomer.
 func (q *DBQuerier) FindOrdersByCustomer(ctx context.Context, customerID int32) ([]FindOrdersByCustomerRow, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "FindOrdersByCustomer")
-	rows, err := q.conn.Query(ctx, findOrdersByCustomerSQL, customerID)
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(findOrdersByCustomerSQL, findOrdersByCustomerStmt), customerID)
 	if err != nil {
 		return nil, fmt.Errorf("query FindOrdersByCustomer: %w", err)
 	}
@@ -271,7 +704,7 @@ func (q *DBQuerier) FindOrdersByCustomer(ctx context.Context, customerID int32)
 
 // FindOrdersByCustomerBatch implements Querier.FindOrdersByCustomerBatch.
 func (q *DBQuerier) FindOrdersByCustomerBatch(batch genericBatch, customerID int32) {
-	batch.Queue(findOrdersByCustomerSQL, customerID)
+	batch.Queue(q.chooseSQL(findOrdersByCustomerSQL, findOrdersByCustomerStmt), customerID)
 }
 
 // FindOrdersByCustomerScan implements Querier.FindOrdersByCustomerScan.
@@ -295,12 +728,44 @@ func (q *DBQuerier) FindOrdersByCustomerScan(results pgx.BatchResults) ([]FindOr
 	return items, err
 }
 
+// FindOrdersByCustomer queues a FindOrdersByCustomer query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) FindOrdersByCustomer(customerID int32) *FindOrdersByCustomerHandle {
+	b.q.FindOrdersByCustomerBatch(b.batch, customerID)
+	h := &FindOrdersByCustomerHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// FindOrdersByCustomerHandle is the result of a FindOrdersByCustomer query queued in a Batch.
+type FindOrdersByCustomerHandle struct {
+	b   *Batch
+	res []FindOrdersByCustomerRow
+	err error
+}
+
+func (h *FindOrdersByCustomerHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.FindOrdersByCustomerScan(results)
+	return h.err
+}
+
+// Result returns the result of the FindOrdersByCustomer query. Returns an error if the
+// batch wasn't sent.
+func (h *FindOrdersByCustomerHandle) Result() ([]FindOrdersByCustomerRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("FindOrdersByCustomer result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const findProductsInOrderSQL = `SELECT o.order_id, p.product_id, p.name
 FROM orders o
   INNER JOIN order_product op USING (order_id)
   INNER JOIN product p USING (product_id)
 WHERE o.order_id = $1;`
 
+const findProductsInOrderStmt = "pggen_FindProductsInOrder_ffa6ed446f2b6183"
+
 type FindProductsInOrderRow struct {
 	OrderID   *int32  `json:"order_id"`
 	ProductID *int32  `json:"product_id"`
@@ -310,7 +775,7 @@ type FindProductsInOrderRow struct {
 // FindProductsInOrder implements Querier.FindProductsInOrder.
 func (q *DBQuerier) FindProductsInOrder(ctx context.Context, orderID int32) ([]FindProductsInOrderRow, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "FindProductsInOrder")
-	rows, err := q.conn.Query(ctx, findProductsInOrderSQL, orderID)
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(findProductsInOrderSQL, findProductsInOrderStmt), orderID)
 	if err != nil {
 		return nil, fmt.Errorf("query FindProductsInOrder: %w", err)
 	}
@@ -331,7 +796,7 @@ func (q *DBQuerier) FindProductsInOrder(ctx context.Context, orderID int32) ([]F
 
 // FindProductsInOrderBatch implements Querier.FindProductsInOrderBatch.
 func (q *DBQuerier) FindProductsInOrderBatch(batch genericBatch, orderID int32) {
-	batch.Queue(findProductsInOrderSQL, orderID)
+	batch.Queue(q.chooseSQL(findProductsInOrderSQL, findProductsInOrderStmt), orderID)
 }
 
 // FindProductsInOrderScan implements Querier.FindProductsInOrderScan.
@@ -355,10 +820,42 @@ func (q *DBQuerier) FindProductsInOrderScan(results pgx.BatchResults) ([]FindPro
 	return items, err
 }
 
+// FindProductsInOrder queues a FindProductsInOrder query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) FindProductsInOrder(orderID int32) *FindProductsInOrderHandle {
+	b.q.FindProductsInOrderBatch(b.batch, orderID)
+	h := &FindProductsInOrderHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// FindProductsInOrderHandle is the result of a FindProductsInOrder query queued in a Batch.
+type FindProductsInOrderHandle struct {
+	b   *Batch
+	res []FindProductsInOrderRow
+	err error
+}
+
+func (h *FindProductsInOrderHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.FindProductsInOrderScan(results)
+	return h.err
+}
+
+// Result returns the result of the FindProductsInOrder query. Returns an error if the
+// batch wasn't sent.
+func (h *FindProductsInOrderHandle) Result() ([]FindProductsInOrderRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("FindProductsInOrder result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const insertCustomerSQL = `INSERT INTO customer (first_name, last_name, email)
 VALUES ($1, $2, $3)
 RETURNING *;`
 
+const insertCustomerStmt = "pggen_InsertCustomer_d451b2a574736700"
+
 type InsertCustomerParams struct {
 	FirstName string
 	LastName  string
@@ -375,7 +872,7 @@ type InsertCustomerRow struct {
 // InsertCustomer implements Querier.InsertCustomer.
 func (q *DBQuerier) InsertCustomer(ctx context.Context, params InsertCustomerParams) (InsertCustomerRow, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "InsertCustomer")
-	row := q.conn.QueryRow(ctx, insertCustomerSQL, params.FirstName, params.LastName, params.Email)
+	row := q.conn.QueryRow(ctx, q.chooseSQL(insertCustomerSQL, insertCustomerStmt), params.FirstName, params.LastName, params.Email)
 	var item InsertCustomerRow
 	if err := row.Scan(&item.CustomerID, &item.FirstName, &item.LastName, &item.Email); err != nil {
 		return item, fmt.Errorf("query InsertCustomer: %w", err)
@@ -385,7 +882,7 @@ func (q *DBQuerier) InsertCustomer(ctx context.Context, params InsertCustomerPar
 
 // InsertCustomerBatch implements Querier.InsertCustomerBatch.
 func (q *DBQuerier) InsertCustomerBatch(batch genericBatch, params InsertCustomerParams) {
-	batch.Queue(insertCustomerSQL, params.FirstName, params.LastName, params.Email)
+	batch.Queue(q.chooseSQL(insertCustomerSQL, insertCustomerStmt), params.FirstName, params.LastName, params.Email)
 }
 
 // InsertCustomerScan implements Querier.InsertCustomerScan.
@@ -398,10 +895,42 @@ func (q *DBQuerier) InsertCustomerScan(results pgx.BatchResults) (InsertCustomer
 	return item, nil
 }
 
+// InsertCustomer queues a InsertCustomer query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) InsertCustomer(params InsertCustomerParams) *InsertCustomerHandle {
+	b.q.InsertCustomerBatch(b.batch, params)
+	h := &InsertCustomerHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// InsertCustomerHandle is the result of a InsertCustomer query queued in a Batch.
+type InsertCustomerHandle struct {
+	b   *Batch
+	res InsertCustomerRow
+	err error
+}
+
+func (h *InsertCustomerHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.InsertCustomerScan(results)
+	return h.err
+}
+
+// Result returns the result of the InsertCustomer query. Returns an error if the
+// batch wasn't sent.
+func (h *InsertCustomerHandle) Result() (InsertCustomerRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("InsertCustomer result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const insertOrderSQL = `INSERT INTO orders (order_date, order_total, customer_id)
 VALUES ($1, $2, $3)
 RETURNING *;`
 
+const insertOrderStmt = "pggen_InsertOrder_890650cfcce3a725"
+
 type InsertOrderParams struct {
 	OrderDate  pgtype.Timestamptz
 	OrderTotal pgtype.Numeric
@@ -418,7 +947,7 @@ type InsertOrderRow struct {
 // InsertOrder implements Querier.InsertOrder.
 func (q *DBQuerier) InsertOrder(ctx context.Context, params InsertOrderParams) (InsertOrderRow, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "InsertOrder")
-	row := q.conn.QueryRow(ctx, insertOrderSQL, params.OrderDate, params.OrderTotal, params.CustID)
+	row := q.conn.QueryRow(ctx, q.chooseSQL(insertOrderSQL, insertOrderStmt), params.OrderDate, params.OrderTotal, params.CustID)
 	var item InsertOrderRow
 	if err := row.Scan(&item.OrderID, &item.OrderDate, &item.OrderTotal, &item.CustomerID); err != nil {
 		return item, fmt.Errorf("query InsertOrder: %w", err)
@@ -428,7 +957,7 @@ func (q *DBQuerier) InsertOrder(ctx context.Context, params InsertOrderParams) (
 
 // InsertOrderBatch implements Querier.InsertOrderBatch.
 func (q *DBQuerier) InsertOrderBatch(batch genericBatch, params InsertOrderParams) {
-	batch.Queue(insertOrderSQL, params.OrderDate, params.OrderTotal, params.CustID)
+	batch.Queue(q.chooseSQL(insertOrderSQL, insertOrderStmt), params.OrderDate, params.OrderTotal, params.CustID)
 }
 
 // InsertOrderScan implements Querier.InsertOrderScan.
@@ -441,6 +970,36 @@ func (q *DBQuerier) InsertOrderScan(results pgx.BatchResults) (InsertOrderRow, e
 	return item, nil
 }
 
+// InsertOrder queues a InsertOrder query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) InsertOrder(params InsertOrderParams) *InsertOrderHandle {
+	b.q.InsertOrderBatch(b.batch, params)
+	h := &InsertOrderHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// InsertOrderHandle is the result of a InsertOrder query queued in a Batch.
+type InsertOrderHandle struct {
+	b   *Batch
+	res InsertOrderRow
+	err error
+}
+
+func (h *InsertOrderHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.InsertOrderScan(results)
+	return h.err
+}
+
+// Result returns the result of the InsertOrder query. Returns an error if the
+// batch wasn't sent.
+func (h *InsertOrderHandle) Result() (InsertOrderRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("InsertOrder result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 // textPreferrer wraps a pgtype.ValueTranscoder and sets the preferred encoding
 // format to text instead binary (the default). pggen uses the text format
 // when the OID is unknownOID because the binary format requires the OID.
@@ -55,7 +55,7 @@ func TestNewQuerier_FindOrdersByCustomer_PrepareAllQueries(t *testing.T) {
 		pgtest.WithGuardedStmtCache(insertOrderSQL, insertCustomerSQL))
 	defer cleanup()
 	ctx := context.Background()
-	q := NewQuerier(conn)
+	q := NewQuerierConfig(conn, QuerierConfig{UsePreparedStatements: true})
 
 	t.Run("PrepareAllQueries", func(t *testing.T) {
 		err := PrepareAllQueries(ctx, conn)
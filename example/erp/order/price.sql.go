@@ -9,8 +9,28 @@ import (
 	"github.com/jackc/pgx/v4"
 )
 
+// PriceQuerier is the interface for the queries in price.sql.
+// Querier embeds PriceQuerier.
+type PriceQuerier interface {
+	FindOrdersByPrice(ctx context.Context, minTotal pgtype.Numeric) ([]FindOrdersByPriceRow, error)
+	// FindOrdersByPriceBatch enqueues a FindOrdersByPrice query into batch to be executed
+	// later by the batch.
+	FindOrdersByPriceBatch(batch genericBatch, minTotal pgtype.Numeric)
+	// FindOrdersByPriceScan scans the result of an executed FindOrdersByPriceBatch query.
+	FindOrdersByPriceScan(results pgx.BatchResults) ([]FindOrdersByPriceRow, error)
+
+	FindOrdersMRR(ctx context.Context) ([]FindOrdersMRRRow, error)
+	// FindOrdersMRRBatch enqueues a FindOrdersMRR query into batch to be executed
+	// later by the batch.
+	FindOrdersMRRBatch(batch genericBatch)
+	// FindOrdersMRRScan scans the result of an executed FindOrdersMRRBatch query.
+	FindOrdersMRRScan(results pgx.BatchResults) ([]FindOrdersMRRRow, error)
+}
+
 const findOrdersByPriceSQL = `SELECT * FROM orders WHERE order_total > $1;`
 
+const findOrdersByPriceStmt = "pggen_FindOrdersByPrice_e5cb52ebfb90f390"
+
 type FindOrdersByPriceRow struct {
 	OrderID    int32              `json:"order_id"`
 	OrderDate  pgtype.Timestamptz `json:"order_date"`
@@ -21,7 +41,7 @@ type FindOrdersByPriceRow struct {
 // FindOrdersByPrice implements Querier.FindOrdersByPrice.
 func (q *DBQuerier) FindOrdersByPrice(ctx context.Context, minTotal pgtype.Numeric) ([]FindOrdersByPriceRow, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "FindOrdersByPrice")
-	rows, err := q.conn.Query(ctx, findOrdersByPriceSQL, minTotal)
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(findOrdersByPriceSQL, findOrdersByPriceStmt), minTotal)
 	if err != nil {
 		return nil, fmt.Errorf("query FindOrdersByPrice: %w", err)
 	}
@@ -42,7 +62,7 @@ func (q *DBQuerier) FindOrdersByPrice(ctx context.Context, minTotal pgtype.Numer
 
 // FindOrdersByPriceBatch implements Querier.FindOrdersByPriceBatch.
 func (q *DBQuerier) FindOrdersByPriceBatch(batch genericBatch, minTotal pgtype.Numeric) {
-	batch.Queue(findOrdersByPriceSQL, minTotal)
+	batch.Queue(q.chooseSQL(findOrdersByPriceSQL, findOrdersByPriceStmt), minTotal)
 }
 
 // FindOrdersByPriceScan implements Querier.FindOrdersByPriceScan.
@@ -66,10 +86,42 @@ func (q *DBQuerier) FindOrdersByPriceScan(results pgx.BatchResults) ([]FindOrder
 	return items, err
 }
 
+// FindOrdersByPrice queues a FindOrdersByPrice query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) FindOrdersByPrice(minTotal pgtype.Numeric) *FindOrdersByPriceHandle {
+	b.q.FindOrdersByPriceBatch(b.batch, minTotal)
+	h := &FindOrdersByPriceHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// FindOrdersByPriceHandle is the result of a FindOrdersByPrice query queued in a Batch.
+type FindOrdersByPriceHandle struct {
+	b   *Batch
+	res []FindOrdersByPriceRow
+	err error
+}
+
+func (h *FindOrdersByPriceHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.FindOrdersByPriceScan(results)
+	return h.err
+}
+
+// Result returns the result of the FindOrdersByPrice query. Returns an error if the
+// batch wasn't sent.
+func (h *FindOrdersByPriceHandle) Result() ([]FindOrdersByPriceRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("FindOrdersByPrice result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const findOrdersMRRSQL = `SELECT date_trunc('month', order_date) AS month, sum(order_total) AS order_mrr
 FROM orders
 GROUP BY date_trunc('month', order_date);`
 
+const findOrdersMRRStmt = "pggen_FindOrdersMRR_a652b0c75a97e455"
+
 type FindOrdersMRRRow struct {
 	Month    pgtype.Timestamptz `json:"month"`
 	OrderMRR pgtype.Numeric     `json:"order_mrr"`
@@ -78,7 +130,7 @@ type FindOrdersMRRRow struct {
 // FindOrdersMRR implements Querier.FindOrdersMRR.
 func (q *DBQuerier) FindOrdersMRR(ctx context.Context) ([]FindOrdersMRRRow, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "FindOrdersMRR")
-	rows, err := q.conn.Query(ctx, findOrdersMRRSQL)
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(findOrdersMRRSQL, findOrdersMRRStmt))
 	if err != nil {
 		return nil, fmt.Errorf("query FindOrdersMRR: %w", err)
 	}
@@ -99,7 +151,7 @@ func (q *DBQuerier) FindOrdersMRR(ctx context.Context) ([]FindOrdersMRRRow, erro
 
 // FindOrdersMRRBatch implements Querier.FindOrdersMRRBatch.
 func (q *DBQuerier) FindOrdersMRRBatch(batch genericBatch) {
-	batch.Queue(findOrdersMRRSQL)
+	batch.Queue(q.chooseSQL(findOrdersMRRSQL, findOrdersMRRStmt))
 }
 
 // FindOrdersMRRScan implements Querier.FindOrdersMRRScan.
@@ -122,3 +174,33 @@ func (q *DBQuerier) FindOrdersMRRScan(results pgx.BatchResults) ([]FindOrdersMRR
 	}
 	return items, err
 }
+
+// FindOrdersMRR queues a FindOrdersMRR query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) FindOrdersMRR() *FindOrdersMRRHandle {
+	b.q.FindOrdersMRRBatch(b.batch)
+	h := &FindOrdersMRRHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// FindOrdersMRRHandle is the result of a FindOrdersMRR query queued in a Batch.
+type FindOrdersMRRHandle struct {
+	b   *Batch
+	res []FindOrdersMRRRow
+	err error
+}
+
+func (h *FindOrdersMRRHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.FindOrdersMRRScan(results)
+	return h.err
+}
+
+// Result returns the result of the FindOrdersMRR query. Returns an error if the
+// batch wasn't sent.
+func (h *FindOrdersMRRHandle) Result() ([]FindOrdersMRRRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("FindOrdersMRR result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
@@ -4,10 +4,12 @@ package go_pointer_types
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v4"
+	"time"
 )
 
 // Querier is a typesafe Go interface backed by SQL queries.
@@ -57,11 +59,17 @@ type Querier interface {
 	GenSeriesStrBatch(batch genericBatch)
 	// GenSeriesStrScan scans the result of an executed GenSeriesStrBatch query.
 	GenSeriesStrScan(results pgx.BatchResults) ([]*string, error)
+
+	// RunInTx runs fn in a transaction using a Querier bound to the
+	// transaction. See DBQuerier.RunInTx.
+	RunInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error
 }
 
 type DBQuerier struct {
-	conn  genericConn   // underlying Postgres transport to use
-	types *typeResolver // resolve types by name
+	conn    genericConn   // underlying Postgres transport to use
+	replica genericConn   // transport for read-only queries; nil unless routing
+	types   *typeResolver // resolve types by name
+	cfg     QuerierConfig // config used to create the querier
 }
 
 var _ Querier = &DBQuerier{}
@@ -107,17 +115,362 @@ type QuerierConfig struct {
 	// transitive dependencies, pggen will use the binary encoding format for
 	// the input parameter.
 	DataTypes []pgtype.DataType
+
+	// UsePreparedStatements runs queries by the statement names prepared by
+	// PrepareAllQueries instead of by SQL text. Every connection used by the
+	// querier must run PrepareAllQueries first, typically in the AfterConnect
+	// callback of pgxpool.Config.
+	//
+	// If false, the default, queries run by SQL text, which works whether or
+	// not the connection prepared the statements.
+	UsePreparedStatements bool
+
+	// PgBouncerCompat enables compatibility with PgBouncer in transaction
+	// pooling mode, which doesn't support prepared statements. Each query runs
+	// with pgx.QuerySimpleProtocol, pggen-generated transcoders always encode
+	// in the text format, UsePreparedStatements is ignored, and
+	// PrepareAllQueriesConfig doesn't prepare any statements.
+	//
+	// pgx only sends a pgx.Batch with the simple protocol if
+	// PreferSimpleProtocol is set on pgx.ConnConfig, so Batch.Send runs the
+	// queued queries one at a time with the simple protocol instead of in a
+	// single network round-trip.
+	PgBouncerCompat bool
 }
 
 // NewQuerierConfig creates a DBQuerier that implements Querier with the given
 // config. conn is typically *pgx.Conn, pgx.Tx, or *pgxpool.Pool.
 func NewQuerierConfig(conn genericConn, cfg QuerierConfig) *DBQuerier {
-	return &DBQuerier{conn: conn, types: newTypeResolver(cfg.DataTypes)}
+	if cfg.PgBouncerCompat {
+		conn = simpleProtocolConn{conn}
+	}
+	return &DBQuerier{conn: conn, types: newTypeResolver(cfg.DataTypes, cfg.PgBouncerCompat), cfg: cfg}
 }
 
 // WithTx creates a new DBQuerier that uses the transaction to run all queries.
+// The returned DBQuerier keeps the config of q. If q is a routing querier, the
+// returned DBQuerier runs read-only queries in the transaction too.
 func (q *DBQuerier) WithTx(tx pgx.Tx) (*DBQuerier, error) {
-	return &DBQuerier{conn: tx}, nil
+	var conn genericConn = tx
+	if q.cfg.PgBouncerCompat {
+		conn = simpleProtocolConn{conn}
+	}
+	return &DBQuerier{conn: conn, types: q.types, cfg: q.cfg}, nil
+}
+
+// NewRoutingQuerier creates a DBQuerier that runs read-only queries on replica
+// and all other queries on primary. pggen classifies a query as read-only if
+// the query plan doesn't modify a table or lock rows. Queries with the
+// route=primary pragma, batches, and transactions always use primary.
+func NewRoutingQuerier(primary, replica genericConn) *DBQuerier {
+	return NewRoutingQuerierConfig(primary, replica, QuerierConfig{})
+}
+
+// NewRoutingQuerierConfig creates a DBQuerier like NewRoutingQuerier with the
+// given config. The config applies to both primary and replica.
+func NewRoutingQuerierConfig(primary, replica genericConn, cfg QuerierConfig) *DBQuerier {
+	q := NewQuerierConfig(primary, cfg)
+	if cfg.PgBouncerCompat {
+		replica = simpleProtocolConn{replica}
+	}
+	q.replica = replica
+	return q
+}
+
+// readConn returns the Postgres transport for read-only queries.
+func (q *DBQuerier) readConn() genericConn {
+	if q.replica == nil {
+		return q.conn
+	}
+	return q.replica
+}
+
+// simpleProtocolConn is a genericConn that runs all queries with the simple
+// protocol, overriding pgx.ConnConfig.PreferSimpleProtocol.
+type simpleProtocolConn struct {
+	genericConn
+}
+
+func (c simpleProtocolConn) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return c.genericConn.Query(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, args...)...)
+}
+
+func (c simpleProtocolConn) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return c.genericConn.QueryRow(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, args...)...)
+}
+
+func (c simpleProtocolConn) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	return c.genericConn.Exec(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, arguments...)...)
+}
+
+// transport returns the underlying Postgres transport of q, unwrapping any
+// wrapper added by the config.
+func (q *DBQuerier) transport() genericConn {
+	if c, ok := q.conn.(simpleProtocolConn); ok {
+		return c.genericConn
+	}
+	return q.conn
+}
+
+// txBeginner is any Postgres connection transport that can begin a
+// transaction, most commonly *pgx.Conn or *pgxpool.Pool.
+type txBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// TxOptions controls how RunInTx begins and retries a transaction.
+type TxOptions struct {
+	// TxOptions are the options used to begin a top-level transaction. Ignored
+	// for nested transactions, which use a savepoint.
+	pgx.TxOptions
+
+	// MaxRetries is the maximum number of times to retry a top-level
+	// transaction that failed with a serialization failure (SQLSTATE 40001) or
+	// a deadlock (SQLSTATE 40P01). Zero disables retries.
+	MaxRetries int
+
+	// Backoff returns how long to wait before the nth retry, starting at 1. If
+	// nil, retries immediately.
+	Backoff func(retry int) time.Duration
+}
+
+// RunInTx runs fn in a transaction. fn receives a Querier bound to the
+// transaction with the same config as q. If fn returns nil, RunInTx commits
+// the transaction; otherwise, RunInTx rolls back the transaction and returns
+// the error from fn.
+//
+// If q is already bound to a transaction, like the Querier passed to fn,
+// RunInTx creates a nested transaction using a savepoint. Only the top-level
+// transaction is retried because a serialization failure aborts the entire
+// transaction.
+func (q *DBQuerier) RunInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error {
+	_, isNested := q.transport().(pgx.Tx)
+	for retry := 1; ; retry++ {
+		err := q.runInTx(ctx, opts, fn)
+		if err == nil || isNested || retry > opts.MaxRetries || !isRetryableTxErr(err) {
+			return err
+		}
+		if opts.Backoff == nil {
+			continue
+		}
+		timer := time.NewTimer(opts.Backoff(retry))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("retry tx: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// runInTx runs a single attempt of fn in a transaction.
+func (q *DBQuerier) runInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error {
+	var tx pgx.Tx
+	var err error
+	switch conn := q.transport().(type) {
+	case pgx.Tx:
+		tx, err = conn.Begin(ctx) // savepoint
+	case txBeginner:
+		tx, err = conn.BeginTx(ctx, opts.TxOptions)
+	default:
+		return fmt.Errorf("begin tx: conn of type %T cannot begin a transaction", conn)
+	}
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	txq, _ := q.WithTx(tx)
+	if err := fn(txq); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			return fmt.Errorf("rollback tx: %v; original error: %w", rbErr, err)
+		}
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// isRetryableTxErr returns true if err is a serialization failure or deadlock
+// such that retrying the entire transaction might succeed.
+func isRetryableTxErr(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+// batchSender is any Postgres connection transport that can send a batch of
+// queries, most commonly *pgx.Conn, pgx.Tx, or *pgxpool.Pool.
+type batchSender interface {
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// Batch is a typed builder for a pgx.Batch. Each query method on Batch queues
+// the query and returns a handle. After calling Send, use Result on the handle
+// to get the typed result of the query.
+type Batch struct {
+	q       *DBQuerier
+	batch   queueBatch    // *pgx.Batch, or *simpleBatch with PgBouncerCompat
+	handles []batchHandle // handles in the same order as the queued queries
+	sent    bool
+}
+
+// batchHandle scans the result of a single query queued in a Batch.
+type batchHandle interface {
+	scan(results pgx.BatchResults) error
+}
+
+var errBatchNotSent = errors.New("batch not sent")
+
+// queueBatch is a genericBatch that counts the queued queries.
+type queueBatch interface {
+	genericBatch
+	Len() int
+}
+
+// NewBatch creates a typed Batch that sends queries using the conn of q.
+func (q *DBQuerier) NewBatch() *Batch {
+	if q.cfg.PgBouncerCompat {
+		return &Batch{q: q, batch: &simpleBatch{}}
+	}
+	return &Batch{q: q, batch: &pgx.Batch{}}
+}
+
+// Send sends all queued queries with SendBatch and scans the result of each
+// query into its handle in queue order. If any query fails, Send returns a
+// BatchError with the errors of all failed queries.
+func (b *Batch) Send(ctx context.Context) error {
+	if b.sent {
+		return errors.New("send batch: batch already sent")
+	}
+	if b.batch.Len() != len(b.handles) {
+		return fmt.Errorf("send batch: %d queued queries but %d handles", b.batch.Len(), len(b.handles))
+	}
+	var results pgx.BatchResults
+	switch batch := b.batch.(type) {
+	case *simpleBatch:
+		b.sent = true
+		results = &simpleBatchResults{ctx: ctx, conn: b.q.conn, queries: batch.queries}
+	case *pgx.Batch:
+		sender, ok := b.q.transport().(batchSender)
+		if !ok {
+			return fmt.Errorf("send batch: conn of type %T cannot send a batch", b.q.transport())
+		}
+		b.sent = true
+		results = sender.SendBatch(ctx, batch)
+	default:
+		return fmt.Errorf("send batch: unknown batch type %T", b.batch)
+	}
+	var errs []error
+	for _, h := range b.handles {
+		if err := h.scan(results); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := results.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("close batch results: %w", err))
+	}
+	if len(errs) > 0 {
+		return BatchError{Errs: errs}
+	}
+	return nil
+}
+
+// BatchError is the error returned by Batch.Send with the errors of all failed
+// queries in queue order.
+type BatchError struct {
+	Errs []error
+}
+
+func (e BatchError) Error() string {
+	msg := fmt.Sprintf("send batch: %d errors", len(e.Errs))
+	for _, err := range e.Errs {
+		msg += "; " + err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the first error.
+func (e BatchError) Unwrap() error { return e.Errs[0] }
+
+// simpleBatch is a genericBatch that records the queued queries so that
+// simpleBatchResults can run them with the simple protocol.
+type simpleBatch struct {
+	queries []simpleQuery
+}
+
+type simpleQuery struct {
+	sql  string
+	args []interface{}
+}
+
+func (b *simpleBatch) Queue(query string, arguments ...interface{}) {
+	b.queries = append(b.queries, simpleQuery{sql: query, args: arguments})
+}
+
+func (b *simpleBatch) Len() int { return len(b.queries) }
+
+// simpleBatchResults is a pgx.BatchResults that runs the next query of a
+// simpleBatch on conn when reading its result. conn is a simpleProtocolConn,
+// so each query runs with the simple protocol.
+type simpleBatchResults struct {
+	ctx     context.Context
+	conn    genericConn
+	queries []simpleQuery
+	idx     int // index of the next query
+}
+
+func (r *simpleBatchResults) next() (simpleQuery, error) {
+	if r.idx == len(r.queries) {
+		return simpleQuery{}, fmt.Errorf("no result for batch query %d", r.idx)
+	}
+	q := r.queries[r.idx]
+	r.idx++
+	return q, nil
+}
+
+func (r *simpleBatchResults) Exec() (pgconn.CommandTag, error) {
+	q, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	return r.conn.Exec(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) Query() (pgx.Rows, error) {
+	q, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	return r.conn.Query(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) QueryRow() pgx.Row {
+	q, err := r.next()
+	if err != nil {
+		return errRow{err: err}
+	}
+	return r.conn.QueryRow(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) Close() error { return nil }
+
+// errRow is a pgx.Row that returns err from Scan.
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(...interface{}) error { return r.err }
+
+// chooseSQL returns the name of the prepared statement if the querier uses
+// prepared statements. Otherwise, returns the SQL text.
+func (q *DBQuerier) chooseSQL(sql, stmtName string) string {
+	if q.cfg.UsePreparedStatements && !q.cfg.PgBouncerCompat {
+		return stmtName
+	}
+	return sql
 }
 
 // preparer is any Postgres connection transport that provides a way to prepare
@@ -130,45 +483,60 @@ type preparer interface {
 // queries in querier files. Typical usage is as the AfterConnect callback
 // for pgxpool.Config
 //
-// pgx will use the prepared statement if available. Calling PrepareAllQueries
-// is an optional optimization to avoid a network round-trip the first time pgx
-// runs a query if pgx statement caching is enabled.
+// Each statement is prepared under a deterministic name derived from the query
+// name and SQL text. A querier created with
+// QuerierConfig.UsePreparedStatements runs queries by those names, avoiding a
+// network round-trip to prepare the statement the first time pgx runs a query.
+//
+// Use PrepareAllQueriesConfig with the config of the querier to skip preparing
+// statements with QuerierConfig.PgBouncerCompat.
 func PrepareAllQueries(ctx context.Context, p preparer) error {
-	if _, err := p.Prepare(ctx, genSeries1SQL, genSeries1SQL); err != nil {
+	if _, err := p.Prepare(ctx, genSeries1Stmt, genSeries1SQL); err != nil {
 		return fmt.Errorf("prepare query 'GenSeries1': %w", err)
 	}
-	if _, err := p.Prepare(ctx, genSeriesSQL, genSeriesSQL); err != nil {
+	if _, err := p.Prepare(ctx, genSeriesStmt, genSeriesSQL); err != nil {
 		return fmt.Errorf("prepare query 'GenSeries': %w", err)
 	}
-	if _, err := p.Prepare(ctx, genSeriesArr1SQL, genSeriesArr1SQL); err != nil {
+	if _, err := p.Prepare(ctx, genSeriesArr1Stmt, genSeriesArr1SQL); err != nil {
 		return fmt.Errorf("prepare query 'GenSeriesArr1': %w", err)
 	}
-	if _, err := p.Prepare(ctx, genSeriesArrSQL, genSeriesArrSQL); err != nil {
+	if _, err := p.Prepare(ctx, genSeriesArrStmt, genSeriesArrSQL); err != nil {
 		return fmt.Errorf("prepare query 'GenSeriesArr': %w", err)
 	}
-	if _, err := p.Prepare(ctx, genSeriesStr1SQL, genSeriesStr1SQL); err != nil {
+	if _, err := p.Prepare(ctx, genSeriesStr1Stmt, genSeriesStr1SQL); err != nil {
 		return fmt.Errorf("prepare query 'GenSeriesStr1': %w", err)
 	}
-	if _, err := p.Prepare(ctx, genSeriesStrSQL, genSeriesStrSQL); err != nil {
+	if _, err := p.Prepare(ctx, genSeriesStrStmt, genSeriesStrSQL); err != nil {
 		return fmt.Errorf("prepare query 'GenSeriesStr': %w", err)
 	}
 	return nil
 }
 
+// PrepareAllQueriesConfig is like PrepareAllQueries but doesn't prepare any
+// statements if cfg.PgBouncerCompat is set, because PgBouncer in transaction
+// pooling mode doesn't support prepared statements.
+func PrepareAllQueriesConfig(ctx context.Context, p preparer, cfg QuerierConfig) error {
+	if cfg.PgBouncerCompat {
+		return nil
+	}
+	return PrepareAllQueries(ctx, p)
+}
+
 // typeResolver looks up the pgtype.ValueTranscoder by Postgres type name.
 type typeResolver struct {
-	connInfo *pgtype.ConnInfo // types by Postgres type name
+	connInfo   *pgtype.ConnInfo // types by Postgres type name
+	preferText bool             // if true, always encode in the text format
 }
 
-func newTypeResolver(types []pgtype.DataType) *typeResolver {
+func newTypeResolver(types []pgtype.DataType, preferText bool) *typeResolver {
 	ci := pgtype.NewConnInfo()
 	for _, typ := range types {
-		if txt, ok := typ.Value.(textPreferrer); ok && typ.OID != unknownOID {
+		if txt, ok := typ.Value.(textPreferrer); ok && typ.OID != unknownOID && !preferText {
 			typ.Value = txt.ValueTranscoder
 		}
 		ci.RegisterDataType(typ)
 	}
-	return &typeResolver{connInfo: ci}
+	return &typeResolver{connInfo: ci, preferText: preferText}
 }
 
 // findValue find the OID, and pgtype.ValueTranscoder for a Postgres type name.
@@ -177,8 +545,11 @@ func (tr *typeResolver) findValue(name string) (uint32, pgtype.ValueTranscoder,
 	if !ok {
 		return 0, nil, false
 	}
-	v := pgtype.NewValue(typ.Value)
-	return typ.OID, v.(pgtype.ValueTranscoder), true
+	v := pgtype.NewValue(typ.Value).(pgtype.ValueTranscoder)
+	if _, isText := v.(textPreferrer); tr.preferText && !isText {
+		v = textPreferrer{v, name}
+	}
+	return typ.OID, v, true
 }
 
 // setValue sets the value of a ValueTranscoder to a value that should always
@@ -194,10 +565,12 @@ const genSeries1SQL = `SELECT n
 FROM generate_series(0, 2) n
 LIMIT 1;`
 
+const genSeries1Stmt = "pggen_GenSeries1_1f04f171385c1199"
+
 // GenSeries1 implements Querier.GenSeries1.
 func (q *DBQuerier) GenSeries1(ctx context.Context) (*int, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "GenSeries1")
-	row := q.conn.QueryRow(ctx, genSeries1SQL)
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(genSeries1SQL, genSeries1Stmt))
 	var item int
 	if err := row.Scan(&item); err != nil {
 		return &item, fmt.Errorf("query GenSeries1: %w", err)
@@ -207,7 +580,7 @@ func (q *DBQuerier) GenSeries1(ctx context.Context) (*int, error) {
 
 // GenSeries1Batch implements Querier.GenSeries1Batch.
 func (q *DBQuerier) GenSeries1Batch(batch genericBatch) {
-	batch.Queue(genSeries1SQL)
+	batch.Queue(q.chooseSQL(genSeries1SQL, genSeries1Stmt))
 }
 
 // GenSeries1Scan implements Querier.GenSeries1Scan.
@@ -220,13 +593,45 @@ func (q *DBQuerier) GenSeries1Scan(results pgx.BatchResults) (*int, error) {
 	return &item, nil
 }
 
+// GenSeries1 queues a GenSeries1 query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) GenSeries1() *GenSeries1Handle {
+	b.q.GenSeries1Batch(b.batch)
+	h := &GenSeries1Handle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// GenSeries1Handle is the result of a GenSeries1 query queued in a Batch.
+type GenSeries1Handle struct {
+	b   *Batch
+	res *int
+	err error
+}
+
+func (h *GenSeries1Handle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.GenSeries1Scan(results)
+	return h.err
+}
+
+// Result returns the result of the GenSeries1 query. Returns an error if the
+// batch wasn't sent.
+func (h *GenSeries1Handle) Result() (*int, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("GenSeries1 result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const genSeriesSQL = `SELECT n
 FROM generate_series(0, 2) n;`
 
+const genSeriesStmt = "pggen_GenSeries_89e03f930af338a1"
+
 // GenSeries implements Querier.GenSeries.
 func (q *DBQuerier) GenSeries(ctx context.Context) ([]*int, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "GenSeries")
-	rows, err := q.conn.Query(ctx, genSeriesSQL)
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(genSeriesSQL, genSeriesStmt))
 	if err != nil {
 		return nil, fmt.Errorf("query GenSeries: %w", err)
 	}
@@ -247,7 +652,7 @@ func (q *DBQuerier) GenSeries(ctx context.Context) ([]*int, error) {
 
 // GenSeriesBatch implements Querier.GenSeriesBatch.
 func (q *DBQuerier) GenSeriesBatch(batch genericBatch) {
-	batch.Queue(genSeriesSQL)
+	batch.Queue(q.chooseSQL(genSeriesSQL, genSeriesStmt))
 }
 
 // GenSeriesScan implements Querier.GenSeriesScan.
@@ -271,13 +676,45 @@ func (q *DBQuerier) GenSeriesScan(results pgx.BatchResults) ([]*int, error) {
 	return items, err
 }
 
+// GenSeries queues a GenSeries query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) GenSeries() *GenSeriesHandle {
+	b.q.GenSeriesBatch(b.batch)
+	h := &GenSeriesHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// GenSeriesHandle is the result of a GenSeries query queued in a Batch.
+type GenSeriesHandle struct {
+	b   *Batch
+	res []*int
+	err error
+}
+
+func (h *GenSeriesHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.GenSeriesScan(results)
+	return h.err
+}
+
+// Result returns the result of the GenSeries query. Returns an error if the
+// batch wasn't sent.
+func (h *GenSeriesHandle) Result() ([]*int, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("GenSeries result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const genSeriesArr1SQL = `SELECT array_agg(n)
 FROM generate_series(0, 2) n;`
 
+const genSeriesArr1Stmt = "pggen_GenSeriesArr1_93bdd7138a1b2b10"
+
 // GenSeriesArr1 implements Querier.GenSeriesArr1.
 func (q *DBQuerier) GenSeriesArr1(ctx context.Context) ([]int, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "GenSeriesArr1")
-	row := q.conn.QueryRow(ctx, genSeriesArr1SQL)
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(genSeriesArr1SQL, genSeriesArr1Stmt))
 	item := []int{}
 	if err := row.Scan(&item); err != nil {
 		return item, fmt.Errorf("query GenSeriesArr1: %w", err)
@@ -287,7 +724,7 @@ func (q *DBQuerier) GenSeriesArr1(ctx context.Context) ([]int, error) {
 
 // GenSeriesArr1Batch implements Querier.GenSeriesArr1Batch.
 func (q *DBQuerier) GenSeriesArr1Batch(batch genericBatch) {
-	batch.Queue(genSeriesArr1SQL)
+	batch.Queue(q.chooseSQL(genSeriesArr1SQL, genSeriesArr1Stmt))
 }
 
 // GenSeriesArr1Scan implements Querier.GenSeriesArr1Scan.
@@ -300,13 +737,45 @@ func (q *DBQuerier) GenSeriesArr1Scan(results pgx.BatchResults) ([]int, error) {
 	return item, nil
 }
 
+// GenSeriesArr1 queues a GenSeriesArr1 query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) GenSeriesArr1() *GenSeriesArr1Handle {
+	b.q.GenSeriesArr1Batch(b.batch)
+	h := &GenSeriesArr1Handle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// GenSeriesArr1Handle is the result of a GenSeriesArr1 query queued in a Batch.
+type GenSeriesArr1Handle struct {
+	b   *Batch
+	res []int
+	err error
+}
+
+func (h *GenSeriesArr1Handle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.GenSeriesArr1Scan(results)
+	return h.err
+}
+
+// Result returns the result of the GenSeriesArr1 query. Returns an error if the
+// batch wasn't sent.
+func (h *GenSeriesArr1Handle) Result() ([]int, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("GenSeriesArr1 result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const genSeriesArrSQL = `SELECT array_agg(n)
 FROM generate_series(0, 2) n;`
 
+const genSeriesArrStmt = "pggen_GenSeriesArr_ae0b31fd8a22f873"
+
 // GenSeriesArr implements Querier.GenSeriesArr.
 func (q *DBQuerier) GenSeriesArr(ctx context.Context) ([][]int, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "GenSeriesArr")
-	rows, err := q.conn.Query(ctx, genSeriesArrSQL)
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(genSeriesArrSQL, genSeriesArrStmt))
 	if err != nil {
 		return nil, fmt.Errorf("query GenSeriesArr: %w", err)
 	}
@@ -327,7 +796,7 @@ func (q *DBQuerier) GenSeriesArr(ctx context.Context) ([][]int, error) {
 
 // GenSeriesArrBatch implements Querier.GenSeriesArrBatch.
 func (q *DBQuerier) GenSeriesArrBatch(batch genericBatch) {
-	batch.Queue(genSeriesArrSQL)
+	batch.Queue(q.chooseSQL(genSeriesArrSQL, genSeriesArrStmt))
 }
 
 // GenSeriesArrScan implements Querier.GenSeriesArrScan.
@@ -351,14 +820,46 @@ func (q *DBQuerier) GenSeriesArrScan(results pgx.BatchResults) ([][]int, error)
 	return items, err
 }
 
+// GenSeriesArr queues a GenSeriesArr query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) GenSeriesArr() *GenSeriesArrHandle {
+	b.q.GenSeriesArrBatch(b.batch)
+	h := &GenSeriesArrHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// GenSeriesArrHandle is the result of a GenSeriesArr query queued in a Batch.
+type GenSeriesArrHandle struct {
+	b   *Batch
+	res [][]int
+	err error
+}
+
+func (h *GenSeriesArrHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.GenSeriesArrScan(results)
+	return h.err
+}
+
+// Result returns the result of the GenSeriesArr query. Returns an error if the
+// batch wasn't sent.
+func (h *GenSeriesArrHandle) Result() ([][]int, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("GenSeriesArr result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const genSeriesStr1SQL = `SELECT n::text
 FROM generate_series(0, 2) n
 LIMIT 1;`
 
+const genSeriesStr1Stmt = "pggen_GenSeriesStr1_684e74326e5f4f71"
+
 // GenSeriesStr1 implements Querier.GenSeriesStr1.
 func (q *DBQuerier) GenSeriesStr1(ctx context.Context) (*string, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "GenSeriesStr1")
-	row := q.conn.QueryRow(ctx, genSeriesStr1SQL)
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(genSeriesStr1SQL, genSeriesStr1Stmt))
 	var item string
 	if err := row.Scan(&item); err != nil {
 		return &item, fmt.Errorf("query GenSeriesStr1: %w", err)
@@ -368,7 +869,7 @@ func (q *DBQuerier) GenSeriesStr1(ctx context.Context) (*string, error) {
 
 // GenSeriesStr1Batch implements Querier.GenSeriesStr1Batch.
 func (q *DBQuerier) GenSeriesStr1Batch(batch genericBatch) {
-	batch.Queue(genSeriesStr1SQL)
+	batch.Queue(q.chooseSQL(genSeriesStr1SQL, genSeriesStr1Stmt))
 }
 
 // GenSeriesStr1Scan implements Querier.GenSeriesStr1Scan.
@@ -381,13 +882,45 @@ func (q *DBQuerier) GenSeriesStr1Scan(results pgx.BatchResults) (*string, error)
 	return &item, nil
 }
 
+// GenSeriesStr1 queues a GenSeriesStr1 query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) GenSeriesStr1() *GenSeriesStr1Handle {
+	b.q.GenSeriesStr1Batch(b.batch)
+	h := &GenSeriesStr1Handle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// GenSeriesStr1Handle is the result of a GenSeriesStr1 query queued in a Batch.
+type GenSeriesStr1Handle struct {
+	b   *Batch
+	res *string
+	err error
+}
+
+func (h *GenSeriesStr1Handle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.GenSeriesStr1Scan(results)
+	return h.err
+}
+
+// Result returns the result of the GenSeriesStr1 query. Returns an error if the
+// batch wasn't sent.
+func (h *GenSeriesStr1Handle) Result() (*string, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("GenSeriesStr1 result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const genSeriesStrSQL = `SELECT n::text
 FROM generate_series(0, 2) n;`
 
+const genSeriesStrStmt = "pggen_GenSeriesStr_73dc278c4cfd4deb"
+
 // GenSeriesStr implements Querier.GenSeriesStr.
 func (q *DBQuerier) GenSeriesStr(ctx context.Context) ([]*string, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "GenSeriesStr")
-	rows, err := q.conn.Query(ctx, genSeriesStrSQL)
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(genSeriesStrSQL, genSeriesStrStmt))
 	if err != nil {
 		return nil, fmt.Errorf("query GenSeriesStr: %w", err)
 	}
@@ -408,7 +941,7 @@ func (q *DBQuerier) GenSeriesStr(ctx context.Context) ([]*string, error) {
 
 // GenSeriesStrBatch implements Querier.GenSeriesStrBatch.
 func (q *DBQuerier) GenSeriesStrBatch(batch genericBatch) {
-	batch.Queue(genSeriesStrSQL)
+	batch.Queue(q.chooseSQL(genSeriesStrSQL, genSeriesStrStmt))
 }
 
 // GenSeriesStrScan implements Querier.GenSeriesStrScan.
@@ -432,6 +965,36 @@ func (q *DBQuerier) GenSeriesStrScan(results pgx.BatchResults) ([]*string, error
 	return items, err
 }
 
+// GenSeriesStr queues a GenSeriesStr query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) GenSeriesStr() *GenSeriesStrHandle {
+	b.q.GenSeriesStrBatch(b.batch)
+	h := &GenSeriesStrHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// GenSeriesStrHandle is the result of a GenSeriesStr query queued in a Batch.
+type GenSeriesStrHandle struct {
+	b   *Batch
+	res []*string
+	err error
+}
+
+func (h *GenSeriesStrHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.GenSeriesStrScan(results)
+	return h.err
+}
+
+// Result returns the result of the GenSeriesStr query. Returns an error if the
+// batch wasn't sent.
+func (h *GenSeriesStrHandle) Result() ([]*string, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("GenSeriesStr result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 // textPreferrer wraps a pgtype.ValueTranscoder and sets the preferred encoding
 // format to text instead binary (the default). pggen uses the text format
 // when the OID is unknownOID because the binary format requires the OID.
@@ -8,12 +8,25 @@ import (
 	"github.com/jackc/pgx/v4"
 )
 
+// SeparateOutDirAlphaQueryQuerier is the interface for the queries in query.sql.
+// Querier embeds SeparateOutDirAlphaQueryQuerier.
+type SeparateOutDirAlphaQueryQuerier interface {
+	Alpha(ctx context.Context) (string, error)
+	// AlphaBatch enqueues a Alpha query into batch to be executed
+	// later by the batch.
+	AlphaBatch(batch genericBatch)
+	// AlphaScan scans the result of an executed AlphaBatch query.
+	AlphaScan(results pgx.BatchResults) (string, error)
+}
+
 const alphaSQL = `SELECT 'alpha' as output;`
 
+const alphaStmt = "pggen_Alpha_37e34a4df15546c6"
+
 // Alpha implements Querier.Alpha.
 func (q *DBQuerier) Alpha(ctx context.Context) (string, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "Alpha")
-	row := q.conn.QueryRow(ctx, alphaSQL)
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(alphaSQL, alphaStmt))
 	var item string
 	if err := row.Scan(&item); err != nil {
 		return item, fmt.Errorf("query Alpha: %w", err)
@@ -23,7 +36,7 @@ func (q *DBQuerier) Alpha(ctx context.Context) (string, error) {
 
 // AlphaBatch implements Querier.AlphaBatch.
 func (q *DBQuerier) AlphaBatch(batch genericBatch) {
-	batch.Queue(alphaSQL)
+	batch.Queue(q.chooseSQL(alphaSQL, alphaStmt))
 }
 
 // AlphaScan implements Querier.AlphaScan.
@@ -35,3 +48,33 @@ func (q *DBQuerier) AlphaScan(results pgx.BatchResults) (string, error) {
 	}
 	return item, nil
 }
+
+// Alpha queues a Alpha query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) Alpha() *AlphaHandle {
+	b.q.AlphaBatch(b.batch)
+	h := &AlphaHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// AlphaHandle is the result of a Alpha query queued in a Batch.
+type AlphaHandle struct {
+	b   *Batch
+	res string
+	err error
+}
+
+func (h *AlphaHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.AlphaScan(results)
+	return h.err
+}
+
+// Result returns the result of the Alpha query. Returns an error if the
+// batch wasn't sent.
+func (h *AlphaHandle) Result() (string, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("Alpha result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
@@ -8,12 +8,25 @@ import (
 	"github.com/jackc/pgx/v4"
 )
 
+// BravoQueryQuerier is the interface for the queries in query.sql.
+// Querier embeds BravoQueryQuerier.
+type BravoQueryQuerier interface {
+	Bravo(ctx context.Context) (string, error)
+	// BravoBatch enqueues a Bravo query into batch to be executed
+	// later by the batch.
+	BravoBatch(batch genericBatch)
+	// BravoScan scans the result of an executed BravoBatch query.
+	BravoScan(results pgx.BatchResults) (string, error)
+}
+
 const bravoSQL = `SELECT 'bravo' as output;`
 
+const bravoStmt = "pggen_Bravo_50f6b517e06fdafd"
+
 // Bravo implements Querier.Bravo.
 func (q *DBQuerier) Bravo(ctx context.Context) (string, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "Bravo")
-	row := q.conn.QueryRow(ctx, bravoSQL)
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(bravoSQL, bravoStmt))
 	var item string
 	if err := row.Scan(&item); err != nil {
 		return item, fmt.Errorf("query Bravo: %w", err)
@@ -23,7 +36,7 @@ func (q *DBQuerier) Bravo(ctx context.Context) (string, error) {
 
 // BravoBatch implements Querier.BravoBatch.
 func (q *DBQuerier) BravoBatch(batch genericBatch) {
-	batch.Queue(bravoSQL)
+	batch.Queue(q.chooseSQL(bravoSQL, bravoStmt))
 }
 
 // BravoScan implements Querier.BravoScan.
@@ -35,3 +48,33 @@ func (q *DBQuerier) BravoScan(results pgx.BatchResults) (string, error) {
 	}
 	return item, nil
 }
+
+// Bravo queues a Bravo query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) Bravo() *BravoHandle {
+	b.q.BravoBatch(b.batch)
+	h := &BravoHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// BravoHandle is the result of a Bravo query queued in a Batch.
+type BravoHandle struct {
+	b   *Batch
+	res string
+	err error
+}
+
+func (h *BravoHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.BravoScan(results)
+	return h.err
+}
+
+// Result returns the result of the Bravo query. Returns an error if the
+// batch wasn't sent.
+func (h *BravoHandle) Result() (string, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("Bravo result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
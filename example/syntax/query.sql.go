@@ -4,10 +4,12 @@ package syntax
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v4"
+	"time"
 )
 
 // Querier is a typesafe Go interface backed by SQL queries.
@@ -86,11 +88,17 @@ type Querier interface {
 	GoKeywordBatch(batch genericBatch, go_ string)
 	// GoKeywordScan scans the result of an executed GoKeywordBatch query.
 	GoKeywordScan(results pgx.BatchResults) (string, error)
+
+	// RunInTx runs fn in a transaction using a Querier bound to the
+	// transaction. See DBQuerier.RunInTx.
+	RunInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error
 }
 
 type DBQuerier struct {
-	conn  genericConn   // underlying Postgres transport to use
-	types *typeResolver // resolve types by name
+	conn    genericConn   // underlying Postgres transport to use
+	replica genericConn   // transport for read-only queries; nil unless routing
+	types   *typeResolver // resolve types by name
+	cfg     QuerierConfig // config used to create the querier
 }
 
 var _ Querier = &DBQuerier{}
@@ -136,17 +144,362 @@ type QuerierConfig struct {
 	// transitive dependencies, pggen will use the binary encoding format for
 	// the input parameter.
 	DataTypes []pgtype.DataType
+
+	// UsePreparedStatements runs queries by the statement names prepared by
+	// PrepareAllQueries instead of by SQL text. Every connection used by the
+	// querier must run PrepareAllQueries first, typically in the AfterConnect
+	// callback of pgxpool.Config.
+	//
+	// If false, the default, queries run by SQL text, which works whether or
+	// not the connection prepared the statements.
+	UsePreparedStatements bool
+
+	// PgBouncerCompat enables compatibility with PgBouncer in transaction
+	// pooling mode, which doesn't support prepared statements. Each query runs
+	// with pgx.QuerySimpleProtocol, pggen-generated transcoders always encode
+	// in the text format, UsePreparedStatements is ignored, and
+	// PrepareAllQueriesConfig doesn't prepare any statements.
+	//
+	// pgx only sends a pgx.Batch with the simple protocol if
+	// PreferSimpleProtocol is set on pgx.ConnConfig, so Batch.Send runs the
+	// queued queries one at a time with the simple protocol instead of in a
+	// single network round-trip.
+	PgBouncerCompat bool
 }
 
 // NewQuerierConfig creates a DBQuerier that implements Querier with the given
 // config. conn is typically *pgx.Conn, pgx.Tx, or *pgxpool.Pool.
 func NewQuerierConfig(conn genericConn, cfg QuerierConfig) *DBQuerier {
-	return &DBQuerier{conn: conn, types: newTypeResolver(cfg.DataTypes)}
+	if cfg.PgBouncerCompat {
+		conn = simpleProtocolConn{conn}
+	}
+	return &DBQuerier{conn: conn, types: newTypeResolver(cfg.DataTypes, cfg.PgBouncerCompat), cfg: cfg}
 }
 
 // WithTx creates a new DBQuerier that uses the transaction to run all queries.
+// The returned DBQuerier keeps the config of q. If q is a routing querier, the
+// returned DBQuerier runs read-only queries in the transaction too.
 func (q *DBQuerier) WithTx(tx pgx.Tx) (*DBQuerier, error) {
-	return &DBQuerier{conn: tx}, nil
+	var conn genericConn = tx
+	if q.cfg.PgBouncerCompat {
+		conn = simpleProtocolConn{conn}
+	}
+	return &DBQuerier{conn: conn, types: q.types, cfg: q.cfg}, nil
+}
+
+// NewRoutingQuerier creates a DBQuerier that runs read-only queries on replica
+// and all other queries on primary. pggen classifies a query as read-only if
+// the query plan doesn't modify a table or lock rows. Queries with the
+// route=primary pragma, batches, and transactions always use primary.
+func NewRoutingQuerier(primary, replica genericConn) *DBQuerier {
+	return NewRoutingQuerierConfig(primary, replica, QuerierConfig{})
+}
+
+// NewRoutingQuerierConfig creates a DBQuerier like NewRoutingQuerier with the
+// given config. The config applies to both primary and replica.
+func NewRoutingQuerierConfig(primary, replica genericConn, cfg QuerierConfig) *DBQuerier {
+	q := NewQuerierConfig(primary, cfg)
+	if cfg.PgBouncerCompat {
+		replica = simpleProtocolConn{replica}
+	}
+	q.replica = replica
+	return q
+}
+
+// readConn returns the Postgres transport for read-only queries.
+func (q *DBQuerier) readConn() genericConn {
+	if q.replica == nil {
+		return q.conn
+	}
+	return q.replica
+}
+
+// simpleProtocolConn is a genericConn that runs all queries with the simple
+// protocol, overriding pgx.ConnConfig.PreferSimpleProtocol.
+type simpleProtocolConn struct {
+	genericConn
+}
+
+func (c simpleProtocolConn) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return c.genericConn.Query(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, args...)...)
+}
+
+func (c simpleProtocolConn) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return c.genericConn.QueryRow(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, args...)...)
+}
+
+func (c simpleProtocolConn) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	return c.genericConn.Exec(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, arguments...)...)
+}
+
+// transport returns the underlying Postgres transport of q, unwrapping any
+// wrapper added by the config.
+func (q *DBQuerier) transport() genericConn {
+	if c, ok := q.conn.(simpleProtocolConn); ok {
+		return c.genericConn
+	}
+	return q.conn
+}
+
+// txBeginner is any Postgres connection transport that can begin a
+// transaction, most commonly *pgx.Conn or *pgxpool.Pool.
+type txBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// TxOptions controls how RunInTx begins and retries a transaction.
+type TxOptions struct {
+	// TxOptions are the options used to begin a top-level transaction. Ignored
+	// for nested transactions, which use a savepoint.
+	pgx.TxOptions
+
+	// MaxRetries is the maximum number of times to retry a top-level
+	// transaction that failed with a serialization failure (SQLSTATE 40001) or
+	// a deadlock (SQLSTATE 40P01). Zero disables retries.
+	MaxRetries int
+
+	// Backoff returns how long to wait before the nth retry, starting at 1. If
+	// nil, retries immediately.
+	Backoff func(retry int) time.Duration
+}
+
+// RunInTx runs fn in a transaction. fn receives a Querier bound to the
+// transaction with the same config as q. If fn returns nil, RunInTx commits
+// the transaction; otherwise, RunInTx rolls back the transaction and returns
+// the error from fn.
+//
+// If q is already bound to a transaction, like the Querier passed to fn,
+// RunInTx creates a nested transaction using a savepoint. Only the top-level
+// transaction is retried because a serialization failure aborts the entire
+// transaction.
+func (q *DBQuerier) RunInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error {
+	_, isNested := q.transport().(pgx.Tx)
+	for retry := 1; ; retry++ {
+		err := q.runInTx(ctx, opts, fn)
+		if err == nil || isNested || retry > opts.MaxRetries || !isRetryableTxErr(err) {
+			return err
+		}
+		if opts.Backoff == nil {
+			continue
+		}
+		timer := time.NewTimer(opts.Backoff(retry))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("retry tx: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// runInTx runs a single attempt of fn in a transaction.
+func (q *DBQuerier) runInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error {
+	var tx pgx.Tx
+	var err error
+	switch conn := q.transport().(type) {
+	case pgx.Tx:
+		tx, err = conn.Begin(ctx) // savepoint
+	case txBeginner:
+		tx, err = conn.BeginTx(ctx, opts.TxOptions)
+	default:
+		return fmt.Errorf("begin tx: conn of type %T cannot begin a transaction", conn)
+	}
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	txq, _ := q.WithTx(tx)
+	if err := fn(txq); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			return fmt.Errorf("rollback tx: %v; original error: %w", rbErr, err)
+		}
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// isRetryableTxErr returns true if err is a serialization failure or deadlock
+// such that retrying the entire transaction might succeed.
+func isRetryableTxErr(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+// batchSender is any Postgres connection transport that can send a batch of
+// queries, most commonly *pgx.Conn, pgx.Tx, or *pgxpool.Pool.
+type batchSender interface {
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// Batch is a typed builder for a pgx.Batch. Each query method on Batch queues
+// the query and returns a handle. After calling Send, use Result on the handle
+// to get the typed result of the query.
+type Batch struct {
+	q       *DBQuerier
+	batch   queueBatch    // *pgx.Batch, or *simpleBatch with PgBouncerCompat
+	handles []batchHandle // handles in the same order as the queued queries
+	sent    bool
+}
+
+// batchHandle scans the result of a single query queued in a Batch.
+type batchHandle interface {
+	scan(results pgx.BatchResults) error
+}
+
+var errBatchNotSent = errors.New("batch not sent")
+
+// queueBatch is a genericBatch that counts the queued queries.
+type queueBatch interface {
+	genericBatch
+	Len() int
+}
+
+// NewBatch creates a typed Batch that sends queries using the conn of q.
+func (q *DBQuerier) NewBatch() *Batch {
+	if q.cfg.PgBouncerCompat {
+		return &Batch{q: q, batch: &simpleBatch{}}
+	}
+	return &Batch{q: q, batch: &pgx.Batch{}}
+}
+
+// Send sends all queued queries with SendBatch and scans the result of each
+// query into its handle in queue order. If any query fails, Send returns a
+// BatchError with the errors of all failed queries.
+func (b *Batch) Send(ctx context.Context) error {
+	if b.sent {
+		return errors.New("send batch: batch already sent")
+	}
+	if b.batch.Len() != len(b.handles) {
+		return fmt.Errorf("send batch: %d queued queries but %d handles", b.batch.Len(), len(b.handles))
+	}
+	var results pgx.BatchResults
+	switch batch := b.batch.(type) {
+	case *simpleBatch:
+		b.sent = true
+		results = &simpleBatchResults{ctx: ctx, conn: b.q.conn, queries: batch.queries}
+	case *pgx.Batch:
+		sender, ok := b.q.transport().(batchSender)
+		if !ok {
+			return fmt.Errorf("send batch: conn of type %T cannot send a batch", b.q.transport())
+		}
+		b.sent = true
+		results = sender.SendBatch(ctx, batch)
+	default:
+		return fmt.Errorf("send batch: unknown batch type %T", b.batch)
+	}
+	var errs []error
+	for _, h := range b.handles {
+		if err := h.scan(results); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := results.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("close batch results: %w", err))
+	}
+	if len(errs) > 0 {
+		return BatchError{Errs: errs}
+	}
+	return nil
+}
+
+// BatchError is the error returned by Batch.Send with the errors of all failed
+// queries in queue order.
+type BatchError struct {
+	Errs []error
+}
+
+func (e BatchError) Error() string {
+	msg := fmt.Sprintf("send batch: %d errors", len(e.Errs))
+	for _, err := range e.Errs {
+		msg += "; " + err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the first error.
+func (e BatchError) Unwrap() error { return e.Errs[0] }
+
+// simpleBatch is a genericBatch that records the queued queries so that
+// simpleBatchResults can run them with the simple protocol.
+type simpleBatch struct {
+	queries []simpleQuery
+}
+
+type simpleQuery struct {
+	sql  string
+	args []interface{}
+}
+
+func (b *simpleBatch) Queue(query string, arguments ...interface{}) {
+	b.queries = append(b.queries, simpleQuery{sql: query, args: arguments})
+}
+
+func (b *simpleBatch) Len() int { return len(b.queries) }
+
+// simpleBatchResults is a pgx.BatchResults that runs the next query of a
+// simpleBatch on conn when reading its result. conn is a simpleProtocolConn,
+// so each query runs with the simple protocol.
+type simpleBatchResults struct {
+	ctx     context.Context
+	conn    genericConn
+	queries []simpleQuery
+	idx     int // index of the next query
+}
+
+func (r *simpleBatchResults) next() (simpleQuery, error) {
+	if r.idx == len(r.queries) {
+		return simpleQuery{}, fmt.Errorf("no result for batch query %d", r.idx)
+	}
+	q := r.queries[r.idx]
+	r.idx++
+	return q, nil
+}
+
+func (r *simpleBatchResults) Exec() (pgconn.CommandTag, error) {
+	q, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	return r.conn.Exec(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) Query() (pgx.Rows, error) {
+	q, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	return r.conn.Query(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) QueryRow() pgx.Row {
+	q, err := r.next()
+	if err != nil {
+		return errRow{err: err}
+	}
+	return r.conn.QueryRow(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) Close() error { return nil }
+
+// errRow is a pgx.Row that returns err from Scan.
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(...interface{}) error { return r.err }
+
+// chooseSQL returns the name of the prepared statement if the querier uses
+// prepared statements. Otherwise, returns the SQL text.
+func (q *DBQuerier) chooseSQL(sql, stmtName string) string {
+	if q.cfg.UsePreparedStatements && !q.cfg.PgBouncerCompat {
+		return stmtName
+	}
+	return sql
 }
 
 // preparer is any Postgres connection transport that provides a way to prepare
@@ -159,40 +512,54 @@ type preparer interface {
 // queries in querier files. Typical usage is as the AfterConnect callback
 // for pgxpool.Config
 //
-// pgx will use the prepared statement if available. Calling PrepareAllQueries
-// is an optional optimization to avoid a network round-trip the first time pgx
-// runs a query if pgx statement caching is enabled.
+// Each statement is prepared under a deterministic name derived from the query
+// name and SQL text. A querier created with
+// QuerierConfig.UsePreparedStatements runs queries by those names, avoiding a
+// network round-trip to prepare the statement the first time pgx runs a query.
+//
+// Use PrepareAllQueriesConfig with the config of the querier to skip preparing
+// statements with QuerierConfig.PgBouncerCompat.
 func PrepareAllQueries(ctx context.Context, p preparer) error {
-	if _, err := p.Prepare(ctx, backtickSQL, backtickSQL); err != nil {
+	if _, err := p.Prepare(ctx, backtickStmt, backtickSQL); err != nil {
 		return fmt.Errorf("prepare query 'Backtick': %w", err)
 	}
-	if _, err := p.Prepare(ctx, backtickQuoteBacktickSQL, backtickQuoteBacktickSQL); err != nil {
+	if _, err := p.Prepare(ctx, backtickQuoteBacktickStmt, backtickQuoteBacktickSQL); err != nil {
 		return fmt.Errorf("prepare query 'BacktickQuoteBacktick': %w", err)
 	}
-	if _, err := p.Prepare(ctx, backtickNewlineSQL, backtickNewlineSQL); err != nil {
+	if _, err := p.Prepare(ctx, backtickNewlineStmt, backtickNewlineSQL); err != nil {
 		return fmt.Errorf("prepare query 'BacktickNewline': %w", err)
 	}
-	if _, err := p.Prepare(ctx, backtickDoubleQuoteSQL, backtickDoubleQuoteSQL); err != nil {
+	if _, err := p.Prepare(ctx, backtickDoubleQuoteStmt, backtickDoubleQuoteSQL); err != nil {
 		return fmt.Errorf("prepare query 'BacktickDoubleQuote': %w", err)
 	}
-	if _, err := p.Prepare(ctx, backtickBackslashNSQL, backtickBackslashNSQL); err != nil {
+	if _, err := p.Prepare(ctx, backtickBackslashNStmt, backtickBackslashNSQL); err != nil {
 		return fmt.Errorf("prepare query 'BacktickBackslashN': %w", err)
 	}
-	if _, err := p.Prepare(ctx, illegalNameSymbolsSQL, illegalNameSymbolsSQL); err != nil {
+	if _, err := p.Prepare(ctx, illegalNameSymbolsStmt, illegalNameSymbolsSQL); err != nil {
 		return fmt.Errorf("prepare query 'IllegalNameSymbols': %w", err)
 	}
-	if _, err := p.Prepare(ctx, spaceAfterSQL, spaceAfterSQL); err != nil {
+	if _, err := p.Prepare(ctx, spaceAfterStmt, spaceAfterSQL); err != nil {
 		return fmt.Errorf("prepare query 'SpaceAfter': %w", err)
 	}
-	if _, err := p.Prepare(ctx, badEnumNameSQL, badEnumNameSQL); err != nil {
+	if _, err := p.Prepare(ctx, badEnumNameStmt, badEnumNameSQL); err != nil {
 		return fmt.Errorf("prepare query 'BadEnumName': %w", err)
 	}
-	if _, err := p.Prepare(ctx, goKeywordSQL, goKeywordSQL); err != nil {
+	if _, err := p.Prepare(ctx, goKeywordStmt, goKeywordSQL); err != nil {
 		return fmt.Errorf("prepare query 'GoKeyword': %w", err)
 	}
 	return nil
 }
 
+// PrepareAllQueriesConfig is like PrepareAllQueries but doesn't prepare any
+// statements if cfg.PgBouncerCompat is set, because PgBouncer in transaction
+// pooling mode doesn't support prepared statements.
+func PrepareAllQueriesConfig(ctx context.Context, p preparer, cfg QuerierConfig) error {
+	if cfg.PgBouncerCompat {
+		return nil
+	}
+	return PrepareAllQueries(ctx, p)
+}
+
 // UnnamedEnum123 represents the Postgres enum "123".
 type UnnamedEnum123 string
 
@@ -205,20 +572,106 @@ const (
 
 func (u UnnamedEnum123) String() string { return string(u) }
 
+// AllUnnamedEnum123Values returns all labels of the Postgres enum "123"
+// in the Postgres sort order.
+func AllUnnamedEnum123Values() []UnnamedEnum123 {
+	return []UnnamedEnum123{
+		UnnamedEnum123InconvertibleEnumName,
+		UnnamedEnum123UnnamedLabel1,
+		UnnamedEnum123UnnamedLabel2111,
+		UnnamedEnum123UnnamedLabel3,
+	}
+}
+
+// ParseUnnamedEnum123 returns the UnnamedEnum123 for a label of the Postgres enum
+// "123". Returns an error if s isn't a label.
+func ParseUnnamedEnum123(s string) (UnnamedEnum123, error) {
+	if val := UnnamedEnum123(s); val.Valid() {
+		return val, nil
+	}
+	return "", fmt.Errorf("unknown UnnamedEnum123 label %q", s)
+}
+
+// Valid returns true if u is a label of the Postgres enum "123".
+func (u UnnamedEnum123) Valid() bool { return u.ordinal() >= 0 }
+
+// ordinal returns the position of u in the Postgres sort order of the
+// enum "123", or -1 if u isn't a label.
+func (u UnnamedEnum123) ordinal() int {
+	switch u {
+	case UnnamedEnum123InconvertibleEnumName:
+		return 0
+	case UnnamedEnum123UnnamedLabel1:
+		return 1
+	case UnnamedEnum123UnnamedLabel2111:
+		return 2
+	case UnnamedEnum123UnnamedLabel3:
+		return 3
+	default:
+		return -1
+	}
+}
+
+// Compare returns -1 if u sorts before other, 0 if they're equal, and +1
+// if u sorts after other, using the Postgres sort order of the enum
+// "123". Invalid values sort before all labels.
+func (u UnnamedEnum123) Compare(other UnnamedEnum123) int {
+	ord, otherOrd := u.ordinal(), other.ordinal()
+	switch {
+	case ord < otherOrd:
+		return -1
+	case ord > otherOrd:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Less returns true if u sorts before other using the Postgres sort
+// order of the enum "123".
+func (u UnnamedEnum123) Less(other UnnamedEnum123) bool { return u.Compare(other) < 0 }
+
+// MarshalText implements encoding.TextMarshaler. The zero value, like from a
+// NULL column, marshals as empty text. Returns an error for other values that
+// aren't a label of the Postgres enum "123".
+func (u UnnamedEnum123) MarshalText() ([]byte, error) {
+	if u != "" && !u.Valid() {
+		return nil, fmt.Errorf("marshal UnnamedEnum123: unknown label %q", string(u))
+	}
+	return []byte(u), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Empty text unmarshals to
+// the zero value. Returns an error for other text that isn't a label of the
+// Postgres enum "123".
+func (u *UnnamedEnum123) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*u = ""
+		return nil
+	}
+	val, err := ParseUnnamedEnum123(string(text))
+	if err != nil {
+		return fmt.Errorf("unmarshal UnnamedEnum123: %w", err)
+	}
+	*u = val
+	return nil
+}
+
 // typeResolver looks up the pgtype.ValueTranscoder by Postgres type name.
 type typeResolver struct {
-	connInfo *pgtype.ConnInfo // types by Postgres type name
+	connInfo   *pgtype.ConnInfo // types by Postgres type name
+	preferText bool             // if true, always encode in the text format
 }
 
-func newTypeResolver(types []pgtype.DataType) *typeResolver {
+func newTypeResolver(types []pgtype.DataType, preferText bool) *typeResolver {
 	ci := pgtype.NewConnInfo()
 	for _, typ := range types {
-		if txt, ok := typ.Value.(textPreferrer); ok && typ.OID != unknownOID {
+		if txt, ok := typ.Value.(textPreferrer); ok && typ.OID != unknownOID && !preferText {
 			typ.Value = txt.ValueTranscoder
 		}
 		ci.RegisterDataType(typ)
 	}
-	return &typeResolver{connInfo: ci}
+	return &typeResolver{connInfo: ci, preferText: preferText}
 }
 
 // findValue find the OID, and pgtype.ValueTranscoder for a Postgres type name.
@@ -227,8 +680,11 @@ func (tr *typeResolver) findValue(name string) (uint32, pgtype.ValueTranscoder,
 	if !ok {
 		return 0, nil, false
 	}
-	v := pgtype.NewValue(typ.Value)
-	return typ.OID, v.(pgtype.ValueTranscoder), true
+	v := pgtype.NewValue(typ.Value).(pgtype.ValueTranscoder)
+	if _, isText := v.(textPreferrer); tr.preferText && !isText {
+		v = textPreferrer{v, name}
+	}
+	return typ.OID, v, true
 }
 
 // setValue sets the value of a ValueTranscoder to a value that should always
@@ -242,10 +698,12 @@ func (tr *typeResolver) setValue(vt pgtype.ValueTranscoder, val interface{}) pgt
 
 const backtickSQL = "SELECT '`';"
 
+const backtickStmt = "pggen_Backtick_831078d61a5b721b"
+
 // Backtick implements Querier.Backtick.
 func (q *DBQuerier) Backtick(ctx context.Context) (string, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "Backtick")
-	row := q.conn.QueryRow(ctx, backtickSQL)
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(backtickSQL, backtickStmt))
 	var item string
 	if err := row.Scan(&item); err != nil {
 		return item, fmt.Errorf("query Backtick: %w", err)
@@ -255,7 +713,7 @@ func (q *DBQuerier) Backtick(ctx context.Context) (string, error) {
 
 // BacktickBatch implements Querier.BacktickBatch.
 func (q *DBQuerier) BacktickBatch(batch genericBatch) {
-	batch.Queue(backtickSQL)
+	batch.Queue(q.chooseSQL(backtickSQL, backtickStmt))
 }
 
 // BacktickScan implements Querier.BacktickScan.
@@ -268,12 +726,44 @@ func (q *DBQuerier) BacktickScan(results pgx.BatchResults) (string, error) {
 	return item, nil
 }
 
+// Backtick queues a Backtick query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) Backtick() *BacktickHandle {
+	b.q.BacktickBatch(b.batch)
+	h := &BacktickHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// BacktickHandle is the result of a Backtick query queued in a Batch.
+type BacktickHandle struct {
+	b   *Batch
+	res string
+	err error
+}
+
+func (h *BacktickHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.BacktickScan(results)
+	return h.err
+}
+
+// Result returns the result of the Backtick query. Returns an error if the
+// batch wasn't sent.
+func (h *BacktickHandle) Result() (string, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("Backtick result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const backtickQuoteBacktickSQL = "SELECT '`\"`';"
 
+const backtickQuoteBacktickStmt = "pggen_BacktickQuoteBacktick_5cc3d7890a0b3bec"
+
 // BacktickQuoteBacktick implements Querier.BacktickQuoteBacktick.
 func (q *DBQuerier) BacktickQuoteBacktick(ctx context.Context) (string, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "BacktickQuoteBacktick")
-	row := q.conn.QueryRow(ctx, backtickQuoteBacktickSQL)
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(backtickQuoteBacktickSQL, backtickQuoteBacktickStmt))
 	var item string
 	if err := row.Scan(&item); err != nil {
 		return item, fmt.Errorf("query BacktickQuoteBacktick: %w", err)
@@ -283,7 +773,7 @@ func (q *DBQuerier) BacktickQuoteBacktick(ctx context.Context) (string, error) {
 
 // BacktickQuoteBacktickBatch implements Querier.BacktickQuoteBacktickBatch.
 func (q *DBQuerier) BacktickQuoteBacktickBatch(batch genericBatch) {
-	batch.Queue(backtickQuoteBacktickSQL)
+	batch.Queue(q.chooseSQL(backtickQuoteBacktickSQL, backtickQuoteBacktickStmt))
 }
 
 // BacktickQuoteBacktickScan implements Querier.BacktickQuoteBacktickScan.
@@ -296,12 +786,44 @@ func (q *DBQuerier) BacktickQuoteBacktickScan(results pgx.BatchResults) (string,
 	return item, nil
 }
 
+// BacktickQuoteBacktick queues a BacktickQuoteBacktick query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) BacktickQuoteBacktick() *BacktickQuoteBacktickHandle {
+	b.q.BacktickQuoteBacktickBatch(b.batch)
+	h := &BacktickQuoteBacktickHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// BacktickQuoteBacktickHandle is the result of a BacktickQuoteBacktick query queued in a Batch.
+type BacktickQuoteBacktickHandle struct {
+	b   *Batch
+	res string
+	err error
+}
+
+func (h *BacktickQuoteBacktickHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.BacktickQuoteBacktickScan(results)
+	return h.err
+}
+
+// Result returns the result of the BacktickQuoteBacktick query. Returns an error if the
+// batch wasn't sent.
+func (h *BacktickQuoteBacktickHandle) Result() (string, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("BacktickQuoteBacktick result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const backtickNewlineSQL = "SELECT '`\n';"
 
+const backtickNewlineStmt = "pggen_BacktickNewline_629a1f055d97e9b3"
+
 // BacktickNewline implements Querier.BacktickNewline.
 func (q *DBQuerier) BacktickNewline(ctx context.Context) (string, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "BacktickNewline")
-	row := q.conn.QueryRow(ctx, backtickNewlineSQL)
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(backtickNewlineSQL, backtickNewlineStmt))
 	var item string
 	if err := row.Scan(&item); err != nil {
 		return item, fmt.Errorf("query BacktickNewline: %w", err)
@@ -311,7 +833,7 @@ func (q *DBQuerier) BacktickNewline(ctx context.Context) (string, error) {
 
 // BacktickNewlineBatch implements Querier.BacktickNewlineBatch.
 func (q *DBQuerier) BacktickNewlineBatch(batch genericBatch) {
-	batch.Queue(backtickNewlineSQL)
+	batch.Queue(q.chooseSQL(backtickNewlineSQL, backtickNewlineStmt))
 }
 
 // BacktickNewlineScan implements Querier.BacktickNewlineScan.
@@ -324,12 +846,44 @@ func (q *DBQuerier) BacktickNewlineScan(results pgx.BatchResults) (string, error
 	return item, nil
 }
 
+// BacktickNewline queues a BacktickNewline query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) BacktickNewline() *BacktickNewlineHandle {
+	b.q.BacktickNewlineBatch(b.batch)
+	h := &BacktickNewlineHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// BacktickNewlineHandle is the result of a BacktickNewline query queued in a Batch.
+type BacktickNewlineHandle struct {
+	b   *Batch
+	res string
+	err error
+}
+
+func (h *BacktickNewlineHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.BacktickNewlineScan(results)
+	return h.err
+}
+
+// Result returns the result of the BacktickNewline query. Returns an error if the
+// batch wasn't sent.
+func (h *BacktickNewlineHandle) Result() (string, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("BacktickNewline result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const backtickDoubleQuoteSQL = "SELECT '`\"';"
 
+const backtickDoubleQuoteStmt = "pggen_BacktickDoubleQuote_31912b15f202657b"
+
 // BacktickDoubleQuote implements Querier.BacktickDoubleQuote.
 func (q *DBQuerier) BacktickDoubleQuote(ctx context.Context) (string, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "BacktickDoubleQuote")
-	row := q.conn.QueryRow(ctx, backtickDoubleQuoteSQL)
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(backtickDoubleQuoteSQL, backtickDoubleQuoteStmt))
 	var item string
 	if err := row.Scan(&item); err != nil {
 		return item, fmt.Errorf("query BacktickDoubleQuote: %w", err)
@@ -339,7 +893,7 @@ func (q *DBQuerier) BacktickDoubleQuote(ctx context.Context) (string, error) {
 
 // BacktickDoubleQuoteBatch implements Querier.BacktickDoubleQuoteBatch.
 func (q *DBQuerier) BacktickDoubleQuoteBatch(batch genericBatch) {
-	batch.Queue(backtickDoubleQuoteSQL)
+	batch.Queue(q.chooseSQL(backtickDoubleQuoteSQL, backtickDoubleQuoteStmt))
 }
 
 // BacktickDoubleQuoteScan implements Querier.BacktickDoubleQuoteScan.
@@ -352,12 +906,44 @@ func (q *DBQuerier) BacktickDoubleQuoteScan(results pgx.BatchResults) (string, e
 	return item, nil
 }
 
+// BacktickDoubleQuote queues a BacktickDoubleQuote query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) BacktickDoubleQuote() *BacktickDoubleQuoteHandle {
+	b.q.BacktickDoubleQuoteBatch(b.batch)
+	h := &BacktickDoubleQuoteHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// BacktickDoubleQuoteHandle is the result of a BacktickDoubleQuote query queued in a Batch.
+type BacktickDoubleQuoteHandle struct {
+	b   *Batch
+	res string
+	err error
+}
+
+func (h *BacktickDoubleQuoteHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.BacktickDoubleQuoteScan(results)
+	return h.err
+}
+
+// Result returns the result of the BacktickDoubleQuote query. Returns an error if the
+// batch wasn't sent.
+func (h *BacktickDoubleQuoteHandle) Result() (string, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("BacktickDoubleQuote result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const backtickBackslashNSQL = "SELECT '`\\n';"
 
+const backtickBackslashNStmt = "pggen_BacktickBackslashN_a2f343dc98cb5939"
+
 // BacktickBackslashN implements Querier.BacktickBackslashN.
 func (q *DBQuerier) BacktickBackslashN(ctx context.Context) (string, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "BacktickBackslashN")
-	row := q.conn.QueryRow(ctx, backtickBackslashNSQL)
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(backtickBackslashNSQL, backtickBackslashNStmt))
 	var item string
 	if err := row.Scan(&item); err != nil {
 		return item, fmt.Errorf("query BacktickBackslashN: %w", err)
@@ -367,7 +953,7 @@ func (q *DBQuerier) BacktickBackslashN(ctx context.Context) (string, error) {
 
 // BacktickBackslashNBatch implements Querier.BacktickBackslashNBatch.
 func (q *DBQuerier) BacktickBackslashNBatch(batch genericBatch) {
-	batch.Queue(backtickBackslashNSQL)
+	batch.Queue(q.chooseSQL(backtickBackslashNSQL, backtickBackslashNStmt))
 }
 
 // BacktickBackslashNScan implements Querier.BacktickBackslashNScan.
@@ -380,8 +966,40 @@ func (q *DBQuerier) BacktickBackslashNScan(results pgx.BatchResults) (string, er
 	return item, nil
 }
 
+// BacktickBackslashN queues a BacktickBackslashN query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) BacktickBackslashN() *BacktickBackslashNHandle {
+	b.q.BacktickBackslashNBatch(b.batch)
+	h := &BacktickBackslashNHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// BacktickBackslashNHandle is the result of a BacktickBackslashN query queued in a Batch.
+type BacktickBackslashNHandle struct {
+	b   *Batch
+	res string
+	err error
+}
+
+func (h *BacktickBackslashNHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.BacktickBackslashNScan(results)
+	return h.err
+}
+
+// Result returns the result of the BacktickBackslashN query. Returns an error if the
+// batch wasn't sent.
+func (h *BacktickBackslashNHandle) Result() (string, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("BacktickBackslashN result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const illegalNameSymbolsSQL = "SELECT '`\\n' as \"$\", $1 as \"foo.bar!@#$%&*()\"\"--+\";"
 
+const illegalNameSymbolsStmt = "pggen_IllegalNameSymbols_6a3626c9c2c7f8fc"
+
 type IllegalNameSymbolsRow struct {
 	UnnamedColumn0 string `json:"$"`
 	FooBar         string `json:"foo.bar!@#$%&*()\"--+"`
@@ -390,7 +1008,7 @@ type IllegalNameSymbolsRow struct {
 // IllegalNameSymbols implements Querier.IllegalNameSymbols.
 func (q *DBQuerier) IllegalNameSymbols(ctx context.Context, helloWorld string) (IllegalNameSymbolsRow, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "IllegalNameSymbols")
-	row := q.conn.QueryRow(ctx, illegalNameSymbolsSQL, helloWorld)
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(illegalNameSymbolsSQL, illegalNameSymbolsStmt), helloWorld)
 	var item IllegalNameSymbolsRow
 	if err := row.Scan(&item.UnnamedColumn0, &item.FooBar); err != nil {
 		return item, fmt.Errorf("query IllegalNameSymbols: %w", err)
@@ -400,7 +1018,7 @@ func (q *DBQuerier) IllegalNameSymbols(ctx context.Context, helloWorld string) (
 
 // IllegalNameSymbolsBatch implements Querier.IllegalNameSymbolsBatch.
 func (q *DBQuerier) IllegalNameSymbolsBatch(batch genericBatch, helloWorld string) {
-	batch.Queue(illegalNameSymbolsSQL, helloWorld)
+	batch.Queue(q.chooseSQL(illegalNameSymbolsSQL, illegalNameSymbolsStmt), helloWorld)
 }
 
 // IllegalNameSymbolsScan implements Querier.IllegalNameSymbolsScan.
@@ -413,12 +1031,44 @@ func (q *DBQuerier) IllegalNameSymbolsScan(results pgx.BatchResults) (IllegalNam
 	return item, nil
 }
 
+// IllegalNameSymbols queues a IllegalNameSymbols query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) IllegalNameSymbols(helloWorld string) *IllegalNameSymbolsHandle {
+	b.q.IllegalNameSymbolsBatch(b.batch, helloWorld)
+	h := &IllegalNameSymbolsHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// IllegalNameSymbolsHandle is the result of a IllegalNameSymbols query queued in a Batch.
+type IllegalNameSymbolsHandle struct {
+	b   *Batch
+	res IllegalNameSymbolsRow
+	err error
+}
+
+func (h *IllegalNameSymbolsHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.IllegalNameSymbolsScan(results)
+	return h.err
+}
+
+// Result returns the result of the IllegalNameSymbols query. Returns an error if the
+// batch wasn't sent.
+func (h *IllegalNameSymbolsHandle) Result() (IllegalNameSymbolsRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("IllegalNameSymbols result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const spaceAfterSQL = `SELECT $1;`
 
+const spaceAfterStmt = "pggen_SpaceAfter_55f848d77c10cbee"
+
 // SpaceAfter implements Querier.SpaceAfter.
 func (q *DBQuerier) SpaceAfter(ctx context.Context, space string) (string, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "SpaceAfter")
-	row := q.conn.QueryRow(ctx, spaceAfterSQL, space)
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(spaceAfterSQL, spaceAfterStmt), space)
 	var item string
 	if err := row.Scan(&item); err != nil {
 		return item, fmt.Errorf("query SpaceAfter: %w", err)
@@ -428,7 +1078,7 @@ func (q *DBQuerier) SpaceAfter(ctx context.Context, space string) (string, error
 
 // SpaceAfterBatch implements Querier.SpaceAfterBatch.
 func (q *DBQuerier) SpaceAfterBatch(batch genericBatch, space string) {
-	batch.Queue(spaceAfterSQL, space)
+	batch.Queue(q.chooseSQL(spaceAfterSQL, spaceAfterStmt), space)
 }
 
 // SpaceAfterScan implements Querier.SpaceAfterScan.
@@ -441,12 +1091,44 @@ func (q *DBQuerier) SpaceAfterScan(results pgx.BatchResults) (string, error) {
 	return item, nil
 }
 
+// SpaceAfter queues a SpaceAfter query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) SpaceAfter(space string) *SpaceAfterHandle {
+	b.q.SpaceAfterBatch(b.batch, space)
+	h := &SpaceAfterHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// SpaceAfterHandle is the result of a SpaceAfter query queued in a Batch.
+type SpaceAfterHandle struct {
+	b   *Batch
+	res string
+	err error
+}
+
+func (h *SpaceAfterHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.SpaceAfterScan(results)
+	return h.err
+}
+
+// Result returns the result of the SpaceAfter query. Returns an error if the
+// batch wasn't sent.
+func (h *SpaceAfterHandle) Result() (string, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("SpaceAfter result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const badEnumNameSQL = `SELECT 'inconvertible_enum_name'::"123";`
 
+const badEnumNameStmt = "pggen_BadEnumName_3128914efe17cfb8"
+
 // BadEnumName implements Querier.BadEnumName.
 func (q *DBQuerier) BadEnumName(ctx context.Context) (UnnamedEnum123, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "BadEnumName")
-	row := q.conn.QueryRow(ctx, badEnumNameSQL)
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(badEnumNameSQL, badEnumNameStmt))
 	var item UnnamedEnum123
 	if err := row.Scan(&item); err != nil {
 		return item, fmt.Errorf("query BadEnumName: %w", err)
@@ -456,7 +1138,7 @@ func (q *DBQuerier) BadEnumName(ctx context.Context) (UnnamedEnum123, error) {
 
 // BadEnumNameBatch implements Querier.BadEnumNameBatch.
 func (q *DBQuerier) BadEnumNameBatch(batch genericBatch) {
-	batch.Queue(badEnumNameSQL)
+	batch.Queue(q.chooseSQL(badEnumNameSQL, badEnumNameStmt))
 }
 
 // BadEnumNameScan implements Querier.BadEnumNameScan.
@@ -469,12 +1151,44 @@ func (q *DBQuerier) BadEnumNameScan(results pgx.BatchResults) (UnnamedEnum123, e
 	return item, nil
 }
 
+// BadEnumName queues a BadEnumName query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) BadEnumName() *BadEnumNameHandle {
+	b.q.BadEnumNameBatch(b.batch)
+	h := &BadEnumNameHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// BadEnumNameHandle is the result of a BadEnumName query queued in a Batch.
+type BadEnumNameHandle struct {
+	b   *Batch
+	res UnnamedEnum123
+	err error
+}
+
+func (h *BadEnumNameHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.BadEnumNameScan(results)
+	return h.err
+}
+
+// Result returns the result of the BadEnumName query. Returns an error if the
+// batch wasn't sent.
+func (h *BadEnumNameHandle) Result() (UnnamedEnum123, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("BadEnumName result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const goKeywordSQL = `SELECT $1::text;`
 
+const goKeywordStmt = "pggen_GoKeyword_3544691657c16657"
+
 // GoKeyword implements Querier.GoKeyword.
 func (q *DBQuerier) GoKeyword(ctx context.Context, go_ string) (string, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "GoKeyword")
-	row := q.conn.QueryRow(ctx, goKeywordSQL, go_)
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(goKeywordSQL, goKeywordStmt), go_)
 	var item string
 	if err := row.Scan(&item); err != nil {
 		return item, fmt.Errorf("query GoKeyword: %w", err)
@@ -484,7 +1198,7 @@ func (q *DBQuerier) GoKeyword(ctx context.Context, go_ string) (string, error) {
 
 // GoKeywordBatch implements Querier.GoKeywordBatch.
 func (q *DBQuerier) GoKeywordBatch(batch genericBatch, go_ string) {
-	batch.Queue(goKeywordSQL, go_)
+	batch.Queue(q.chooseSQL(goKeywordSQL, goKeywordStmt), go_)
 }
 
 // GoKeywordScan implements Querier.GoKeywordScan.
@@ -497,6 +1211,36 @@ func (q *DBQuerier) GoKeywordScan(results pgx.BatchResults) (string, error) {
 	return item, nil
 }
 
+// GoKeyword queues a GoKeyword query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) GoKeyword(go_ string) *GoKeywordHandle {
+	b.q.GoKeywordBatch(b.batch, go_)
+	h := &GoKeywordHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// GoKeywordHandle is the result of a GoKeyword query queued in a Batch.
+type GoKeywordHandle struct {
+	b   *Batch
+	res string
+	err error
+}
+
+func (h *GoKeywordHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.GoKeywordScan(results)
+	return h.err
+}
+
+// Result returns the result of the GoKeyword query. Returns an error if the
+// batch wasn't sent.
+func (h *GoKeywordHandle) Result() (string, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("GoKeyword result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 // textPreferrer wraps a pgtype.ValueTranscoder and sets the preferred encoding
 // format to text instead binary (the default). pggen uses the text format
 // when the OID is unknownOID because the binary format requires the OID.
@@ -4,10 +4,12 @@ package void
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v4"
+	"time"
 )
 
 // Querier is a typesafe Go interface backed by SQL queries.
@@ -50,11 +52,17 @@ type Querier interface {
 	VoidThree2Batch(batch genericBatch)
 	// VoidThree2Scan scans the result of an executed VoidThree2Batch query.
 	VoidThree2Scan(results pgx.BatchResults) ([]string, error)
+
+	// RunInTx runs fn in a transaction using a Querier bound to the
+	// transaction. See DBQuerier.RunInTx.
+	RunInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error
 }
 
 type DBQuerier struct {
-	conn  genericConn   // underlying Postgres transport to use
-	types *typeResolver // resolve types by name
+	conn    genericConn   // underlying Postgres transport to use
+	replica genericConn   // transport for read-only queries; nil unless routing
+	types   *typeResolver // resolve types by name
+	cfg     QuerierConfig // config used to create the querier
 }
 
 var _ Querier = &DBQuerier{}
@@ -100,17 +108,362 @@ type QuerierConfig struct {
 	// transitive dependencies, pggen will use the binary encoding format for
 	// the input parameter.
 	DataTypes []pgtype.DataType
+
+	// UsePreparedStatements runs queries by the statement names prepared by
+	// PrepareAllQueries instead of by SQL text. Every connection used by the
+	// querier must run PrepareAllQueries first, typically in the AfterConnect
+	// callback of pgxpool.Config.
+	//
+	// If false, the default, queries run by SQL text, which works whether or
+	// not the connection prepared the statements.
+	UsePreparedStatements bool
+
+	// PgBouncerCompat enables compatibility with PgBouncer in transaction
+	// pooling mode, which doesn't support prepared statements. Each query runs
+	// with pgx.QuerySimpleProtocol, pggen-generated transcoders always encode
+	// in the text format, UsePreparedStatements is ignored, and
+	// PrepareAllQueriesConfig doesn't prepare any statements.
+	//
+	// pgx only sends a pgx.Batch with the simple protocol if
+	// PreferSimpleProtocol is set on pgx.ConnConfig, so Batch.Send runs the
+	// queued queries one at a time with the simple protocol instead of in a
+	// single network round-trip.
+	PgBouncerCompat bool
 }
 
 // NewQuerierConfig creates a DBQuerier that implements Querier with the given
 // config. conn is typically *pgx.Conn, pgx.Tx, or *pgxpool.Pool.
 func NewQuerierConfig(conn genericConn, cfg QuerierConfig) *DBQuerier {
-	return &DBQuerier{conn: conn, types: newTypeResolver(cfg.DataTypes)}
+	if cfg.PgBouncerCompat {
+		conn = simpleProtocolConn{conn}
+	}
+	return &DBQuerier{conn: conn, types: newTypeResolver(cfg.DataTypes, cfg.PgBouncerCompat), cfg: cfg}
 }
 
 // WithTx creates a new DBQuerier that uses the transaction to run all queries.
+// The returned DBQuerier keeps the config of q. If q is a routing querier, the
+// returned DBQuerier runs read-only queries in the transaction too.
 func (q *DBQuerier) WithTx(tx pgx.Tx) (*DBQuerier, error) {
-	return &DBQuerier{conn: tx}, nil
+	var conn genericConn = tx
+	if q.cfg.PgBouncerCompat {
+		conn = simpleProtocolConn{conn}
+	}
+	return &DBQuerier{conn: conn, types: q.types, cfg: q.cfg}, nil
+}
+
+// NewRoutingQuerier creates a DBQuerier that runs read-only queries on replica
+// and all other queries on primary. pggen classifies a query as read-only if
+// the query plan doesn't modify a table or lock rows. Queries with the
+// route=primary pragma, batches, and transactions always use primary.
+func NewRoutingQuerier(primary, replica genericConn) *DBQuerier {
+	return NewRoutingQuerierConfig(primary, replica, QuerierConfig{})
+}
+
+// NewRoutingQuerierConfig creates a DBQuerier like NewRoutingQuerier with the
+// given config. The config applies to both primary and replica.
+func NewRoutingQuerierConfig(primary, replica genericConn, cfg QuerierConfig) *DBQuerier {
+	q := NewQuerierConfig(primary, cfg)
+	if cfg.PgBouncerCompat {
+		replica = simpleProtocolConn{replica}
+	}
+	q.replica = replica
+	return q
+}
+
+// readConn returns the Postgres transport for read-only queries.
+func (q *DBQuerier) readConn() genericConn {
+	if q.replica == nil {
+		return q.conn
+	}
+	return q.replica
+}
+
+// simpleProtocolConn is a genericConn that runs all queries with the simple
+// protocol, overriding pgx.ConnConfig.PreferSimpleProtocol.
+type simpleProtocolConn struct {
+	genericConn
+}
+
+func (c simpleProtocolConn) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return c.genericConn.Query(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, args...)...)
+}
+
+func (c simpleProtocolConn) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return c.genericConn.QueryRow(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, args...)...)
+}
+
+func (c simpleProtocolConn) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	return c.genericConn.Exec(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, arguments...)...)
+}
+
+// transport returns the underlying Postgres transport of q, unwrapping any
+// wrapper added by the config.
+func (q *DBQuerier) transport() genericConn {
+	if c, ok := q.conn.(simpleProtocolConn); ok {
+		return c.genericConn
+	}
+	return q.conn
+}
+
+// txBeginner is any Postgres connection transport that can begin a
+// transaction, most commonly *pgx.Conn or *pgxpool.Pool.
+type txBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// TxOptions controls how RunInTx begins and retries a transaction.
+type TxOptions struct {
+	// TxOptions are the options used to begin a top-level transaction. Ignored
+	// for nested transactions, which use a savepoint.
+	pgx.TxOptions
+
+	// MaxRetries is the maximum number of times to retry a top-level
+	// transaction that failed with a serialization failure (SQLSTATE 40001) or
+	// a deadlock (SQLSTATE 40P01). Zero disables retries.
+	MaxRetries int
+
+	// Backoff returns how long to wait before the nth retry, starting at 1. If
+	// nil, retries immediately.
+	Backoff func(retry int) time.Duration
+}
+
+// RunInTx runs fn in a transaction. fn receives a Querier bound to the
+// transaction with the same config as q. If fn returns nil, RunInTx commits
+// the transaction; otherwise, RunInTx rolls back the transaction and returns
+// the error from fn.
+//
+// If q is already bound to a transaction, like the Querier passed to fn,
+// RunInTx creates a nested transaction using a savepoint. Only the top-level
+// transaction is retried because a serialization failure aborts the entire
+// transaction.
+func (q *DBQuerier) RunInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error {
+	_, isNested := q.transport().(pgx.Tx)
+	for retry := 1; ; retry++ {
+		err := q.runInTx(ctx, opts, fn)
+		if err == nil || isNested || retry > opts.MaxRetries || !isRetryableTxErr(err) {
+			return err
+		}
+		if opts.Backoff == nil {
+			continue
+		}
+		timer := time.NewTimer(opts.Backoff(retry))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("retry tx: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// runInTx runs a single attempt of fn in a transaction.
+func (q *DBQuerier) runInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error {
+	var tx pgx.Tx
+	var err error
+	switch conn := q.transport().(type) {
+	case pgx.Tx:
+		tx, err = conn.Begin(ctx) // savepoint
+	case txBeginner:
+		tx, err = conn.BeginTx(ctx, opts.TxOptions)
+	default:
+		return fmt.Errorf("begin tx: conn of type %T cannot begin a transaction", conn)
+	}
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	txq, _ := q.WithTx(tx)
+	if err := fn(txq); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			return fmt.Errorf("rollback tx: %v; original error: %w", rbErr, err)
+		}
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// isRetryableTxErr returns true if err is a serialization failure or deadlock
+// such that retrying the entire transaction might succeed.
+func isRetryableTxErr(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+// batchSender is any Postgres connection transport that can send a batch of
+// queries, most commonly *pgx.Conn, pgx.Tx, or *pgxpool.Pool.
+type batchSender interface {
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// Batch is a typed builder for a pgx.Batch. Each query method on Batch queues
+// the query and returns a handle. After calling Send, use Result on the handle
+// to get the typed result of the query.
+type Batch struct {
+	q       *DBQuerier
+	batch   queueBatch    // *pgx.Batch, or *simpleBatch with PgBouncerCompat
+	handles []batchHandle // handles in the same order as the queued queries
+	sent    bool
+}
+
+// batchHandle scans the result of a single query queued in a Batch.
+type batchHandle interface {
+	scan(results pgx.BatchResults) error
+}
+
+var errBatchNotSent = errors.New("batch not sent")
+
+// queueBatch is a genericBatch that counts the queued queries.
+type queueBatch interface {
+	genericBatch
+	Len() int
+}
+
+// NewBatch creates a typed Batch that sends queries using the conn of q.
+func (q *DBQuerier) NewBatch() *Batch {
+	if q.cfg.PgBouncerCompat {
+		return &Batch{q: q, batch: &simpleBatch{}}
+	}
+	return &Batch{q: q, batch: &pgx.Batch{}}
+}
+
+// Send sends all queued queries with SendBatch and scans the result of each
+// query into its handle in queue order. If any query fails, Send returns a
+// BatchError with the errors of all failed queries.
+func (b *Batch) Send(ctx context.Context) error {
+	if b.sent {
+		return errors.New("send batch: batch already sent")
+	}
+	if b.batch.Len() != len(b.handles) {
+		return fmt.Errorf("send batch: %d queued queries but %d handles", b.batch.Len(), len(b.handles))
+	}
+	var results pgx.BatchResults
+	switch batch := b.batch.(type) {
+	case *simpleBatch:
+		b.sent = true
+		results = &simpleBatchResults{ctx: ctx, conn: b.q.conn, queries: batch.queries}
+	case *pgx.Batch:
+		sender, ok := b.q.transport().(batchSender)
+		if !ok {
+			return fmt.Errorf("send batch: conn of type %T cannot send a batch", b.q.transport())
+		}
+		b.sent = true
+		results = sender.SendBatch(ctx, batch)
+	default:
+		return fmt.Errorf("send batch: unknown batch type %T", b.batch)
+	}
+	var errs []error
+	for _, h := range b.handles {
+		if err := h.scan(results); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := results.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("close batch results: %w", err))
+	}
+	if len(errs) > 0 {
+		return BatchError{Errs: errs}
+	}
+	return nil
+}
+
+// BatchError is the error returned by Batch.Send with the errors of all failed
+// queries in queue order.
+type BatchError struct {
+	Errs []error
+}
+
+func (e BatchError) Error() string {
+	msg := fmt.Sprintf("send batch: %d errors", len(e.Errs))
+	for _, err := range e.Errs {
+		msg += "; " + err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the first error.
+func (e BatchError) Unwrap() error { return e.Errs[0] }
+
+// simpleBatch is a genericBatch that records the queued queries so that
+// simpleBatchResults can run them with the simple protocol.
+type simpleBatch struct {
+	queries []simpleQuery
+}
+
+type simpleQuery struct {
+	sql  string
+	args []interface{}
+}
+
+func (b *simpleBatch) Queue(query string, arguments ...interface{}) {
+	b.queries = append(b.queries, simpleQuery{sql: query, args: arguments})
+}
+
+func (b *simpleBatch) Len() int { return len(b.queries) }
+
+// simpleBatchResults is a pgx.BatchResults that runs the next query of a
+// simpleBatch on conn when reading its result. conn is a simpleProtocolConn,
+// so each query runs with the simple protocol.
+type simpleBatchResults struct {
+	ctx     context.Context
+	conn    genericConn
+	queries []simpleQuery
+	idx     int // index of the next query
+}
+
+func (r *simpleBatchResults) next() (simpleQuery, error) {
+	if r.idx == len(r.queries) {
+		return simpleQuery{}, fmt.Errorf("no result for batch query %d", r.idx)
+	}
+	q := r.queries[r.idx]
+	r.idx++
+	return q, nil
+}
+
+func (r *simpleBatchResults) Exec() (pgconn.CommandTag, error) {
+	q, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	return r.conn.Exec(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) Query() (pgx.Rows, error) {
+	q, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	return r.conn.Query(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) QueryRow() pgx.Row {
+	q, err := r.next()
+	if err != nil {
+		return errRow{err: err}
+	}
+	return r.conn.QueryRow(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) Close() error { return nil }
+
+// errRow is a pgx.Row that returns err from Scan.
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(...interface{}) error { return r.err }
+
+// chooseSQL returns the name of the prepared statement if the querier uses
+// prepared statements. Otherwise, returns the SQL text.
+func (q *DBQuerier) chooseSQL(sql, stmtName string) string {
+	if q.cfg.UsePreparedStatements && !q.cfg.PgBouncerCompat {
+		return stmtName
+	}
+	return sql
 }
 
 // preparer is any Postgres connection transport that provides a way to prepare
@@ -123,42 +476,57 @@ type preparer interface {
 // queries in querier files. Typical usage is as the AfterConnect callback
 // for pgxpool.Config
 //
-// pgx will use the prepared statement if available. Calling PrepareAllQueries
-// is an optional optimization to avoid a network round-trip the first time pgx
-// runs a query if pgx statement caching is enabled.
+// Each statement is prepared under a deterministic name derived from the query
+// name and SQL text. A querier created with
+// QuerierConfig.UsePreparedStatements runs queries by those names, avoiding a
+// network round-trip to prepare the statement the first time pgx runs a query.
+//
+// Use PrepareAllQueriesConfig with the config of the querier to skip preparing
+// statements with QuerierConfig.PgBouncerCompat.
 func PrepareAllQueries(ctx context.Context, p preparer) error {
-	if _, err := p.Prepare(ctx, voidOnlySQL, voidOnlySQL); err != nil {
+	if _, err := p.Prepare(ctx, voidOnlyStmt, voidOnlySQL); err != nil {
 		return fmt.Errorf("prepare query 'VoidOnly': %w", err)
 	}
-	if _, err := p.Prepare(ctx, voidOnlyTwoParamsSQL, voidOnlyTwoParamsSQL); err != nil {
+	if _, err := p.Prepare(ctx, voidOnlyTwoParamsStmt, voidOnlyTwoParamsSQL); err != nil {
 		return fmt.Errorf("prepare query 'VoidOnlyTwoParams': %w", err)
 	}
-	if _, err := p.Prepare(ctx, voidTwoSQL, voidTwoSQL); err != nil {
+	if _, err := p.Prepare(ctx, voidTwoStmt, voidTwoSQL); err != nil {
 		return fmt.Errorf("prepare query 'VoidTwo': %w", err)
 	}
-	if _, err := p.Prepare(ctx, voidThreeSQL, voidThreeSQL); err != nil {
+	if _, err := p.Prepare(ctx, voidThreeStmt, voidThreeSQL); err != nil {
 		return fmt.Errorf("prepare query 'VoidThree': %w", err)
 	}
-	if _, err := p.Prepare(ctx, voidThree2SQL, voidThree2SQL); err != nil {
+	if _, err := p.Prepare(ctx, voidThree2Stmt, voidThree2SQL); err != nil {
 		return fmt.Errorf("prepare query 'VoidThree2': %w", err)
 	}
 	return nil
 }
 
+// PrepareAllQueriesConfig is like PrepareAllQueries but doesn't prepare any
+// statements if cfg.PgBouncerCompat is set, because PgBouncer in transaction
+// pooling mode doesn't support prepared statements.
+func PrepareAllQueriesConfig(ctx context.Context, p preparer, cfg QuerierConfig) error {
+	if cfg.PgBouncerCompat {
+		return nil
+	}
+	return PrepareAllQueries(ctx, p)
+}
+
 // typeResolver looks up the pgtype.ValueTranscoder by Postgres type name.
 type typeResolver struct {
-	connInfo *pgtype.ConnInfo // types by Postgres type name
+	connInfo   *pgtype.ConnInfo // types by Postgres type name
+	preferText bool             // if true, always encode in the text format
 }
 
-func newTypeResolver(types []pgtype.DataType) *typeResolver {
+func newTypeResolver(types []pgtype.DataType, preferText bool) *typeResolver {
 	ci := pgtype.NewConnInfo()
 	for _, typ := range types {
-		if txt, ok := typ.Value.(textPreferrer); ok && typ.OID != unknownOID {
+		if txt, ok := typ.Value.(textPreferrer); ok && typ.OID != unknownOID && !preferText {
 			typ.Value = txt.ValueTranscoder
 		}
 		ci.RegisterDataType(typ)
 	}
-	return &typeResolver{connInfo: ci}
+	return &typeResolver{connInfo: ci, preferText: preferText}
 }
 
 // findValue find the OID, and pgtype.ValueTranscoder for a Postgres type name.
@@ -167,8 +535,11 @@ func (tr *typeResolver) findValue(name string) (uint32, pgtype.ValueTranscoder,
 	if !ok {
 		return 0, nil, false
 	}
-	v := pgtype.NewValue(typ.Value)
-	return typ.OID, v.(pgtype.ValueTranscoder), true
+	v := pgtype.NewValue(typ.Value).(pgtype.ValueTranscoder)
+	if _, isText := v.(textPreferrer); tr.preferText && !isText {
+		v = textPreferrer{v, name}
+	}
+	return typ.OID, v, true
 }
 
 // setValue sets the value of a ValueTranscoder to a value that should always
@@ -182,10 +553,12 @@ func (tr *typeResolver) setValue(vt pgtype.ValueTranscoder, val interface{}) pgt
 
 const voidOnlySQL = `SELECT void_fn();`
 
+const voidOnlyStmt = "pggen_VoidOnly_e6b13c70696c93d8"
+
 // VoidOnly implements Querier.VoidOnly.
 func (q *DBQuerier) VoidOnly(ctx context.Context) (pgconn.CommandTag, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "VoidOnly")
-	cmdTag, err := q.conn.Exec(ctx, voidOnlySQL)
+	cmdTag, err := q.readConn().Exec(ctx, q.chooseSQL(voidOnlySQL, voidOnlyStmt))
 	if err != nil {
 		return cmdTag, fmt.Errorf("exec query VoidOnly: %w", err)
 	}
@@ -194,7 +567,7 @@ func (q *DBQuerier) VoidOnly(ctx context.Context) (pgconn.CommandTag, error) {
 
 // VoidOnlyBatch implements Querier.VoidOnlyBatch.
 func (q *DBQuerier) VoidOnlyBatch(batch genericBatch) {
-	batch.Queue(voidOnlySQL)
+	batch.Queue(q.chooseSQL(voidOnlySQL, voidOnlyStmt))
 }
 
 // VoidOnlyScan implements Querier.VoidOnlyScan.
@@ -206,12 +579,44 @@ func (q *DBQuerier) VoidOnlyScan(results pgx.BatchResults) (pgconn.CommandTag, e
 	return cmdTag, err
 }
 
+// VoidOnly queues a VoidOnly query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) VoidOnly() *VoidOnlyHandle {
+	b.q.VoidOnlyBatch(b.batch)
+	h := &VoidOnlyHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// VoidOnlyHandle is the result of a VoidOnly query queued in a Batch.
+type VoidOnlyHandle struct {
+	b   *Batch
+	res pgconn.CommandTag
+	err error
+}
+
+func (h *VoidOnlyHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.VoidOnlyScan(results)
+	return h.err
+}
+
+// Result returns the result of the VoidOnly query. Returns an error if the
+// batch wasn't sent.
+func (h *VoidOnlyHandle) Result() (pgconn.CommandTag, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("VoidOnly result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const voidOnlyTwoParamsSQL = `SELECT void_fn_two_params($1, 'text');`
 
+const voidOnlyTwoParamsStmt = "pggen_VoidOnlyTwoParams_826f4a2424d13fed"
+
 // VoidOnlyTwoParams implements Querier.VoidOnlyTwoParams.
 func (q *DBQuerier) VoidOnlyTwoParams(ctx context.Context, id int32) (pgconn.CommandTag, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "VoidOnlyTwoParams")
-	cmdTag, err := q.conn.Exec(ctx, voidOnlyTwoParamsSQL, id)
+	cmdTag, err := q.readConn().Exec(ctx, q.chooseSQL(voidOnlyTwoParamsSQL, voidOnlyTwoParamsStmt), id)
 	if err != nil {
 		return cmdTag, fmt.Errorf("exec query VoidOnlyTwoParams: %w", err)
 	}
@@ -220,7 +625,7 @@ func (q *DBQuerier) VoidOnlyTwoParams(ctx context.Context, id int32) (pgconn.Com
 
 // VoidOnlyTwoParamsBatch implements Querier.VoidOnlyTwoParamsBatch.
 func (q *DBQuerier) VoidOnlyTwoParamsBatch(batch genericBatch, id int32) {
-	batch.Queue(voidOnlyTwoParamsSQL, id)
+	batch.Queue(q.chooseSQL(voidOnlyTwoParamsSQL, voidOnlyTwoParamsStmt), id)
 }
 
 // VoidOnlyTwoParamsScan implements Querier.VoidOnlyTwoParamsScan.
@@ -232,12 +637,44 @@ func (q *DBQuerier) VoidOnlyTwoParamsScan(results pgx.BatchResults) (pgconn.Comm
 	return cmdTag, err
 }
 
+// VoidOnlyTwoParams queues a VoidOnlyTwoParams query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) VoidOnlyTwoParams(id int32) *VoidOnlyTwoParamsHandle {
+	b.q.VoidOnlyTwoParamsBatch(b.batch, id)
+	h := &VoidOnlyTwoParamsHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// VoidOnlyTwoParamsHandle is the result of a VoidOnlyTwoParams query queued in a Batch.
+type VoidOnlyTwoParamsHandle struct {
+	b   *Batch
+	res pgconn.CommandTag
+	err error
+}
+
+func (h *VoidOnlyTwoParamsHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.VoidOnlyTwoParamsScan(results)
+	return h.err
+}
+
+// Result returns the result of the VoidOnlyTwoParams query. Returns an error if the
+// batch wasn't sent.
+func (h *VoidOnlyTwoParamsHandle) Result() (pgconn.CommandTag, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("VoidOnlyTwoParams result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const voidTwoSQL = `SELECT void_fn(), 'foo' as name;`
 
+const voidTwoStmt = "pggen_VoidTwo_fcca4cd221b0ed57"
+
 // VoidTwo implements Querier.VoidTwo.
 func (q *DBQuerier) VoidTwo(ctx context.Context) (string, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "VoidTwo")
-	row := q.conn.QueryRow(ctx, voidTwoSQL)
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(voidTwoSQL, voidTwoStmt))
 	var item string
 	if err := row.Scan(nil, &item); err != nil {
 		return item, fmt.Errorf("query VoidTwo: %w", err)
@@ -247,7 +684,7 @@ func (q *DBQuerier) VoidTwo(ctx context.Context) (string, error) {
 
 // VoidTwoBatch implements Querier.VoidTwoBatch.
 func (q *DBQuerier) VoidTwoBatch(batch genericBatch) {
-	batch.Queue(voidTwoSQL)
+	batch.Queue(q.chooseSQL(voidTwoSQL, voidTwoStmt))
 }
 
 // VoidTwoScan implements Querier.VoidTwoScan.
@@ -260,8 +697,40 @@ func (q *DBQuerier) VoidTwoScan(results pgx.BatchResults) (string, error) {
 	return item, nil
 }
 
+// VoidTwo queues a VoidTwo query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) VoidTwo() *VoidTwoHandle {
+	b.q.VoidTwoBatch(b.batch)
+	h := &VoidTwoHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// VoidTwoHandle is the result of a VoidTwo query queued in a Batch.
+type VoidTwoHandle struct {
+	b   *Batch
+	res string
+	err error
+}
+
+func (h *VoidTwoHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.VoidTwoScan(results)
+	return h.err
+}
+
+// Result returns the result of the VoidTwo query. Returns an error if the
+// batch wasn't sent.
+func (h *VoidTwoHandle) Result() (string, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("VoidTwo result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const voidThreeSQL = `SELECT void_fn(), 'foo' as foo, 'bar' as bar;`
 
+const voidThreeStmt = "pggen_VoidThree_2386ffa8881ab666"
+
 type VoidThreeRow struct {
 	Foo string `json:"foo"`
 	Bar string `json:"bar"`
@@ -270,7 +739,7 @@ type VoidThreeRow struct {
 // VoidThree implements Querier.VoidThree.
 func (q *DBQuerier) VoidThree(ctx context.Context) (VoidThreeRow, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "VoidThree")
-	row := q.conn.QueryRow(ctx, voidThreeSQL)
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(voidThreeSQL, voidThreeStmt))
 	var item VoidThreeRow
 	if err := row.Scan(nil, &item.Foo, &item.Bar); err != nil {
 		return item, fmt.Errorf("query VoidThree: %w", err)
@@ -280,7 +749,7 @@ func (q *DBQuerier) VoidThree(ctx context.Context) (VoidThreeRow, error) {
 
 // VoidThreeBatch implements Querier.VoidThreeBatch.
 func (q *DBQuerier) VoidThreeBatch(batch genericBatch) {
-	batch.Queue(voidThreeSQL)
+	batch.Queue(q.chooseSQL(voidThreeSQL, voidThreeStmt))
 }
 
 // VoidThreeScan implements Querier.VoidThreeScan.
@@ -293,12 +762,44 @@ func (q *DBQuerier) VoidThreeScan(results pgx.BatchResults) (VoidThreeRow, error
 	return item, nil
 }
 
+// VoidThree queues a VoidThree query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) VoidThree() *VoidThreeHandle {
+	b.q.VoidThreeBatch(b.batch)
+	h := &VoidThreeHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// VoidThreeHandle is the result of a VoidThree query queued in a Batch.
+type VoidThreeHandle struct {
+	b   *Batch
+	res VoidThreeRow
+	err error
+}
+
+func (h *VoidThreeHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.VoidThreeScan(results)
+	return h.err
+}
+
+// Result returns the result of the VoidThree query. Returns an error if the
+// batch wasn't sent.
+func (h *VoidThreeHandle) Result() (VoidThreeRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("VoidThree result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const voidThree2SQL = `SELECT 'foo' as foo, void_fn(), void_fn();`
 
+const voidThree2Stmt = "pggen_VoidThree2_f9f70c87cb55f3f8"
+
 // VoidThree2 implements Querier.VoidThree2.
 func (q *DBQuerier) VoidThree2(ctx context.Context) ([]string, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "VoidThree2")
-	rows, err := q.conn.Query(ctx, voidThree2SQL)
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(voidThree2SQL, voidThree2Stmt))
 	if err != nil {
 		return nil, fmt.Errorf("query VoidThree2: %w", err)
 	}
@@ -319,7 +820,7 @@ func (q *DBQuerier) VoidThree2(ctx context.Context) ([]string, error) {
 
 // VoidThree2Batch implements Querier.VoidThree2Batch.
 func (q *DBQuerier) VoidThree2Batch(batch genericBatch) {
-	batch.Queue(voidThree2SQL)
+	batch.Queue(q.chooseSQL(voidThree2SQL, voidThree2Stmt))
 }
 
 // VoidThree2Scan implements Querier.VoidThree2Scan.
@@ -343,6 +844,36 @@ func (q *DBQuerier) VoidThree2Scan(results pgx.BatchResults) ([]string, error) {
 	return items, err
 }
 
+// VoidThree2 queues a VoidThree2 query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) VoidThree2() *VoidThree2Handle {
+	b.q.VoidThree2Batch(b.batch)
+	h := &VoidThree2Handle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// VoidThree2Handle is the result of a VoidThree2 query queued in a Batch.
+type VoidThree2Handle struct {
+	b   *Batch
+	res []string
+	err error
+}
+
+func (h *VoidThree2Handle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.VoidThree2Scan(results)
+	return h.err
+}
+
+// Result returns the result of the VoidThree2 query. Returns an error if the
+// batch wasn't sent.
+func (h *VoidThree2Handle) Result() ([]string, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("VoidThree2 result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 // textPreferrer wraps a pgtype.ValueTranscoder and sets the preferred encoding
 // format to text instead binary (the default). pggen uses the text format
 // when the OID is unknownOID because the binary format requires the OID.
@@ -73,9 +73,31 @@ const (
 	ResultKindExec ResultKind = ":exec"
 )
 
+// PaginateKind is the kind of pagination to generate for a :many query.
+type PaginateKind string
+
+const (
+	PaginateNone   PaginateKind = ""       // no pagination
+	PaginateKeyset PaginateKind = "keyset" // keyset pagination using the ORDER BY columns
+)
+
+// RouteKind is where a routing querier runs a query.
+type RouteKind string
+
+const (
+	RouteAuto    RouteKind = ""        // replica if the query is read-only, otherwise primary
+	RoutePrimary RouteKind = "primary" // always primary, like for read-after-write queries
+)
+
 // Pragmas are options to control generated code for a single query.
 type Pragmas struct {
-	ProtobufType string // package qualified protocol buffer message type to use for output rows
+	ProtobufType string       // package qualified protocol buffer message type to use for output rows
+	Paginate     PaginateKind // pagination to generate for a :many query
+	Route        RouteKind    // where a routing querier runs the query
+	RowType      string       // name of the Go struct for output rows, shared by queries with the same row type
+	// Fully qualified Go types for json and jsonb params and output columns by
+	// name, like "payload" => "example.com/foo.Payload".
+	JSONTypes map[string]string
 }
 
 // An query is represented by one of the following query nodes.
@@ -0,0 +1,110 @@
+package golang
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/leg100/pggen/internal/codegen"
+)
+
+// genTestModule is the module path of the temp Go module for generated code.
+// Generated code lives in the "example.com/foo" package. Stub packages for
+// custom Go types, like "example.com/ids", live in the same module.
+const genTestModule = "example.com"
+
+// generateCode generates Go code for queryFiles in the foo package of a temp Go
+// module and type checks the module with go vet. Returns the generated code
+// of the first query file. files are extra files to write into the module,
+// keyed by slash-separated path relative to the module root, like stub
+// packages for custom Go types.
+func generateCode(t *testing.T, opts GenerateOptions, queryFiles []codegen.QueryFile, files map[string]string) string {
+	t.Helper()
+	return generateModule(t, opts, queryFiles, files, "vet")
+}
+
+// generateAndTest is like generateCode but also writes testSrc to a test file
+// in the foo package and runs the module tests with go test.
+func generateAndTest(t *testing.T, opts GenerateOptions, queryFiles []codegen.QueryFile, testSrc string) string {
+	t.Helper()
+	return generateModule(t, opts, queryFiles, map[string]string{"foo/gen_test.go": testSrc}, "test")
+}
+
+// generateModule generates Go code in a temp Go module and runs the go
+// command goCmd, like "vet", on every package in the module.
+func generateModule(t *testing.T, opts GenerateOptions, queryFiles []codegen.QueryFile, files map[string]string, goCmd string) string {
+	t.Helper()
+	dir := newGenTestModule(t, files)
+	opts.GoPkg = "foo"
+	opts.OutputDir = filepath.Join(dir, "foo")
+	if err := os.MkdirAll(opts.OutputDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := Generate(opts, queryFiles); err != nil {
+		t.Fatal(err)
+	}
+	runGo(t, dir, goCmd, "./...")
+	return readGenerated(t, opts.OutputDir, queryFiles[0])
+}
+
+// newGenTestModule creates a temp Go module with the same requirements as
+// pggen so the generated code can import pgx and pgtype from the module cache.
+func newGenTestModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go binary not found on PATH")
+	}
+	modBytes, err := ioutil.ReadFile("../../../go.mod")
+	if err != nil {
+		t.Fatalf("read pggen go.mod: %s", err)
+	}
+	sumBytes, err := ioutil.ReadFile("../../../go.sum")
+	if err != nil {
+		t.Fatalf("read pggen go.sum: %s", err)
+	}
+	// Generic code, like Null[T], needs Go 1.18.
+	mod := regexp.MustCompile(`(?m)^module .*$`).ReplaceAllString(string(modBytes), "module "+genTestModule)
+	mod = regexp.MustCompile(`(?m)^go .*$`).ReplaceAllString(mod, "go 1.18")
+	dir := t.TempDir()
+	if files == nil {
+		files = make(map[string]string, 2)
+	}
+	files["go.mod"] = mod
+	files["go.sum"] = string(sumBytes)
+	for path, src := range files {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// runGo runs the go command with args in dir without network access and fails
+// the test with the output if the command fails.
+func runGo(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOSUMDB=off")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go %s: %s\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+// readGenerated reads the generated Go file for the query file from outDir.
+func readGenerated(t *testing.T, outDir string, queryFile codegen.QueryFile) string {
+	t.Helper()
+	got, err := ioutil.ReadFile(filepath.Join(outDir, filepath.Base(queryFile.SourcePath)+".go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(got)
+}
@@ -42,6 +42,13 @@ func (d DeclarerSet) ListAll() []Declarer {
 	return decls
 }
 
+// declarerImports are the imports the leader file needs for a declarer, keyed
+// by the dedupe key of the declarer.
+var declarerImports = map[string][]string{
+	NewNullDeclarer().DedupeKey():          nullImports,
+	NewRangeResolverDeclarer().DedupeKey(): rangeImports,
+}
+
 // FindInputDeclarers finds all necessary Declarers for types that appear in
 // the input parameters. Returns nil if no declarers are needed.
 func FindInputDeclarers(typ gotype.Type) DeclarerSet {
@@ -62,6 +69,10 @@ func FindInputDeclarers(typ gotype.Type) DeclarerSet {
 				NewArrayInitDeclarer(typ),
 			)
 		}
+	case gotype.RangeType:
+		decls.AddAll(NewRangeInitDeclarer(typ))
+	case gotype.MultirangeType:
+		decls.AddAll(NewMultirangeInitDeclarer(typ))
 	}
 	decls.AddAll(NewTypeResolverInitDeclarer()) // always add
 	findInputDeclsHelper(typ, decls)
@@ -122,6 +133,27 @@ func findOutputDeclsHelper(typ gotype.Type, decls DeclarerSet, hadCompositeParen
 			findOutputDeclsHelper(childType, decls, true)
 		}
 
+	case gotype.NullType:
+		decls.AddAll(NewNullDeclarer())
+
+	case gotype.DomainType:
+		decls.AddAll(NewDomainTypeDeclarer(typ))
+
+	case gotype.RangeType:
+		decls.AddAll(
+			NewRangeTypeDeclarer(typ),
+			NewRangeTranscoderDeclarer(typ),
+			NewRangeResolverDeclarer(),
+		)
+		findOutputDeclsHelper(typ.Elem, decls, hadCompositeParent)
+
+	case gotype.MultirangeType:
+		decls.AddAll(
+			NewMultirangeTranscoderDeclarer(typ),
+			NewRangeResolverDeclarer(),
+		)
+		findOutputDeclsHelper(typ.Elem, decls, hadCompositeParent)
+
 	case gotype.ArrayType:
 		decls.AddAll(NewTypeResolverDeclarer())
 		switch typ.Elem.(type) {
@@ -150,18 +182,19 @@ func (c ConstantDeclarer) Declare(string) (string, error) { return c.str, nil }
 
 const typeResolverInitDecl = `// typeResolver looks up the pgtype.ValueTranscoder by Postgres type name.
 type typeResolver struct {
-	connInfo *pgtype.ConnInfo // types by Postgres type name
+	connInfo   *pgtype.ConnInfo // types by Postgres type name
+	preferText bool             // if true, always encode in the text format
 }
 
-func newTypeResolver(types []pgtype.DataType) *typeResolver {
+func newTypeResolver(types []pgtype.DataType, preferText bool) *typeResolver {
 	ci := pgtype.NewConnInfo()
 	for _, typ := range types {
-		if txt, ok := typ.Value.(textPreferrer); ok && typ.OID != unknownOID {
+		if txt, ok := typ.Value.(textPreferrer); ok && typ.OID != unknownOID && !preferText {
 			typ.Value = txt.ValueTranscoder
 		}
 		ci.RegisterDataType(typ)
 	}
-	return &typeResolver{connInfo: ci}
+	return &typeResolver{connInfo: ci, preferText: preferText}
 }
 
 // findValue find the OID, and pgtype.ValueTranscoder for a Postgres type name.
@@ -170,8 +203,11 @@ func (tr *typeResolver) findValue(name string) (uint32, pgtype.ValueTranscoder,
 	if !ok {
 		return 0, nil, false
 	}
-	v := pgtype.NewValue(typ.Value)
-	return typ.OID, v.(pgtype.ValueTranscoder), true
+	v := pgtype.NewValue(typ.Value).(pgtype.ValueTranscoder)
+	if _, isText := v.(textPreferrer); tr.preferText && !isText {
+		v = textPreferrer{v, name}
+	}
+	return typ.OID, v, true
 }
 
 // setValue sets the value of a ValueTranscoder to a value that should always
@@ -214,7 +250,7 @@ func (tr *typeResolver) newCompositeValue(name string, fields ...compositeField)
 	// Okay to ignore error because it's only thrown when the number of field
 	// names does not equal the number of ValueTranscoders.
 	typ, _ := pgtype.NewCompositeTypeValues(name, fs, vals)
-	if !isBinaryOk {
+	if !isBinaryOk || tr.preferText {
 		return textPreferrer{typ, name}
 	}
 	return typ
@@ -233,7 +269,7 @@ func (tr *typeResolver) newArrayValue(name, elemName string, defaultVal func() p
 		elemValFunc = defaultVal
 	}
 	typ := pgtype.NewArrayType(name, elemOID, elemValFunc)
-	if elemOID == unknownOID {
+	if elemOID == unknownOID || tr.preferText {
 		return textPreferrer{typ, name}
 	}
 	return typ
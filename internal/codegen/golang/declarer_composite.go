@@ -34,12 +34,20 @@ func NameCompositeRawFunc(typ gotype.CompositeType) string {
 // composite type.
 type CompositeTypeDeclarer struct {
 	comp gotype.CompositeType
+	tags []StructTag // if nil, use defaultStructTags
 }
 
 func NewCompositeTypeDeclarer(comp gotype.CompositeType) CompositeTypeDeclarer {
 	return CompositeTypeDeclarer{comp: comp}
 }
 
+// WithStructTags returns a copy of the declarer that emits the struct tags
+// on each field. Every field of a composite type is nullable.
+func (c CompositeTypeDeclarer) WithStructTags(tags []StructTag) CompositeTypeDeclarer {
+	c.tags = tags
+	return c
+}
+
 func (c CompositeTypeDeclarer) DedupeKey() string {
 	return "composite::" + c.comp.Name
 }
@@ -65,6 +73,10 @@ func (c CompositeTypeDeclarer) Declare(pkgPath string) (string, error) {
 	}
 	// Struct fields.
 	nameLen, typeLen := getLongestNameTypes(c.comp, pkgPath)
+	structTags := c.tags
+	if structTags == nil {
+		structTags = defaultStructTags
+	}
 	for i, name := range c.comp.FieldNames {
 		// Name
 		sb.WriteRune('\t')
@@ -73,11 +85,12 @@ func (c CompositeTypeDeclarer) Declare(pkgPath string) (string, error) {
 		qualType := c.comp.FieldTypes[i].QualifyRel(pkgPath)
 		sb.WriteString(strings.Repeat(" ", nameLen-len(name)))
 		sb.WriteString(qualType)
-		// JSON struct tag
-		sb.WriteString(strings.Repeat(" ", typeLen-len(qualType)))
-		sb.WriteString("`json:")
-		sb.WriteString(strconv.Quote(c.comp.PgComposite.ColumnNames[i]))
-		sb.WriteString("`")
+		// Struct tags
+		tags := emitStructTags(structTags, c.comp.PgComposite.ColumnNames[i], name, true)
+		if tags != "" {
+			sb.WriteString(strings.Repeat(" ", typeLen-len(qualType)))
+			sb.WriteString(tags)
+		}
 		sb.WriteRune('\n')
 	}
 	sb.WriteString("}")
@@ -166,6 +179,14 @@ func (c CompositeTranscoderDeclarer) Declare(pkgPath string) (string, error) {
 			sb.WriteString("tr.")
 			sb.WriteString(NameArrayTranscoderFunc(fieldType))
 			sb.WriteString("()")
+		case gotype.RangeType:
+			sb.WriteString("tr.")
+			sb.WriteString(NameRangeTranscoderFunc(fieldType))
+			sb.WriteString("()")
+		case gotype.MultirangeType:
+			sb.WriteString("tr.")
+			sb.WriteString(NameMultirangeTranscoderFunc(fieldType))
+			sb.WriteString("()")
 		case gotype.VoidType:
 			// skip
 		default:
@@ -173,6 +194,9 @@ func (c CompositeTranscoderDeclarer) Declare(pkgPath string) (string, error) {
 			// TODO: support builtin types and builtin wrappers that use a different
 			// initialization syntax.
 			pgType := c.typ.PgComposite.ColumnTypes[i]
+			if domain, ok := pgType.(pg.DomainType); ok {
+				pgType = domain.BaseType // pgx only knows the base type
+			}
 			if pgType == nil || pgType == (pg.VoidType{}) {
 				sb.WriteString("nil,")
 			} else {
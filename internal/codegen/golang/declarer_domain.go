@@ -0,0 +1,52 @@
+package golang
+
+import (
+	"github.com/leg100/pggen/internal/codegen/golang/gotype"
+	"strconv"
+	"strings"
+)
+
+// DomainTypeDeclarer declares a new named Go type for a Postgres domain, like
+// "type Email string".
+type DomainTypeDeclarer struct {
+	domain gotype.DomainType
+}
+
+func NewDomainTypeDeclarer(domain gotype.DomainType) DomainTypeDeclarer {
+	return DomainTypeDeclarer{domain: domain}
+}
+
+func (d DomainTypeDeclarer) name() string {
+	return strings.TrimPrefix(d.domain.Name, "*")
+}
+
+func (d DomainTypeDeclarer) DedupeKey() string {
+	return "domain_type::" + d.name()
+}
+
+func (d DomainTypeDeclarer) Declare(pkgPath string) (string, error) {
+	sb := &strings.Builder{}
+	// Doc string with the domain constraints.
+	sb.WriteString("// ")
+	sb.WriteString(d.name())
+	sb.WriteString(" represents the Postgres domain ")
+	sb.WriteString(strconv.Quote(d.domain.PgDomain.Name))
+	sb.WriteString(".\n")
+	if d.domain.PgDomain.IsNotNull || len(d.domain.PgDomain.CheckExprs) > 0 {
+		sb.WriteString("//\n// Constraints:\n")
+		if d.domain.PgDomain.IsNotNull {
+			sb.WriteString("//   - NOT NULL\n")
+		}
+		for _, expr := range d.domain.PgDomain.CheckExprs {
+			sb.WriteString("//   - ")
+			sb.WriteString(expr)
+			sb.WriteString("\n")
+		}
+	}
+	// Type declaration.
+	sb.WriteString("type ")
+	sb.WriteString(d.name())
+	sb.WriteString(" ")
+	sb.WriteString(d.domain.Elem.QualifyRel(pkgPath))
+	return sb.String(), nil
+}
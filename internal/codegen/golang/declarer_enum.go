@@ -2,6 +2,7 @@ package golang
 
 import (
 	"github.com/leg100/pggen/internal/codegen/golang/gotype"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -64,10 +65,133 @@ func (e EnumTypeDeclarer) Declare(string) (string, error) {
 	sb.WriteString(e.enum.Name)
 	sb.WriteString(") String() string { return string(")
 	sb.WriteByte(dispatcher)
-	sb.WriteString(") }")
+	sb.WriteString(") }\n\n")
+	e.declareHelpers(sb, string(dispatcher))
 	return sb.String(), nil
 }
 
+// declareHelpers writes the functions and methods to validate, parse, list,
+// compare, and marshal enum values. Comparisons use the Postgres sort order
+// of the enum labels, not the Go string order.
+func (e EnumTypeDeclarer) declareHelpers(sb *strings.Builder, d string) {
+	name := e.enum.Name
+	pgName := strconv.Quote(e.enum.PgEnum.Name)
+	sorted := e.sortedLabels()
+
+	// All values
+	sb.WriteString("// All" + name + "Values returns all labels of the Postgres enum " + pgName + "\n")
+	sb.WriteString("// in the Postgres sort order.\n")
+	sb.WriteString("func All" + name + "Values() []" + name + " {\n")
+	sb.WriteString("\treturn []" + name + "{")
+	for _, label := range sorted {
+		sb.WriteString("\n\t\t" + label + ",")
+	}
+	if len(sorted) > 0 {
+		sb.WriteString("\n\t")
+	}
+	sb.WriteString("}\n}\n\n")
+
+	// Parse
+	sb.WriteString("// Parse" + name + " returns the " + name + " for a label of the Postgres enum\n")
+	sb.WriteString("// " + pgName + ". Returns an error if s isn't a label.\n")
+	sb.WriteString("func Parse" + name + "(s string) (" + name + ", error) {\n")
+	sb.WriteString("\tif val := " + name + "(s); val.Valid() {\n")
+	sb.WriteString("\t\treturn val, nil\n")
+	sb.WriteString("\t}\n")
+	sb.WriteString("\treturn \"\", fmt.Errorf(\"unknown " + name + " label %q\", s)\n")
+	sb.WriteString("}\n\n")
+
+	// Valid
+	sb.WriteString("// Valid returns true if " + d + " is a label of the Postgres enum " + pgName + ".\n")
+	sb.WriteString("func (" + d + " " + name + ") Valid() bool { return " + d + ".ordinal() >= 0 }\n\n")
+
+	// Ordinal
+	sb.WriteString("// ordinal returns the position of " + d + " in the Postgres sort order of the\n")
+	sb.WriteString("// enum " + pgName + ", or -1 if " + d + " isn't a label.\n")
+	sb.WriteString("func (" + d + " " + name + ") ordinal() int {\n")
+	sb.WriteString("\tswitch " + d + " {\n")
+	for i, label := range sorted {
+		sb.WriteString("\tcase " + label + ":\n")
+		sb.WriteString("\t\treturn " + strconv.Itoa(i) + "\n")
+	}
+	sb.WriteString("\tdefault:\n")
+	sb.WriteString("\t\treturn -1\n")
+	sb.WriteString("\t}\n")
+	sb.WriteString("}\n\n")
+
+	// Compare
+	sb.WriteString("// Compare returns -1 if " + d + " sorts before other, 0 if they're equal, and +1\n")
+	sb.WriteString("// if " + d + " sorts after other, using the Postgres sort order of the enum\n")
+	sb.WriteString("// " + pgName + ". Invalid values sort before all labels.\n")
+	sb.WriteString("func (" + d + " " + name + ") Compare(other " + name + ") int {\n")
+	sb.WriteString("\tord, otherOrd := " + d + ".ordinal(), other.ordinal()\n")
+	sb.WriteString("\tswitch {\n")
+	sb.WriteString("\tcase ord < otherOrd:\n")
+	sb.WriteString("\t\treturn -1\n")
+	sb.WriteString("\tcase ord > otherOrd:\n")
+	sb.WriteString("\t\treturn 1\n")
+	sb.WriteString("\tdefault:\n")
+	sb.WriteString("\t\treturn 0\n")
+	sb.WriteString("\t}\n")
+	sb.WriteString("}\n\n")
+
+	// Less
+	sb.WriteString("// Less returns true if " + d + " sorts before other using the Postgres sort\n")
+	sb.WriteString("// order of the enum " + pgName + ".\n")
+	sb.WriteString("func (" + d + " " + name + ") Less(other " + name + ") bool { return " + d + ".Compare(other) < 0 }\n\n")
+
+	// MarshalText
+	// Postgres enum labels can't be empty, so the empty string is the zero
+	// value from a NULL column, not an unknown label.
+	sb.WriteString("// MarshalText implements encoding.TextMarshaler. The zero value, like from a\n")
+	sb.WriteString("// NULL column, marshals as empty text. Returns an error for other values that\n")
+	sb.WriteString("// aren't a label of the Postgres enum " + pgName + ".\n")
+	sb.WriteString("func (" + d + " " + name + ") MarshalText() ([]byte, error) {\n")
+	sb.WriteString("\tif " + d + " != \"\" && !" + d + ".Valid() {\n")
+	sb.WriteString("\t\treturn nil, fmt.Errorf(\"marshal " + name + ": unknown label %q\", string(" + d + "))\n")
+	sb.WriteString("\t}\n")
+	sb.WriteString("\treturn []byte(" + d + "), nil\n")
+	sb.WriteString("}\n\n")
+
+	// UnmarshalText
+	sb.WriteString("// UnmarshalText implements encoding.TextUnmarshaler. Empty text unmarshals to\n")
+	sb.WriteString("// the zero value. Returns an error for other text that isn't a label of the\n")
+	sb.WriteString("// Postgres enum " + pgName + ".\n")
+	sb.WriteString("func (" + d + " *" + name + ") UnmarshalText(text []byte) error {\n")
+	sb.WriteString("\tif len(text) == 0 {\n")
+	sb.WriteString("\t\t*" + d + " = \"\"\n")
+	sb.WriteString("\t\treturn nil\n")
+	sb.WriteString("\t}\n")
+	sb.WriteString("\tval, err := Parse" + name + "(string(text))\n")
+	sb.WriteString("\tif err != nil {\n")
+	sb.WriteString("\t\treturn fmt.Errorf(\"unmarshal " + name + ": %w\", err)\n")
+	sb.WriteString("\t}\n")
+	sb.WriteString("\t*" + d + " = val\n")
+	sb.WriteString("\treturn nil\n")
+	sb.WriteString("}")
+}
+
+// sortedLabels returns the Go const labels of the enum in the Postgres sort
+// order from pg.EnumType.Orders. Labels added with ALTER TYPE ... ADD VALUE
+// BEFORE might have an order that differs from the label order.
+func (e EnumTypeDeclarer) sortedLabels() []string {
+	labels := make([]string, len(e.enum.Labels))
+	copy(labels, e.enum.Labels)
+	orders := e.enum.PgEnum.Orders
+	if len(orders) != len(labels) {
+		return labels
+	}
+	idxs := make([]int, len(labels))
+	for i := range idxs {
+		idxs[i] = i
+	}
+	sort.SliceStable(idxs, func(i, j int) bool { return orders[idxs[i]] < orders[idxs[j]] })
+	for i, idx := range idxs {
+		labels[i] = e.enum.Labels[idx]
+	}
+	return labels
+}
+
 // EnumTranscoderDeclarer declares a new Go function that creates a pgx decoder
 // for the Postgres type represented by the gotype.EnumType.
 type EnumTranscoderDeclarer struct {
@@ -0,0 +1,106 @@
+package golang
+
+// nullImports are the imports the Null declarer needs in the leader file.
+var nullImports = []string{"database/sql/driver", "encoding/json", "reflect"}
+
+const nullDecl = `// Null is a nullable value of type T, like a nullable Postgres column. Valid
+// is false if the value is null.
+//
+// Null implements sql.Scanner and driver.Valuer to read and write query
+// params and rows. Null also implements the pgtype.Value methods so composite
+// types and arrays can decode into Null.
+type Null[T any] struct {
+	V     T
+	Valid bool
+}
+
+// NewNull returns a valid Null for v.
+func NewNull[T any](v T) Null[T] {
+	return Null[T]{V: v, Valid: true}
+}
+
+// Scan implements sql.Scanner.
+func (n *Null[T]) Scan(src interface{}) error {
+	if src == nil {
+		*n = Null[T]{}
+		return nil
+	}
+	if v, ok := src.(T); ok {
+		*n = Null[T]{V: v, Valid: true}
+		return nil
+	}
+	// Convert like database/sql, for example, from an int64 driver value to
+	// an int32.
+	var v T
+	dst := reflect.ValueOf(&v).Elem()
+	val := reflect.ValueOf(src)
+	if !val.Type().ConvertibleTo(dst.Type()) {
+		return fmt.Errorf("scan %T into Null[%T]", src, v)
+	}
+	dst.Set(val.Convert(dst.Type()))
+	*n = Null[T]{V: v, Valid: true}
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (n Null[T]) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.V, nil
+}
+
+// Get implements pgtype.Value.
+func (n Null[T]) Get() interface{} {
+	if !n.Valid {
+		return nil
+	}
+	return n.V
+}
+
+// Set implements pgtype.Value.
+func (n *Null[T]) Set(src interface{}) error {
+	return n.Scan(src)
+}
+
+// AssignTo implements pgtype.Value.
+func (n Null[T]) AssignTo(dst interface{}) error {
+	switch dst := dst.(type) {
+	case *Null[T]:
+		*dst = n
+		return nil
+	case *T:
+		if !n.Valid {
+			return fmt.Errorf("cannot assign null Null[%T] to %T", n.V, dst)
+		}
+		*dst = n.V
+		return nil
+	}
+	return fmt.Errorf("cannot assign Null[%T] to %T", n.V, dst)
+}
+
+// MarshalJSON implements json.Marshaler. A null value marshals to null.
+func (n Null[T]) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.V)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *Null[T]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*n = Null[T]{}
+		return nil
+	}
+	if err := json.Unmarshal(data, &n.V); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}`
+
+// NewNullDeclarer declares the generic Null type used by NullStyleGeneric.
+func NewNullDeclarer() ConstantDeclarer {
+	return NewConstantDeclarer("null::Null", nullDecl)
+}
@@ -0,0 +1,825 @@
+package golang
+
+import (
+	"fmt"
+	"github.com/leg100/pggen/internal/codegen/golang/gotype"
+	"github.com/leg100/pggen/internal/pg"
+	"strconv"
+	"strings"
+)
+
+// rangeImports are the imports the range resolver declarer needs in the
+// leader file.
+var rangeImports = []string{"reflect"}
+
+// NameRangeTranscoderFunc returns the function name that creates a
+// pgtype.ValueTranscoder for the range type that's used to decode rows
+// returned by Postgres.
+func NameRangeTranscoderFunc(typ gotype.RangeType) string {
+	return "new" + typ.Name
+}
+
+// NameRangeInitFunc returns the name of the function that creates an
+// initialized pgtype.ValueTranscoder for the range type used as a query
+// parameter.
+func NameRangeInitFunc(typ gotype.RangeType) string {
+	return "new" + typ.Name + "Init"
+}
+
+// NameMultirangeTranscoderFunc returns the function name that creates a
+// pgtype.ValueTranscoder for the multirange type that's used to decode rows
+// returned by Postgres.
+func NameMultirangeTranscoderFunc(typ gotype.MultirangeType) string {
+	return "new" + typ.Elem.BaseName() + "Multirange"
+}
+
+// NameMultirangeInitFunc returns the name of the function that creates an
+// initialized pgtype.ValueTranscoder for the multirange type used as a query
+// parameter.
+func NameMultirangeInitFunc(typ gotype.MultirangeType) string {
+	return "new" + typ.Elem.BaseName() + "MultirangeInit"
+}
+
+// findRangeElemPgx returns the pgtype type that transcodes the bounds of the
+// range. The subtype of a range over a domain is the domain base type.
+func findRangeElemPgx(typ pg.RangeType) (gotype.Type, bool) {
+	elem := typ.ElemType
+	if domain, ok := elem.(pg.DomainType); ok {
+		elem = domain.BaseType
+	}
+	return gotype.FindKnownTypePgx(elem.OID())
+}
+
+// RangeTypeDeclarer declares a new Go struct to represent a user-defined
+// Postgres range type.
+type RangeTypeDeclarer struct {
+	typ gotype.RangeType
+}
+
+func NewRangeTypeDeclarer(typ gotype.RangeType) RangeTypeDeclarer {
+	return RangeTypeDeclarer{typ: typ}
+}
+
+func (r RangeTypeDeclarer) DedupeKey() string {
+	return "range::" + r.typ.Name
+}
+
+func (r RangeTypeDeclarer) Declare(pkgPath string) (string, error) {
+	sb := &strings.Builder{}
+	// Doc string
+	sb.WriteString("// ")
+	sb.WriteString(r.typ.Name)
+	sb.WriteString(" represents the Postgres range type ")
+	sb.WriteString(strconv.Quote(r.typ.PgRange.Name))
+	sb.WriteString(". Lower and\n")
+	sb.WriteString("// Upper are only valid if the bound type is pgtype.Inclusive or\n")
+	sb.WriteString("// pgtype.Exclusive. An empty range has pgtype.Empty bound types.\n")
+	// Struct declaration.
+	elemType := r.typ.Elem.QualifyRel(pkgPath)
+	sb.WriteString("type ")
+	sb.WriteString(r.typ.Name)
+	sb.WriteString(" struct {\n")
+	sb.WriteString("\tLower     " + elemType + "\n")
+	sb.WriteString("\tUpper     " + elemType + "\n")
+	sb.WriteString("\tLowerType pgtype.BoundType\n")
+	sb.WriteString("\tUpperType pgtype.BoundType\n")
+	sb.WriteString("}")
+	return sb.String(), nil
+}
+
+// RangeTranscoderDeclarer declares a new Go function that creates a pgx
+// decoder for the Postgres type represented by the gotype.RangeType.
+type RangeTranscoderDeclarer struct {
+	typ gotype.RangeType
+}
+
+func NewRangeTranscoderDeclarer(typ gotype.RangeType) RangeTranscoderDeclarer {
+	return RangeTranscoderDeclarer{typ}
+}
+
+func (r RangeTranscoderDeclarer) DedupeKey() string {
+	return "type_resolver::" + r.typ.Name + "_01_transcoder"
+}
+
+func (r RangeTranscoderDeclarer) Declare(pkgPath string) (string, error) {
+	funcName := NameRangeTranscoderFunc(r.typ)
+	elemType, ok := findRangeElemPgx(r.typ.PgRange)
+	if !ok {
+		return "", fmt.Errorf("no pgtype type for subtype %s of range type %s", r.typ.PgRange.ElemType, r.typ.PgRange.Name)
+	}
+	sb := &strings.Builder{}
+	sb.Grow(256)
+
+	// Doc comment
+	sb.WriteString("// ")
+	sb.WriteString(funcName)
+	sb.WriteString(" creates a new pgtype.ValueTranscoder for the Postgres\n")
+	sb.WriteString("// range type '")
+	sb.WriteString(r.typ.PgRange.Name)
+	sb.WriteString("'.\n")
+
+	// Function signature
+	sb.WriteString("func (tr *typeResolver) ")
+	sb.WriteString(funcName)
+	sb.WriteString("() pgtype.ValueTranscoder {\n\t")
+
+	// newRangeValue call
+	sb.WriteString("return tr.newRangeValue(")
+	sb.WriteString(strconv.Quote(r.typ.PgRange.Name))
+	sb.WriteString(", ")
+	sb.WriteString(strconv.Quote(r.typ.PgRange.ElemType.String()))
+	sb.WriteString(", &")
+	sb.WriteString(elemType.QualifyRel(pkgPath))
+	sb.WriteString("{})\n")
+	sb.WriteString("}")
+	return sb.String(), nil
+}
+
+// RangeInitDeclarer declares a new Go function that creates an initialized
+// pgtype.ValueTranscoder for the Postgres type represented by the
+// gotype.RangeType to encode query parameters.
+type RangeInitDeclarer struct {
+	typ gotype.RangeType
+}
+
+func NewRangeInitDeclarer(typ gotype.RangeType) RangeInitDeclarer {
+	return RangeInitDeclarer{typ}
+}
+
+func (r RangeInitDeclarer) DedupeKey() string {
+	return "type_resolver::" + r.typ.Name + "_02_init"
+}
+
+func (r RangeInitDeclarer) Declare(string) (string, error) {
+	funcName := NameRangeInitFunc(r.typ)
+	sb := &strings.Builder{}
+	sb.Grow(256)
+
+	// Doc comment
+	sb.WriteString("// ")
+	sb.WriteString(funcName)
+	sb.WriteString(" creates an initialized pgtype.ValueTranscoder for the\n")
+	sb.WriteString("// Postgres range type '")
+	sb.WriteString(r.typ.PgRange.Name)
+	sb.WriteString("' to encode query parameters.\n")
+
+	// Function signature
+	sb.WriteString("func (tr *typeResolver) ")
+	sb.WriteString(funcName)
+	sb.WriteString("(v *")
+	sb.WriteString(r.typ.Name)
+	sb.WriteString(") pgtype.ValueTranscoder {\n\t")
+
+	// Function body
+	sb.WriteString("return tr.setValue(tr.")
+	sb.WriteString(NameRangeTranscoderFunc(r.typ))
+	sb.WriteString("(), v)\n")
+	sb.WriteString("}")
+	return sb.String(), nil
+}
+
+// MultirangeTranscoderDeclarer declares a new Go function that creates a pgx
+// decoder for the Postgres type represented by the gotype.MultirangeType.
+type MultirangeTranscoderDeclarer struct {
+	typ gotype.MultirangeType
+}
+
+func NewMultirangeTranscoderDeclarer(typ gotype.MultirangeType) MultirangeTranscoderDeclarer {
+	return MultirangeTranscoderDeclarer{typ}
+}
+
+func (m MultirangeTranscoderDeclarer) DedupeKey() string {
+	return "type_resolver::" + NameMultirangeTranscoderFunc(m.typ) + "_01_transcoder"
+}
+
+func (m MultirangeTranscoderDeclarer) Declare(pkgPath string) (string, error) {
+	funcName := NameMultirangeTranscoderFunc(m.typ)
+	sb := &strings.Builder{}
+	sb.Grow(256)
+
+	// Doc comment
+	sb.WriteString("// ")
+	sb.WriteString(funcName)
+	sb.WriteString(" creates a new pgtype.ValueTranscoder for the Postgres\n")
+	sb.WriteString("// multirange type '")
+	sb.WriteString(m.typ.PgMultirange.Name)
+	sb.WriteString("'.\n")
+
+	// Function signature
+	sb.WriteString("func (tr *typeResolver) ")
+	sb.WriteString(funcName)
+	sb.WriteString("() pgtype.ValueTranscoder {\n\t")
+
+	// newMultirangeValue call
+	sb.WriteString("return tr.newMultirangeValue(")
+	sb.WriteString(strconv.Quote(m.typ.PgMultirange.Name))
+	sb.WriteString(", ")
+
+	// Range element transcoder
+	switch elem := m.typ.Elem.(type) {
+	case gotype.RangeType:
+		sb.WriteString("tr.")
+		sb.WriteString(NameRangeTranscoderFunc(elem))
+	default:
+		rangeType, ok := gotype.FindKnownTypePgx(m.typ.PgMultirange.RangeType.OID())
+		if !ok {
+			return "", fmt.Errorf("no pgtype type for range type %s of multirange type %s", m.typ.PgMultirange.RangeType, m.typ.PgMultirange.Name)
+		}
+		sb.WriteString("func() pgtype.ValueTranscoder { return &")
+		sb.WriteString(rangeType.QualifyRel(pkgPath))
+		sb.WriteString("{} }")
+	}
+	sb.WriteString(")\n")
+	sb.WriteString("}")
+	return sb.String(), nil
+}
+
+// MultirangeInitDeclarer declares a new Go function that creates an
+// initialized pgtype.ValueTranscoder for the Postgres type represented by the
+// gotype.MultirangeType to encode query parameters.
+type MultirangeInitDeclarer struct {
+	typ gotype.MultirangeType
+}
+
+func NewMultirangeInitDeclarer(typ gotype.MultirangeType) MultirangeInitDeclarer {
+	return MultirangeInitDeclarer{typ}
+}
+
+func (m MultirangeInitDeclarer) DedupeKey() string {
+	return "type_resolver::" + NameMultirangeTranscoderFunc(m.typ) + "_02_init"
+}
+
+func (m MultirangeInitDeclarer) Declare(pkgPath string) (string, error) {
+	funcName := NameMultirangeInitFunc(m.typ)
+	sb := &strings.Builder{}
+	sb.Grow(256)
+
+	// Doc comment
+	sb.WriteString("// ")
+	sb.WriteString(funcName)
+	sb.WriteString(" creates an initialized pgtype.ValueTranscoder for the\n")
+	sb.WriteString("// Postgres multirange type '")
+	sb.WriteString(m.typ.PgMultirange.Name)
+	sb.WriteString("' to encode query parameters.\n")
+
+	// Function signature
+	sb.WriteString("func (tr *typeResolver) ")
+	sb.WriteString(funcName)
+	sb.WriteString("(v ")
+	sb.WriteString(m.typ.QualifyRel(pkgPath))
+	sb.WriteString(") pgtype.ValueTranscoder {\n\t")
+
+	// Function body
+	sb.WriteString("return tr.setValue(tr.")
+	sb.WriteString(NameMultirangeTranscoderFunc(m.typ))
+	sb.WriteString("(), v)\n")
+	sb.WriteString("}")
+	return sb.String(), nil
+}
+
+const rangeResolverDecl = `// rangeValue is a pgtype.ValueTranscoder for a Postgres range type that
+// pgtype doesn't support, like a user-defined range. rangeValue sets from and
+// assigns to a struct with Lower, Upper, LowerType, and UpperType fields.
+type rangeValue struct {
+	name      string                 // Postgres type name
+	elem      pgtype.ValueTranscoder // transcoder of the range subtype
+	lower     pgtype.ValueTranscoder // nil if lowerType is unbounded or empty
+	upper     pgtype.ValueTranscoder // nil if upperType is unbounded or empty
+	lowerType pgtype.BoundType
+	upperType pgtype.BoundType
+	status    pgtype.Status
+}
+
+func (tr *typeResolver) newRangeValue(name, elemName string, defaultVal pgtype.ValueTranscoder) pgtype.ValueTranscoder {
+	if _, val, ok := tr.findValue(name); ok {
+		return val
+	}
+	elem := defaultVal
+	if _, val, ok := tr.findValue(elemName); ok {
+		elem = val
+	}
+	typ := &rangeValue{name: name, elem: elem}
+	if tr.preferText {
+		return textPreferrer{typ, name}
+	}
+	return typ
+}
+
+func (r *rangeValue) NewTypeValue() pgtype.Value {
+	return &rangeValue{name: r.name, elem: r.elem}
+}
+
+func (r *rangeValue) TypeName() string { return r.name }
+
+func (r *rangeValue) newElem() pgtype.ValueTranscoder {
+	return pgtype.NewValue(r.elem).(pgtype.ValueTranscoder)
+}
+
+func (r *rangeValue) Set(src interface{}) error {
+	*r = rangeValue{name: r.name, elem: r.elem, status: pgtype.Null}
+	v := reflect.ValueOf(src)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	lower, upper := v.FieldByName("Lower"), v.FieldByName("Upper")
+	lowerType, upperType := v.FieldByName("LowerType"), v.FieldByName("UpperType")
+	if v.Kind() != reflect.Struct || !lower.IsValid() || !upper.IsValid() || !lowerType.IsValid() || !upperType.IsValid() {
+		return fmt.Errorf("cannot set range %s from %T", r.name, src)
+	}
+	r.lowerType = pgtype.BoundType(lowerType.Uint())
+	r.upperType = pgtype.BoundType(upperType.Uint())
+	if r.lowerType != pgtype.Unbounded && r.lowerType != pgtype.Empty {
+		r.lower = r.newElem()
+		if err := setRangeElem(r.lower, lower.Interface()); err != nil {
+			return fmt.Errorf("set range %s lower bound: %w", r.name, err)
+		}
+	}
+	if r.upperType != pgtype.Unbounded && r.upperType != pgtype.Empty {
+		r.upper = r.newElem()
+		if err := setRangeElem(r.upper, upper.Interface()); err != nil {
+			return fmt.Errorf("set range %s upper bound: %w", r.name, err)
+		}
+	}
+	r.status = pgtype.Present
+	return nil
+}
+
+func (r *rangeValue) Get() interface{} {
+	switch r.status {
+	case pgtype.Present:
+		return r
+	case pgtype.Null:
+		return nil
+	default:
+		return r.status
+	}
+}
+
+func (r *rangeValue) AssignTo(dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("cannot assign range %s to non-pointer %T", r.name, dst)
+	}
+	v = v.Elem()
+	if r.status != pgtype.Present {
+		if v.Kind() == reflect.Ptr {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		return fmt.Errorf("cannot assign null range %s to %T", r.name, dst)
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot assign range %s to %T", r.name, dst)
+	}
+	lower, upper := v.FieldByName("Lower"), v.FieldByName("Upper")
+	lowerType, upperType := v.FieldByName("LowerType"), v.FieldByName("UpperType")
+	if !lower.IsValid() || !upper.IsValid() || !lowerType.IsValid() || !upperType.IsValid() {
+		return fmt.Errorf("cannot assign range %s to %T", r.name, dst)
+	}
+	lowerType.SetUint(uint64(r.lowerType))
+	upperType.SetUint(uint64(r.upperType))
+	lower.Set(reflect.Zero(lower.Type()))
+	upper.Set(reflect.Zero(upper.Type()))
+	if r.lower != nil {
+		if err := assignRangeElem(r.lower, lower); err != nil {
+			return fmt.Errorf("assign range %s lower bound: %w", r.name, err)
+		}
+	}
+	if r.upper != nil {
+		if err := assignRangeElem(r.upper, upper); err != nil {
+			return fmt.Errorf("assign range %s upper bound: %w", r.name, err)
+		}
+	}
+	return nil
+}
+
+func (r *rangeValue) DecodeText(ci *pgtype.ConnInfo, src []byte) error {
+	*r = rangeValue{name: r.name, elem: r.elem, status: pgtype.Null}
+	if src == nil {
+		return nil
+	}
+	utr, err := pgtype.ParseUntypedTextRange(string(src))
+	if err != nil {
+		return fmt.Errorf("decode range %s: %w", r.name, err)
+	}
+	r.lowerType, r.upperType = utr.LowerType, utr.UpperType
+	if r.lowerType != pgtype.Unbounded && r.lowerType != pgtype.Empty {
+		r.lower = r.newElem()
+		if err := r.lower.DecodeText(ci, []byte(utr.Lower)); err != nil {
+			return fmt.Errorf("decode range %s lower bound: %w", r.name, err)
+		}
+	}
+	if r.upperType != pgtype.Unbounded && r.upperType != pgtype.Empty {
+		r.upper = r.newElem()
+		if err := r.upper.DecodeText(ci, []byte(utr.Upper)); err != nil {
+			return fmt.Errorf("decode range %s upper bound: %w", r.name, err)
+		}
+	}
+	r.status = pgtype.Present
+	return nil
+}
+
+func (r *rangeValue) DecodeBinary(ci *pgtype.ConnInfo, src []byte) error {
+	*r = rangeValue{name: r.name, elem: r.elem, status: pgtype.Null}
+	if src == nil {
+		return nil
+	}
+	ubr, err := pgtype.ParseUntypedBinaryRange(src)
+	if err != nil {
+		return fmt.Errorf("decode range %s: %w", r.name, err)
+	}
+	r.lowerType, r.upperType = ubr.LowerType, ubr.UpperType
+	if r.lowerType != pgtype.Unbounded && r.lowerType != pgtype.Empty {
+		r.lower = r.newElem()
+		if err := r.lower.DecodeBinary(ci, ubr.Lower); err != nil {
+			return fmt.Errorf("decode range %s lower bound: %w", r.name, err)
+		}
+	}
+	if r.upperType != pgtype.Unbounded && r.upperType != pgtype.Empty {
+		r.upper = r.newElem()
+		if err := r.upper.DecodeBinary(ci, ubr.Upper); err != nil {
+			return fmt.Errorf("decode range %s upper bound: %w", r.name, err)
+		}
+	}
+	r.status = pgtype.Present
+	return nil
+}
+
+func (r *rangeValue) EncodeText(ci *pgtype.ConnInfo, buf []byte) ([]byte, error) {
+	switch r.status {
+	case pgtype.Null:
+		return nil, nil
+	case pgtype.Undefined:
+		return nil, fmt.Errorf("cannot encode undefined range %s", r.name)
+	}
+	switch r.lowerType {
+	case pgtype.Exclusive, pgtype.Unbounded:
+		buf = append(buf, '(')
+	case pgtype.Inclusive:
+		buf = append(buf, '[')
+	case pgtype.Empty:
+		return append(buf, "empty"...), nil
+	default:
+		return nil, fmt.Errorf("unknown lower bound type %v for range %s", r.lowerType, r.name)
+	}
+	var err error
+	if r.lowerType != pgtype.Unbounded {
+		if buf, err = appendRangeTextBound(ci, buf, r.lower); err != nil {
+			return nil, fmt.Errorf("encode range %s lower bound: %w", r.name, err)
+		}
+	}
+	buf = append(buf, ',')
+	if r.upperType != pgtype.Unbounded {
+		if buf, err = appendRangeTextBound(ci, buf, r.upper); err != nil {
+			return nil, fmt.Errorf("encode range %s upper bound: %w", r.name, err)
+		}
+	}
+	switch r.upperType {
+	case pgtype.Exclusive, pgtype.Unbounded:
+		buf = append(buf, ')')
+	case pgtype.Inclusive:
+		buf = append(buf, ']')
+	default:
+		return nil, fmt.Errorf("unknown upper bound type %v for range %s", r.upperType, r.name)
+	}
+	return buf, nil
+}
+
+func (r *rangeValue) EncodeBinary(ci *pgtype.ConnInfo, buf []byte) ([]byte, error) {
+	switch r.status {
+	case pgtype.Null:
+		return nil, nil
+	case pgtype.Undefined:
+		return nil, fmt.Errorf("cannot encode undefined range %s", r.name)
+	}
+	// Flags from the Postgres source, src/include/utils/rangetypes.h.
+	var flags byte
+	switch r.lowerType {
+	case pgtype.Inclusive:
+		flags |= 0x02
+	case pgtype.Unbounded:
+		flags |= 0x08
+	case pgtype.Exclusive:
+	case pgtype.Empty:
+		return append(buf, 0x01), nil
+	default:
+		return nil, fmt.Errorf("unknown lower bound type %v for range %s", r.lowerType, r.name)
+	}
+	switch r.upperType {
+	case pgtype.Inclusive:
+		flags |= 0x04
+	case pgtype.Unbounded:
+		flags |= 0x10
+	case pgtype.Exclusive:
+	default:
+		return nil, fmt.Errorf("unknown upper bound type %v for range %s", r.upperType, r.name)
+	}
+	buf = append(buf, flags)
+	var err error
+	if r.lowerType != pgtype.Unbounded {
+		if buf, err = appendBinaryElem(ci, buf, r.lower); err != nil {
+			return nil, fmt.Errorf("encode range %s lower bound: %w", r.name, err)
+		}
+	}
+	if r.upperType != pgtype.Unbounded {
+		if buf, err = appendBinaryElem(ci, buf, r.upper); err != nil {
+			return nil, fmt.Errorf("encode range %s upper bound: %w", r.name, err)
+		}
+	}
+	return buf, nil
+}
+
+// multirangeValue is a pgtype.ValueTranscoder for a Postgres multirange type.
+// multirangeValue sets from and assigns to a slice of ranges.
+type multirangeValue struct {
+	name     string                        // Postgres type name
+	newRange func() pgtype.ValueTranscoder // creates a transcoder for a range
+	ranges   []pgtype.ValueTranscoder
+	status   pgtype.Status
+}
+
+func (tr *typeResolver) newMultirangeValue(name string, newRange func() pgtype.ValueTranscoder) pgtype.ValueTranscoder {
+	if _, val, ok := tr.findValue(name); ok {
+		return val
+	}
+	typ := &multirangeValue{name: name, newRange: newRange}
+	if tr.preferText {
+		return textPreferrer{typ, name}
+	}
+	return typ
+}
+
+func (m *multirangeValue) NewTypeValue() pgtype.Value {
+	return &multirangeValue{name: m.name, newRange: m.newRange}
+}
+
+func (m *multirangeValue) TypeName() string { return m.name }
+
+func (m *multirangeValue) Set(src interface{}) error {
+	*m = multirangeValue{name: m.name, newRange: m.newRange, status: pgtype.Null}
+	v := reflect.ValueOf(src)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || (v.Kind() == reflect.Slice && v.IsNil()) {
+		return nil
+	}
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("cannot set multirange %s from %T", m.name, src)
+	}
+	m.ranges = make([]pgtype.ValueTranscoder, v.Len())
+	for i := range m.ranges {
+		m.ranges[i] = m.newRange()
+		if err := setRangeElem(m.ranges[i], v.Index(i).Interface()); err != nil {
+			return fmt.Errorf("set multirange %s element %d: %w", m.name, i, err)
+		}
+	}
+	m.status = pgtype.Present
+	return nil
+}
+
+func (m *multirangeValue) Get() interface{} {
+	switch m.status {
+	case pgtype.Present:
+		return m
+	case pgtype.Null:
+		return nil
+	default:
+		return m.status
+	}
+}
+
+func (m *multirangeValue) AssignTo(dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("cannot assign multirange %s to %T", m.name, dst)
+	}
+	v = v.Elem()
+	if m.status != pgtype.Present {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	slice := reflect.MakeSlice(v.Type(), len(m.ranges), len(m.ranges))
+	for i, rng := range m.ranges {
+		if err := assignRangeElem(rng, slice.Index(i)); err != nil {
+			return fmt.Errorf("assign multirange %s element %d: %w", m.name, i, err)
+		}
+	}
+	v.Set(slice)
+	return nil
+}
+
+func (m *multirangeValue) DecodeText(ci *pgtype.ConnInfo, src []byte) error {
+	*m = multirangeValue{name: m.name, newRange: m.newRange, status: pgtype.Null}
+	if src == nil {
+		return nil
+	}
+	ranges, err := splitMultirangeText(string(src))
+	if err != nil {
+		return fmt.Errorf("decode multirange %s: %w", m.name, err)
+	}
+	m.ranges = make([]pgtype.ValueTranscoder, len(ranges))
+	for i, rng := range ranges {
+		m.ranges[i] = m.newRange()
+		if err := m.ranges[i].DecodeText(ci, []byte(rng)); err != nil {
+			return fmt.Errorf("decode multirange %s element %d: %w", m.name, i, err)
+		}
+	}
+	m.status = pgtype.Present
+	return nil
+}
+
+func (m *multirangeValue) DecodeBinary(ci *pgtype.ConnInfo, src []byte) error {
+	*m = multirangeValue{name: m.name, newRange: m.newRange, status: pgtype.Null}
+	if src == nil {
+		return nil
+	}
+	if len(src) < 4 {
+		return fmt.Errorf("decode multirange %s: too short", m.name)
+	}
+	n := int(readInt32(src))
+	if n < 0 {
+		return fmt.Errorf("decode multirange %s: invalid length %d", m.name, n)
+	}
+	src = src[4:]
+	m.ranges = make([]pgtype.ValueTranscoder, n)
+	for i := range m.ranges {
+		if len(src) < 4 {
+			return fmt.Errorf("decode multirange %s element %d: too short", m.name, i)
+		}
+		size := int(readInt32(src))
+		if size < 0 || len(src) < 4+size {
+			return fmt.Errorf("decode multirange %s element %d: invalid length %d", m.name, i, size)
+		}
+		m.ranges[i] = m.newRange()
+		if err := m.ranges[i].DecodeBinary(ci, src[4:4+size]); err != nil {
+			return fmt.Errorf("decode multirange %s element %d: %w", m.name, i, err)
+		}
+		src = src[4+size:]
+	}
+	m.status = pgtype.Present
+	return nil
+}
+
+func (m *multirangeValue) EncodeText(ci *pgtype.ConnInfo, buf []byte) ([]byte, error) {
+	switch m.status {
+	case pgtype.Null:
+		return nil, nil
+	case pgtype.Undefined:
+		return nil, fmt.Errorf("cannot encode undefined multirange %s", m.name)
+	}
+	buf = append(buf, '{')
+	for i, rng := range m.ranges {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		var err error
+		if buf, err = rng.EncodeText(ci, buf); err != nil {
+			return nil, fmt.Errorf("encode multirange %s element %d: %w", m.name, i, err)
+		}
+	}
+	return append(buf, '}'), nil
+}
+
+func (m *multirangeValue) EncodeBinary(ci *pgtype.ConnInfo, buf []byte) ([]byte, error) {
+	switch m.status {
+	case pgtype.Null:
+		return nil, nil
+	case pgtype.Undefined:
+		return nil, fmt.Errorf("cannot encode undefined multirange %s", m.name)
+	}
+	n := len(m.ranges)
+	buf = append(buf, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	for i, rng := range m.ranges {
+		var err error
+		if buf, err = appendBinaryElem(ci, buf, rng); err != nil {
+			return nil, fmt.Errorf("encode multirange %s element %d: %w", m.name, i, err)
+		}
+	}
+	return buf, nil
+}
+
+// setRangeElem sets a range bound or multirange element. Sets pgtype values,
+// like pgtype.Numeric, by copying because pgtype can't set a value from
+// itself.
+func setRangeElem(val pgtype.ValueTranscoder, src interface{}) error {
+	v := reflect.ValueOf(val)
+	if v.Kind() == reflect.Ptr && v.Type().Elem() == reflect.TypeOf(src) {
+		v.Elem().Set(reflect.ValueOf(src))
+		return nil
+	}
+	return val.Set(src)
+}
+
+// assignRangeElem assigns a range bound or multirange element to dst. Assigns
+// pgtype values by copying because pgtype can't assign a value to itself.
+func assignRangeElem(val pgtype.ValueTranscoder, dst reflect.Value) error {
+	v := reflect.ValueOf(val)
+	if v.Kind() == reflect.Ptr && v.Type().Elem() == dst.Type() {
+		dst.Set(v.Elem())
+		return nil
+	}
+	return val.AssignTo(dst.Addr().Interface())
+}
+
+// appendRangeTextBound appends a range bound in the text format, quoting the
+// bound if necessary.
+func appendRangeTextBound(ci *pgtype.ConnInfo, buf []byte, val pgtype.ValueTranscoder) ([]byte, error) {
+	bound, err := val.EncodeText(ci, nil)
+	if err != nil {
+		return nil, err
+	}
+	if bound == nil {
+		return nil, fmt.Errorf("bound cannot be null unless the bound type is unbounded")
+	}
+	quote := len(bound) == 0
+	for _, c := range bound {
+		switch c {
+		case ',', '(', ')', '[', ']', '"', '\\', ' ', '\t', '\n', '\r':
+			quote = true
+		}
+	}
+	if !quote {
+		return append(buf, bound...), nil
+	}
+	buf = append(buf, '"')
+	for _, c := range bound {
+		if c == '"' || c == '\\' {
+			buf = append(buf, '\\')
+		}
+		buf = append(buf, c)
+	}
+	return append(buf, '"'), nil
+}
+
+// splitMultirangeText splits a multirange in the text format, like
+// {[1,3),[5,7)}, into the text of each range.
+func splitMultirangeText(src string) ([]string, error) {
+	if len(src) < 2 || src[0] != '{' || src[len(src)-1] != '}' {
+		return nil, fmt.Errorf("invalid multirange %q", src)
+	}
+	body := src[1 : len(src)-1]
+	ranges := make([]string, 0, 4)
+	start, inQuote := -1, false
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case inQuote && c == '\\':
+			i++ // skip the escaped char
+		case c == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case start < 0 && (c == '[' || c == '('):
+			start = i
+		case start >= 0 && (c == ']' || c == ')'):
+			ranges = append(ranges, body[start:i+1])
+			start = -1
+		}
+	}
+	if start >= 0 || inQuote {
+		return nil, fmt.Errorf("invalid multirange %q", src)
+	}
+	return ranges, nil
+}
+
+// appendBinaryElem appends the binary format of val prefixed by the length.
+func appendBinaryElem(ci *pgtype.ConnInfo, buf []byte, val pgtype.ValueTranscoder) ([]byte, error) {
+	sp := len(buf)
+	buf = append(buf, 0, 0, 0, 0)
+	buf, err := val.EncodeBinary(ci, buf)
+	if err != nil {
+		return nil, err
+	}
+	if buf == nil {
+		return nil, fmt.Errorf("element cannot be null")
+	}
+	n := len(buf) - sp - 4
+	buf[sp], buf[sp+1], buf[sp+2], buf[sp+3] = byte(n>>24), byte(n>>16), byte(n>>8), byte(n)
+	return buf, nil
+}
+
+// readInt32 reads a big-endian int32 from the start of src.
+func readInt32(src []byte) int32 {
+	return int32(src[0])<<24 | int32(src[1])<<16 | int32(src[2])<<8 | int32(src[3])
+}`
+
+// NewRangeResolverDeclarer declares the transcoders for range and multirange
+// types.
+func NewRangeResolverDeclarer() ConstantDeclarer {
+	return NewConstantDeclarer("type_resolver::02_range", rangeResolverDecl)
+}
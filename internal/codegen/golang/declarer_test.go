@@ -119,6 +119,20 @@ func TestDeclarers(t *testing.T) {
 				casing.NewCaser(),
 			),
 		},
+		{
+			// Labels added with ALTER TYPE ... ADD VALUE ... BEFORE have a sort
+			// order that differs from the creation order.
+			name: "enum_sort_order",
+			typ: gotype.NewEnumType(
+				emptyPkgPath,
+				pg.EnumType{
+					Name:   "size",
+					Labels: []string{"small", "large", "medium"},
+					Orders: []float32{1, 2, 1.5},
+				},
+				caser,
+			),
+		},
 		{
 			name: "enum_simple",
 			typ: gotype.NewEnumType(
@@ -1,14 +1,23 @@
 package golang
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
 	"github.com/leg100/pggen/internal/errs"
+	"go/format"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"text/template"
 )
 
+// generatedHeader is the first line of every Go file emitted by pggen. The
+// emitter uses the header to find stale generated files to remove.
+const generatedHeader = "// Code generated by pggen. DO NOT EDIT."
+
 // Emitter writes a templated query file to a file.
 type Emitter struct {
 	outDir string
@@ -21,7 +30,8 @@ func NewEmitter(outDir string, tmpl *template.Template) Emitter {
 
 // EmitAllQueryFiles emits a query file for each TemplatedFile. Ensure that
 // emitted files don't clash by prefixing with the parent directory if
-// necessary.
+// necessary. After emitting all files, removes any other pggen generated Go
+// files in the output directory, like the file for a deleted query file.
 func (em Emitter) EmitAllQueryFiles(tfs []TemplatedFile) (mErr error) {
 	outs := em.chooseOutputFiles(tfs)
 	for i, tf := range tfs {
@@ -29,6 +39,9 @@ func (em Emitter) EmitAllQueryFiles(tfs []TemplatedFile) (mErr error) {
 			return err
 		}
 	}
+	if err := em.removeOrphans(outs); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -93,16 +106,95 @@ func (em Emitter) chooseOutputFiles(tfs []TemplatedFile) []string {
 	return outNames
 }
 
-// emitQueryFile emits a single query file.
+// emitQueryFile emits a single query file formatted with gofmt. Writes to a
+// temp file and renames the temp file to the output file so that a failure
+// never leaves a partially written output file.
 func (em Emitter) emitQueryFile(outRelPath string, tf TemplatedFile) (mErr error) {
 	out := filepath.Join(em.outDir, outRelPath)
-	file, err := os.OpenFile(out, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
-	defer errs.Capture(&mErr, file.Close, "close emit query file")
+	buf := &bytes.Buffer{}
+	if err := em.tmpl.ExecuteTemplate(buf, "gen_query", tf); err != nil {
+		return fmt.Errorf("execute generated query file template %s: %w", out, err)
+	}
+	src, err := format.Source(buf.Bytes())
 	if err != nil {
-		return fmt.Errorf("open generated query file for writing: %w", err)
+		return fmt.Errorf("format generated query file %s; the template produced invalid Go: %w", out, err)
 	}
-	if err := em.tmpl.ExecuteTemplate(file, "gen_query", tf); err != nil {
-		return fmt.Errorf("execute generated query file template %s: %w", out, err)
+	return writeFileAtomic(out, src)
+}
+
+// writeFileAtomic writes data to a temp file in the same directory as path and
+// renames the temp file to path.
+func writeFileAtomic(path string, data []byte) (mErr error) {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("create temp file for generated query file: %w", err)
+	}
+	defer func() {
+		if mErr != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write generated query file %s: %w", path, err)
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("chmod generated query file %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close generated query file %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("rename generated query file %s: %w", path, err)
+	}
+	return nil
+}
+
+// removeOrphans removes pggen generated Go files in the output directory that
+// aren't in outRelPaths, like the generated file for a deleted or renamed query
+// file. Only removes files that start with the generated header, so
+// hand-written Go files are never removed.
+func (em Emitter) removeOrphans(outRelPaths []string) error {
+	keep := make(map[string]bool, len(outRelPaths))
+	for _, p := range outRelPaths {
+		keep[p] = true
+	}
+	entries, err := ioutil.ReadDir(em.outDir)
+	if err != nil {
+		return fmt.Errorf("read output dir to remove stale generated files: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || keep[name] || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		path := filepath.Join(em.outDir, name)
+		isGen, err := isGeneratedFile(path)
+		if err != nil {
+			return err
+		}
+		if !isGen {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("remove stale generated file: %w", err)
+		}
 	}
 	return nil
 }
+
+// isGeneratedFile returns true if the first line of the file at path is the
+// pggen generated header.
+func isGeneratedFile(path string) (_ bool, mErr error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("open possibly generated file: %w", err)
+	}
+	defer errs.Capture(&mErr, f.Close, "close possibly generated file")
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && line == "" {
+		return false, nil // empty file
+	}
+	return strings.TrimRight(line, "\r\n") == generatedHeader, nil
+}
@@ -0,0 +1,79 @@
+package golang
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmitter_EmitAllQueryFiles(t *testing.T) {
+	tmpl := template.Must(template.New("").Parse(`
+		{{- define "gen_query" -}}
+		// Code generated by pggen. DO NOT EDIT.
+
+		package {{.GoPkg}}
+		func  foo( ) {}
+		{{- end -}}
+	`))
+	outDir := t.TempDir()
+	files := map[string]string{
+		"stale.sql.go":      generatedHeader + "\n\npackage foo\n",
+		"handwritten.go":    "package foo\n",
+		"handwritten2.go":   "// Code generated by other tool. DO NOT EDIT.\n\npackage foo\n",
+		"query.sql_test.go": generatedHeader + "\n\npackage foo\n",
+		"empty.go":          "",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(outDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	em := NewEmitter(outDir, tmpl)
+	err := em.EmitAllQueryFiles([]TemplatedFile{{GoPkg: "foo", SourcePath: "/src/query.sql"}})
+	require.NoError(t, err)
+
+	got, err := ioutil.ReadFile(filepath.Join(outDir, "query.sql.go"))
+	require.NoError(t, err)
+	assert.Equal(t, generatedHeader+"\n\npackage foo\n\nfunc foo() {}\n", string(got), "should gofmt output")
+
+	entries, err := ioutil.ReadDir(outDir)
+	require.NoError(t, err)
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	want := []string{"empty.go", "handwritten.go", "handwritten2.go", "query.sql.go", "query.sql_test.go"}
+	assert.Equal(t, want, names, "should remove only stale generated files")
+}
+
+func TestEmitter_EmitAllQueryFiles_InvalidGo(t *testing.T) {
+	tmpl := template.Must(template.New("").Parse(`
+		{{- define "gen_query" -}}
+		package {{.GoPkg}}
+		func foo( {
+		{{- end -}}
+	`))
+	outDir := t.TempDir()
+	existing := filepath.Join(outDir, "query.sql.go")
+	if err := ioutil.WriteFile(existing, []byte("package foo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	em := NewEmitter(outDir, tmpl)
+	err := em.EmitAllQueryFiles([]TemplatedFile{{GoPkg: "foo", SourcePath: "/src/query.sql"}})
+	require.Error(t, err, "should fail to format invalid Go")
+
+	got, err := ioutil.ReadFile(existing)
+	require.NoError(t, err)
+	assert.Equal(t, "package foo\n", string(got), "should not overwrite existing file")
+	entries, err := ioutil.ReadDir(outDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "should not leave temp files")
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"github.com/leg100/pggen/internal/casing"
 	"github.com/leg100/pggen/internal/codegen"
+	"github.com/leg100/pggen/internal/pg"
+	"io/ioutil"
 	"path/filepath"
 	"sort"
 	"text/template"
@@ -17,8 +19,47 @@ type GenerateOptions struct {
 	// A map of lowercase acronyms to the upper case equivalent, like:
 	// "api" => "API".
 	Acronyms map[string]string
-	// A map from a Postgres type name to a fully qualified Go type.
+	// A map from a Postgres type name, like "status" or the schema-qualified
+	// "billing.status", to a fully qualified Go type. A key can also be a
+	// schema-qualified table column, like "public.users.id", to override the
+	// type of the output columns from the table column and the params compared
+	// to the table column. A value can have separate non-nullable and nullable
+	// Go types, like "string,*string". See NewTypeResolver.
 	TypeOverrides map[string]string
+	// If true, define a ReadQuerier interface with only the read-only queries.
+	ReadQuerier bool
+	// Paths to Go template files that override query.gotemplate, applied in
+	// order. See parseQueryTemplate.
+	Templates []string
+	// Struct tags for each field of row, param, and composite structs. If nil,
+	// row and composite structs have a json tag and param structs have none.
+	StructTags []StructTag
+	// Fully qualified Go types for json and jsonb composite fields, keyed by
+	// "<composite type>.<field>".
+	JSONTypes map[string]string
+	// The style of Go types for Postgres types like timestamptz. If empty, uses
+	// TypeStylePgtype.
+	TypeStyle TypeStyle
+	// Fully qualified Go types for uuid and numeric with TypeStyleGo, like
+	// "github.com/google/uuid.UUID". If empty, uuid resolves to [16]byte and
+	// numeric resolves to pgtype.Numeric.
+	UUIDType    string
+	DecimalType string
+	// How to represent nullable Postgres values. If empty, uses
+	// NullStyleDefault.
+	NullStyle NullStyle
+	// How to represent Postgres domains. If empty, uses DomainStyleBase.
+	DomainStyle DomainStyle
+	// Postgres type names that exist in more than one schema of the catalog,
+	// like status for billing.status and shipping.status. The Go type names
+	// for these types include the schema, like BillingStatus, even if the
+	// queries use only one of the types, so that adding a query doesn't rename
+	// existing Go types. If nil, uses the names shared by the types the
+	// queries use.
+	SharedTypeNames map[string]struct{}
+	// The Postgres major version used to infer types, like "15", recorded in
+	// the header of each generated file. If empty, omits the version.
+	PostgresVersion string
 }
 
 // Generate emits generated Go files for each of the queryFiles.
@@ -29,10 +70,31 @@ func Generate(opts GenerateOptions, queryFiles []codegen.QueryFile) error {
 	}
 	caser := casing.NewCaser()
 	caser.AddAcronyms(opts.Acronyms)
+	resolver := NewTypeResolver(caser, opts.TypeOverrides)
+	if opts.TypeStyle != "" {
+		resolver = resolver.WithTypeStyle(opts.TypeStyle, opts.UUIDType, opts.DecimalType)
+	}
+	if len(opts.JSONTypes) > 0 {
+		resolver = resolver.WithJSONTypes(opts.JSONTypes)
+	}
+	if opts.NullStyle != "" {
+		resolver = resolver.WithNullStyle(opts.NullStyle)
+	}
+	if opts.DomainStyle != "" {
+		resolver = resolver.WithDomainStyle(opts.DomainStyle)
+	}
+	names := opts.SharedTypeNames
+	if names == nil {
+		names = findQualifiedNames(queryFiles)
+	}
+	if len(names) > 0 {
+		resolver = resolver.WithQualifiedNames(names)
+	}
 	templater := NewTemplater(TemplaterOpts{
-		Caser:    caser,
-		Resolver: NewTypeResolver(caser, opts.TypeOverrides),
-		Pkg:      pkgName,
+		Caser:      caser,
+		Resolver:   resolver,
+		Pkg:        pkgName,
+		StructTags: opts.StructTags,
 	})
 	templatedFiles, err := templater.TemplateAll(queryFiles)
 	if err != nil {
@@ -46,12 +108,13 @@ func Generate(opts GenerateOptions, queryFiles []codegen.QueryFile) error {
 
 	// Link each child to the package. Necessary so the leader can define all
 	// Querier methods.
-	pkg := TemplatedPackage{Files: templatedFiles}
+	pkg := TemplatedPackage{Files: templatedFiles, ReadQuerier: opts.ReadQuerier}
 	for i := range templatedFiles {
 		templatedFiles[i].Pkg = pkg
+		templatedFiles[i].PostgresVersion = opts.PostgresVersion
 	}
 
-	tmpl, err := parseQueryTemplate()
+	tmpl, err := parseQueryTemplate(opts.Templates)
 	if err != nil {
 		return fmt.Errorf("parse generated Go code template: %w", err)
 	}
@@ -62,13 +125,76 @@ func Generate(opts GenerateOptions, queryFiles []codegen.QueryFile) error {
 	return nil
 }
 
+// findQualifiedNames returns the names of Postgres types used by the queries
+// that exist in more than one schema, like status for billing.status and
+// shipping.status.
+func findQualifiedNames(queryFiles []codegen.QueryFile) map[string]struct{} {
+	schemas := make(map[string]map[string]struct{}) // type name to schemas
+	var addType func(typ pg.Type)
+	addType = func(typ pg.Type) {
+		if schema := pg.TypeSchema(typ); schema != "" {
+			if schemas[typ.String()] == nil {
+				schemas[typ.String()] = make(map[string]struct{}, 1)
+			}
+			schemas[typ.String()][schema] = struct{}{}
+		}
+		switch typ := typ.(type) {
+		case pg.ArrayType:
+			addType(typ.ElemType)
+		case pg.CompositeType:
+			for _, colType := range typ.ColumnTypes {
+				addType(colType)
+			}
+		case pg.DomainType:
+			addType(typ.BaseType)
+		case pg.RangeType:
+			addType(typ.ElemType)
+		case pg.MultirangeType:
+			addType(typ.RangeType)
+		}
+	}
+	for _, queryFile := range queryFiles {
+		for _, query := range queryFile.Queries {
+			for _, input := range query.Inputs {
+				addType(input.PgType)
+			}
+			for _, output := range query.Outputs {
+				addType(output.PgType)
+			}
+		}
+	}
+	names := make(map[string]struct{})
+	for name, nameSchemas := range schemas {
+		if len(nameSchemas) > 1 {
+			names[name] = struct{}{}
+		}
+	}
+	return names
+}
+
 //go:embed query.gotemplate
 var queryTemplate string
 
-func parseQueryTemplate() (*template.Template, error) {
+// parseQueryTemplate parses query.gotemplate and then each of the override
+// template files. An override file replaces the named templates it defines,
+// like "query_method". If an override file has content outside of a define
+// action, the content replaces the entire "gen_query" template.
+func parseQueryTemplate(overrides []string) (*template.Template, error) {
 	tmpl, err := template.New("gen_query").Parse(queryTemplate)
 	if err != nil {
 		return nil, fmt.Errorf("parse query.gotemplate: %w", err)
 	}
+	for _, path := range overrides {
+		text, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read go template override: %w", err)
+		}
+		// Parse as gen_query so that top-level content replaces gen_query.
+		// text/template ignores a top-level body with only whitespace and
+		// comments, so a file with only define actions keeps gen_query.
+		if _, err := tmpl.New("gen_query").Parse(string(text)); err != nil {
+			return nil, fmt.Errorf("parse go template override %s: %w", path, err)
+		}
+	}
 	return tmpl, nil
 }
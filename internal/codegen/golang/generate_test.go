@@ -0,0 +1,770 @@
+package golang
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/leg100/pggen/internal/ast"
+	"github.com/leg100/pggen/internal/codegen"
+	"github.com/leg100/pggen/internal/pg"
+	"github.com/leg100/pggen/internal/pginfer"
+	"github.com/leg100/pggen/internal/texts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate_Templates(t *testing.T) {
+	queryFiles := []codegen.QueryFile{{
+		SourcePath: "/src/query.sql",
+		Queries: []pginfer.TypedQuery{{
+			Name:        "FindName",
+			ResultKind:  ast.ResultKindOne,
+			PreparedSQL: "SELECT 'foo' AS name",
+			Outputs:     []pginfer.OutputColumn{{PgName: "name", PgType: pg.Text}},
+		}},
+	}}
+	tests := []struct {
+		name     string
+		template string
+		want     func(t *testing.T, got string)
+	}{
+		{
+			name: "named template",
+			template: texts.Dedent(`
+				{{- define "query_extra" }}
+
+				// {{ .Name }}SQLText returns the SQL of the {{ .Name }} query.
+				func {{ .Name }}SQLText() string { return {{ .SQLVarName }} }
+				{{- end -}}
+			`),
+			want: func(t *testing.T, got string) {
+				assert.Contains(t, got, "func (q *DBQuerier) FindName(ctx context.Context)")
+				assert.Contains(t, got, "\n// FindNameSQLText returns the SQL of the FindName query.\n"+
+					"func FindNameSQLText() string { return findNameSQL }\n")
+			},
+		},
+		{
+			name: "whole template",
+			template: texts.Dedent(`
+				// Code generated by pggen. DO NOT EDIT.
+
+				package {{ .GoPkg }}
+				{{ range .Queries }}
+				const {{ .SQLVarName }} = {{ .EmitPreparedSQL }}
+				{{- end }}
+			`),
+			want: func(t *testing.T, got string) {
+				want := "// Code generated by pggen. DO NOT EDIT.\n\npackage foo\n\n" +
+					"const findNameSQL = `SELECT 'foo' AS name`\n"
+				assert.Equal(t, want, got)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmplFile := filepath.Join(t.TempDir(), "custom.gotemplate")
+			if err := ioutil.WriteFile(tmplFile, []byte(tt.template), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got := generateCode(t, GenerateOptions{Templates: []string{tmplFile}}, queryFiles, nil)
+			tt.want(t, got)
+		})
+	}
+}
+
+func TestGenerate_Templates_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		wantErr  string
+	}{
+		{
+			name:     "invalid template",
+			template: `{{ define "query_extra" }}{{ .Name }`,
+			wantErr:  "parse go template override",
+		},
+		{
+			name:     "unknown field",
+			template: `{{ define "query_extra" }}{{ .NoSuchField }}{{ end }}`,
+			wantErr:  "execute generated query file template",
+		},
+		{
+			name:     "invalid Go",
+			template: `{{ define "query_extra" }}func {{ end }}`,
+			wantErr:  "format generated query file",
+		},
+	}
+	queryFiles := []codegen.QueryFile{{
+		SourcePath: "/src/query.sql",
+		Queries: []pginfer.TypedQuery{{
+			Name:        "Void",
+			ResultKind:  ast.ResultKindExec,
+			PreparedSQL: "SELECT 1",
+		}},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmplFile := filepath.Join(t.TempDir(), "custom.gotemplate")
+			if err := ioutil.WriteFile(tmplFile, []byte(tt.template), 0644); err != nil {
+				t.Fatal(err)
+			}
+			opts := GenerateOptions{GoPkg: "foo", OutputDir: t.TempDir(), Templates: []string{tmplFile}}
+			err := Generate(opts, queryFiles)
+			require.Error(t, err)
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Generate() error %q; want substring %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerate_StructTags(t *testing.T) {
+	userType := pg.CompositeType{
+		Name:        "user_info",
+		ColumnNames: []string{"user_name"},
+		ColumnTypes: []pg.Type{pg.Text},
+	}
+	queryFiles := []codegen.QueryFile{{
+		SourcePath: "/src/query.sql",
+		Queries: []pginfer.TypedQuery{{
+			Name:        "FindUser",
+			ResultKind:  ast.ResultKindOne,
+			PreparedSQL: "SELECT user_id, user_info FROM users WHERE user_id = $1 AND first_name = $2 AND last_name = $3",
+			Inputs: []pginfer.InputParam{
+				{PgName: "UserID", PgType: pg.Int4},
+				{PgName: "first_name", PgType: pg.Text},
+				{PgName: "last_name", PgType: pg.Text},
+			},
+			Outputs: []pginfer.OutputColumn{
+				{PgName: "user_id", PgType: pg.Int4},
+				{PgName: "user_info", PgType: userType, Nullable: true},
+			},
+		}},
+	}}
+	tests := []struct {
+		name string
+		tags []StructTag
+		want []string
+	}{
+		{
+			name: "default",
+			want: []string{
+				"type FindUserParams struct {\n\tUserID    int32\n\tFirstName string\n\tLastName  string\n}",
+				"type FindUserRow struct {\n\tUserId   int32     `json:\"user_id\"`\n\tUserInfo *UserInfo `json:\"user_info\"`\n}",
+				"type UserInfo struct {\n\tUserName *string `json:\"user_name\"`\n}",
+			},
+		},
+		{
+			name: "configured",
+			tags: []StructTag{{Key: "json", Case: TagCaseCamel, OmitEmpty: true}, {Key: "db", Case: TagCaseSnake}},
+			want: []string{
+				"type FindUserParams struct {\n" +
+					"\tUserID    int32  `json:\"userID\" db:\"user_id\"`\n" +
+					"\tFirstName string `json:\"firstName\" db:\"first_name\"`\n" +
+					"\tLastName  string `json:\"lastName\" db:\"last_name\"`\n}",
+				"type FindUserRow struct {\n" +
+					"\tUserId   int32     `json:\"userId\" db:\"user_id\"`\n" +
+					"\tUserInfo *UserInfo `json:\"userInfo,omitempty\" db:\"user_info\"`\n}",
+				"type UserInfo struct {\n\tUserName *string `json:\"userName,omitempty\" db:\"user_name\"`\n}",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := generateCode(t, GenerateOptions{StructTags: tt.tags}, queryFiles, nil)
+			for _, want := range tt.want {
+				assert.Contains(t, got, want)
+			}
+		})
+	}
+}
+
+func TestGenerate_TypeStyleGo(t *testing.T) {
+	eventType := pg.CompositeType{
+		Name:        "event",
+		ColumnNames: []string{"event_id", "happened_at", "tags"},
+		ColumnTypes: []pg.Type{pg.UUID, pg.Timestamptz, pg.JSONB},
+	}
+	queryFiles := []codegen.QueryFile{{
+		SourcePath: "/src/query.sql",
+		Queries: []pginfer.TypedQuery{{
+			Name:        "FindEvent",
+			ResultKind:  ast.ResultKindOne,
+			PreparedSQL: "SELECT event_id, happened_at, deleted_at, took, payload, event FROM events WHERE event_id = $1 AND happened_at > $2",
+			Inputs: []pginfer.InputParam{
+				{PgName: "event_id", PgType: pg.UUID},
+				{PgName: "after", PgType: pg.Timestamptz},
+			},
+			Outputs: []pginfer.OutputColumn{
+				{PgName: "event_id", PgType: pg.UUID},
+				{PgName: "happened_at", PgType: pg.Timestamptz},
+				{PgName: "deleted_at", PgType: pg.Timestamptz, Nullable: true},
+				{PgName: "took", PgType: pg.Interval},
+				{PgName: "payload", PgType: pg.JSONB},
+				{PgName: "event", PgType: eventType, Nullable: true},
+			},
+		}},
+	}}
+	opts := GenerateOptions{TypeStyle: TypeStyleGo, UUIDType: "example.com/uuid.UUID"}
+	got := generateCode(t, opts, queryFiles, map[string]string{
+		"uuid/uuid.go": "package uuid\n\ntype UUID [16]byte\n",
+	})
+	for _, want := range []string{
+		"\t\"encoding/json\"\n",
+		"\t\"example.com/uuid\"\n",
+		"\t\"time\"\n",
+		"FindEvent(ctx context.Context, eventId uuid.UUID, after time.Time) (FindEventRow, error)",
+		"type FindEventRow struct {\n" +
+			"\tEventId    uuid.UUID       `json:\"event_id\"`\n" +
+			"\tHappenedAt time.Time       `json:\"happened_at\"`\n" +
+			"\tDeletedAt  *time.Time      `json:\"deleted_at\"`\n" +
+			"\tTook       time.Duration   `json:\"took\"`\n" +
+			"\tPayload    json.RawMessage `json:\"payload\"`\n" +
+			"\tEvent      *Event          `json:\"event\"`\n}",
+		"type Event struct {\n" +
+			"\tEventId    *uuid.UUID      `json:\"event_id\"`\n" +
+			"\tHappenedAt *time.Time      `json:\"happened_at\"`\n" +
+			"\tTags       json.RawMessage `json:\"tags\"`\n}",
+		"compositeField{\"happened_at\", \"timestamptz\", &pgtype.Timestamptz{}},",
+	} {
+		assert.Contains(t, got, want)
+	}
+}
+
+func TestGenerate_NullStyle(t *testing.T) {
+	userType := pg.CompositeType{
+		Name:        "user",
+		ColumnNames: []string{"name"},
+		ColumnTypes: []pg.Type{pg.Text},
+	}
+	queryFiles := []codegen.QueryFile{{
+		SourcePath: "/foo/query.sql",
+		Queries: []pginfer.TypedQuery{{
+			Name:        "FindUser",
+			ResultKind:  ast.ResultKindOne,
+			PreparedSQL: "SELECT name, age, tags, usr FROM users WHERE name = $1",
+			Inputs: []pginfer.InputParam{
+				{PgName: "name", PgType: pg.Text},
+			},
+			Outputs: []pginfer.OutputColumn{
+				{PgName: "name", PgType: pg.Text, Nullable: true},
+				{PgName: "age", PgType: pg.Int8, Nullable: true},
+				{PgName: "tags", PgType: pg.TextArray, Nullable: true},
+				{PgName: "usr", PgType: userType, Nullable: true},
+			},
+		}},
+	}}
+	tests := []struct {
+		nullStyle NullStyle
+		want      []string
+	}{
+		{
+			nullStyle: NullStyleSQL,
+			want: []string{
+				"\t\"database/sql\"\n",
+				"type FindUserRow struct {\n" +
+					"\tName sql.NullString `json:\"name\"`\n" +
+					"\tAge  sql.NullInt64  `json:\"age\"`\n" +
+					"\tTags []*string      `json:\"tags\"`\n" +
+					"\tUsr  *User          `json:\"usr\"`\n}",
+				"type User struct {\n" +
+					"\tName *string `json:\"name\"`\n}",
+			},
+		},
+		{
+			nullStyle: NullStyleGeneric,
+			want: []string{
+				"\t\"database/sql/driver\"\n",
+				"\t\"reflect\"\n",
+				"type Null[T any] struct {",
+				"type FindUserRow struct {\n" +
+					"\tName Null[string] `json:\"name\"`\n" +
+					"\tAge  Null[int]    `json:\"age\"`\n" +
+					"\tTags []*string    `json:\"tags\"`\n" +
+					"\tUsr  *User        `json:\"usr\"`\n}",
+				"type User struct {\n" +
+					"\tName Null[string] `json:\"name\"`\n}",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.nullStyle), func(t *testing.T) {
+			got := generateCode(t, GenerateOptions{NullStyle: tt.nullStyle}, queryFiles, nil)
+			for _, want := range tt.want {
+				assert.Contains(t, got, want)
+			}
+		})
+	}
+}
+
+func TestGenerate_DomainStyle(t *testing.T) {
+	email := pg.DomainType{
+		ID:         90000,
+		Name:       "email",
+		IsNotNull:  true,
+		BaseType:   pg.Text,
+		CheckExprs: []string{"CHECK ((VALUE ~~ '%@%'::text))"},
+	}
+	emailArray := pg.ArrayType{ID: 90001, Name: "_email", ElemType: email}
+	userType := pg.CompositeType{
+		Name:        "user",
+		ColumnNames: []string{"email"},
+		ColumnTypes: []pg.Type{email},
+	}
+	queryFiles := []codegen.QueryFile{{
+		SourcePath: "/foo/query.sql",
+		Queries: []pginfer.TypedQuery{{
+			Name:        "FindUser",
+			ResultKind:  ast.ResultKindOne,
+			PreparedSQL: "SELECT email, backup, emails, usr FROM users WHERE email = $1",
+			Inputs: []pginfer.InputParam{
+				{PgName: "email", PgType: email},
+			},
+			Outputs: []pginfer.OutputColumn{
+				{PgName: "email", PgType: email, Nullable: false},
+				{PgName: "backup", PgType: email, Nullable: true},
+				{PgName: "emails", PgType: emailArray, Nullable: true},
+				{PgName: "usr", PgType: userType, Nullable: true},
+			},
+		}},
+	}}
+	tests := []struct {
+		domainStyle DomainStyle
+		want        []string
+	}{
+		{
+			domainStyle: DomainStyleBase,
+			want: []string{
+				"FindUser(ctx context.Context, email string) (FindUserRow, error)",
+				"type FindUserRow struct {\n" +
+					"\tEmail  string   `json:\"email\"`\n" +
+					"\tBackup *string  `json:\"backup\"`\n" +
+					"\tEmails []string `json:\"emails\"`\n" +
+					"\tUsr    *User    `json:\"usr\"`\n}",
+				"type User struct {\n" +
+					"\tEmail *string `json:\"email\"`\n}",
+			},
+		},
+		{
+			domainStyle: DomainStyleNamed,
+			want: []string{
+				"// Email represents the Postgres domain \"email\".\n" +
+					"//\n" +
+					"// Constraints:\n" +
+					"//   - NOT NULL\n" +
+					"//   - CHECK ((VALUE ~~ '%@%'::text))\n" +
+					"type Email string\n",
+				"FindUser(ctx context.Context, email Email) (FindUserRow, error)",
+				"type FindUserRow struct {\n" +
+					"\tEmail  Email    `json:\"email\"`\n" +
+					"\tBackup *Email   `json:\"backup\"`\n" +
+					"\tEmails []string `json:\"emails\"`\n" +
+					"\tUsr    *User    `json:\"usr\"`\n}",
+				"type User struct {\n" +
+					"\tEmail *Email `json:\"email\"`\n}",
+				"compositeField{\"email\", \"email\", &pgtype.Text{}},",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.domainStyle), func(t *testing.T) {
+			got := generateCode(t, GenerateOptions{DomainStyle: tt.domainStyle}, queryFiles, nil)
+			for _, want := range tt.want {
+				assert.Contains(t, got, want)
+			}
+		})
+	}
+}
+
+func TestGenerate_RangeType(t *testing.T) {
+	floatrange := pg.RangeType{ID: 90000, Name: "floatrange", ElemType: pg.Float8}
+	floatmultirange := pg.MultirangeType{ID: 90001, Name: "floatmultirange", RangeType: floatrange}
+	queryFiles := []codegen.QueryFile{{
+		SourcePath: "/foo/query.sql",
+		Queries: []pginfer.TypedQuery{{
+			Name:        "FindRanges",
+			ResultKind:  ast.ResultKindOne,
+			PreparedSQL: "SELECT $1::floatrange AS r, $2::floatmultirange AS m",
+			Inputs: []pginfer.InputParam{
+				{PgName: "r", PgType: floatrange},
+			},
+			Outputs: []pginfer.OutputColumn{
+				{PgName: "r", PgType: floatrange, Nullable: true},
+				{PgName: "m", PgType: floatmultirange, Nullable: true},
+			},
+		}},
+	}}
+	got := generateCode(t, GenerateOptions{}, queryFiles, nil)
+	for _, want := range []string{
+		"FindRanges(ctx context.Context, r *Floatrange) (FindRangesRow, error)",
+		"type FindRangesRow struct {\n" +
+			"\tR *Floatrange  `json:\"r\"`\n" +
+			"\tM []Floatrange `json:\"m\"`\n}",
+		"type Floatrange struct {\n" +
+			"\tLower     pgtype.Float8\n" +
+			"\tUpper     pgtype.Float8\n" +
+			"\tLowerType pgtype.BoundType\n" +
+			"\tUpperType pgtype.BoundType\n}",
+		"return tr.newRangeValue(\"floatrange\", \"float8\", &pgtype.Float8{})",
+		"return tr.newMultirangeValue(\"floatmultirange\", tr.newFloatrange)",
+		"q.types.newFloatrangeInit(r)",
+		"rRange := q.types.newFloatrange()",
+		"mMultirange := q.types.newFloatrangeMultirange()",
+		"if err := rRange.AssignTo(&item.R); err != nil {",
+	} {
+		assert.Contains(t, got, want)
+	}
+}
+
+func TestGenerate_QualifiedNames(t *testing.T) {
+	billingStatus := pg.EnumType{ID: 90000, Name: "status", Schema: "billing", Labels: []string{"paid"}, Orders: []float32{1}}
+	shippingStatus := pg.EnumType{ID: 90001, Name: "status", Schema: "shipping", Labels: []string{"sent"}, Orders: []float32{1}}
+	invoice := pg.CompositeType{
+		ID:          90002,
+		Name:        "invoice",
+		Schema:      "billing",
+		ColumnNames: []string{"status", "shipping"},
+		ColumnTypes: []pg.Type{billingStatus, shippingStatus},
+	}
+	queryFiles := []codegen.QueryFile{{
+		SourcePath: "/foo/query.sql",
+		Queries: []pginfer.TypedQuery{{
+			Name:        "FindInvoice",
+			ResultKind:  ast.ResultKindOne,
+			PreparedSQL: "SELECT inv FROM billing.invoice inv WHERE inv.status = $1",
+			Inputs: []pginfer.InputParam{
+				{PgName: "status", PgType: billingStatus},
+			},
+			Outputs: []pginfer.OutputColumn{
+				{PgName: "inv", PgType: invoice, Nullable: true},
+			},
+		}},
+	}}
+	got := generateCode(t, GenerateOptions{}, queryFiles, nil)
+	for _, want := range []string{
+		"FindInvoice(ctx context.Context, status BillingStatus) (*Invoice, error)",
+		"type BillingStatus string",
+		"BillingStatusPaid BillingStatus = \"paid\"",
+		"type ShippingStatus string",
+		"ShippingStatusSent ShippingStatus = \"sent\"",
+		"type Invoice struct {\n" +
+			"\tStatus   BillingStatus  `json:\"status\"`\n" +
+			"\tShipping ShippingStatus `json:\"shipping\"`\n}",
+	} {
+		assert.Contains(t, got, want)
+	}
+}
+
+func TestGenerate_SharedTypeNames(t *testing.T) {
+	// The queries only use billing.status, but the catalog also has
+	// shipping.status.
+	billingStatus := pg.EnumType{ID: 90000, Name: "status", Schema: "billing", Labels: []string{"paid"}, Orders: []float32{1}}
+	queryFiles := []codegen.QueryFile{{
+		SourcePath: "/foo/query.sql",
+		Queries: []pginfer.TypedQuery{{
+			Name:        "FindStatus",
+			ResultKind:  ast.ResultKindOne,
+			PreparedSQL: "SELECT status FROM billing.invoice WHERE status = $1",
+			Inputs: []pginfer.InputParam{
+				{PgName: "status", PgType: billingStatus},
+			},
+			Outputs: []pginfer.OutputColumn{
+				{PgName: "status", PgType: billingStatus},
+			},
+		}},
+	}}
+	tests := []struct {
+		name        string
+		sharedNames map[string]struct{}
+		want        string
+	}{
+		{"catalog", map[string]struct{}{"status": {}}, "FindStatus(ctx context.Context, status BillingStatus) (BillingStatus, error)"},
+		{"no catalog", nil, "FindStatus(ctx context.Context, status Status) (Status, error)"},
+		{"catalog without shared names", map[string]struct{}{}, "FindStatus(ctx context.Context, status Status) (Status, error)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := generateCode(t, GenerateOptions{SharedTypeNames: tt.sharedNames}, queryFiles, nil)
+			assert.Contains(t, got, tt.want)
+		})
+	}
+}
+
+func TestGenerate_ColumnOverrides(t *testing.T) {
+	queryFiles := []codegen.QueryFile{{
+		SourcePath: "/foo/query.sql",
+		Queries: []pginfer.TypedQuery{{
+			Name:        "FindOrders",
+			ResultKind:  ast.ResultKindMany,
+			PreparedSQL: "SELECT u.id AS user_id, o.id AS order_id, o.coupon_id, o.total FROM users u JOIN orders o ON o.user_id = u.id WHERE u.id = $1",
+			Inputs: []pginfer.InputParam{
+				{PgName: "user_id", PgType: pg.Int8, TableColumn: "public.users.id"},
+			},
+			Outputs: []pginfer.OutputColumn{
+				{PgName: "user_id", PgType: pg.Int8, TableColumn: "public.users.id"},
+				{PgName: "order_id", PgType: pg.Int8, TableColumn: "public.orders.id"},
+				{PgName: "coupon_id", PgType: pg.Int8, Nullable: true, TableColumn: "public.orders.coupon_id"},
+				{PgName: "total", PgType: pg.Int8, TableColumn: "public.orders.total"},
+			},
+		}},
+	}}
+	opts := GenerateOptions{
+		TypeOverrides: map[string]string{
+			"public.users.id":         "example.com/ids.UserID",
+			"public.orders.id":        "example.com/ids.OrderID",
+			"public.orders.coupon_id": "example.com/ids.CouponID,*example.com/ids.CouponID",
+			"billing.orders.total":    "example.com/ids.Cents",
+		},
+	}
+	got := generateCode(t, opts, queryFiles, map[string]string{
+		"ids/ids.go": "package ids\n\ntype UserID int\n\ntype OrderID int\n\ntype CouponID int\n\ntype Cents int\n",
+	})
+	for _, want := range []string{
+		"\t\"example.com/ids\"\n",
+		"FindOrders(ctx context.Context, userId ids.UserID) ([]FindOrdersRow, error)",
+		"type FindOrdersRow struct {\n" +
+			"\tUserId   ids.UserID    `json:\"user_id\"`\n" +
+			"\tOrderId  ids.OrderID   `json:\"order_id\"`\n" +
+			"\tCouponId *ids.CouponID `json:\"coupon_id\"`\n" +
+			"\tTotal    int           `json:\"total\"`\n}",
+	} {
+		assert.Contains(t, got, want)
+	}
+}
+
+func TestGenerate_JSONType(t *testing.T) {
+	payload := "example.com/foo/payload.Payload"
+	newQueryFiles := func(jsonTypes map[string]string) []codegen.QueryFile {
+		return []codegen.QueryFile{{
+			SourcePath: "/src/query.sql",
+			Queries: []pginfer.TypedQuery{{
+				Name:        "FindPayloads",
+				ResultKind:  ast.ResultKindMany,
+				PreparedSQL: "SELECT payload, history FROM events WHERE payload @> $1",
+				Inputs: []pginfer.InputParam{
+					{PgName: "filter", PgType: pg.JSONB},
+				},
+				Outputs: []pginfer.OutputColumn{
+					{PgName: "payload", PgType: pg.JSONB, Nullable: true},
+					{PgName: "history", PgType: pg.JSONBArray},
+				},
+				JSONTypes: jsonTypes,
+			}},
+		}}
+	}
+
+	t.Run("columns and params", func(t *testing.T) {
+		queryFiles := newQueryFiles(map[string]string{"filter": payload, "payload": payload, "history": payload})
+		got := generateCode(t, GenerateOptions{}, queryFiles, map[string]string{
+			"foo/payload/payload.go": "package payload\n\ntype Payload struct {\n\tKind string `json:\"kind\"`\n}\n",
+		})
+		for _, want := range []string{
+			"\t\"example.com/foo/payload\"\n",
+			"FindPayloads(ctx context.Context, filter payload.Payload) ([]FindPayloadsRow, error)",
+			"type FindPayloadsRow struct {\n" +
+				"\tPayload *payload.Payload  `json:\"payload\"`\n" +
+				"\tHistory []payload.Payload `json:\"history\"`\n}",
+			"\tpayloadJSON := &pgtype.JSONB{}\n\thistoryJSON := &pgtype.JSONBArray{}\n",
+			"rows.Scan(payloadJSON, historyJSON)",
+			"\t\tif err := payloadJSON.AssignTo(&item.Payload); err != nil {\n" +
+				"\t\t\treturn nil, fmt.Errorf(\"unmarshal FindPayloads column payload: %w\", err)\n" +
+				"\t\t}\n",
+		} {
+			assert.Contains(t, got, want)
+		}
+	})
+
+	t.Run("unknown name", func(t *testing.T) {
+		opts := GenerateOptions{GoPkg: "foo", OutputDir: t.TempDir()}
+		err := Generate(opts, newQueryFiles(map[string]string{"paylaod": payload}))
+		if err == nil || !strings.Contains(err.Error(), "no param or output column named paylaod") {
+			t.Errorf("Generate() error %v; want unknown name error", err)
+		}
+	})
+
+	t.Run("not json", func(t *testing.T) {
+		opts := GenerateOptions{GoPkg: "foo", OutputDir: t.TempDir()}
+		queryFiles := newQueryFiles(nil)
+		queryFiles[0].Queries[0].Outputs[0].PgType = pg.Text
+		queryFiles[0].Queries[0].JSONTypes = map[string]string{"payload": payload}
+		err := Generate(opts, queryFiles)
+		if err == nil || !strings.Contains(err.Error(), "requires a json, jsonb, or jsonb[] Postgres type") {
+			t.Errorf("Generate() error %v; want not json error", err)
+		}
+	})
+}
+
+func TestGenerate_PostgresVersion(t *testing.T) {
+	queryFiles := []codegen.QueryFile{{
+		SourcePath: "/src/query.sql",
+		Queries: []pginfer.TypedQuery{{
+			Name:        "Void",
+			ResultKind:  ast.ResultKindExec,
+			PreparedSQL: "SELECT 1",
+		}},
+	}}
+	tests := []struct {
+		name    string
+		version string
+		want    string
+	}{
+		{"no version", "", "// Code generated by pggen. DO NOT EDIT.\n\npackage foo\n"},
+		{"version", "15", "// Code generated by pggen. DO NOT EDIT.\n// Generated against Postgres 15.\n\npackage foo\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outDir := t.TempDir()
+			opts := GenerateOptions{GoPkg: "foo", OutputDir: outDir, PostgresVersion: tt.version}
+			if err := Generate(opts, queryFiles); err != nil {
+				t.Fatal(err)
+			}
+			got := readGenerated(t, outDir, queryFiles[0])
+			if !strings.HasPrefix(got, tt.want) {
+				t.Errorf("Generate() header mismatch; got:\n%s\nwant prefix:\n%s", got, tt.want)
+			}
+			// The emitter must still recognize the file as generated.
+			isGen, err := isGeneratedFile(filepath.Join(outDir, "query.sql.go"))
+			require.NoError(t, err)
+			assert.True(t, isGen)
+		})
+	}
+}
+
+func TestGenerate_EnumNull(t *testing.T) {
+	deviceType := pg.EnumType{ID: 90000, Name: "device_type", Labels: []string{"phone", "ipad"}, Orders: []float32{1, 2}}
+	queryFiles := []codegen.QueryFile{{
+		SourcePath: "/foo/query.sql",
+		Queries: []pginfer.TypedQuery{{
+			Name:        "FindDevice",
+			ResultKind:  ast.ResultKindOne,
+			PreparedSQL: "SELECT name, type FROM device",
+			Outputs: []pginfer.OutputColumn{
+				{PgName: "name", PgType: pg.Text},
+				{PgName: "type", PgType: deviceType, Nullable: true},
+			},
+		}},
+	}}
+	// A NULL enum column scans to the zero value, which must round trip
+	// through JSON.
+	testSrc := texts.Dedent(`
+		package foo
+
+		import (
+			"encoding/json"
+			"testing"
+		)
+
+		func TestFindDeviceRow_JSON(t *testing.T) {
+			bs, err := json.Marshal(FindDeviceRow{Name: "foo"})
+			if err != nil {
+				t.Fatalf("marshal row with null enum: %s", err)
+			}
+			if want := ` + "`" + `{"name":"foo","type":""}` + "`" + `; string(bs) != want {
+				t.Errorf("marshal row with null enum: got %s; want %s", bs, want)
+			}
+			row := FindDeviceRow{Type: DeviceTypePhone}
+			if err := json.Unmarshal(bs, &row); err != nil {
+				t.Fatalf("unmarshal row with null enum: %s", err)
+			}
+			if row.Type != "" {
+				t.Errorf("unmarshal row with null enum: got type %q; want empty", row.Type)
+			}
+			if _, err := json.Marshal(FindDeviceRow{Type: "laptop"}); err == nil {
+				t.Error("marshal row with unknown enum label: want error")
+			}
+		}
+	`)
+	got := generateAndTest(t, GenerateOptions{}, queryFiles, testSrc)
+	assert.Contains(t, got, "\tif d != \"\" && !d.Valid() {\n")
+}
+
+func TestGenerate_WithTx(t *testing.T) {
+	queryFiles := []codegen.QueryFile{{
+		SourcePath: "/foo/query.sql",
+		Queries: []pginfer.TypedQuery{{
+			Name:        "DeleteAuthor",
+			ResultKind:  ast.ResultKindExec,
+			PreparedSQL: "DELETE FROM author WHERE author_id = $1",
+			Inputs: []pginfer.InputParam{
+				{PgName: "author_id", PgType: pg.Int4},
+			},
+		}},
+	}}
+	// WithTx once dropped the types and config of the querier, so queries in
+	// a transaction lost custom data types and PgBouncer compatibility.
+	testSrc := texts.Dedent(`
+		package foo
+
+		import (
+			"reflect"
+			"testing"
+
+			"github.com/jackc/pgtype"
+			"github.com/jackc/pgx/v4"
+		)
+
+		type fakeTx struct{ pgx.Tx }
+
+		func TestDBQuerier_WithTx(t *testing.T) {
+			cfg := QuerierConfig{
+				DataTypes:       []pgtype.DataType{{Value: &pgtype.Text{}, Name: "custom_text", OID: 90000}},
+				PgBouncerCompat: true,
+			}
+			q := NewQuerierConfig(fakeTx{}, cfg)
+			txq, err := q.WithTx(fakeTx{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if txq.types != q.types {
+				t.Error("WithTx querier doesn't keep the types of the querier")
+			}
+			if !reflect.DeepEqual(txq.cfg, cfg) {
+				t.Errorf("WithTx querier config: got %+v; want %+v", txq.cfg, cfg)
+			}
+			if _, ok := txq.conn.(simpleProtocolConn); !ok {
+				t.Errorf("WithTx querier conn with PgBouncerCompat: got %T; want simpleProtocolConn", txq.conn)
+			}
+		}
+	`)
+	generateAndTest(t, GenerateOptions{}, queryFiles, testSrc)
+}
+
+func TestGenerate_RowType(t *testing.T) {
+	outputs := []pginfer.OutputColumn{
+		{PgName: "id", PgType: pg.Int4},
+		{PgName: "name", PgType: pg.Text, Nullable: true},
+	}
+	queryFiles := []codegen.QueryFile{
+		{
+			SourcePath: "/foo/author.sql",
+			Queries: []pginfer.TypedQuery{{
+				Name:        "InsertAuthor",
+				ResultKind:  ast.ResultKindOne,
+				PreparedSQL: "INSERT INTO author (name) VALUES ($1) RETURNING *",
+				Inputs:      []pginfer.InputParam{{PgName: "name", PgType: pg.Text}},
+				Outputs:     outputs,
+				RowType:     "Author",
+			}},
+		},
+		{
+			SourcePath: "/foo/find.sql",
+			Queries: []pginfer.TypedQuery{{
+				Name:        "FindAuthors",
+				ResultKind:  ast.ResultKindMany,
+				PreparedSQL: "SELECT * FROM author",
+				Outputs:     outputs,
+				RowType:     "Author",
+			}},
+		},
+	}
+	got := generateCode(t, GenerateOptions{}, queryFiles, nil)
+	for _, want := range []string{
+		"InsertAuthor(ctx context.Context, name string) (Author, error)",
+		"type Author struct {\n",
+	} {
+		assert.Contains(t, got, want)
+	}
+	assert.NotContains(t, got, "InsertAuthorRow")
+}
@@ -43,6 +43,23 @@ func FindKnownTypeNonNullable(oid pgtype.OID) (Type, bool) {
 	return typ.pgNative, true
 }
 
+// FindGoStyleType returns the native Go type, like time.Time, for a Postgres
+// OID that's nicer to work with than the corresponding pgtype type, like
+// pgtype.Timestamptz. Only includes types that pgtype can transcode to and from
+// directly, including as array elements and composite fields. If nullable, the
+// type is a pointer or slice of pointers, like *time.Time. If there is no
+// native Go type for the OID, returns nil.
+func FindGoStyleType(oid pgtype.OID, nullable bool) (Type, bool) {
+	typ, ok := goStyleTypesByOID[oid]
+	if !ok {
+		return nil, false
+	}
+	if nullable {
+		return typ.nullable, true
+	}
+	return typ.nonNullable, true
+}
+
 // Native go types are not prefixed.
 //goland:noinspection GoUnusedGlobalVariable
 var (
@@ -87,6 +104,22 @@ var (
 	ByteSlice     = NewOpaqueType("[]byte")
 )
 
+// Native go types for the "go" type style.
+//goland:noinspection GoUnusedGlobalVariable
+var (
+	Time                = NewOpaqueType("time.Time")
+	Timep               = NewOpaqueType("*time.Time")
+	TimeSlice           = NewOpaqueType("[]time.Time")
+	TimepSlice          = NewOpaqueType("[]*time.Time")
+	Duration            = NewOpaqueType("time.Duration")
+	Durationp           = NewOpaqueType("*time.Duration")
+	UUIDBytes           = NewOpaqueType("[16]byte")
+	UUIDBytesp          = NewOpaqueType("*[16]byte")
+	UUIDBytesSlice      = NewOpaqueType("[][16]byte")
+	JSONRawMessage      = NewOpaqueType("encoding/json.RawMessage")
+	JSONRawMessageSlice = NewOpaqueType("[]encoding/json.RawMessage")
+)
+
 // pgtype types prefixed with "pg".
 var (
 	PgBool             = NewOpaqueType("github.com/jackc/pgtype.Bool")
@@ -245,3 +278,26 @@ var knownTypesByOID = map[pgtype.OID]knownGoType{
 	pgtype.DaterangeOID:        {PgDaterange, nil, nil},
 	pgtype.Int8rangeOID:        {PgInt8range, nil, nil},
 }
+
+// goStyleTypesByOID are the native Go types for the "go" type style. The
+// pgNative type is unused. A SQL null json value decodes to the JSON literal
+// null, so json.RawMessage represents both nullable and non-nullable json.
+// Like text arrays, uuid arrays use non-pointer elements because pgtype can't
+// encode a nil *[16]byte element. pgtype decodes an interval into a
+// time.Duration by counting a month as 30 days and a day as 24 hours, so
+// months and days don't survive a round-trip; time.Duration encodes as
+// microseconds only.
+var goStyleTypesByOID = map[pgtype.OID]knownGoType{
+	pgtype.DateOID:             {PgDate, Timep, Time},
+	pgtype.TimestampOID:        {PgTimestamp, Timep, Time},
+	pgtype.TimestamptzOID:      {PgTimestamptz, Timep, Time},
+	pgtype.DateArrayOID:        {PgDateArray, TimepSlice, TimeSlice},
+	pgtype.TimestampArrayOID:   {PgTimestampArray, TimepSlice, TimeSlice},
+	pgtype.TimestamptzArrayOID: {PgTimestamptzArray, TimepSlice, TimeSlice},
+	pgtype.IntervalOID:         {PgInterval, Durationp, Duration},
+	pgtype.UUIDOID:             {PgUUID, UUIDBytesp, UUIDBytes},
+	pgtype.UUIDArrayOID:        {PgUUIDArray, UUIDBytesSlice, UUIDBytesSlice},
+	pgtype.JSONOID:             {PgJSON, JSONRawMessage, JSONRawMessage},
+	pgtype.JSONBOID:            {PgJSONB, JSONRawMessage, JSONRawMessage},
+	pgtype.JSONBArrayOID:       {PgJSONBArray, JSONRawMessageSlice, JSONRawMessageSlice},
+}
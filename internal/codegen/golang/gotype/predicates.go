@@ -1,5 +1,7 @@
 package gotype
 
+import "strings"
+
 // HasCompositeType returns true if t or any of t's descendants (for array and
 // composite types) is a composite type.
 func HasCompositeType(t Type) bool {
@@ -30,3 +32,20 @@ func HasArrayType(t Type) bool {
 		return false
 	}
 }
+
+// IsPointer returns true if the Go type for t is a pointer, like *string or a
+// composite type, which the generated code always refers to with a pointer.
+func IsPointer(t Type) bool {
+	switch t := t.(type) {
+	case CompositeType, RangeType:
+		return true
+	case DomainType:
+		return strings.HasPrefix(t.Name, "*")
+	case OpaqueType:
+		return strings.HasPrefix(t.Name, "*")
+	case JSONType:
+		return strings.HasPrefix(t.Name, "*")
+	default:
+		return false
+	}
+}
@@ -48,3 +48,34 @@ func TestHasArrayType(t *testing.T) {
 		})
 	}
 }
+
+func TestIsPointer(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  Type
+		want bool
+	}{
+		{"enum", EnumType{Name: "Status"}, false},
+		{"void", VoidType{}, false},
+		{"builtin", NewOpaqueType("string"), false},
+		{"builtin pointer", NewOpaqueType("*string"), true},
+		{"qualified pointer", NewOpaqueType("*example.com/foo.Qux"), true},
+		{"slice of pointers", NewOpaqueType("[]*example.com/foo.Qux"), false},
+		{"pgtype", NewOpaqueType("github.com/jackc/pgtype.Text"), false},
+		{"array", ArrayType{Name: "[]Foo"}, false},
+		{"composite", CompositeType{Name: "Foo"}, true},
+		{"range", RangeType{Name: "Floatrange"}, true},
+		{"domain", DomainType{Name: "Email"}, false},
+		{"nullable domain", DomainType{Name: "*Email"}, true},
+		{"json", JSONType{Name: "Payload"}, false},
+		{"nullable json", JSONType{Name: "*Payload"}, true},
+		{"null", NullType{Elem: NewOpaqueType("string")}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsPointer(tt.typ); got != tt.want {
+				t.Errorf("IsPointer() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -69,6 +69,56 @@ type (
 		Name    string
 	}
 
+	// JSONType is a user-provided Go type, like a struct, for the value of a
+	// Postgres json or jsonb type. Generated code decodes the value with
+	// json.Unmarshal and encodes it with json.Marshal.
+	JSONType struct {
+		PgTyp   pg.Type // original Postgres json, jsonb, or jsonb array type
+		PkgPath string
+		Pkg     string
+		Name    string // name with leading brackets or star, like "[]Payload"
+	}
+
+	// NullType is the generic Null[T] type that pggen generates to represent a
+	// nullable Postgres value, like Null[string] for a nullable text column.
+	NullType struct {
+		PgTyp   pg.Type // original Postgres type
+		PkgPath string  // package path of the generated Null type
+		Pkg     string
+		Elem    Type // type of the non-null value, like string for Null[string]
+	}
+
+	// DomainType is a named Go type for a Postgres domain, like
+	// "type Email string" for a domain over text.
+	DomainType struct {
+		PgDomain pg.DomainType // original Postgres domain type
+		PkgPath  string
+		Pkg      string
+		Name     string // name with a leading star if nullable, like "*Email"
+		Elem     Type   // underlying Go type, like string
+	}
+
+	// RangeType is a struct type that represents a user-defined Postgres range
+	// type, like floatrange. Built-in ranges use pgtype types, like
+	// pgtype.Int4range.
+	RangeType struct {
+		PgRange pg.RangeType // original Postgres range type
+		PkgPath string
+		Pkg     string
+		Name    string // Go-style type name in UpperCamelCase
+		Elem    Type   // type of the lower and upper bounds, like float64
+	}
+
+	// MultirangeType is a Go slice type that represents a Postgres multirange
+	// type, like []Floatrange for floatmultirange.
+	MultirangeType struct {
+		PgMultirange pg.MultirangeType // original Postgres multirange type
+		PkgPath      string            // package path of the range type
+		Pkg          string
+		Name         string // name with leading brackets, like "[]Floatrange"
+		Elem         Type   // range type of each element, like Floatrange
+	}
+
 	// CompositeType is a struct type that represents a Postgres composite type,
 	// typically from a table.
 	CompositeType struct {
@@ -105,6 +155,36 @@ func (o OpaqueType) Package() string                  { return o.Pkg }
 func (o OpaqueType) BaseName() string                 { return o.Name }
 func (o OpaqueType) PgType() pg.Type                  { return o.PgTyp }
 
+func (j JSONType) QualifyRel(pkgPath string) string { return qualifyRel(j, pkgPath) }
+func (j JSONType) Import() string                   { return j.PkgPath }
+func (j JSONType) Package() string                  { return j.Pkg }
+func (j JSONType) BaseName() string                 { return j.Name }
+func (j JSONType) PgType() pg.Type                  { return j.PgTyp }
+
+func (n NullType) QualifyRel(pkgPath string) string { return qualifyRel(n, pkgPath) }
+func (n NullType) Import() string                   { return n.PkgPath }
+func (n NullType) Package() string                  { return n.Pkg }
+func (n NullType) BaseName() string                 { return "Null[" + n.Elem.QualifyRel(n.PkgPath) + "]" }
+func (n NullType) PgType() pg.Type                  { return n.PgTyp }
+
+func (d DomainType) QualifyRel(pkgPath string) string { return qualifyRel(d, pkgPath) }
+func (d DomainType) Import() string                   { return d.PkgPath }
+func (d DomainType) Package() string                  { return d.Pkg }
+func (d DomainType) BaseName() string                 { return d.Name }
+func (d DomainType) PgType() pg.Type                  { return d.PgDomain }
+
+func (r RangeType) QualifyRel(pkgPath string) string { return "*" + qualifyRel(r, pkgPath) }
+func (r RangeType) Import() string                   { return r.PkgPath }
+func (r RangeType) Package() string                  { return r.Pkg }
+func (r RangeType) BaseName() string                 { return r.Name }
+func (r RangeType) PgType() pg.Type                  { return r.PgRange }
+
+func (m MultirangeType) QualifyRel(pkgPath string) string { return qualifyRel(m, pkgPath) }
+func (m MultirangeType) Import() string                   { return m.PkgPath }
+func (m MultirangeType) Package() string                  { return m.Pkg }
+func (m MultirangeType) BaseName() string                 { return m.Name }
+func (m MultirangeType) PgType() pg.Type                  { return m.PgMultirange }
+
 func (c CompositeType) QualifyRel(pkgPath string) string { return "*" + qualifyRel(c, pkgPath) }
 func (c CompositeType) Import() string                   { return c.PkgPath }
 func (c CompositeType) Package() string                  { return c.Pkg }
@@ -183,6 +263,66 @@ func NewEnumType(pkgPath string, pgEnum pg.EnumType, caser casing.Caser) EnumTyp
 	}
 }
 
+// NewRangeType creates a struct type for the user-defined Postgres range with
+// bounds of type elem.
+func NewRangeType(pkgPath string, pgRange pg.RangeType, caser casing.Caser, elem Type) RangeType {
+	name := caser.ToUpperGoIdent(pgRange.Name)
+	if name == "" {
+		name = ChooseFallbackName(pgRange.Name, "UnnamedRange")
+	}
+	return RangeType{
+		PgRange: pgRange,
+		PkgPath: pkgPath,
+		Pkg:     ExtractShortPackage([]byte(pkgPath)),
+		Name:    name,
+		Elem:    elem,
+	}
+}
+
+// NewMultirangeType creates a slice type for the Postgres multirange with
+// elements of the range type elem.
+func NewMultirangeType(pgMultirange pg.MultirangeType, elem Type) MultirangeType {
+	return MultirangeType{
+		PgMultirange: pgMultirange,
+		PkgPath:      elem.Import(),
+		Pkg:          elem.Package(),
+		Name:         "[]" + elem.BaseName(),
+		Elem:         elem,
+	}
+}
+
+// NewDomainType creates a named Go type for the Postgres domain with the
+// underlying Go type elem. If nullable, the type is a pointer to the named
+// type.
+func NewDomainType(pkgPath string, pgDomain pg.DomainType, caser casing.Caser, elem Type, nullable bool) DomainType {
+	name := caser.ToUpperGoIdent(pgDomain.Name)
+	if name == "" {
+		name = ChooseFallbackName(pgDomain.Name, "UnnamedDomain")
+	}
+	if nullable {
+		name = "*" + name
+	}
+	return DomainType{
+		PgDomain: pgDomain,
+		PkgPath:  pkgPath,
+		Pkg:      ExtractShortPackage([]byte(pkgPath)),
+		Name:     name,
+		Elem:     elem,
+	}
+}
+
+// NewJSONType creates a JSONType for the Postgres json or jsonb type by parsing
+// the fully qualified Go type, with the same syntax as NewOpaqueType.
+func NewJSONType(pgt pg.Type, qualType string) JSONType {
+	opaque := NewOpaqueType(qualType)
+	return JSONType{
+		PgTyp:   pgt,
+		PkgPath: opaque.PkgPath,
+		Pkg:     opaque.Pkg,
+		Name:    opaque.Name,
+	}
+}
+
 // NewOpaqueType creates a OpaqueType by parsing the fully qualified Go type
 // like "github.com/leg100/pggen.GenerateOpts", or a builtin type like "string".
 // Supports slice and pointer types:
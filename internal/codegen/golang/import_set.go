@@ -24,12 +24,17 @@ func (s *ImportSet) AddPackage(p string) {
 // types.
 func (s *ImportSet) AddType(typ gotype.Type) {
 	s.AddPackage(typ.Import())
-	comp, ok := typ.(gotype.CompositeType)
-	if !ok {
-		return
-	}
-	for _, childType := range comp.FieldTypes {
-		s.AddType(childType)
+	switch typ := typ.(type) {
+	case gotype.CompositeType:
+		for _, childType := range typ.FieldTypes {
+			s.AddType(childType)
+		}
+	case gotype.NullType:
+		s.AddType(typ.Elem)
+	case gotype.RangeType:
+		s.AddType(typ.Elem)
+	case gotype.MultirangeType:
+		s.AddType(typ.Elem)
 	}
 }
 
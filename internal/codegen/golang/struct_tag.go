@@ -0,0 +1,140 @@
+package golang
+
+import (
+	"fmt"
+	"github.com/leg100/pggen/internal/casing"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TagCase is the case style for the name in a struct tag.
+type TagCase string
+
+const (
+	TagCasePg     TagCase = "pg"     // the Postgres name, unchanged, like "author_id"
+	TagCaseCamel  TagCase = "camel"  // lowerCamelCase, like "authorId"
+	TagCasePascal TagCase = "pascal" // UpperCamelCase, like "AuthorId"
+	TagCaseSnake  TagCase = "snake"  // snake_case, like "author_id"
+)
+
+// StructTag is a struct tag to emit on each field of the generated row,
+// param, and composite structs, like `json:"authorId,omitempty"`.
+type StructTag struct {
+	Key  string  // struct tag key, like "json" or "db"
+	Case TagCase // case style for the tag name; empty means TagCasePg
+	// If true, append ",omitempty" to the tag for fields of nullable columns.
+	OmitEmpty bool
+}
+
+// defaultStructTags are the tags for row and composite structs if no struct
+// tags are configured. Param structs have no tags by default.
+var defaultStructTags = []StructTag{{Key: "json"}}
+
+// tagCaser converts names for struct tags. We don't use the configured
+// acronyms because struct tag names are usually consumed outside of Go, like
+// as JSON keys.
+var tagCaser = casing.NewCaser()
+
+// ParseStructTag parses a struct tag config in the form
+// "key[:case][,omitempty]", like "db", "json:camel", or "json:camel,omitempty".
+func ParseStructTag(s string) (StructTag, error) {
+	tag := StructTag{}
+	keyCase := s
+	if idx := strings.IndexByte(s, ','); idx > -1 {
+		keyCase = s[:idx]
+		if opt := s[idx+1:]; opt != "omitempty" {
+			return StructTag{}, fmt.Errorf("struct tag %q: unknown option %q; want omitempty", s, opt)
+		}
+		tag.OmitEmpty = true
+	}
+	tag.Key = keyCase
+	if idx := strings.IndexByte(keyCase, ':'); idx > -1 {
+		tag.Key = keyCase[:idx]
+		tag.Case = TagCase(keyCase[idx+1:])
+		switch tag.Case {
+		case TagCasePg, TagCaseCamel, TagCasePascal, TagCaseSnake:
+		default:
+			return StructTag{}, fmt.Errorf("struct tag %q: unknown case %q; want one of pg, camel, pascal, or snake", s, tag.Case)
+		}
+	}
+	if tag.Key == "" {
+		return StructTag{}, fmt.Errorf("struct tag %q: empty key", s)
+	}
+	for _, ch := range tag.Key {
+		// Same as reflect.StructTag.Lookup: a key is non-control chars other
+		// than space, quote, and colon.
+		if ch <= ' ' || ch == '"' || ch == ':' || ch == 0x7f {
+			return StructTag{}, fmt.Errorf("struct tag %q: invalid key %q", s, tag.Key)
+		}
+	}
+	return tag, nil
+}
+
+// emitStructTags emits the struct tags for a field, like
+// `json:"author_id" db:"author_id"`, or the empty string if there are no
+// tags. The name is the Postgres name of the field. If the name is empty, like
+// for an unnamed param, uses goName.
+func emitStructTags(tags []StructTag, name, goName string, nullable bool) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	if name == "" {
+		name = goName
+	}
+	sb := &strings.Builder{}
+	sb.WriteRune('`')
+	for i, tag := range tags {
+		if i > 0 {
+			sb.WriteRune(' ')
+		}
+		value := tag.caseName(name)
+		if tag.OmitEmpty && nullable {
+			value += ",omitempty"
+		}
+		sb.WriteString(tag.Key)
+		sb.WriteRune(':')
+		sb.WriteString(strconv.Quote(value))
+	}
+	sb.WriteRune('`')
+	return sb.String()
+}
+
+// caseName converts the Postgres name to the case style of the tag.
+func (t StructTag) caseName(name string) string {
+	switch t.Case {
+	case TagCaseCamel:
+		upper := tagCaser.ToUpperGoIdent(name)
+		if upper == "" {
+			return name
+		}
+		first, size := utf8.DecodeRuneInString(upper)
+		return string(unicode.ToLower(first)) + upper[size:]
+	case TagCasePascal:
+		if upper := tagCaser.ToUpperGoIdent(name); upper != "" {
+			return upper
+		}
+		return name
+	case TagCaseSnake:
+		return toSnakeCase(name)
+	default:
+		return name
+	}
+}
+
+// toSnakeCase converts a name like "FirstName" or "first_name" to
+// "first_name".
+func toSnakeCase(name string) string {
+	sb := &strings.Builder{}
+	sb.Grow(len(name) + 4)
+	prev := rune(0)
+	for _, ch := range name {
+		if unicode.IsUpper(ch) && (unicode.IsLower(prev) || unicode.IsDigit(prev)) {
+			sb.WriteRune('_')
+		}
+		sb.WriteRune(unicode.ToLower(ch))
+		prev = ch
+	}
+	return sb.String()
+}
@@ -0,0 +1,73 @@
+package golang
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStructTag(t *testing.T) {
+	tests := []struct {
+		give    string
+		want    StructTag
+		wantErr string
+	}{
+		{give: "db", want: StructTag{Key: "db"}},
+		{give: "json:camel", want: StructTag{Key: "json", Case: TagCaseCamel}},
+		{give: "json:pg,omitempty", want: StructTag{Key: "json", Case: TagCasePg, OmitEmpty: true}},
+		{give: "yaml,omitempty", want: StructTag{Key: "yaml", OmitEmpty: true}},
+		{give: "", wantErr: "empty key"},
+		{give: ":camel", wantErr: "empty key"},
+		{give: "json:kebab", wantErr: `unknown case "kebab"`},
+		{give: "json,string", wantErr: `unknown option "string"`},
+		{give: "a b", wantErr: `invalid key "a b"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.give, func(t *testing.T) {
+			got, err := ParseStructTag(tt.give)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseStructTag(%q) error %v; want substring %q", tt.give, err, tt.wantErr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEmitStructTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     []StructTag
+		pgName   string
+		nullable bool
+		want     string
+	}{
+		{"none", nil, "author_id", false, ""},
+		{"pg", []StructTag{{Key: "db"}}, "author_id", false, "`db:\"author_id\"`"},
+		{"camel", []StructTag{{Key: "json", Case: TagCaseCamel}}, "author_id", false, "`json:\"authorId\"`"},
+		{"camel pascal input", []StructTag{{Key: "json", Case: TagCaseCamel}}, "FirstName", false, "`json:\"firstName\"`"},
+		{"pascal", []StructTag{{Key: "json", Case: TagCasePascal}}, "author_id", false, "`json:\"AuthorId\"`"},
+		{"snake", []StructTag{{Key: "json", Case: TagCaseSnake}}, "FirstName", false, "`json:\"first_name\"`"},
+		{"snake acronym", []StructTag{{Key: "json", Case: TagCaseSnake}}, "AuthorID", false, "`json:\"author_id\"`"},
+		{"omitempty not null", []StructTag{{Key: "json", OmitEmpty: true}}, "name", false, "`json:\"name\"`"},
+		{"omitempty nullable", []StructTag{{Key: "json", OmitEmpty: true}}, "name", true, "`json:\"name,omitempty\"`"},
+		{"empty name", []StructTag{{Key: "db"}}, "", false, "`db:\"goName\"`"},
+		{
+			name:     "multiple",
+			tags:     []StructTag{{Key: "json", Case: TagCaseCamel, OmitEmpty: true}, {Key: "db"}},
+			pgName:   "author_id",
+			nullable: true,
+			want:     "`json:\"authorId,omitempty\" db:\"author_id\"`",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := emitStructTags(tt.tags, tt.pgName, "goName", tt.nullable)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/leg100/pggen/internal/ast"
 	"github.com/leg100/pggen/internal/codegen/golang/gotype"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
@@ -12,10 +13,31 @@ import (
 // files do not necessarily reside in the same directory.
 type TemplatedPackage struct {
 	Files []TemplatedFile // sorted lexicographically by path
+	// True if the leader should define a ReadQuerier interface with only the
+	// queries that don't modify tables.
+	ReadQuerier bool
+}
+
+// ReadOnlyQueries returns the queries in all files that don't modify tables or
+// lock rows.
+func (tp TemplatedPackage) ReadOnlyQueries() []TemplatedQuery {
+	var queries []TemplatedQuery
+	for _, file := range tp.Files {
+		for _, query := range file.Queries {
+			if query.ReadOnly {
+				queries = append(queries, query)
+			}
+		}
+	}
+	return queries
 }
 
 // TemplatedFile is the Go version of a SQL query file with all information
 // needed to execute the codegen template.
+//
+// TemplatedFile is also the data for custom templates from --go-template, so
+// the exported fields and methods of TemplatedFile, TemplatedQuery,
+// TemplatedParam, and TemplatedColumn are a stable API. Only add to them.
 type TemplatedFile struct {
 	Pkg        TemplatedPackage // the parent package containing this file
 	PkgPath    string           // full package path, like "github.com/foo/bar"
@@ -28,6 +50,13 @@ type TemplatedFile struct {
 	IsLeader bool
 	// Any declarations this file should declare. Only set on leader.
 	Declarers []Declarer
+	// Name of the interface for the queries in this file, like
+	// CustomerQuerier for customer.sql. The Querier interface embeds the
+	// interface of each file. Empty if the package has only one file.
+	QuerierName string
+	// The Postgres server version used to infer types, like "15.4". Empty if
+	// unknown or if the version depends on an existing database.
+	PostgresVersion string
 }
 
 // TemplatedQuery is a query with all information required to execute the
@@ -35,18 +64,41 @@ type TemplatedFile struct {
 type TemplatedQuery struct {
 	Name        string            // name of the query, from the comment preceding the query
 	SQLVarName  string            // name of the string variable containing the SQL
+	StmtVarName string            // name of the string variable containing the prepared statement name
+	StmtName    string            // deterministic name of the prepared statement, like "pggen_FindAuthors_8c3f0a5e1b2d4c6f"
 	ResultKind  ast.ResultKind    // kind of result: :one, :many, or :exec
 	Doc         string            // doc from the source query file, formatted for Go
 	PreparedSQL string            // SQL query, ready to run with PREPARE statement
 	Inputs      []TemplatedParam  // input parameters to the query
 	Outputs     []TemplatedColumn // output columns of the query
+	Keyset      *TemplatedKeyset  // keyset pagination for the query, if any
+	ReadOnly    bool              // true if the query doesn't modify tables or lock rows
+	OnReplica   bool              // true if a routing querier runs the query on the replica
+	RowType     string            // name of the output row struct, like FindAuthorsRow, or the row type from the row-type pragma
+	// True if another query with the same row type declares the row struct.
+	sharesRow bool
+	// Struct tags for the row and param structs. If nil, the row struct has
+	// defaultStructTags and the param struct has no tags.
+	structTags []StructTag
 }
 
 type TemplatedParam struct {
+	PgName    string // original name of the param, like 'FirstName' from pggen.arg('FirstName')
 	UpperName string // name of the param in UpperCamelCase, like 'FirstName' from pggen.arg('FirstName')
 	LowerName string // name of the param in lowerCamelCase, like 'firstName' from pggen.arg('FirstName')
 	QualType  string // package-qualified Go type to use for this param
 	Type      gotype.Type
+	Nullable  bool // true if the Go type can represent null, like a pointer
+	// Keyset is set if the param is the opaque keyset pagination cursor. The
+	// cursor expands into multiple query params.
+	Keyset *TemplatedKeyset
+}
+
+// TemplatedKeyset is the keyset pagination for a :many query with the
+// paginate=keyset pragma.
+type TemplatedKeyset struct {
+	CursorType string            // name of the opaque cursor type, like "FindAuthorsCursor"
+	Columns    []TemplatedColumn // the ORDER BY output columns in sort order
 }
 
 type TemplatedColumn struct {
@@ -55,6 +107,13 @@ type TemplatedColumn struct {
 	LowerName string // name in Go-style (lowerCamelCase)
 	Type      gotype.Type
 	QualType  string // package qualified Go type to use for the column, like "pgtype.Text"
+	Nullable  bool   // true if the column might be null
+}
+
+// SourceBase returns the file name of the source SQL file, like
+// "customer.sql".
+func (tf TemplatedFile) SourceBase() string {
+	return filepath.Base(tf.SourcePath)
 }
 
 func (tf TemplatedFile) needsPgconnImport() bool {
@@ -99,14 +158,41 @@ func (tq TemplatedQuery) EmitParams() string {
 	}
 }
 
-func getLongestInput(inputs []TemplatedParam) int {
-	max := 0
+// EmitBatchParams emits the TemplatedQuery.Inputs into method parameters
+// without a leading comma. For use in a typed batch builder method definition.
+func (tq TemplatedQuery) EmitBatchParams() string {
+	return strings.TrimPrefix(tq.EmitParams(), ", ")
+}
+
+// EmitBatchArgs emits the TemplatedQuery.Inputs into comma separated names
+// that forward the params of a typed batch builder method to the Batch method.
+func (tq TemplatedQuery) EmitBatchArgs() string {
+	switch len(tq.Inputs) {
+	case 0:
+		return ""
+	case 1, 2:
+		sb := strings.Builder{}
+		for _, input := range tq.Inputs {
+			sb.WriteString(", ")
+			sb.WriteString(input.LowerName)
+		}
+		return sb.String()
+	default:
+		return ", params"
+	}
+}
+
+func getLongestInput(inputs []TemplatedParam) (int, int) {
+	nameLen, typeLen := 0, 0
 	for _, in := range inputs {
-		if len(in.UpperName) > max {
-			max = len(in.UpperName)
+		if len(in.UpperName) > nameLen {
+			nameLen = len(in.UpperName)
+		}
+		if len(in.QualType) > typeLen {
+			typeLen = len(in.QualType)
 		}
 	}
-	return max
+	return nameLen, typeLen
 }
 
 // EmitParamStruct emits the struct definition for query params if needed.
@@ -118,18 +204,32 @@ func (tq TemplatedQuery) EmitParamStruct() string {
 	sb.WriteString("\n\ntype ")
 	sb.WriteString(tq.Name)
 	sb.WriteString("Params struct {\n")
-	typeCol := getLongestInput(tq.Inputs) + 1 // 1 space
-	for _, out := range tq.Inputs {
+	nameLen, typeLen := getLongestInput(tq.Inputs)
+	typeCol, tagCol := nameLen+1, typeLen+1 // 1 space
+	for _, in := range tq.Inputs {
 		sb.WriteString("\t")
-		sb.WriteString(out.UpperName)
-		sb.WriteString(strings.Repeat(" ", typeCol-len(out.UpperName)))
-		sb.WriteString(out.QualType)
+		sb.WriteString(in.UpperName)
+		sb.WriteString(strings.Repeat(" ", typeCol-len(in.UpperName)))
+		sb.WriteString(in.QualType)
+		if tags := emitStructTags(tq.structTags, in.PgName, in.LowerName, in.Nullable); tags != "" {
+			sb.WriteString(strings.Repeat(" ", tagCol-len(in.QualType)))
+			sb.WriteString(tags)
+		}
 		sb.WriteRune('\n')
 	}
 	sb.WriteString("}")
 	return sb.String()
 }
 
+// EmitConn emits the expression for the Postgres transport that runs the
+// query.
+func (tq TemplatedQuery) EmitConn() string {
+	if tq.OnReplica {
+		return "q.readConn()"
+	}
+	return "q.conn"
+}
+
 // EmitParamNames emits the TemplatedQuery.Inputs into comma separated names
 // for use in a method invocation.
 func (tq TemplatedQuery) EmitParamNames() string {
@@ -147,10 +247,38 @@ func (tq TemplatedQuery) EmitParamNames() string {
 			sb.WriteString("(")
 			sb.WriteString(name)
 			sb.WriteString(")")
+		case gotype.RangeType:
+			sb.WriteString("q.types.")
+			sb.WriteString(NameRangeInitFunc(typ))
+			sb.WriteString("(")
+			sb.WriteString(name)
+			sb.WriteString(")")
+		case gotype.MultirangeType:
+			sb.WriteString("q.types.")
+			sb.WriteString(NameMultirangeInitFunc(typ))
+			sb.WriteString("(")
+			sb.WriteString(name)
+			sb.WriteString(")")
 		default:
 			sb.WriteString(name)
 		}
 	}
+	appendInput := func(sb *strings.Builder, input TemplatedParam, name string) {
+		if input.Keyset == nil {
+			appendParam(sb, input.Type, name)
+			return
+		}
+		// Expand the cursor into the params for the cursor set flag and the
+		// value of each sort column.
+		sb.WriteString(name)
+		sb.WriteString(".isSet")
+		for _, col := range input.Keyset.Columns {
+			sb.WriteString(", ")
+			sb.WriteString(name)
+			sb.WriteRune('.')
+			sb.WriteString(col.LowerName)
+		}
+	}
 	switch len(tq.Inputs) {
 	case 0:
 		return ""
@@ -158,14 +286,14 @@ func (tq TemplatedQuery) EmitParamNames() string {
 		sb := &strings.Builder{}
 		for _, input := range tq.Inputs {
 			sb.WriteString(", ")
-			appendParam(sb, input.Type, input.LowerName)
+			appendInput(sb, input, input.LowerName)
 		}
 		return sb.String()
 	default:
 		sb := &strings.Builder{}
 		for _, input := range tq.Inputs {
 			sb.WriteString(", ")
-			appendParam(sb, input.Type, "params."+input.UpperName)
+			appendInput(sb, input, "params."+input.UpperName)
 		}
 		return sb.String()
 	}
@@ -206,10 +334,22 @@ func (tq TemplatedQuery) EmitRowScanArgs() (string, error) {
 			sb.WriteString(out.LowerName)
 			sb.WriteString("Row")
 
+		case gotype.RangeType:
+			sb.WriteString(out.LowerName)
+			sb.WriteString("Range")
+
+		case gotype.MultirangeType:
+			sb.WriteString(out.LowerName)
+			sb.WriteString("Multirange")
+
+		case gotype.JSONType:
+			sb.WriteString(out.LowerName)
+			sb.WriteString("JSON")
+
 		case gotype.VoidType:
 			sb.WriteString("nil")
 
-		case gotype.EnumType, gotype.OpaqueType:
+		case gotype.EnumType, gotype.OpaqueType, gotype.NullType, gotype.DomainType:
 			if hasOnlyOneNonVoid {
 				sb.WriteString("&item")
 			} else {
@@ -241,7 +381,7 @@ func (tq TemplatedQuery) EmitResultType() (string, error) {
 		case 1:
 			return "[]" + outs[0].QualType, nil
 		default:
-			return "[]" + tq.Name + "Row", nil
+			return "[]" + tq.RowType, nil
 		}
 	case ast.ResultKindOne:
 		switch len(outs) {
@@ -250,7 +390,7 @@ func (tq TemplatedQuery) EmitResultType() (string, error) {
 		case 1:
 			return outs[0].QualType, nil
 		default:
-			return tq.Name + "Row", nil
+			return tq.RowType, nil
 		}
 	default:
 		return "", fmt.Errorf("unhandled EmitResultType kind: %s", tq.ResultKind)
@@ -301,6 +441,30 @@ func (tq TemplatedQuery) EmitResultDecoders() (string, error) {
 				sb.WriteString(NameArrayTranscoderFunc(typ))
 				sb.WriteString("()")
 			}
+		case gotype.RangeType:
+			sb.WriteString(indent)
+			sb.WriteString(out.LowerName)
+			sb.WriteString("Range := q.types.")
+			sb.WriteString(NameRangeTranscoderFunc(typ))
+			sb.WriteString("()")
+		case gotype.MultirangeType:
+			sb.WriteString(indent)
+			sb.WriteString(out.LowerName)
+			sb.WriteString("Multirange := q.types.")
+			sb.WriteString(NameMultirangeTranscoderFunc(typ))
+			sb.WriteString("()")
+		case gotype.JSONType:
+			// Decode into the pgtype JSON type and unmarshal in
+			// EmitResultAssigns so errors include the column name.
+			decoderType, ok := gotype.FindKnownTypePgx(typ.PgTyp.OID())
+			if !ok {
+				return "", fmt.Errorf("no pgtype decoder for json type %s", typ.PgTyp.String())
+			}
+			sb.WriteString(indent)
+			sb.WriteString(out.LowerName)
+			sb.WriteString("JSON := &")
+			sb.WriteString(decoderType.QualifyRel(""))
+			sb.WriteString("{}")
 		default:
 			continue
 		}
@@ -315,6 +479,9 @@ func (tq TemplatedQuery) EmitResultDecoders() (string, error) {
 // output struct.
 //
 // Copies pgtype.EnumArray fields into Go enum array types.
+//
+// Unmarshals pgtype JSON fields into the user-provided Go type of a json-type
+// column.
 func (tq TemplatedQuery) EmitResultAssigns(zeroVal string) (string, error) {
 	sb := &strings.Builder{}
 	indent := "\n\t"
@@ -362,6 +529,48 @@ func (tq TemplatedQuery) EmitResultAssigns(zeroVal string) (string, error) {
 				sb.WriteString(indent)
 				sb.WriteString("}")
 			}
+		case gotype.RangeType, gotype.MultirangeType:
+			suffix := "Range"
+			if _, ok := typ.(gotype.MultirangeType); ok {
+				suffix = "Multirange"
+			}
+			sb.WriteString(indent)
+			sb.WriteString("if err := ")
+			sb.WriteString(out.LowerName)
+			sb.WriteString(suffix)
+			sb.WriteString(".AssignTo(&item")
+			if len(removeVoidColumns(tq.Outputs)) > 1 {
+				sb.WriteRune('.')
+				sb.WriteString(out.UpperName)
+			}
+			sb.WriteString("); err != nil {")
+			sb.WriteString(indent)
+			sb.WriteString("\treturn ")
+			sb.WriteString(zeroVal)
+			sb.WriteString(", fmt.Errorf(\"assign ")
+			sb.WriteString(tq.Name)
+			sb.WriteString(" row: %w\", err)")
+			sb.WriteString(indent)
+			sb.WriteString("}")
+		case gotype.JSONType:
+			sb.WriteString(indent)
+			sb.WriteString("if err := ")
+			sb.WriteString(out.LowerName)
+			sb.WriteString("JSON.AssignTo(&item")
+			if len(removeVoidColumns(tq.Outputs)) > 1 {
+				sb.WriteRune('.')
+				sb.WriteString(out.UpperName)
+			}
+			sb.WriteString("); err != nil {")
+			sb.WriteString(indent)
+			sb.WriteString("\treturn ")
+			sb.WriteString(zeroVal)
+			sb.WriteString(", fmt.Errorf(")
+			colName := strings.ReplaceAll(out.PgName, "%", "%%")
+			sb.WriteString(strconv.Quote("unmarshal " + tq.Name + " column " + colName + ": %w"))
+			sb.WriteString(", err)")
+			sb.WriteString(indent)
+			sb.WriteString("}")
 		}
 	}
 	return sb.String(), nil
@@ -434,11 +643,18 @@ func (tq TemplatedQuery) EmitRowStruct() string {
 		if len(outs) <= 1 {
 			return "" // if there's only 1 output column, return it directly
 		}
+		if tq.sharesRow {
+			return ""
+		}
 		sb := &strings.Builder{}
 		sb.WriteString("\n\ntype ")
-		sb.WriteString(tq.Name)
-		sb.WriteString("Row struct {\n")
+		sb.WriteString(tq.RowType)
+		sb.WriteString(" struct {\n")
 		maxNameLen, maxTypeLen := getLongestOutput(outs)
+		structTags := tq.structTags
+		if structTags == nil {
+			structTags = defaultStructTags
+		}
 		for _, out := range outs {
 			// Name
 			sb.WriteString("\t")
@@ -446,11 +662,11 @@ func (tq TemplatedQuery) EmitRowStruct() string {
 			// Type
 			sb.WriteString(strings.Repeat(" ", maxNameLen-len(out.UpperName)))
 			sb.WriteString(out.QualType)
-			// JSON struct tag
-			sb.WriteString(strings.Repeat(" ", maxTypeLen-len(out.QualType)))
-			sb.WriteString("`json:")
-			sb.WriteString(strconv.Quote(out.PgName))
-			sb.WriteString("`")
+			// Struct tags
+			if tags := emitStructTags(structTags, out.PgName, out.LowerName, out.Nullable); tags != "" {
+				sb.WriteString(strings.Repeat(" ", maxTypeLen-len(out.QualType)))
+				sb.WriteString(tags)
+			}
 			sb.WriteRune('\n')
 		}
 		sb.WriteString("}")
@@ -460,6 +676,121 @@ func (tq TemplatedQuery) EmitRowStruct() string {
 	}
 }
 
+// EmitKeysetCursor emits the opaque cursor type and the NextCursor helper for
+// a query with keyset pagination.
+func (tq TemplatedQuery) EmitKeysetCursor() (string, error) {
+	if tq.Keyset == nil {
+		return "", nil
+	}
+	rowsType, err := tq.EmitResultType()
+	if err != nil {
+		return "", fmt.Errorf("emit keyset cursor: %w", err)
+	}
+	isRowStruct := len(removeVoidColumns(tq.Outputs)) > 1
+	cursor := tq.Keyset.CursorType
+	cols := tq.Keyset.Columns
+	sb := &strings.Builder{}
+
+	// Cursor type.
+	sb.WriteString("\n\n// ")
+	sb.WriteString(cursor)
+	sb.WriteString(" is an opaque cursor for keyset pagination.\n")
+	sb.WriteString("// The zero value starts at the first page. Use MarshalText to encode the\n")
+	sb.WriteString("// cursor as a URL-safe string.\n")
+	sb.WriteString("type ")
+	sb.WriteString(cursor)
+	sb.WriteString(" struct {\n")
+	nameLen := len("isSet")
+	for _, col := range cols {
+		if len(col.LowerName) > nameLen {
+			nameLen = len(col.LowerName)
+		}
+	}
+	sb.WriteString("\tisSet")
+	sb.WriteString(strings.Repeat(" ", nameLen-len("isSet")+1))
+	sb.WriteString("bool\n")
+	for _, col := range cols {
+		sb.WriteString("\t")
+		sb.WriteString(col.LowerName)
+		sb.WriteString(strings.Repeat(" ", nameLen-len(col.LowerName)+1))
+		sb.WriteString(col.QualType)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("}")
+
+	// MarshalText.
+	sb.WriteString("\n\n// MarshalText implements encoding.TextMarshaler.\n")
+	sb.WriteString("func (c " + cursor + ") MarshalText() ([]byte, error) {\n")
+	sb.WriteString("\tif !c.isSet {\n")
+	sb.WriteString("\t\treturn []byte{}, nil\n")
+	sb.WriteString("\t}\n")
+	sb.WriteString("\tkeys, err := json.Marshal([]interface{}{")
+	for i, col := range cols {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("c.")
+		sb.WriteString(col.LowerName)
+	}
+	sb.WriteString("})\n")
+	sb.WriteString("\tif err != nil {\n")
+	sb.WriteString("\t\treturn nil, fmt.Errorf(\"marshal " + cursor + ": %w\", err)\n")
+	sb.WriteString("\t}\n")
+	sb.WriteString("\treturn []byte(base64.RawURLEncoding.EncodeToString(keys)), nil\n")
+	sb.WriteString("}")
+
+	// UnmarshalText.
+	sb.WriteString("\n\n// UnmarshalText implements encoding.TextUnmarshaler.\n")
+	sb.WriteString("func (c *" + cursor + ") UnmarshalText(text []byte) error {\n")
+	sb.WriteString("\tif len(text) == 0 {\n")
+	sb.WriteString("\t\t*c = " + cursor + "{}\n")
+	sb.WriteString("\t\treturn nil\n")
+	sb.WriteString("\t}\n")
+	sb.WriteString("\tkeys, err := base64.RawURLEncoding.DecodeString(string(text))\n")
+	sb.WriteString("\tif err != nil {\n")
+	sb.WriteString("\t\treturn fmt.Errorf(\"unmarshal " + cursor + ": %w\", err)\n")
+	sb.WriteString("\t}\n")
+	sb.WriteString("\tvar raws []json.RawMessage\n")
+	sb.WriteString("\tif err := json.Unmarshal(keys, &raws); err != nil {\n")
+	sb.WriteString("\t\treturn fmt.Errorf(\"unmarshal " + cursor + ": %w\", err)\n")
+	sb.WriteString("\t}\n")
+	sb.WriteString("\tif len(raws) != " + strconv.Itoa(len(cols)) + " {\n")
+	sb.WriteString("\t\treturn fmt.Errorf(\"unmarshal " + cursor + ": want " + strconv.Itoa(len(cols)) + " key(s); got %d\", len(raws))\n")
+	sb.WriteString("\t}\n")
+	sb.WriteString("\tcursor := " + cursor + "{isSet: true}\n")
+	for i, col := range cols {
+		sb.WriteString("\tif err := json.Unmarshal(raws[" + strconv.Itoa(i) + "], &cursor." + col.LowerName + "); err != nil {\n")
+		sb.WriteString("\t\treturn fmt.Errorf(\"unmarshal " + cursor + " key " + col.PgName + ": %w\", err)\n")
+		sb.WriteString("\t}\n")
+	}
+	sb.WriteString("\t*c = cursor\n")
+	sb.WriteString("\treturn nil\n")
+	sb.WriteString("}")
+
+	// NextCursor.
+	sb.WriteString("\n\n// " + tq.Name + "NextCursor returns the cursor for the page after rows.\n")
+	sb.WriteString("// Returns false if rows is empty.\n")
+	sb.WriteString("func " + tq.Name + "NextCursor(rows " + rowsType + ") (" + cursor + ", bool) {\n")
+	sb.WriteString("\tif len(rows) == 0 {\n")
+	sb.WriteString("\t\treturn " + cursor + "{}, false\n")
+	sb.WriteString("\t}\n")
+	sb.WriteString("\tlast := rows[len(rows)-1]\n")
+	sb.WriteString("\treturn " + cursor + "{\n")
+	sb.WriteString("\t\tisSet:" + strings.Repeat(" ", nameLen-len("isSet")+1) + "true,\n")
+	for _, col := range cols {
+		sb.WriteString("\t\t" + col.LowerName + ":" + strings.Repeat(" ", nameLen-len(col.LowerName)+1))
+		if isRowStruct {
+			sb.WriteString("last." + col.UpperName)
+		} else {
+			sb.WriteString("last")
+		}
+		sb.WriteString(",\n")
+	}
+	sb.WriteString("\t}, true\n")
+	sb.WriteString("}")
+	return sb.String(), nil
+}
+
 // removeVoidColumns makes a copy of cols with all VoidType columns removed.
 // Useful because return types shouldn't contain the void type but we need
 // to use a nil placeholder for void types when scanning a pgx.Row.
@@ -1,10 +1,17 @@
 package golang
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"github.com/leg100/pggen/internal/ast"
 	"github.com/leg100/pggen/internal/casing"
 	"github.com/leg100/pggen/internal/codegen"
+	"github.com/leg100/pggen/internal/codegen/golang/gotype"
 	"github.com/leg100/pggen/internal/gomod"
+	"github.com/leg100/pggen/internal/pg"
+	"github.com/leg100/pggen/internal/pginfer"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"unicode"
@@ -12,9 +19,10 @@ import (
 
 // Templater creates query file templates.
 type Templater struct {
-	caser    casing.Caser
-	resolver TypeResolver
-	pkg      string // Go package name
+	caser      casing.Caser
+	resolver   TypeResolver
+	pkg        string      // Go package name
+	structTags []StructTag // if nil, use the default struct tags
 }
 
 // TemplaterOpts is options to control the template logic.
@@ -22,13 +30,18 @@ type TemplaterOpts struct {
 	Caser    casing.Caser
 	Resolver TypeResolver
 	Pkg      string // Go package name
+	// Struct tags for the row, param, and composite structs. If nil, row and
+	// composite structs have a json tag with the Postgres name and param
+	// structs have no tags.
+	StructTags []StructTag
 }
 
 func NewTemplater(opts TemplaterOpts) Templater {
 	return Templater{
-		pkg:      opts.Pkg,
-		caser:    opts.Caser,
-		resolver: opts.Resolver,
+		pkg:        opts.Pkg,
+		caser:      opts.Caser,
+		resolver:   opts.Resolver,
+		structTags: opts.StructTags,
 	}
 }
 
@@ -58,7 +71,35 @@ func (tm Templater) TemplateAll(files []codegen.QueryFile) ([]TemplatedFile, err
 	}
 
 	// Add declarers to leader file.
-	goQueryFiles[firstIndex].Declarers = allDeclarers.ListAll()
+	decls := allDeclarers.ListAll()
+	if tm.structTags != nil {
+		for i, decl := range decls {
+			if d, ok := decl.(CompositeTypeDeclarer); ok {
+				decls[i] = d.WithStructTags(tm.structTags)
+			}
+		}
+	}
+	goQueryFiles[firstIndex].Declarers = decls
+	leaderImports := NewImportSet()
+	for _, pkg := range goQueryFiles[firstIndex].Imports {
+		leaderImports.AddPackage(pkg)
+	}
+	for key, pkgs := range declarerImports {
+		if _, ok := allDeclarers[key]; ok {
+			for _, pkg := range pkgs {
+				leaderImports.AddPackage(pkg)
+			}
+		}
+	}
+	goQueryFiles[firstIndex].Imports = leaderImports.SortedPackages()
+
+	tm.nameQueriers(goQueryFiles)
+	if err := shareRowTypes(goQueryFiles); err != nil {
+		return nil, err
+	}
+	if err := checkGeneratedNames(goQueryFiles, decls); err != nil {
+		return nil, err
+	}
 
 	// Remove unneeded pgconn import if possible.
 	for i, file := range goQueryFiles {
@@ -110,7 +151,9 @@ func (tm Templater) templateFile(file codegen.QueryFile, isLeader bool) (Templat
 	imports.AddPackage("fmt")
 	imports.AddPackage("github.com/jackc/pgconn")
 	if isLeader {
+		imports.AddPackage("errors")
 		imports.AddPackage("github.com/jackc/pgtype")
+		imports.AddPackage("time")
 	}
 	imports.AddPackage("github.com/jackc/pgx/v4")
 
@@ -143,16 +186,18 @@ func (tm Templater) templateFile(file codegen.QueryFile, isLeader bool) (Templat
 		// Build inputs.
 		inputs := make([]TemplatedParam, len(query.Inputs))
 		for i, input := range query.Inputs {
-			goType, err := tm.resolver.Resolve(input.PgType /*nullable*/, false, pkgPath)
+			goType, err := tm.resolveQueryType(query, input.PgName, input.TableColumn, input.PgType /*nullable*/, false, pkgPath)
 			if err != nil {
 				return TemplatedFile{}, nil, err
 			}
 			imports.AddType(goType)
 			inputs[i] = TemplatedParam{
+				PgName:    input.PgName,
 				UpperName: tm.chooseUpperName(input.PgName, "UnnamedParam", i, len(query.Inputs)),
 				LowerName: tm.chooseLowerName(input.PgName, "unnamedParam", i, len(query.Inputs)),
 				QualType:  goType.QualifyRel(pkgPath),
 				Type:      goType,
+				Nullable:  gotype.IsPointer(goType),
 			}
 			ds := FindInputDeclarers(goType).ListAll()
 			declarers.AddAll(ds...)
@@ -161,30 +206,74 @@ func (tm Templater) templateFile(file codegen.QueryFile, isLeader bool) (Templat
 		// Build outputs.
 		outputs := make([]TemplatedColumn, len(query.Outputs))
 		for i, out := range query.Outputs {
-			goType, err := tm.resolver.Resolve(out.PgType, out.Nullable, pkgPath)
+			goType, err := tm.resolveQueryType(query, out.PgName, out.TableColumn, out.PgType, out.Nullable, pkgPath)
 			if err != nil {
 				return TemplatedFile{}, nil, err
 			}
 			imports.AddType(goType)
+			if _, ok := goType.(gotype.JSONType); ok {
+				imports.AddPackage("github.com/jackc/pgtype") // to decode the JSON
+			}
 			outputs[i] = TemplatedColumn{
 				PgName:    out.PgName,
 				UpperName: tm.chooseUpperName(out.PgName, "UnnamedColumn", i, len(query.Outputs)),
 				LowerName: tm.chooseLowerName(out.PgName, "UnnamedColumn", i, len(query.Outputs)),
 				Type:      goType,
 				QualType:  goType.QualifyRel(pkgPath),
+				Nullable:  out.Nullable,
 			}
 			ds := FindOutputDeclarers(goType).ListAll()
 			declarers.AddAll(ds...)
 		}
 
+		if err := checkJSONTypeNames(query); err != nil {
+			return TemplatedFile{}, nil, err
+		}
+
+		// Collapse the keyset pagination params into an opaque cursor param
+		// followed by the limit param.
+		var keyset *TemplatedKeyset
+		if query.Keyset != nil {
+			ks, err := tm.templateKeyset(query, outputs)
+			if err != nil {
+				return TemplatedFile{}, nil, err
+			}
+			keyset = ks
+			numParams := len(query.Keyset.SortColumns) + 2
+			limit := inputs[len(inputs)-1]
+			limit.PgName, limit.UpperName, limit.LowerName = "limit", "Limit", "limit"
+			cursor := TemplatedParam{
+				PgName:    "cursor",
+				UpperName: "Cursor",
+				LowerName: "cursor",
+				QualType:  keyset.CursorType,
+				Type:      gotype.NewOpaqueType(keyset.CursorType),
+				Keyset:    keyset,
+			}
+			inputs = append(inputs[:len(inputs)-numParams], cursor, limit)
+			imports.AddPackage("encoding/base64")
+			imports.AddPackage("encoding/json")
+		}
+
+		rowType := query.RowType
+		if rowType == "" {
+			rowType = tm.caser.ToUpperGoIdent(query.Name) + "Row"
+		}
 		queries = append(queries, TemplatedQuery{
 			Name:        tm.caser.ToUpperGoIdent(query.Name),
 			SQLVarName:  tm.caser.ToLowerGoIdent(query.Name) + "SQL",
+			StmtVarName: tm.caser.ToLowerGoIdent(query.Name) + "Stmt",
+			StmtName:    nameStatement(tm.caser.ToUpperGoIdent(query.Name), query.PreparedSQL),
 			ResultKind:  query.ResultKind,
 			Doc:         docs.String(),
 			PreparedSQL: query.PreparedSQL,
 			Inputs:      inputs,
 			Outputs:     outputs,
+			Keyset:      keyset,
+			ReadOnly:    query.ReadOnly,
+			OnReplica:   query.ReadOnly && query.Route != ast.RoutePrimary,
+			RowType:     rowType,
+			structTags:  tm.structTags,
 		})
 	}
 
@@ -198,6 +287,320 @@ func (tm Templater) templateFile(file codegen.QueryFile, isLeader bool) (Templat
 	}, declarers, nil
 }
 
+// resolveQueryType resolves the Go type for a param or output column of a
+// query, using the Go type from the json-type pragma for the name if present.
+// Otherwise, uses the override for the schema-qualified table column, like
+// "public.users.id", that the param or output column comes from, if any.
+func (tm Templater) resolveQueryType(query pginfer.TypedQuery, name string, column string, pgt pg.Type, nullable bool, pkgPath string) (gotype.Type, error) {
+	jsonType, ok := query.JSONTypes[name]
+	if !ok {
+		return tm.resolver.ResolveColumn(column, pgt, nullable, pkgPath)
+	}
+	typ, err := tm.resolver.ResolveJSON(pgt, nullable, jsonType)
+	if err != nil {
+		return nil, fmt.Errorf("query %s json-type for %s: %w", query.Name, name, err)
+	}
+	return typ, nil
+}
+
+// checkJSONTypeNames checks that each name in the json-type pragma of a query
+// is a param or output column, to catch typos.
+func checkJSONTypeNames(query pginfer.TypedQuery) error {
+	names := make(map[string]struct{}, len(query.Inputs)+len(query.Outputs))
+	for _, input := range query.Inputs {
+		names[input.PgName] = struct{}{}
+	}
+	for _, out := range query.Outputs {
+		names[out.PgName] = struct{}{}
+	}
+	for name := range query.JSONTypes {
+		if _, ok := names[name]; !ok {
+			return fmt.Errorf("query %s json-type for %s: no param or output column named %s", query.Name, name, name)
+		}
+	}
+	return nil
+}
+
+// templateKeyset creates the keyset pagination cursor for a query with the
+// paginate=keyset pragma.
+func (tm Templater) templateKeyset(query pginfer.TypedQuery, outputs []TemplatedColumn) (*TemplatedKeyset, error) {
+	cols := make([]TemplatedColumn, len(query.Keyset.SortColumns))
+	for i, idx := range query.Keyset.SortColumns {
+		col := outputs[idx]
+		switch typ := col.Type.(type) {
+		case gotype.CompositeType:
+			return nil, fmt.Errorf("keyset pagination for query %s doesn't support composite sort column %s", query.Name, col.PgName)
+		case gotype.ArrayType:
+			if _, ok := typ.Elem.(gotype.CompositeType); ok {
+				return nil, fmt.Errorf("keyset pagination for query %s doesn't support composite array sort column %s", query.Name, col.PgName)
+			}
+		}
+		col.LowerName = tm.caser.ToLowerGoIdent(col.PgName)
+		if col.LowerName == "" || col.LowerName == "isSet" {
+			col.LowerName = "key" + strconv.Itoa(i)
+		}
+		cols[i] = col
+	}
+	return &TemplatedKeyset{
+		CursorType: tm.caser.ToUpperGoIdent(query.Name) + "Cursor",
+		Columns:    cols,
+	}, nil
+}
+
+// shareRowTypes marks all but the first query with a row struct of the same
+// row type from the row-type pragma to share the row struct of the first
+// query. Returns an error if the queries have different output columns or if
+// a row type collides with the default row struct of another query.
+func shareRowTypes(files []TemplatedFile) error {
+	type rowQuery struct {
+		query    TemplatedQuery
+		isShared bool // true if the query has a row-type pragma
+	}
+	firsts := make(map[string]rowQuery)
+	for i, file := range files {
+		for j, query := range file.Queries {
+			if query.ResultKind == ast.ResultKindExec || len(removeVoidColumns(query.Outputs)) <= 1 {
+				continue // no row struct
+			}
+			isShared := query.RowType != query.Name+"Row"
+			first, ok := firsts[query.RowType]
+			if !ok {
+				firsts[query.RowType] = rowQuery{query: query, isShared: isShared}
+				continue
+			}
+			if !first.isShared || !isShared {
+				return fmt.Errorf("row type %s of query %s collides with the row struct of query %s", query.RowType, query.Name, first.query.Name)
+			}
+			if !sameRowColumns(first.query.Outputs, query.Outputs) {
+				return fmt.Errorf("queries %s and %s have row type %s but different output columns", first.query.Name, query.Name, query.RowType)
+			}
+			files[i].Queries[j].sharesRow = true
+		}
+	}
+	return nil
+}
+
+// sameRowColumns returns true if the output columns have the same row struct
+// fields.
+func sameRowColumns(a, b []TemplatedColumn) bool {
+	a, b = removeVoidColumns(a), removeVoidColumns(b)
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].PgName != b[i].PgName || a[i].UpperName != b[i].UpperName ||
+			a[i].QualType != b[i].QualType || a[i].Nullable != b[i].Nullable {
+			return false
+		}
+	}
+	return true
+}
+
+// reservedNames are the package-level identifiers generated in the leader
+// file that a per-file querier interface, a query, or a declared type must not
+// shadow.
+var reservedNames = map[string]bool{
+	"Querier":                 true,
+	"DBQuerier":               true,
+	"ReadQuerier":             true,
+	"QuerierConfig":           true,
+	"NewQuerier":              true,
+	"NewQuerierConfig":        true,
+	"NewRoutingQuerier":       true,
+	"NewRoutingQuerierConfig": true,
+	"TxOptions":               true,
+	"Batch":                   true,
+	"BatchError":              true,
+	"PrepareAllQueries":       true,
+	"PrepareAllQueriesConfig": true,
+}
+
+// reservedDBQuerierMethods are the generated methods on DBQuerier that a query
+// method must not shadow.
+var reservedDBQuerierMethods = map[string]bool{
+	"WithTx":   true,
+	"RunInTx":  true,
+	"NewBatch": true,
+}
+
+// reservedBatchMethods are the generated methods on Batch that a query method
+// must not shadow.
+var reservedBatchMethods = map[string]bool{
+	"Send": true,
+}
+
+// checkGeneratedNames returns an error if an identifier generated for a query,
+// like the FindAuthorsHandle type or the FindAuthorsBatch method, or a type
+// declared for a Postgres type collides with a reserved identifier or another
+// generated identifier.
+func checkGeneratedNames(files []TemplatedFile, decls []Declarer) error {
+	pkgNames := make(map[string]string) // identifier to what declares it
+	for name := range reservedNames {
+		pkgNames[name] = "the generated " + name
+	}
+	querierMethods := make(map[string]string)
+	for name := range reservedDBQuerierMethods {
+		querierMethods[name] = "the generated method DBQuerier." + name
+	}
+	batchMethods := make(map[string]string)
+	for name := range reservedBatchMethods {
+		batchMethods[name] = "the generated method Batch." + name
+	}
+	add := func(names map[string]string, name, desc string) error {
+		if other, ok := names[name]; ok {
+			return fmt.Errorf("%s collides with %s", desc, other)
+		}
+		names[name] = desc
+		return nil
+	}
+
+	for _, decl := range decls {
+		if name, ok := declaredTypeName(decl); ok {
+			if err := add(pkgNames, name, "the declared type "+name); err != nil {
+				return err
+			}
+		}
+	}
+	for _, file := range files {
+		if file.QuerierName == "" {
+			continue
+		}
+		if err := add(pkgNames, file.QuerierName, "the querier interface "+file.QuerierName); err != nil {
+			return err
+		}
+	}
+	for _, file := range files {
+		for _, query := range file.Queries {
+			type ident struct {
+				names map[string]string // namespace of the identifier
+				name  string
+				kind  string
+			}
+			idents := []ident{
+				{querierMethods, query.Name, "method DBQuerier." + query.Name},
+				{querierMethods, query.Name + "Batch", "method DBQuerier." + query.Name + "Batch"},
+				{querierMethods, query.Name + "Scan", "method DBQuerier." + query.Name + "Scan"},
+				{batchMethods, query.Name, "method Batch." + query.Name},
+				{pkgNames, query.Name + "Handle", "type " + query.Name + "Handle"},
+			}
+			if len(query.Inputs) >= 3 {
+				idents = append(idents, ident{pkgNames, query.Name + "Params", "type " + query.Name + "Params"})
+			}
+			if query.ResultKind != ast.ResultKindExec && len(removeVoidColumns(query.Outputs)) > 1 && !query.sharesRow {
+				idents = append(idents, ident{pkgNames, query.RowType, "type " + query.RowType})
+			}
+			for _, id := range idents {
+				if err := add(id.names, id.name, "the "+id.kind+" of query "+query.Name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// declaredTypeName returns the name of the Go type declared by decl, if any.
+func declaredTypeName(decl Declarer) (string, bool) {
+	switch d := decl.(type) {
+	case CompositeTypeDeclarer:
+		return d.comp.Name, true
+	case EnumTypeDeclarer:
+		return d.enum.Name, true
+	case DomainTypeDeclarer:
+		return d.name(), true
+	case RangeTypeDeclarer:
+		return d.typ.Name, true
+	default:
+		return "", false
+	}
+}
+
+// nameQueriers sets the QuerierName of each file to a per-file interface name
+// derived from the file name, like CustomerQuerier for customer.sql. If names
+// collide, prefixes the parent directory names of the colliding files until
+// the names are unique. Leaves the names empty if there's only one file
+// because the Querier interface already contains all queries.
+func (tm Templater) nameQueriers(files []TemplatedFile) {
+	if len(files) < 2 {
+		return
+	}
+	depths := make([]int, len(files))
+	for i := range depths {
+		depths[i] = 1
+	}
+	for {
+		names := make(map[string][]int, len(files))
+		for i, file := range files {
+			name := tm.querierName(file.SourcePath, depths[i])
+			files[i].QuerierName = name
+			names[name] = append(names[name], i)
+		}
+		isDone := true
+		for name, idxs := range names {
+			if len(idxs) < 2 && !reservedNames[name] {
+				continue
+			}
+			for _, idx := range idxs {
+				if depths[idx] < len(splitPath(files[idx].SourcePath)) {
+					depths[idx]++
+					isDone = false
+				}
+			}
+		}
+		if isDone {
+			break
+		}
+	}
+	// Fallback to a numeric suffix if the paths don't disambiguate, like
+	// "foo_bar.sql" and "foo-bar.sql" which are both FooBar.
+	seen := make(map[string]bool, len(files))
+	for i, file := range files {
+		name := file.QuerierName
+		for n := 2; seen[name] || reservedNames[name]; n++ {
+			name = strings.TrimSuffix(file.QuerierName, "Querier") + strconv.Itoa(n) + "Querier"
+		}
+		seen[name] = true
+		files[i].QuerierName = name
+	}
+}
+
+// querierName returns the interface name for the queries in the file at path
+// using the last depth path components, like OrderCustomerQuerier for
+// "erp/order/customer.sql" with depth 2.
+func (tm Templater) querierName(path string, depth int) string {
+	parts := splitPath(path)
+	if depth < len(parts) {
+		parts = parts[len(parts)-depth:]
+	}
+	last := len(parts) - 1
+	parts[last] = strings.TrimSuffix(parts[last], filepath.Ext(parts[last]))
+	name := tm.caser.ToUpperGoIdent(strings.Join(parts, "_"))
+	if name == "" {
+		name = "File"
+	}
+	return name + "Querier"
+}
+
+// splitPath splits path into non-empty path components.
+func splitPath(path string) []string {
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+	nonEmpty := parts[:0]
+	for _, p := range parts {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return nonEmpty
+}
+
+// nameStatement returns a deterministic name for the prepared statement of a
+// query. The name includes a hash of the SQL so that a changed query never
+// reuses a stale prepared statement with the same name.
+func nameStatement(queryName, sql string) string {
+	hash := sha256.Sum256([]byte(queryName + "\x00" + sql))
+	return "pggen_" + queryName + "_" + hex.EncodeToString(hash[:8])
+}
+
 // chooseUpperName converts pgName into an capitalized Go identifier name.
 // If it's not possible to convert pgName into an identifier, uses fallback with
 // a suffix using idx.
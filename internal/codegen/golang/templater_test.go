@@ -0,0 +1,209 @@
+package golang
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leg100/pggen/internal/ast"
+	"github.com/leg100/pggen/internal/casing"
+	"github.com/leg100/pggen/internal/codegen/golang/gotype"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNameStatement(t *testing.T) {
+	name := nameStatement("FindAuthors", "SELECT * FROM author;")
+	if want := "pggen_FindAuthors_"; !strings.HasPrefix(name, want) {
+		t.Errorf("nameStatement() = %q; want prefix %q", name, want)
+	}
+	if again := nameStatement("FindAuthors", "SELECT * FROM author;"); again != name {
+		t.Errorf("nameStatement() not deterministic: %q != %q", again, name)
+	}
+	if other := nameStatement("FindAuthors", "SELECT 1;"); other == name {
+		t.Errorf("nameStatement() same name %q for different SQL", name)
+	}
+	if other := nameStatement("FindAuthor", "SELECT * FROM author;"); other == name {
+		t.Errorf("nameStatement() same name %q for different query name", name)
+	}
+}
+
+func TestTemplater_NameQueriers(t *testing.T) {
+	caser := casing.NewCaser()
+	caser.AddAcronym("db", "DB")
+	tm := NewTemplater(TemplaterOpts{Caser: caser})
+	tests := []struct {
+		name  string
+		paths []string
+		want  []string
+	}{
+		{
+			name:  "single file",
+			paths: []string{"/erp/order/customer.sql"},
+			want:  []string{""},
+		},
+		{
+			name:  "distinct files",
+			paths: []string{"/erp/order/customer.sql", "/erp/order/price.sql"},
+			want:  []string{"CustomerQuerier", "PriceQuerier"},
+		},
+		{
+			name:  "same file names",
+			paths: []string{"/out/alpha/query.sql", "/out/alpha/alpha/query.sql", "/out/bravo/query.sql"},
+			want:  []string{"OutAlphaQueryQuerier", "AlphaAlphaQueryQuerier", "BravoQueryQuerier"},
+		},
+		{
+			name:  "reserved name",
+			paths: []string{"/app/db.sql", "/app/user.sql"},
+			want:  []string{"AppDBQuerier", "UserQuerier"},
+		},
+		{
+			name:  "indistinguishable paths",
+			paths: []string{"/foo_bar.sql", "/foo-bar.sql"},
+			want:  []string{"FooBarQuerier", "FooBar2Querier"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := make([]TemplatedFile, len(tt.paths))
+			for i, path := range tt.paths {
+				files[i].SourcePath = path
+			}
+			tm.nameQueriers(files)
+			got := make([]string, len(files))
+			for i, file := range files {
+				got[i] = file.QuerierName
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestShareRowTypes(t *testing.T) {
+	cols := []TemplatedColumn{
+		{PgName: "id", UpperName: "ID", QualType: "int32"},
+		{PgName: "name", UpperName: "Name", QualType: "*string", Nullable: true},
+	}
+	otherCols := []TemplatedColumn{
+		{PgName: "id", UpperName: "ID", QualType: "int32"},
+		{PgName: "name", UpperName: "Name", QualType: "string"},
+	}
+	query := func(name, rowType string, outs []TemplatedColumn) TemplatedQuery {
+		return TemplatedQuery{Name: name, ResultKind: ast.ResultKindOne, RowType: rowType, Outputs: outs}
+	}
+	tests := []struct {
+		name      string
+		queries   []TemplatedQuery
+		wantShare []bool
+		wantErr   string
+	}{
+		{
+			name:      "shared",
+			queries:   []TemplatedQuery{query("InsertAuthor", "Author", cols), query("FindAuthor", "Author", cols)},
+			wantShare: []bool{false, true},
+		},
+		{
+			name:      "default row types",
+			queries:   []TemplatedQuery{query("InsertAuthor", "InsertAuthorRow", cols), query("FindAuthor", "FindAuthorRow", cols)},
+			wantShare: []bool{false, false},
+		},
+		{
+			name:    "different columns",
+			queries: []TemplatedQuery{query("InsertAuthor", "Author", cols), query("FindAuthor", "Author", otherCols)},
+			wantErr: "queries InsertAuthor and FindAuthor have row type Author but different output columns",
+		},
+		{
+			name:    "collides with default row type",
+			queries: []TemplatedQuery{query("Find", "FindRow", cols), query("InsertAuthor", "FindRow", cols)},
+			wantErr: "row type FindRow of query InsertAuthor collides with the row struct of query Find",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := []TemplatedFile{{Queries: tt.queries}}
+			err := shareRowTypes(files)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]bool, len(files[0].Queries))
+			for i, q := range files[0].Queries {
+				got[i] = q.sharesRow
+			}
+			assert.Equal(t, tt.wantShare, got)
+		})
+	}
+}
+
+func TestCheckGeneratedNames(t *testing.T) {
+	cols := []TemplatedColumn{
+		{PgName: "id", UpperName: "ID", QualType: "int32"},
+		{PgName: "name", UpperName: "Name", QualType: "string"},
+	}
+	query := func(name string) TemplatedQuery {
+		return TemplatedQuery{Name: name, ResultKind: ast.ResultKindOne, RowType: name + "Row", Outputs: cols}
+	}
+	enum := func(name string) Declarer {
+		return NewEnumTypeDeclarer(gotype.EnumType{Name: name})
+	}
+	tests := []struct {
+		name    string
+		queries []TemplatedQuery
+		decls   []Declarer
+		wantErr string
+	}{
+		{
+			name:    "ok",
+			queries: []TemplatedQuery{query("FindAuthor"), query("Batch")},
+			decls:   []Declarer{enum("DeviceType")},
+		},
+		{
+			name:    "query method shadows NewBatch",
+			queries: []TemplatedQuery{query("NewBatch")},
+			wantErr: "the method DBQuerier.NewBatch of query NewBatch collides with the generated method DBQuerier.NewBatch",
+		},
+		{
+			name:    "batch method shadows NewBatch",
+			queries: []TemplatedQuery{query("New")},
+			wantErr: "the method DBQuerier.NewBatch of query New collides with the generated method DBQuerier.NewBatch",
+		},
+		{
+			name:    "query shadows Batch.Send",
+			queries: []TemplatedQuery{query("Send")},
+			wantErr: "the method Batch.Send of query Send collides with the generated method Batch.Send",
+		},
+		{
+			name:    "row struct shadows BatchError",
+			queries: []TemplatedQuery{{Name: "Find", ResultKind: ast.ResultKindOne, RowType: "BatchError", Outputs: cols}},
+			wantErr: "the type BatchError of query Find collides with the generated BatchError",
+		},
+		{
+			name:    "scan method shadows query",
+			queries: []TemplatedQuery{query("Find"), query("FindScan")},
+			wantErr: "the method DBQuerier.FindScan of query FindScan collides with the method DBQuerier.FindScan of query Find",
+		},
+		{
+			name:    "handle shadows declared type",
+			queries: []TemplatedQuery{query("Find")},
+			decls:   []Declarer{enum("FindHandle")},
+			wantErr: "the type FindHandle of query Find collides with the declared type FindHandle",
+		},
+		{
+			name:    "declared type shadows Batch",
+			decls:   []Declarer{enum("Batch")},
+			wantErr: "the declared type Batch collides with the generated Batch",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := []TemplatedFile{{Queries: tt.queries}}
+			err := checkGeneratedNames(files, tt.decls)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
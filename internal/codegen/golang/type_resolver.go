@@ -2,6 +2,7 @@ package golang
 
 import (
 	"fmt"
+	"github.com/jackc/pgtype"
 	"github.com/leg100/pggen/internal/casing"
 	"github.com/leg100/pggen/internal/codegen/golang/gotype"
 	"github.com/leg100/pggen/internal/pg"
@@ -9,31 +10,288 @@ import (
 	"strings"
 )
 
+// TypeStyle is the style of Go types for Postgres types that pgtype can
+// transcode to both a pgtype type and a native Go type, like timestamptz.
+type TypeStyle string
+
+const (
+	// TypeStylePgtype uses the pgtype types, like pgtype.Timestamptz. The
+	// default.
+	TypeStylePgtype TypeStyle = "pgtype"
+	// TypeStyleGo uses native Go types, like time.Time and *time.Time for a
+	// timestamptz, and a configured Go type for uuid and numeric.
+	TypeStyleGo TypeStyle = "go"
+)
+
+// NullStyle is how to represent a nullable Postgres value in Go.
+type NullStyle string
+
+const (
+	// NullStyleDefault uses a pointer for some types, like *string for text,
+	// and a pgtype type for others, like pgtype.Int8 for bigint. The default.
+	NullStyleDefault NullStyle = "default"
+	// NullStylePointer uses a pointer, like *int for bigint, and a slice of
+	// pointers for arrays, like []*int for bigint[].
+	NullStylePointer NullStyle = "pointer"
+	// NullStylePgtype uses a pgtype type, like pgtype.Text for text.
+	NullStylePgtype NullStyle = "pgtype"
+	// NullStyleSQL uses a database/sql type, like sql.NullString for text.
+	// Types without a database/sql type, array elements, and composite fields
+	// use NullStylePointer.
+	NullStyleSQL NullStyle = "sql"
+	// NullStyleGeneric uses a generated generic type, like Null[string] for
+	// text. Array elements use NullStylePointer. Requires Go 1.18 or later.
+	NullStyleGeneric NullStyle = "generic"
+)
+
+// DomainStyle is how to represent a Postgres domain in Go.
+type DomainStyle string
+
+const (
+	// DomainStyleBase uses the Go type of the domain's base type, like string
+	// for a domain over text. The default.
+	DomainStyleBase DomainStyle = "base"
+	// DomainStyleNamed uses a generated named Go type, like "type Email
+	// string", if the base type resolves to a builtin Go type. Other domains
+	// use DomainStyleBase.
+	DomainStyleNamed DomainStyle = "named"
+)
+
+// domainElems is the set of builtin Go types that DomainStyleNamed declares
+// a named Go type for.
+var domainElems = map[string]struct{}{
+	"bool":    {},
+	"float32": {},
+	"float64": {},
+	"int":     {},
+	"int16":   {},
+	"int32":   {},
+	"int64":   {},
+	"string":  {},
+}
+
+// sqlNullTypes maps a Go type to the database/sql type for the nullable Go
+// type.
+var sqlNullTypes = map[string]string{
+	"bool":      "database/sql.NullBool",
+	"float64":   "database/sql.NullFloat64",
+	"int":       "database/sql.NullInt64",
+	"int16":     "database/sql.NullInt16",
+	"int32":     "database/sql.NullInt32",
+	"int64":     "database/sql.NullInt64",
+	"string":    "database/sql.NullString",
+	"time.Time": "database/sql.NullTime",
+}
+
+// genericNullElems is the set of Go types that the generated Null[T] type
+// supports. Null scans values using database/sql driver values, so T must be
+// convertible from the driver value.
+var genericNullElems = map[string]struct{}{
+	"[]byte":    {},
+	"bool":      {},
+	"float32":   {},
+	"float64":   {},
+	"int":       {},
+	"int16":     {},
+	"int32":     {},
+	"int64":     {},
+	"string":    {},
+	"time.Time": {},
+}
+
 // TypeResolver handles the mapping between Postgres and Go types.
 type TypeResolver struct {
 	caser     casing.Caser
-	overrides map[string]string
+	overrides map[string]typeOverride
+	// Overrides for schema-qualified table columns, like "billing.users.id".
+	// Separate from overrides so a column never matches a type name.
+	columnOverrides map[string]typeOverride
+	style           TypeStyle
+	// Fully qualified Go types for uuid and numeric with TypeStyleGo. If empty,
+	// uuid resolves to [16]byte and numeric to pgtype.Numeric.
+	uuidType    string
+	decimalType string
+	// Fully qualified Go types for json and jsonb composite fields, keyed by
+	// "<composite type>.<field>".
+	jsonTypes   map[string]string
+	nullStyle   NullStyle
+	domainStyle DomainStyle
+	// Postgres type names that exist in more than one schema, like status for
+	// billing.status and shipping.status. The Go type names for these types
+	// include the schema, like BillingStatus.
+	qualifiedNames map[string]struct{}
 }
 
+// typeOverride is the user-provided Go types for a Postgres type or table
+// column. An empty Go type means use the default Go type.
+type typeOverride struct {
+	nonNullable string // Go type for values that can't be null, like string
+	nullable    string // Go type for values that can be null, like *string
+}
+
+// goType returns the Go type of the override for a value with the
+// nullability.
+func (o typeOverride) goType(nullable bool) string {
+	if nullable {
+		return o.nullable
+	}
+	return o.nonNullable
+}
+
+// NewTypeResolver creates a resolver that maps Postgres types to Go types. The
+// overrides map a Postgres type name, like "text" or "billing.status", or a
+// schema-qualified table column, like "billing.users.id", to a fully qualified
+// Go type. A single Go type, like "string", applies to both non-nullable and
+// nullable values. An override for separate non-nullable and nullable Go types
+// uses a comma, like "string,*string", and for a type also maps the array type
+// to a slice of the non-nullable Go type, like "_text" to "[]string", unless
+// overridden. One side of the comma may be empty to use the default Go type.
 func NewTypeResolver(c casing.Caser, overrides map[string]string) TypeResolver {
-	overs := make(map[string]string, len(overrides))
+	overs := make(map[string]typeOverride, len(overrides))
+	arrayOvers := make(map[string]typeOverride)
+	colOvers := make(map[string]typeOverride)
 	for k, v := range overrides {
+		if strings.Count(k, ".") == 2 {
+			colOvers[k] = parseTypeOverride(v)
+			continue
+		}
+		over := parseTypeOverride(v)
 		for _, alias := range listAliases(k) {
-			overs[alias] = v
+			overs[alias] = over
+		}
+		// Map the array type for separate non-nullable and nullable Go types to
+		// a slice of the non-nullable Go type, like []string. A nil slice
+		// represents a null array.
+		name := k[strings.LastIndexByte(k, '.')+1:]
+		if !strings.Contains(v, ",") || strings.HasPrefix(name, "_") || over.nonNullable == "" {
+			continue
+		}
+		arr := "[]" + over.nonNullable
+		for _, alias := range listAliases(k) {
+			arrayOvers[arrayTypeName(alias)] = typeOverride{nonNullable: arr, nullable: arr}
+		}
+	}
+	for k, over := range arrayOvers {
+		if _, ok := overs[k]; !ok {
+			overs[k] = over
 		}
 	}
-	return TypeResolver{caser: c, overrides: overs}
+	return TypeResolver{caser: c, overrides: overs, columnOverrides: colOvers, style: TypeStylePgtype, nullStyle: NullStyleDefault, domainStyle: DomainStyleBase}
+}
+
+// WithTypeStyle returns a copy of the resolver that resolves types using the
+// style. uuidType and decimalType are fully qualified Go types, like
+// "github.com/google/uuid.UUID", to use for uuid and numeric with
+// TypeStyleGo. An empty string means uuid resolves to [16]byte and numeric
+// resolves to pgtype.Numeric.
+func (tr TypeResolver) WithTypeStyle(style TypeStyle, uuidType, decimalType string) TypeResolver {
+	tr.style = style
+	tr.uuidType = uuidType
+	tr.decimalType = decimalType
+	return tr
+}
+
+// WithJSONTypes returns a copy of the resolver that resolves json and jsonb
+// composite fields to user-provided Go types. The keys are like "event.payload"
+// for the payload field of the event composite type. The values are fully
+// qualified Go types, like "example.com/foo.Payload".
+func (tr TypeResolver) WithJSONTypes(jsonTypes map[string]string) TypeResolver {
+	tr.jsonTypes = jsonTypes
+	return tr
+}
+
+// WithNullStyle returns a copy of the resolver that resolves nullable types
+// using the null style.
+func (tr TypeResolver) WithNullStyle(style NullStyle) TypeResolver {
+	tr.nullStyle = style
+	return tr
+}
+
+// WithDomainStyle returns a copy of the resolver that resolves domain types
+// using the domain style.
+func (tr TypeResolver) WithDomainStyle(style DomainStyle) TypeResolver {
+	tr.domainStyle = style
+	return tr
+}
+
+// WithQualifiedNames returns a copy of the resolver that includes the schema in
+// Go type names for Postgres types named one of names, like BillingStatus for
+// billing.status. Use for type names that exist in more than one schema.
+func (tr TypeResolver) WithQualifiedNames(names map[string]struct{}) TypeResolver {
+	tr.qualifiedNames = names
+	return tr
+}
+
+// ResolveJSON maps a Postgres json, jsonb, or jsonb array type to a JSONType
+// for the fully qualified Go type the JSON value holds, like
+// "example.com/foo.Payload". For an array, the Go type is a slice of goType.
+// If nullable, the Go type is a pointer to goType.
+func (tr TypeResolver) ResolveJSON(pgt pg.Type, nullable bool, goType string) (gotype.JSONType, error) {
+	switch pgt.OID() {
+	case pgtype.JSONOID, pgtype.JSONBOID:
+		if nullable && !strings.HasPrefix(goType, "*") {
+			goType = "*" + goType
+		}
+	case pgtype.JSONBArrayOID:
+		goType = "[]" + goType
+	default:
+		return gotype.JSONType{}, fmt.Errorf("json type %s requires a json, jsonb, or jsonb[] Postgres type; got %s", goType, pgt.String())
+	}
+	return gotype.NewJSONType(pgt, goType), nil
+}
+
+// ResolveColumn maps a Postgres type to a Go type for a param or output column
+// that comes from the schema-qualified table column, like "public.users.id".
+// Uses the override for the table column and nullability if any. Otherwise,
+// resolves the type like Resolve. An empty column means the param or output
+// column doesn't come from a table column.
+func (tr TypeResolver) ResolveColumn(column string, pgt pg.Type, nullable bool, pkgPath string) (gotype.Type, error) {
+	over, ok := tr.columnOverrides[column]
+	if goType := over.goType(nullable); ok && goType != "" {
+		opaque := gotype.NewOpaqueType(goType)
+		opaque.PgTyp = pgt
+		return opaque, nil
+	}
+	return tr.Resolve(pgt, nullable, pkgPath)
 }
 
 // Resolve maps a Postgres type to a Go type.
 func (tr TypeResolver) Resolve(pgt pg.Type, nullable bool, pkgPath string) (gotype.Type, error) {
-	// Custom user override.
-	if goType, ok := tr.overrides[pgt.String()]; ok {
+	// Custom user override, by the schema-qualified name, like billing.status,
+	// or by the type name.
+	over, ok := tr.overrides[pg.QualifiedName(pgt)]
+	if !ok {
+		over, ok = tr.overrides[pgt.String()]
+	}
+	if goType := over.goType(nullable); ok && goType != "" {
 		opaque := gotype.NewOpaqueType(goType)
 		opaque.PgTyp = pgt
 		return opaque, nil
 	}
 
+	// Domain type, mapped from the base type.
+	if domain, ok := pgt.(pg.DomainType); ok {
+		return tr.resolveDomain(domain, nullable, pkgPath)
+	}
+
+	// Nullable type for the null style.
+	if nullable && tr.nullStyle != NullStyleDefault && tr.nullStyle != "" {
+		typ, ok, err := tr.resolveNullStyle(pgt, pkgPath)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return typ, nil
+		}
+	}
+
+	// Native Go type for the go style.
+	if tr.style == TypeStyleGo {
+		if typ, ok := tr.resolveGoStyle(pgt, nullable); ok {
+			return typ, nil
+		}
+	}
+
 	// Known type.
 	var typ gotype.Type
 	var isKnownType bool
@@ -66,13 +324,22 @@ func (tr TypeResolver) Resolve(pgt pg.Type, nullable bool, pkgPath string) (goty
 	// New type that pggen will define in generated source code.
 	switch pgt := pgt.(type) {
 	case pg.ArrayType:
+		switch elem := pgt.ElemType.(type) {
+		case pg.DomainType:
+			return tr.resolveDomainArray(pgt, elem, pkgPath)
+		case pg.RangeType, pg.MultirangeType:
+			return nil, fmt.Errorf("arrays of range type %q aren't supported; map array type %q with --go-type", elem.String(), pgt.Name)
+		}
 		elemType, err := tr.Resolve(pgt.ElemType, nullable, pkgPath)
 		if err != nil {
 			return nil, fmt.Errorf("resolve array elem type for array type %q: %w", pgt.Name, err)
 		}
-		return gotype.NewArrayType(pkgPath, pgt, tr.caser, elemType), nil
+		return tr.newArrayType(pkgPath, pgt, elemType), nil
 	case pg.EnumType:
-		enum := gotype.NewEnumType(pkgPath, pgt, tr.caser)
+		named := pgt
+		named.Name = tr.identName(pgt)
+		enum := gotype.NewEnumType(pkgPath, named, tr.caser)
+		enum.PgEnum = pgt
 		return enum, nil
 	case pg.CompositeType:
 		comp, err := CreateCompositeType(pkgPath, pgt, tr, tr.caser)
@@ -80,11 +347,211 @@ func (tr TypeResolver) Resolve(pgt pg.Type, nullable bool, pkgPath string) (goty
 			return nil, fmt.Errorf("create composite type: %w", err)
 		}
 		return comp, nil
+	case pg.RangeType:
+		elemType, err := tr.Resolve(pgt.ElemType /*nullable*/, false, pkgPath)
+		if err != nil {
+			return nil, fmt.Errorf("resolve subtype for range type %q: %w", pgt.Name, err)
+		}
+		if _, ok := findRangeElemPgx(pgt); !ok {
+			return nil, fmt.Errorf("range type %q has subtype %s without a pgtype type; map the range with --go-type", pgt.Name, pgt.ElemType.String())
+		}
+		named := pgt
+		named.Name = tr.identName(pgt)
+		rng := gotype.NewRangeType(pkgPath, named, tr.caser, elemType)
+		rng.PgRange = pgt
+		return rng, nil
+	case pg.MultirangeType:
+		rangeType, err := tr.Resolve(pgt.RangeType /*nullable*/, false, pkgPath)
+		if err != nil {
+			return nil, fmt.Errorf("resolve range type for multirange type %q: %w", pgt.Name, err)
+		}
+		if _, ok := rangeType.(gotype.RangeType); !ok {
+			if _, ok := gotype.FindKnownTypePgx(pgt.RangeType.OID()); !ok {
+				return nil, fmt.Errorf("multirange type %q has range type %s without a pgtype type; map the multirange with --go-type", pgt.Name, pgt.RangeType.String())
+			}
+		}
+		return gotype.NewMultirangeType(pgt, rangeType), nil
 	}
 
 	return nil, fmt.Errorf("no go type found for Postgres type %s oid=%d", pgt.String(), pgt.OID())
 }
 
+// resolveGoStyle maps a Postgres type to a native Go type, like time.Time, or
+// to the configured uuid or decimal Go type.
+func (tr TypeResolver) resolveGoStyle(pgt pg.Type, nullable bool) (gotype.Type, bool) {
+	goType := ""
+	isArray := false
+	switch pgt.OID() {
+	case pgtype.UUIDOID:
+		goType = tr.uuidType
+	case pgtype.UUIDArrayOID:
+		goType, isArray = tr.uuidType, true
+	case pgtype.NumericOID:
+		goType = tr.decimalType
+	case pgtype.NumericArrayOID:
+		goType, isArray = tr.decimalType, true
+	}
+	if goType != "" {
+		// Like text arrays, use non-pointer elements because pgtype can't encode
+		// nil pointer elements.
+		if isArray {
+			goType = "[]" + goType
+		} else if nullable {
+			goType = "*" + goType
+		}
+		opaque := gotype.NewOpaqueType(goType)
+		opaque.PgTyp = pgt
+		return opaque, true
+	}
+
+	typ, ok := gotype.FindGoStyleType(pgt.OID(), nullable)
+	if !ok {
+		return nil, false
+	}
+	opaque := typ.(gotype.OpaqueType)
+	opaque.PgTyp = pgt
+	return opaque, true
+}
+
+// resolveNullStyle maps a nullable Postgres type to a Go type using the null
+// style. Returns false if the default type should be used, like for enums,
+// composite types, and types that are already pgtype types.
+func (tr TypeResolver) resolveNullStyle(pgt pg.Type, pkgPath string) (gotype.Type, bool, error) {
+	if tr.nullStyle == NullStylePgtype {
+		typ, ok := gotype.FindKnownTypePgx(pgt.OID())
+		if !ok {
+			return nil, false, nil
+		}
+		opaque := typ.(gotype.OpaqueType)
+		opaque.PgTyp = pgt
+		return opaque, true, nil
+	}
+
+	base, err := tr.Resolve(pgt /*nullable*/, false, pkgPath)
+	if err != nil {
+		return nil, false, err
+	}
+	opaque, ok := base.(gotype.OpaqueType)
+	if !ok || opaque.PkgPath == "github.com/jackc/pgtype" {
+		return nil, false, nil
+	}
+
+	elemName := opaque.QualifyRel("")
+	if elemName == "[]byte" && tr.nullStyle != NullStyleGeneric {
+		return opaque, true, nil // a nil slice is null
+	}
+
+	// Arrays use pointer elements for all styles since the pgtype array types
+	// only encode and decode elements by reflection. pgtype only supports nil
+	// elements for the builtin types, so keep other arrays as is.
+	if strings.HasPrefix(elemName, "[]") && elemName != "[]byte" {
+		if _, ok := genericNullElems[strings.TrimPrefix(elemName, "[]")]; ok {
+			opaque.Name = "[]*" + opaque.Name[len("[]"):]
+		}
+		return opaque, true, nil
+	}
+
+	switch tr.nullStyle {
+	case NullStyleSQL:
+		if sqlType, ok := sqlNullTypes[elemName]; ok {
+			typ := gotype.NewOpaqueType(sqlType)
+			typ.PgTyp = pgt
+			return typ, true, nil
+		}
+	case NullStyleGeneric:
+		if _, ok := genericNullElems[elemName]; ok {
+			return gotype.NullType{
+				PgTyp:   pgt,
+				PkgPath: pkgPath,
+				Pkg:     gotype.ExtractShortPackage([]byte(pkgPath)),
+				Elem:    opaque,
+			}, true, nil
+		}
+	}
+
+	if !strings.HasPrefix(opaque.Name, "*") {
+		opaque.Name = "*" + opaque.Name
+	}
+	return opaque, true, nil
+}
+
+// resolveDomain maps a Postgres domain to the Go type of the base type or, for
+// DomainStyleNamed, to a named Go type with the base type as the underlying
+// type.
+func (tr TypeResolver) resolveDomain(pgt pg.DomainType, nullable bool, pkgPath string) (gotype.Type, error) {
+	base, err := tr.Resolve(pgt.BaseType, nullable, pkgPath)
+	if err != nil {
+		return nil, fmt.Errorf("resolve base type for domain type %q: %w", pgt.Name, err)
+	}
+	switch typ := base.(type) {
+	case gotype.OpaqueType:
+		elemName := strings.TrimPrefix(typ.Name, "*")
+		if _, ok := domainElems[elemName]; ok && tr.domainStyle == DomainStyleNamed {
+			isPtr := elemName != typ.Name
+			named := pgt
+			named.Name = tr.identName(pgt)
+			domain := gotype.NewDomainType(pkgPath, named, tr.caser, gotype.NewOpaqueType(elemName), isPtr)
+			domain.PgDomain = pgt
+			return domain, nil
+		}
+		typ.PgTyp = pgt
+		return typ, nil
+	case gotype.NullType:
+		typ.PgTyp = pgt
+		return typ, nil
+	default:
+		return base, nil
+	}
+}
+
+// resolveDomainArray maps a Postgres array of domains to a slice of the base
+// type's non-nullable Go type, like []string, since the generated code has no
+// transcoder for arrays of named domain types.
+func (tr TypeResolver) resolveDomainArray(pgt pg.ArrayType, domain pg.DomainType, pkgPath string) (gotype.Type, error) {
+	elemType, err := tr.WithDomainStyle(DomainStyleBase).Resolve(domain.BaseType /*nullable*/, false, pkgPath)
+	if err != nil {
+		return nil, fmt.Errorf("resolve array elem type for array type %q: %w", pgt.Name, err)
+	}
+	opaque, ok := elemType.(gotype.OpaqueType)
+	if !ok {
+		return tr.newArrayType(pkgPath, pgt, elemType), nil
+	}
+	return gotype.OpaqueType{
+		PgTyp:   pgt,
+		PkgPath: opaque.PkgPath,
+		Pkg:     opaque.Pkg,
+		Name:    "[]" + opaque.Name,
+	}, nil
+}
+
+// newArrayType creates a Go slice type for the Postgres array type, named
+// like the element type.
+func (tr TypeResolver) newArrayType(pkgPath string, pgt pg.ArrayType, elemType gotype.Type) gotype.ArrayType {
+	named := pgt
+	named.Name = tr.identName(pgt)
+	arr := gotype.NewArrayType(pkgPath, named, tr.caser, elemType)
+	arr.PgArray = pgt
+	return arr
+}
+
+// identName returns the Postgres name to derive the Go type name from. For a
+// type name in more than one schema, the name includes the schema, like
+// billing_status for billing.status, so that the Go type names don't collide.
+// An array uses the name of the element type, like _billing_status.
+func (tr TypeResolver) identName(pgt pg.Type) string {
+	if arr, ok := pgt.(pg.ArrayType); ok {
+		if elemName := tr.identName(arr.ElemType); elemName != arr.ElemType.String() {
+			return "_" + elemName
+		}
+		return arr.Name
+	}
+	schema := pg.TypeSchema(pgt)
+	if _, ok := tr.qualifiedNames[pgt.String()]; ok && schema != "" {
+		return schema + "_" + pgt.String()
+	}
+	return pgt.String()
+}
+
 // CreateCompositeType creates a struct to represent a Postgres composite type.
 // The type is rooted under pkgPath.
 func CreateCompositeType(
@@ -93,10 +560,15 @@ func CreateCompositeType(
 	resolver TypeResolver,
 	caser casing.Caser,
 ) (gotype.CompositeType, error) {
-	name := caser.ToUpperGoIdent(pgt.Name)
+	name := caser.ToUpperGoIdent(resolver.identName(pgt))
 	if name == "" {
 		name = gotype.ChooseFallbackName(pgt.Name, "UnnamedStruct")
 	}
+	// pgtype assigns composite fields using AssignTo and Set, which don't
+	// support the database/sql types, so use pointers instead.
+	if resolver.nullStyle == NullStyleSQL {
+		resolver = resolver.WithNullStyle(NullStylePointer)
+	}
 	fieldNames := make([]string, len(pgt.ColumnNames))
 	fieldTypes := make([]gotype.Type, len(pgt.ColumnTypes))
 	for i, colName := range pgt.ColumnNames {
@@ -105,6 +577,14 @@ func CreateCompositeType(
 			ident = gotype.ChooseFallbackName(colName, "UnnamedField"+strconv.Itoa(i))
 		}
 		fieldNames[i] = ident
+		if jsonType, ok := resolver.jsonTypes[pgt.Name+"."+colName]; ok {
+			fieldType, err := resolver.ResolveJSON(pgt.ColumnTypes[i] /*nullable*/, true, jsonType)
+			if err != nil {
+				return gotype.CompositeType{}, fmt.Errorf("resolve composite column json type %s.%s: %w", pgt.Name, colName, err)
+			}
+			fieldTypes[i] = fieldType
+			continue
+		}
 		fieldType, err := resolver.Resolve(pgt.ColumnTypes[i] /*nullable*/, true, pkgPath)
 		if err != nil {
 			return gotype.CompositeType{}, fmt.Errorf("resolve composite column type %s.%s: %w", pgt.Name, colName, err)
@@ -122,6 +602,27 @@ func CreateCompositeType(
 	return ct, nil
 }
 
+// parseTypeOverride parses the Go types of an override, like "string" for
+// both non-nullable and nullable values, or "string,*string" for separate
+// non-nullable and nullable Go types.
+func parseTypeOverride(goTypes string) typeOverride {
+	i := strings.IndexByte(goTypes, ',')
+	if i == -1 {
+		return typeOverride{nonNullable: goTypes, nullable: goTypes}
+	}
+	return typeOverride{
+		nonNullable: strings.TrimSpace(goTypes[:i]),
+		nullable:    strings.TrimSpace(goTypes[i+1:]),
+	}
+}
+
+// arrayTypeName returns the Postgres array type name for a type name, like
+// "_text" for "text" or "billing._status" for "billing.status".
+func arrayTypeName(name string) string {
+	i := strings.LastIndexByte(name, '.')
+	return name[:i+1] + "_" + name[i+1:]
+}
+
 func listAliases(name string) []string {
 	if strings.HasPrefix(name, "_") {
 		aliases := listElemAliases(name[1:])
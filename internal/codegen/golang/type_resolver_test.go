@@ -178,6 +178,742 @@ func TestTypeResolver_Resolve(t *testing.T) {
 	}
 }
 
+func TestTypeResolver_Resolve_GoStyle(t *testing.T) {
+	testPkgPath := "github.com/leg100/pggen/internal/codegen/golang/test_resolve"
+	caser := casing.NewCaser()
+	tests := []struct {
+		name        string
+		uuidType    string
+		decimalType string
+		pgType      pg.Type
+		nullable    bool
+		want        gotype.Type
+	}{
+		{
+			name:   "timestamptz",
+			pgType: pg.Timestamptz,
+			want:   gotype.OpaqueType{PgTyp: pg.Timestamptz, PkgPath: "time", Pkg: "time", Name: "Time"},
+		},
+		{
+			name:     "timestamptz nullable",
+			pgType:   pg.Timestamptz,
+			nullable: true,
+			want:     gotype.OpaqueType{PgTyp: pg.Timestamptz, PkgPath: "time", Pkg: "time", Name: "*Time"},
+		},
+		{
+			name:     "timestamptz array nullable",
+			pgType:   pg.TimestamptzArray,
+			nullable: true,
+			want:     gotype.OpaqueType{PgTyp: pg.TimestamptzArray, PkgPath: "time", Pkg: "time", Name: "[]*Time"},
+		},
+		{
+			name:   "date",
+			pgType: pg.Date,
+			want:   gotype.OpaqueType{PgTyp: pg.Date, PkgPath: "time", Pkg: "time", Name: "Time"},
+		},
+		{
+			name:   "interval",
+			pgType: pg.Interval,
+			want:   gotype.OpaqueType{PgTyp: pg.Interval, PkgPath: "time", Pkg: "time", Name: "Duration"},
+		},
+		{
+			name:   "time of day unchanged",
+			pgType: pg.Time,
+			want:   gotype.OpaqueType{PgTyp: pg.Time, PkgPath: "github.com/jackc/pgtype", Pkg: "pgtype", Name: "Time"},
+		},
+		{
+			name:   "jsonb",
+			pgType: pg.JSONB,
+			want:   gotype.OpaqueType{PgTyp: pg.JSONB, PkgPath: "encoding/json", Pkg: "json", Name: "RawMessage"},
+		},
+		{
+			name:     "jsonb array",
+			pgType:   pg.JSONBArray,
+			nullable: true,
+			want:     gotype.OpaqueType{PgTyp: pg.JSONBArray, PkgPath: "encoding/json", Pkg: "json", Name: "[]RawMessage"},
+		},
+		{
+			name:   "uuid",
+			pgType: pg.UUID,
+			want:   gotype.OpaqueType{PgTyp: pg.UUID, Name: "[16]byte"},
+		},
+		{
+			name:     "uuid nullable",
+			pgType:   pg.UUID,
+			nullable: true,
+			want:     gotype.OpaqueType{PgTyp: pg.UUID, Name: "*[16]byte"},
+		},
+		{
+			name:     "uuid type",
+			uuidType: "github.com/google/uuid.UUID",
+			pgType:   pg.UUID,
+			nullable: true,
+			want:     gotype.OpaqueType{PgTyp: pg.UUID, PkgPath: "github.com/google/uuid", Pkg: "uuid", Name: "*UUID"},
+		},
+		{
+			name:     "uuid type array",
+			uuidType: "github.com/google/uuid.UUID",
+			pgType:   pg.UUIDArray,
+			want:     gotype.OpaqueType{PgTyp: pg.UUIDArray, PkgPath: "github.com/google/uuid", Pkg: "uuid", Name: "[]UUID"},
+		},
+		{
+			name:   "numeric without decimal type",
+			pgType: pg.Numeric,
+			want:   gotype.OpaqueType{PgTyp: pg.Numeric, PkgPath: "github.com/jackc/pgtype", Pkg: "pgtype", Name: "Numeric"},
+		},
+		{
+			name:        "decimal type",
+			decimalType: "github.com/shopspring/decimal.Decimal",
+			pgType:      pg.Numeric,
+			want:        gotype.OpaqueType{PgTyp: pg.Numeric, PkgPath: "github.com/shopspring/decimal", Pkg: "decimal", Name: "Decimal"},
+		},
+		{
+			name:        "decimal type array nullable",
+			decimalType: "github.com/shopspring/decimal.Decimal",
+			pgType:      pg.NumericArray,
+			nullable:    true,
+			want:        gotype.OpaqueType{PgTyp: pg.NumericArray, PkgPath: "github.com/shopspring/decimal", Pkg: "decimal", Name: "[]Decimal"},
+		},
+		{
+			name:     "composite field",
+			pgType:   pg.CompositeType{Name: "event", ColumnNames: []string{"at"}, ColumnTypes: []pg.Type{pg.Timestamptz}},
+			nullable: true,
+			want: gotype.CompositeType{
+				PgComposite: pg.CompositeType{Name: "event", ColumnNames: []string{"at"}, ColumnTypes: []pg.Type{pg.Timestamptz}},
+				PkgPath:     testPkgPath,
+				Pkg:         "test_resolve",
+				Name:        "Event",
+				FieldNames:  []string{"At"},
+				FieldTypes: []gotype.Type{
+					gotype.OpaqueType{PgTyp: pg.Timestamptz, PkgPath: "time", Pkg: "time", Name: "*Time"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := NewTypeResolver(caser, nil).WithTypeStyle(TypeStyleGo, tt.uuidType, tt.decimalType)
+			got, err := resolver.Resolve(tt.pgType, tt.nullable, testPkgPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTypeResolver_Resolve_NullStyle(t *testing.T) {
+	testPkgPath := "github.com/leg100/pggen/internal/codegen/golang/test_resolve"
+	caser := casing.NewCaser()
+	nullString := gotype.NullType{
+		PgTyp:   pg.Text,
+		PkgPath: testPkgPath,
+		Pkg:     "test_resolve",
+		Elem:    gotype.OpaqueType{PgTyp: pg.Text, Name: "string"},
+	}
+	tests := []struct {
+		name      string
+		nullStyle NullStyle
+		typeStyle TypeStyle
+		pgType    pg.Type
+		nullable  bool
+		want      gotype.Type
+	}{
+		{
+			name:      "pointer",
+			nullStyle: NullStylePointer,
+			pgType:    pg.Int8,
+			nullable:  true,
+			want:      gotype.OpaqueType{PgTyp: pg.Int8, Name: "*int"},
+		},
+		{
+			name:      "pointer non-nullable",
+			nullStyle: NullStylePointer,
+			pgType:    pg.Int8,
+			want:      gotype.OpaqueType{PgTyp: pg.Int8, Name: "int"},
+		},
+		{
+			name:      "pointer array",
+			nullStyle: NullStylePointer,
+			pgType:    pg.TextArray,
+			nullable:  true,
+			want:      gotype.OpaqueType{PgTyp: pg.TextArray, Name: "[]*string"},
+		},
+		{
+			name:      "pointer bytea",
+			nullStyle: NullStylePointer,
+			pgType:    pg.Bytea,
+			nullable:  true,
+			want:      gotype.OpaqueType{PgTyp: pg.Bytea, Name: "[]byte"},
+		},
+		{
+			name:      "pointer without go type",
+			nullStyle: NullStylePointer,
+			pgType:    pg.Numeric,
+			nullable:  true,
+			want:      gotype.OpaqueType{PgTyp: pg.Numeric, PkgPath: "github.com/jackc/pgtype", Pkg: "pgtype", Name: "Numeric"},
+		},
+		{
+			name:      "pgtype",
+			nullStyle: NullStylePgtype,
+			pgType:    pg.Text,
+			nullable:  true,
+			want:      gotype.OpaqueType{PgTyp: pg.Text, PkgPath: "github.com/jackc/pgtype", Pkg: "pgtype", Name: "Text"},
+		},
+		{
+			name:      "sql",
+			nullStyle: NullStyleSQL,
+			pgType:    pg.Int8,
+			nullable:  true,
+			want:      gotype.OpaqueType{PgTyp: pg.Int8, PkgPath: "database/sql", Pkg: "sql", Name: "NullInt64"},
+		},
+		{
+			name:      "sql without sql type",
+			nullStyle: NullStyleSQL,
+			typeStyle: TypeStyleGo,
+			pgType:    pg.Interval,
+			nullable:  true,
+			want:      gotype.OpaqueType{PgTyp: pg.Interval, PkgPath: "time", Pkg: "time", Name: "*Duration"},
+		},
+		{
+			name:      "sql go style",
+			nullStyle: NullStyleSQL,
+			typeStyle: TypeStyleGo,
+			pgType:    pg.Timestamptz,
+			nullable:  true,
+			want:      gotype.OpaqueType{PgTyp: pg.Timestamptz, PkgPath: "database/sql", Pkg: "sql", Name: "NullTime"},
+		},
+		{
+			name:      "sql composite field",
+			nullStyle: NullStyleSQL,
+			pgType:    pg.CompositeType{Name: "user", ColumnNames: []string{"name"}, ColumnTypes: []pg.Type{pg.Text}},
+			nullable:  true,
+			want: gotype.CompositeType{
+				PgComposite: pg.CompositeType{Name: "user", ColumnNames: []string{"name"}, ColumnTypes: []pg.Type{pg.Text}},
+				PkgPath:     testPkgPath,
+				Pkg:         "test_resolve",
+				Name:        "User",
+				FieldNames:  []string{"Name"},
+				FieldTypes:  []gotype.Type{gotype.OpaqueType{PgTyp: pg.Text, Name: "*string"}},
+			},
+		},
+		{
+			name:      "generic",
+			nullStyle: NullStyleGeneric,
+			pgType:    pg.Text,
+			nullable:  true,
+			want:      nullString,
+		},
+		{
+			name:      "generic composite field",
+			nullStyle: NullStyleGeneric,
+			pgType:    pg.CompositeType{Name: "user", ColumnNames: []string{"name"}, ColumnTypes: []pg.Type{pg.Text}},
+			nullable:  true,
+			want: gotype.CompositeType{
+				PgComposite: pg.CompositeType{Name: "user", ColumnNames: []string{"name"}, ColumnTypes: []pg.Type{pg.Text}},
+				PkgPath:     testPkgPath,
+				Pkg:         "test_resolve",
+				Name:        "User",
+				FieldNames:  []string{"Name"},
+				FieldTypes:  []gotype.Type{nullString},
+			},
+		},
+		{
+			name:      "generic unsupported elem",
+			nullStyle: NullStyleGeneric,
+			typeStyle: TypeStyleGo,
+			pgType:    pg.UUID,
+			nullable:  true,
+			want:      gotype.OpaqueType{PgTyp: pg.UUID, Name: "*[16]byte"},
+		},
+		{
+			name:      "generic enum unchanged",
+			nullStyle: NullStyleGeneric,
+			pgType:    pg.EnumType{Name: "device_type", Labels: []string{"phone"}},
+			nullable:  true,
+			want: gotype.NewEnumType(testPkgPath,
+				pg.EnumType{Name: "device_type", Labels: []string{"phone"}}, caser),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := NewTypeResolver(caser, nil).WithNullStyle(tt.nullStyle)
+			if tt.typeStyle != "" {
+				resolver = resolver.WithTypeStyle(tt.typeStyle, "", "")
+			}
+			got, err := resolver.Resolve(tt.pgType, tt.nullable, testPkgPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got)
+			if nullType, ok := got.(gotype.NullType); ok {
+				assert.Equal(t, "Null[string]", nullType.BaseName())
+			}
+		})
+	}
+}
+
+func TestTypeResolver_Resolve_DomainStyle(t *testing.T) {
+	testPkgPath := "github.com/leg100/pggen/internal/codegen/golang/test_resolve"
+	caser := casing.NewCaser()
+	email := pg.DomainType{ID: 90000, Name: "email", BaseType: pg.Text}
+	amount := pg.DomainType{ID: 90001, Name: "amount", BaseType: pg.Numeric}
+	emailArray := pg.ArrayType{ID: 90002, Name: "_email", ElemType: email}
+	tests := []struct {
+		name        string
+		domainStyle DomainStyle
+		overrides   map[string]string
+		pgType      pg.Type
+		nullable    bool
+		want        gotype.Type
+	}{
+		{
+			name:        "base",
+			domainStyle: DomainStyleBase,
+			pgType:      email,
+			want:        gotype.OpaqueType{PgTyp: email, Name: "string"},
+		},
+		{
+			name:        "base nullable",
+			domainStyle: DomainStyleBase,
+			pgType:      email,
+			nullable:    true,
+			want:        gotype.OpaqueType{PgTyp: email, Name: "*string"},
+		},
+		{
+			name:        "base pgtype",
+			domainStyle: DomainStyleBase,
+			pgType:      amount,
+			nullable:    true,
+			want:        gotype.OpaqueType{PgTyp: amount, PkgPath: "github.com/jackc/pgtype", Pkg: "pgtype", Name: "Numeric"},
+		},
+		{
+			name:        "named",
+			domainStyle: DomainStyleNamed,
+			pgType:      email,
+			want: gotype.DomainType{
+				PgDomain: email,
+				PkgPath:  testPkgPath,
+				Pkg:      "test_resolve",
+				Name:     "Email",
+				Elem:     gotype.OpaqueType{Name: "string"},
+			},
+		},
+		{
+			name:        "named nullable",
+			domainStyle: DomainStyleNamed,
+			pgType:      email,
+			nullable:    true,
+			want: gotype.DomainType{
+				PgDomain: email,
+				PkgPath:  testPkgPath,
+				Pkg:      "test_resolve",
+				Name:     "*Email",
+				Elem:     gotype.OpaqueType{Name: "string"},
+			},
+		},
+		{
+			name:        "named without builtin base",
+			domainStyle: DomainStyleNamed,
+			pgType:      amount,
+			want:        gotype.OpaqueType{PgTyp: amount, PkgPath: "github.com/jackc/pgtype", Pkg: "pgtype", Name: "Numeric"},
+		},
+		{
+			name:        "base array",
+			domainStyle: DomainStyleBase,
+			pgType:      emailArray,
+			nullable:    true,
+			want:        gotype.OpaqueType{PgTyp: emailArray, Name: "[]string"},
+		},
+		{
+			name:        "named array",
+			domainStyle: DomainStyleNamed,
+			pgType:      emailArray,
+			want:        gotype.OpaqueType{PgTyp: emailArray, Name: "[]string"},
+		},
+		{
+			name:        "override",
+			domainStyle: DomainStyleNamed,
+			overrides:   map[string]string{"email": "example.com/mail.Address"},
+			pgType:      email,
+			want:        gotype.OpaqueType{PgTyp: email, PkgPath: "example.com/mail", Pkg: "mail", Name: "Address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := NewTypeResolver(caser, tt.overrides).WithDomainStyle(tt.domainStyle)
+			got, err := resolver.Resolve(tt.pgType, tt.nullable, testPkgPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTypeResolver_Resolve_Schema(t *testing.T) {
+	testPkgPath := "github.com/leg100/pggen/internal/codegen/golang/test_resolve"
+	caser := casing.NewCaser()
+	billingStatus := pg.EnumType{ID: 90000, Name: "status", Schema: "billing", Labels: []string{"paid"}}
+	shippingStatus := pg.EnumType{ID: 90001, Name: "status", Schema: "shipping", Labels: []string{"sent"}}
+	shippingStatuses := pg.ArrayType{ID: 90002, Name: "_status", Schema: "shipping", ElemType: shippingStatus}
+	invoice := pg.CompositeType{ID: 90003, Name: "invoice", Schema: "billing", ColumnNames: []string{"id"}, ColumnTypes: []pg.Type{pg.Int8}}
+	qualified := map[string]struct{}{"status": {}, "_status": {}}
+	tests := []struct {
+		name      string
+		overrides map[string]string
+		qualified map[string]struct{}
+		pgType    pg.Type
+		want      string // Go type name
+	}{
+		{
+			name:   "unique name",
+			pgType: billingStatus,
+			want:   "Status",
+		},
+		{
+			name:      "qualified enum",
+			qualified: qualified,
+			pgType:    billingStatus,
+			want:      "BillingStatus",
+		},
+		{
+			name:      "qualified array",
+			qualified: qualified,
+			pgType:    shippingStatuses,
+			want:      "[]ShippingStatus",
+		},
+		{
+			name:      "unqualified composite",
+			qualified: qualified,
+			pgType:    invoice,
+			want:      "Invoice",
+		},
+		{
+			name:      "schema override",
+			overrides: map[string]string{"billing.status": "example.com/billing.Status"},
+			qualified: qualified,
+			pgType:    billingStatus,
+			want:      "Status",
+		},
+		{
+			name:      "schema override other schema",
+			overrides: map[string]string{"billing.status": "example.com/billing.Status"},
+			qualified: qualified,
+			pgType:    shippingStatus,
+			want:      "ShippingStatus",
+		},
+		{
+			name:      "name override",
+			overrides: map[string]string{"status": "example.com/status.Status"},
+			qualified: qualified,
+			pgType:    shippingStatus,
+			want:      "Status",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := NewTypeResolver(caser, tt.overrides).WithQualifiedNames(tt.qualified)
+			got, err := resolver.Resolve(tt.pgType, true, testPkgPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got.BaseName())
+			assert.Equal(t, tt.pgType, got.PgType())
+		})
+	}
+}
+
+func TestTypeResolver_ResolveColumn(t *testing.T) {
+	testPkgPath := "github.com/leg100/pggen/internal/codegen/golang/test_resolve"
+	caser := casing.NewCaser()
+	overrides := map[string]string{
+		"public.users.id":    "example.com/ids.UserID",
+		"billing.users.id":   "example.com/ids.BillingUserID",
+		"public.orders.id":   "example.com/ids.OrderID,example.com/ids.NullOrderID",
+		"public.orders.tags": "[]string",
+		"int8":               "int64",
+	}
+	tests := []struct {
+		name     string
+		column   string
+		pgType   pg.Type
+		nullable bool
+		want     string // Go type relative to the test package
+	}{
+		{name: "users column", column: "public.users.id", pgType: pg.Int8, want: "ids.UserID"},
+		{name: "users column nullable", column: "public.users.id", pgType: pg.Int8, nullable: true, want: "ids.UserID"},
+		{name: "other schema column", column: "billing.users.id", pgType: pg.Int8, want: "ids.BillingUserID"},
+		{name: "orders column", column: "public.orders.id", pgType: pg.Int8, want: "ids.OrderID"},
+		{name: "orders column nullable", column: "public.orders.id", pgType: pg.Int8, nullable: true, want: "ids.NullOrderID"},
+		{name: "slice column nullable", column: "public.orders.tags", pgType: pg.TextArray, nullable: true, want: "[]string"},
+		{name: "other column", column: "public.orders.total", pgType: pg.Int8, want: "int64"},
+		{name: "other schema", column: "shipping.users.id", pgType: pg.Int8, want: "int64"},
+		{name: "no column", column: "", pgType: pg.Int8, want: "int64"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := NewTypeResolver(caser, overrides)
+			got, err := resolver.ResolveColumn(tt.column, tt.pgType, tt.nullable, testPkgPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got.QualifyRel(testPkgPath))
+			assert.Equal(t, tt.pgType, got.PgType())
+		})
+	}
+}
+
+func TestTypeResolver_Resolve_ColumnOverrideNotType(t *testing.T) {
+	testPkgPath := "github.com/leg100/pggen/internal/codegen/golang/test_resolve"
+	// A column override for the status column of the billing table doesn't
+	// map the status type in the billing schema.
+	resolver := NewTypeResolver(casing.NewCaser(), map[string]string{
+		"public.billing.status": "example.com/billing.Status",
+	})
+	status := pg.EnumType{ID: 90000, Name: "status", Schema: "billing", Labels: []string{"paid"}}
+	got, err := resolver.Resolve(status, false, testPkgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Status", got.BaseName())
+	assert.NotEqual(t, "billing.Status", got.QualifyRel(testPkgPath))
+}
+
+func TestTypeResolver_Resolve_NullableOverrides(t *testing.T) {
+	testPkgPath := "github.com/leg100/pggen/internal/codegen/golang/test_resolve"
+	caser := casing.NewCaser()
+	status := pg.EnumType{ID: 90000, Name: "status", Schema: "billing", Labels: []string{"paid"}}
+	statuses := pg.ArrayType{ID: 90001, Name: "_status", Schema: "billing", ElemType: status}
+	tests := []struct {
+		name      string
+		overrides map[string]string
+		pgType    pg.Type
+		nullable  bool
+		want      string // qualified Go type
+	}{
+		{
+			name:      "single type non-null",
+			overrides: map[string]string{"text": "string"},
+			pgType:    pg.Text,
+			want:      "string",
+		},
+		{
+			name:      "single type nullable",
+			overrides: map[string]string{"text": "string"},
+			pgType:    pg.Text,
+			nullable:  true,
+			want:      "string",
+		},
+		{
+			name:      "pair non-null",
+			overrides: map[string]string{"text": "string,*string"},
+			pgType:    pg.Text,
+			want:      "string",
+		},
+		{
+			name:      "pair nullable",
+			overrides: map[string]string{"text": "string,*string"},
+			pgType:    pg.Text,
+			nullable:  true,
+			want:      "*string",
+		},
+		{
+			name:      "pair array",
+			overrides: map[string]string{"text": "string, *string"},
+			pgType:    pg.TextArray,
+			nullable:  true,
+			want:      "[]string",
+		},
+		{
+			name:      "pair array override",
+			overrides: map[string]string{"text": "string,*string", "_text": "[]*string"},
+			pgType:    pg.TextArray,
+			want:      "[]*string",
+		},
+		{
+			name:      "pair alias array",
+			overrides: map[string]string{"bigint": "int64,*int64"},
+			pgType:    pg.Int8Array,
+			want:      "[]int64",
+		},
+		{
+			name:      "non-null only",
+			overrides: map[string]string{"int8": "example.com/ids.ID,"},
+			pgType:    pg.Int8,
+			want:      "ids.ID",
+		},
+		{
+			name:      "non-null only nullable default",
+			overrides: map[string]string{"int8": "example.com/ids.ID,"},
+			pgType:    pg.Int8,
+			nullable:  true,
+			want:      "*int",
+		},
+		{
+			name:      "nullable only non-null default",
+			overrides: map[string]string{"text": ",database/sql.NullString"},
+			pgType:    pg.Text,
+			want:      "string",
+		},
+		{
+			name:      "nullable only",
+			overrides: map[string]string{"text": ",database/sql.NullString"},
+			pgType:    pg.Text,
+			nullable:  true,
+			want:      "sql.NullString",
+		},
+		{
+			name:      "qualified pair array",
+			overrides: map[string]string{"billing.status": "example.com/billing.Status,*example.com/billing.Status"},
+			pgType:    statuses,
+			want:      "[]billing.Status",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := NewTypeResolver(caser, tt.overrides)
+			got, err := resolver.Resolve(tt.pgType, tt.nullable, testPkgPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got.QualifyRel(testPkgPath))
+			assert.Equal(t, tt.pgType, got.PgType())
+		})
+	}
+}
+
+func TestTypeResolver_Resolve_Range(t *testing.T) {
+	testPkgPath := "github.com/leg100/pggen/internal/codegen/golang/test_resolve"
+	caser := casing.NewCaser()
+	floatrange := pg.RangeType{ID: 90000, Name: "floatrange", ElemType: pg.Float8}
+	floatmultirange := pg.MultirangeType{ID: 90001, Name: "floatmultirange", RangeType: floatrange}
+	int4multirange := pg.MultirangeType{ID: 90002, Name: "int4multirange", RangeType: pg.Int4range}
+	pgFloat8 := gotype.OpaqueType{PgTyp: pg.Float8, PkgPath: "github.com/jackc/pgtype", Pkg: "pgtype", Name: "Float8"}
+	floatrangeType := gotype.RangeType{
+		PgRange: floatrange,
+		PkgPath: testPkgPath,
+		Pkg:     "test_resolve",
+		Name:    "Floatrange",
+		Elem:    pgFloat8,
+	}
+	tests := []struct {
+		name    string
+		pgType  pg.Type
+		want    gotype.Type
+		wantErr string
+	}{
+		{
+			name:   "range",
+			pgType: floatrange,
+			want:   floatrangeType,
+		},
+		{
+			name:   "multirange",
+			pgType: floatmultirange,
+			want: gotype.MultirangeType{
+				PgMultirange: floatmultirange,
+				PkgPath:      testPkgPath,
+				Pkg:          "test_resolve",
+				Name:         "[]Floatrange",
+				Elem:         floatrangeType,
+			},
+		},
+		{
+			name:   "multirange of builtin range",
+			pgType: int4multirange,
+			want: gotype.MultirangeType{
+				PgMultirange: int4multirange,
+				PkgPath:      "github.com/jackc/pgtype",
+				Pkg:          "pgtype",
+				Name:         "[]Int4range",
+				Elem:         gotype.OpaqueType{PgTyp: pg.Int4range, PkgPath: "github.com/jackc/pgtype", Pkg: "pgtype", Name: "Int4range"},
+			},
+		},
+		{
+			name:    "array of range",
+			pgType:  pg.ArrayType{ID: 90003, Name: "_floatrange", ElemType: floatrange},
+			wantErr: `arrays of range type "floatrange" aren't supported`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := NewTypeResolver(caser, nil)
+			got, err := resolver.Resolve(tt.pgType, true, testPkgPath)
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatalf("expected error containing %q; got nil", tt.wantErr)
+				}
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTypeResolver_ResolveJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		pgType   pg.Type
+		nullable bool
+		goType   string
+		want     gotype.Type
+		wantErr  bool
+	}{
+		{
+			name:   "jsonb",
+			pgType: pg.JSONB,
+			goType: "example.com/foo.Payload",
+			want:   gotype.JSONType{PgTyp: pg.JSONB, PkgPath: "example.com/foo", Pkg: "foo", Name: "Payload"},
+		},
+		{
+			name:     "jsonb nullable",
+			pgType:   pg.JSONB,
+			nullable: true,
+			goType:   "example.com/foo.Payload",
+			want:     gotype.JSONType{PgTyp: pg.JSONB, PkgPath: "example.com/foo", Pkg: "foo", Name: "*Payload"},
+		},
+		{
+			name:     "jsonb nullable pointer",
+			pgType:   pg.JSONB,
+			nullable: true,
+			goType:   "*example.com/foo.Payload",
+			want:     gotype.JSONType{PgTyp: pg.JSONB, PkgPath: "example.com/foo", Pkg: "foo", Name: "*Payload"},
+		},
+		{
+			name:     "jsonb array",
+			pgType:   pg.JSONBArray,
+			nullable: true,
+			goType:   "example.com/foo.Payload",
+			want:     gotype.JSONType{PgTyp: pg.JSONBArray, PkgPath: "example.com/foo", Pkg: "foo", Name: "[]Payload"},
+		},
+		{
+			name:    "text",
+			pgType:  pg.Text,
+			goType:  "example.com/foo.Payload",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := NewTypeResolver(casing.NewCaser(), nil)
+			got, err := resolver.ResolveJSON(tt.pgType, tt.nullable, tt.goType)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestType_QualifyRel(t *testing.T) {
 	caser := casing.NewCaser()
 	tests := []struct {
@@ -264,7 +1000,9 @@ func TestType_QualifyRel(t *testing.T) {
 
 func TestCreateCompositeType(t *testing.T) {
 	caser := casing.NewCaser()
-	resolver := NewTypeResolver(caser, nil)
+	resolver := NewTypeResolver(caser, nil).WithJSONTypes(map[string]string{
+		"event.body": "example.com/foo.Body",
+	})
 	tests := []struct {
 		pkgPath string
 		pgType  pg.CompositeType
@@ -293,6 +1031,29 @@ func TestCreateCompositeType(t *testing.T) {
 				},
 			},
 		},
+		{
+			pkgPath: "example.com/foo",
+			pgType: pg.CompositeType{
+				Name:        "event",
+				ColumnNames: []string{"body", "raw"},
+				ColumnTypes: []pg.Type{pg.JSONB, pg.JSONB},
+			},
+			want: gotype.CompositeType{
+				PgComposite: pg.CompositeType{
+					Name:        "event",
+					ColumnNames: []string{"body", "raw"},
+					ColumnTypes: []pg.Type{pg.JSONB, pg.JSONB},
+				},
+				PkgPath:    "example.com/foo",
+				Pkg:        "foo",
+				Name:       "Event",
+				FieldNames: []string{"Body", "Raw"},
+				FieldTypes: []gotype.Type{
+					gotype.JSONType{PgTyp: pg.JSONB, PkgPath: "example.com/foo", Pkg: "foo", Name: "*Body"},
+					gotype.OpaqueType{PgTyp: pg.JSONB, PkgPath: "github.com/jackc/pgtype", Pkg: "pgtype", Name: "JSONB"},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.pkgPath+" - "+tt.pgType.Name, func(t *testing.T) {
@@ -0,0 +1,337 @@
+// Package crud emits a query file with create, read, update, and delete
+// queries for tables. The query file is an ordinary pggen query file, so it
+// flows through the same parser, inferrer, and codegen as hand-written
+// queries.
+package crud
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/leg100/pggen/internal/casing"
+	"github.com/leg100/pggen/internal/pg"
+)
+
+// Header is the first line of an emitted query file.
+const Header = "-- Code generated by pggen. DO NOT EDIT."
+
+// Emit returns the contents of a query file with CRUD queries for each of
+// tables. Each query returns all columns of the table as a struct named after
+// the table, like Author, using the row-type pragma.
+//
+// For each table, Emit creates:
+//   - Insert<Table>: inserts a row using defaults for columns with a default.
+//   - Find<Table>By<Key>: finds a row by the primary key or a unique key.
+//   - Update<Table>: updates all non-key columns of a row by primary key.
+//   - Upsert<Table>: inserts a row or updates the row on primary key conflict.
+//   - Delete<Table>: deletes a row by primary key and returns the row.
+//
+// Tables without a primary key only get the insert and unique key queries.
+// If tables in different schemas have the same name, the queries include the
+// schema, like InsertBillingAuthor.
+func Emit(tables []pg.Table, caser casing.Caser) (string, error) {
+	counts := make(map[string]int, len(tables))
+	for _, table := range tables {
+		counts[table.Name]++
+	}
+	sb := &strings.Builder{}
+	sb.WriteString(Header)
+	sb.WriteString("\n")
+	names := make(map[string]string, len(tables)) // Go name to table QualName
+	for _, table := range tables {
+		e, err := newTableEmitter(table, caser, counts[table.Name] > 1)
+		if err != nil {
+			return "", err
+		}
+		if other, ok := names[e.name]; ok {
+			return "", fmt.Errorf("tables %s and %s have the same Go name %s", other, table.QualName, e.name)
+		}
+		names[e.name] = table.QualName
+		e.emitInsert(sb)
+		if len(table.PrimaryKey) > 0 {
+			e.emitFind(sb, table.PrimaryKey)
+		}
+		for _, key := range table.UniqueKeys {
+			e.emitFind(sb, key)
+		}
+		if len(table.PrimaryKey) > 0 {
+			e.emitUpdate(sb)
+			e.emitUpsert(sb)
+			e.emitDelete(sb)
+		}
+	}
+	return sb.String(), nil
+}
+
+// tableEmitter emits the queries for a single table.
+type tableEmitter struct {
+	table pg.Table
+	caser casing.Caser
+	name  string // the table name as a Go identifier, like Author or BillingAuthor
+	pk    map[string]bool
+}
+
+// newTableEmitter creates an emitter for table. If qualify is true, the Go
+// name of the table includes the schema, like BillingAuthor.
+func newTableEmitter(table pg.Table, caser casing.Caser, qualify bool) (tableEmitter, error) {
+	name := caser.ToUpperGoIdent(table.Name)
+	if qualify {
+		name = caser.ToUpperGoIdent(table.Schema + "_" + table.Name)
+	}
+	if name == "" {
+		return tableEmitter{}, fmt.Errorf("table %s has no valid Go identifier", table.QualName)
+	}
+	pk := make(map[string]bool, len(table.PrimaryKey))
+	for _, col := range table.PrimaryKey {
+		pk[col] = true
+	}
+	return tableEmitter{
+		table: table,
+		caser: caser,
+		name:  name,
+		pk:    pk,
+	}, nil
+}
+
+// emitQueryHeader writes the doc comment and name comment for a :one query
+// that returns the table row type.
+func (e tableEmitter) emitQueryHeader(sb *strings.Builder, name, doc string) {
+	sb.WriteString("\n-- ")
+	sb.WriteString(name)
+	sb.WriteString(" ")
+	sb.WriteString(doc)
+	sb.WriteString("\n-- name: ")
+	sb.WriteString(name)
+	sb.WriteString(" :one row-type=")
+	sb.WriteString(e.name)
+	sb.WriteString("\n")
+}
+
+func (e tableEmitter) emitInsert(sb *strings.Builder) {
+	name := "Insert" + e.name
+	e.emitQueryHeader(sb, name,
+		"inserts a row into "+e.table.Name+" and returns the row.")
+	cols := make([]string, 0, len(e.table.Columns))
+	for _, col := range e.table.Columns {
+		// An identity column has an implicit default.
+		if isWritable(col) && !col.HasDefault && !col.IsIdentity {
+			cols = append(cols, col.Name)
+		}
+	}
+	sb.WriteString("INSERT INTO ")
+	sb.WriteString(e.table.QualName)
+	if len(cols) == 0 {
+		sb.WriteString(" DEFAULT VALUES\n")
+	} else {
+		sb.WriteString(" (")
+		writeIdents(sb, cols)
+		sb.WriteString(")\nVALUES (")
+		writeArgs(sb, cols)
+		sb.WriteString(")\n")
+	}
+	e.emitReturning(sb)
+}
+
+func (e tableEmitter) emitFind(sb *strings.Builder, key []string) {
+	name := "Find" + e.name + "By" + e.keyName(key)
+	e.emitQueryHeader(sb, name,
+		"finds a row in "+e.table.Name+" by "+strings.Join(key, ", ")+".")
+	sb.WriteString("SELECT *\nFROM ")
+	sb.WriteString(e.table.QualName)
+	sb.WriteString("\n")
+	writeWhere(sb, key)
+	sb.WriteString(";\n")
+}
+
+func (e tableEmitter) emitUpdate(sb *strings.Builder) {
+	cols := e.updatableColumns()
+	if len(cols) == 0 {
+		return
+	}
+	name := "Update" + e.name
+	e.emitQueryHeader(sb, name,
+		"updates a row in "+e.table.Name+" by "+strings.Join(e.table.PrimaryKey, ", ")+
+			" and returns the row.")
+	sb.WriteString("UPDATE ")
+	sb.WriteString(e.table.QualName)
+	sb.WriteString("\nSET ")
+	for i, col := range cols {
+		if i > 0 {
+			sb.WriteString(",\n    ")
+		}
+		sb.WriteString(quoteIdent(col))
+		sb.WriteString(" = ")
+		writeArg(sb, col)
+	}
+	sb.WriteString("\n")
+	writeWhere(sb, e.table.PrimaryKey)
+	sb.WriteString("\n")
+	e.emitReturning(sb)
+}
+
+func (e tableEmitter) emitUpsert(sb *strings.Builder) {
+	updateCols := e.updatableColumns()
+	if len(updateCols) == 0 {
+		return
+	}
+	cols := make([]string, 0, len(e.table.Columns))
+	for _, col := range e.table.Columns {
+		if !isWritable(col) {
+			if e.pk[col.Name] {
+				return // can't insert the primary key so there's no conflict to upsert
+			}
+			continue
+		}
+		cols = append(cols, col.Name)
+	}
+	name := "Upsert" + e.name
+	e.emitQueryHeader(sb, name,
+		"inserts or updates a row in "+e.table.Name+" by "+
+			strings.Join(e.table.PrimaryKey, ", ")+" and returns the row.")
+	sb.WriteString("INSERT INTO ")
+	sb.WriteString(e.table.QualName)
+	sb.WriteString(" (")
+	writeIdents(sb, cols)
+	sb.WriteString(")\nVALUES (")
+	writeArgs(sb, cols)
+	sb.WriteString(")\nON CONFLICT (")
+	writeIdents(sb, e.table.PrimaryKey)
+	sb.WriteString(") DO UPDATE\nSET ")
+	for i, col := range updateCols {
+		if i > 0 {
+			sb.WriteString(",\n    ")
+		}
+		sb.WriteString(quoteIdent(col))
+		sb.WriteString(" = excluded.")
+		sb.WriteString(quoteIdent(col))
+	}
+	sb.WriteString("\n")
+	e.emitReturning(sb)
+}
+
+func (e tableEmitter) emitDelete(sb *strings.Builder) {
+	name := "Delete" + e.name
+	e.emitQueryHeader(sb, name,
+		"deletes a row from "+e.table.Name+" by "+strings.Join(e.table.PrimaryKey, ", ")+
+			" and returns the row.")
+	sb.WriteString("DELETE FROM ")
+	sb.WriteString(e.table.QualName)
+	sb.WriteString("\n")
+	writeWhere(sb, e.table.PrimaryKey)
+	sb.WriteString("\n")
+	e.emitReturning(sb)
+}
+
+func (e tableEmitter) emitReturning(sb *strings.Builder) {
+	sb.WriteString("RETURNING *;\n")
+}
+
+// updatableColumns returns the names of the writable columns that aren't part
+// of the primary key.
+func (e tableEmitter) updatableColumns() []string {
+	cols := make([]string, 0, len(e.table.Columns))
+	for _, col := range e.table.Columns {
+		if isWritable(col) && !e.pk[col.Name] {
+			cols = append(cols, col.Name)
+		}
+	}
+	return cols
+}
+
+// keyName returns the Go identifier for a key, like AuthorIDAndEmail.
+func (e tableEmitter) keyName(key []string) string {
+	sb := &strings.Builder{}
+	for i, col := range key {
+		if i > 0 {
+			sb.WriteString("And")
+		}
+		sb.WriteString(e.caser.ToUpperGoIdent(col))
+	}
+	return sb.String()
+}
+
+// isWritable returns true if a query can set the value of the column. A
+// GENERATED BY DEFAULT identity column accepts explicit values.
+func isWritable(col pg.TableColumn) bool {
+	return !col.IsGenerated && !col.IsIdentityAlways
+}
+
+func writeIdents(sb *strings.Builder, cols []string) {
+	for i, col := range cols {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(quoteIdent(col))
+	}
+}
+
+func writeArgs(sb *strings.Builder, cols []string) {
+	for i, col := range cols {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		writeArg(sb, col)
+	}
+}
+
+// writeArg writes a pggen.arg for the column. The codegen converts the
+// column name into a Go identifier like any other argument name.
+func writeArg(sb *strings.Builder, col string) {
+	sb.WriteString("pggen.arg('")
+	sb.WriteString(strings.ReplaceAll(col, "'", "''"))
+	sb.WriteString("')")
+}
+
+func writeWhere(sb *strings.Builder, key []string) {
+	sb.WriteString("WHERE ")
+	for i, col := range key {
+		if i > 0 {
+			sb.WriteString("\n  AND ")
+		}
+		sb.WriteString(quoteIdent(col))
+		sb.WriteString(" = ")
+		writeArg(sb, col)
+	}
+}
+
+var simpleIdentRegexp = regexp.MustCompile(`^[a-z_][a-z0-9_$]*$`)
+
+// quoteIdent quotes a Postgres identifier if the identifier isn't a simple,
+// lowercase identifier or if it's a reserved keyword.
+func quoteIdent(name string) string {
+	if simpleIdentRegexp.MatchString(name) && !reservedKeywords[name] {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// reservedKeywords are the Postgres keywords that can't be a column name
+// without quotes.
+// https://www.postgresql.org/docs/13/sql-keywords-appendix.html
+var reservedKeywords = map[string]bool{
+	"all": true, "analyse": true, "analyze": true, "and": true, "any": true,
+	"array": true, "as": true, "asc": true, "asymmetric": true,
+	"authorization": true, "binary": true, "both": true, "case": true,
+	"cast": true, "check": true, "collate": true, "collation": true,
+	"column": true, "concurrently": true, "constraint": true, "create": true,
+	"cross": true, "current_catalog": true, "current_date": true,
+	"current_role": true, "current_schema": true, "current_time": true,
+	"current_timestamp": true, "current_user": true, "default": true,
+	"deferrable": true, "desc": true, "distinct": true, "do": true,
+	"else": true, "end": true, "except": true, "false": true, "fetch": true,
+	"for": true, "foreign": true, "freeze": true, "from": true, "full": true,
+	"grant": true, "group": true, "having": true, "ilike": true, "in": true,
+	"initially": true, "inner": true, "intersect": true, "into": true,
+	"is": true, "isnull": true, "join": true, "lateral": true, "leading": true,
+	"left": true, "like": true, "limit": true, "localtime": true,
+	"localtimestamp": true, "natural": true, "not": true, "notnull": true,
+	"null": true, "offset": true, "on": true, "only": true, "or": true,
+	"order": true, "outer": true, "overlaps": true, "placing": true,
+	"primary": true, "references": true, "returning": true, "right": true,
+	"select": true, "session_user": true, "similar": true, "some": true,
+	"symmetric": true, "table": true, "tablesample": true, "then": true,
+	"to": true, "trailing": true, "true": true, "union": true, "unique": true,
+	"user": true, "using": true, "variadic": true, "verbose": true,
+	"when": true, "where": true, "window": true, "with": true,
+}
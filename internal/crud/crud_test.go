@@ -0,0 +1,235 @@
+package crud
+
+import (
+	gotok "go/token"
+	"testing"
+
+	"github.com/leg100/pggen/internal/casing"
+	"github.com/leg100/pggen/internal/parser"
+	"github.com/leg100/pggen/internal/pg"
+	"github.com/leg100/pggen/internal/texts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmit(t *testing.T) {
+	tests := []struct {
+		name  string
+		table pg.Table
+		want  string
+	}{
+		{
+			name: "primary and unique keys",
+			table: pg.Table{
+				Name:     "author",
+				QualName: "author",
+				Columns: []pg.TableColumn{
+					{Name: "author_id", Number: 1, NotNull: true, HasDefault: true},
+					{Name: "first_name", Number: 2, NotNull: true},
+					{Name: "email", Number: 3},
+				},
+				PrimaryKey: []string{"author_id"},
+				UniqueKeys: [][]string{{"email"}},
+			},
+			want: texts.Dedent(`
+				-- Code generated by pggen. DO NOT EDIT.
+
+				-- InsertAuthor inserts a row into author and returns the row.
+				-- name: InsertAuthor :one row-type=Author
+				INSERT INTO author (first_name, email)
+				VALUES (pggen.arg('first_name'), pggen.arg('email'))
+				RETURNING *;
+
+				-- FindAuthorByAuthorID finds a row in author by author_id.
+				-- name: FindAuthorByAuthorID :one row-type=Author
+				SELECT *
+				FROM author
+				WHERE author_id = pggen.arg('author_id');
+
+				-- FindAuthorByEmail finds a row in author by email.
+				-- name: FindAuthorByEmail :one row-type=Author
+				SELECT *
+				FROM author
+				WHERE email = pggen.arg('email');
+
+				-- UpdateAuthor updates a row in author by author_id and returns the row.
+				-- name: UpdateAuthor :one row-type=Author
+				UPDATE author
+				SET first_name = pggen.arg('first_name'),
+				    email = pggen.arg('email')
+				WHERE author_id = pggen.arg('author_id')
+				RETURNING *;
+
+				-- UpsertAuthor inserts or updates a row in author by author_id and returns the row.
+				-- name: UpsertAuthor :one row-type=Author
+				INSERT INTO author (author_id, first_name, email)
+				VALUES (pggen.arg('author_id'), pggen.arg('first_name'), pggen.arg('email'))
+				ON CONFLICT (author_id) DO UPDATE
+				SET first_name = excluded.first_name,
+				    email = excluded.email
+				RETURNING *;
+
+				-- DeleteAuthor deletes a row from author by author_id and returns the row.
+				-- name: DeleteAuthor :one row-type=Author
+				DELETE FROM author
+				WHERE author_id = pggen.arg('author_id')
+				RETURNING *;
+			`) + "\n",
+		},
+		{
+			name: "no primary key",
+			table: pg.Table{
+				Name:     "log",
+				QualName: "log",
+				Columns: []pg.TableColumn{
+					{Name: "msg", Number: 1},
+					{Name: "id", Number: 2, NotNull: true, IsIdentity: true, IsIdentityAlways: true},
+				},
+			},
+			want: texts.Dedent(`
+				-- Code generated by pggen. DO NOT EDIT.
+
+				-- InsertLog inserts a row into log and returns the row.
+				-- name: InsertLog :one row-type=Log
+				INSERT INTO log (msg)
+				VALUES (pggen.arg('msg'))
+				RETURNING *;
+			`) + "\n",
+		},
+		{
+			name: "identity by default",
+			table: pg.Table{
+				Name:     "tag",
+				QualName: "tag",
+				Columns: []pg.TableColumn{
+					{Name: "id", Number: 1, NotNull: true, IsIdentity: true},
+					{Name: "name", Number: 2},
+				},
+				PrimaryKey: []string{"id"},
+			},
+			want: texts.Dedent(`
+				-- Code generated by pggen. DO NOT EDIT.
+
+				-- InsertTag inserts a row into tag and returns the row.
+				-- name: InsertTag :one row-type=Tag
+				INSERT INTO tag (name)
+				VALUES (pggen.arg('name'))
+				RETURNING *;
+
+				-- FindTagByID finds a row in tag by id.
+				-- name: FindTagByID :one row-type=Tag
+				SELECT *
+				FROM tag
+				WHERE id = pggen.arg('id');
+
+				-- UpdateTag updates a row in tag by id and returns the row.
+				-- name: UpdateTag :one row-type=Tag
+				UPDATE tag
+				SET name = pggen.arg('name')
+				WHERE id = pggen.arg('id')
+				RETURNING *;
+
+				-- UpsertTag inserts or updates a row in tag by id and returns the row.
+				-- name: UpsertTag :one row-type=Tag
+				INSERT INTO tag (id, name)
+				VALUES (pggen.arg('id'), pggen.arg('name'))
+				ON CONFLICT (id) DO UPDATE
+				SET name = excluded.name
+				RETURNING *;
+
+				-- DeleteTag deletes a row from tag by id and returns the row.
+				-- name: DeleteTag :one row-type=Tag
+				DELETE FROM tag
+				WHERE id = pggen.arg('id')
+				RETURNING *;
+			`) + "\n",
+		},
+		{
+			name: "schema-qualified with reserved words",
+			table: pg.Table{
+				Name:     "user",
+				QualName: `auth."user"`,
+				Columns: []pg.TableColumn{
+					{Name: "id", Number: 1, NotNull: true, IsIdentity: true, IsIdentityAlways: true},
+					{Name: "order", Number: 2},
+				},
+				PrimaryKey: []string{"id"},
+			},
+			want: texts.Dedent(`
+				-- Code generated by pggen. DO NOT EDIT.
+
+				-- InsertUser inserts a row into user and returns the row.
+				-- name: InsertUser :one row-type=User
+				INSERT INTO auth."user" ("order")
+				VALUES (pggen.arg('order'))
+				RETURNING *;
+
+				-- FindUserByID finds a row in user by id.
+				-- name: FindUserByID :one row-type=User
+				SELECT *
+				FROM auth."user"
+				WHERE id = pggen.arg('id');
+
+				-- UpdateUser updates a row in user by id and returns the row.
+				-- name: UpdateUser :one row-type=User
+				UPDATE auth."user"
+				SET "order" = pggen.arg('order')
+				WHERE id = pggen.arg('id')
+				RETURNING *;
+
+				-- DeleteUser deletes a row from user by id and returns the row.
+				-- name: DeleteUser :one row-type=User
+				DELETE FROM auth."user"
+				WHERE id = pggen.arg('id')
+				RETURNING *;
+			`) + "\n",
+		},
+	}
+	caser := casing.NewCaser()
+	caser.AddAcronym("id", "ID")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Emit([]pg.Table{tt.table}, caser)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, got)
+			// The emitted queries must be a valid query file.
+			if _, err := parser.ParseFile(gotok.NewFileSet(), "", got, 0); err != nil {
+				t.Errorf("parse emitted query file: %s", err)
+			}
+		})
+	}
+}
+
+func TestEmit_SameTableName(t *testing.T) {
+	caser := casing.NewCaser()
+	tables := []pg.Table{
+		{Name: "author", Schema: "public", QualName: "author", Columns: []pg.TableColumn{{Name: "name", Number: 1}}},
+		{Name: "author", Schema: "billing", QualName: "billing.author", Columns: []pg.TableColumn{{Name: "name", Number: 1}}},
+	}
+	got, err := Emit(tables, caser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := texts.Dedent(`
+		-- Code generated by pggen. DO NOT EDIT.
+
+		-- InsertPublicAuthor inserts a row into author and returns the row.
+		-- name: InsertPublicAuthor :one row-type=PublicAuthor
+		INSERT INTO author (name)
+		VALUES (pggen.arg('name'))
+		RETURNING *;
+
+		-- InsertBillingAuthor inserts a row into author and returns the row.
+		-- name: InsertBillingAuthor :one row-type=BillingAuthor
+		INSERT INTO billing.author (name)
+		VALUES (pggen.arg('name'))
+		RETURNING *;
+	`) + "\n"
+	assert.Equal(t, want, got)
+
+	// A qualified name might collide with another table.
+	tables = append(tables, pg.Table{Name: "billing_author", Schema: "public", QualName: "billing_author"})
+	_, err = Emit(tables, caser)
+	assert.EqualError(t, err, "tables billing.author and billing_author have the same Go name BillingAuthor")
+}
@@ -10,6 +10,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 type parser struct {
@@ -258,6 +259,11 @@ func (p *parser) parseQuery() ast.Query {
 		p.error(pos, "invalid query pragma: "+err.Error())
 		return &ast.BadQuery{From: pos, To: p.pos}
 	}
+	resultKind := ast.ResultKind(annotations[2])
+	if pragmas.Paginate != ast.PaginateNone && resultKind != ast.ResultKindMany {
+		p.error(pos, "invalid query pragma: paginate requires a :many query; got "+string(resultKind))
+		return &ast.BadQuery{From: pos, To: p.pos}
+	}
 
 	templateSQL := sql.String()
 	preparedSQL, params := prepareSQL(templateSQL, names)
@@ -269,7 +275,7 @@ func (p *parser) parseQuery() ast.Query {
 		SourceSQL:   templateSQL,
 		PreparedSQL: preparedSQL,
 		ParamNames:  params,
-		ResultKind:  ast.ResultKind(annotations[2]),
+		ResultKind:  resultKind,
 		Pragmas:     pragmas,
 		Semi:        semi,
 	}
@@ -295,6 +301,38 @@ func parsePragmas(allPragmas string) (ast.Pragmas, error) {
 				return ast.Pragmas{}, err
 			}
 			qp.ProtobufType = p
+		case "paginate":
+			switch kind := ast.PaginateKind(val); kind {
+			case ast.PaginateKeyset:
+				qp.Paginate = kind
+			default:
+				return ast.Pragmas{}, fmt.Errorf("unsupported paginate kind %q; want %q", val, ast.PaginateKeyset)
+			}
+		case "route":
+			switch kind := ast.RouteKind(val); kind {
+			case ast.RoutePrimary:
+				qp.Route = kind
+			default:
+				return ast.Pragmas{}, fmt.Errorf("unsupported route kind %q; want %q", val, ast.RoutePrimary)
+			}
+		case "row-type":
+			if !isExportedIdent(val) {
+				return ast.Pragmas{}, fmt.Errorf("invalid row-type, must be an exported Go identifier; got %q", val)
+			}
+			qp.RowType = val
+		case "json-type":
+			idx := strings.IndexByte(val, ':')
+			if idx <= 0 || idx == len(val)-1 {
+				return ast.Pragmas{}, fmt.Errorf("expected json-type format <name>:<go type>; got %s", val)
+			}
+			name, goType := val[:idx], val[idx+1:]
+			if _, ok := qp.JSONTypes[name]; ok {
+				return ast.Pragmas{}, fmt.Errorf("duplicate json-type for %q", name)
+			}
+			if qp.JSONTypes == nil {
+				qp.JSONTypes = make(map[string]string, 1)
+			}
+			qp.JSONTypes[name] = goType
 		default:
 			return ast.Pragmas{}, fmt.Errorf("unsupported pramga %q", key)
 		}
@@ -327,6 +365,19 @@ func validateProtoMsgType(val string) (string, error) {
 	return val, nil
 }
 
+// isExportedIdent returns true if val is an exported Go identifier.
+func isExportedIdent(val string) bool {
+	for i, r := range val {
+		switch {
+		case i == 0 && !unicode.IsUpper(r):
+			return false
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_':
+			return false
+		}
+	}
+	return val != ""
+}
+
 // argPos is the name and position of expression like pggen.arg('foo').
 type argPos struct {
 	lo, hi int
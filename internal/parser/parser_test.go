@@ -120,6 +120,57 @@ func TestParseFile_Queries(t *testing.T) {
 				Pragmas:     ast.Pragmas{ProtobufType: "Bar"},
 			},
 		},
+		{
+			"-- name: Qux :many paginate=keyset\nSELECT 1 ORDER BY 1;",
+			&ast.SourceQuery{
+				Name:        "Qux",
+				Doc:         &ast.CommentGroup{List: []*ast.LineComment{{Text: "-- name: Qux :many paginate=keyset"}}},
+				SourceSQL:   "SELECT 1 ORDER BY 1;",
+				PreparedSQL: "SELECT 1 ORDER BY 1;",
+				ParamNames:  nil,
+				ResultKind:  ast.ResultKindMany,
+				Pragmas:     ast.Pragmas{Paginate: ast.PaginateKeyset},
+			},
+		},
+		{
+			"-- name: Qux :one route=primary\nSELECT 1;",
+			&ast.SourceQuery{
+				Name:        "Qux",
+				Doc:         &ast.CommentGroup{List: []*ast.LineComment{{Text: "-- name: Qux :one route=primary"}}},
+				SourceSQL:   "SELECT 1;",
+				PreparedSQL: "SELECT 1;",
+				ParamNames:  nil,
+				ResultKind:  ast.ResultKindOne,
+				Pragmas:     ast.Pragmas{Route: ast.RoutePrimary},
+			},
+		},
+		{
+			"-- name: Qux :one json-type=payload:example.com/foo.Payload json-type=meta:*example.com/foo.Meta\nSELECT 1;",
+			&ast.SourceQuery{
+				Name:        "Qux",
+				Doc:         &ast.CommentGroup{List: []*ast.LineComment{{Text: "-- name: Qux :one json-type=payload:example.com/foo.Payload json-type=meta:*example.com/foo.Meta"}}},
+				SourceSQL:   "SELECT 1;",
+				PreparedSQL: "SELECT 1;",
+				ParamNames:  nil,
+				ResultKind:  ast.ResultKindOne,
+				Pragmas: ast.Pragmas{JSONTypes: map[string]string{
+					"payload": "example.com/foo.Payload",
+					"meta":    "*example.com/foo.Meta",
+				}},
+			},
+		},
+		{
+			"-- name: Qux :one row-type=Author\nSELECT 1;",
+			&ast.SourceQuery{
+				Name:        "Qux",
+				Doc:         &ast.CommentGroup{List: []*ast.LineComment{{Text: "-- name: Qux :one row-type=Author"}}},
+				SourceSQL:   "SELECT 1;",
+				PreparedSQL: "SELECT 1;",
+				ParamNames:  nil,
+				ResultKind:  ast.ResultKindOne,
+				Pragmas:     ast.Pragmas{RowType: "Author"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -139,6 +190,30 @@ func TestParseFile_Queries(t *testing.T) {
 
 }
 
+func TestParseFile_Queries_InvalidPragma(t *testing.T) {
+	tests := []struct {
+		src string
+	}{
+		{"-- name: Qux :many foo=bar\nSELECT 1;"},
+		{"-- name: Qux :many paginate=offset\nSELECT 1;"},
+		{"-- name: Qux :one paginate=keyset\nSELECT 1;"},
+		{"-- name: Qux :one route=replica\nSELECT 1;"},
+		{"-- name: Qux :one json-type=payload\nSELECT 1;"},
+		{"-- name: Qux :one json-type=payload:\nSELECT 1;"},
+		{"-- name: Qux :one json-type=a:foo.A json-type=a:foo.B\nSELECT 1;"},
+		{"-- name: Qux :one row-type=author\nSELECT 1;"},
+		{"-- name: Qux :one row-type=foo.Author\nSELECT 1;"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := ParseFile(gotok.NewFileSet(), "", tt.src, Trace)
+			if err == nil {
+				t.Fatal("expected error for invalid pragma")
+			}
+		})
+	}
+}
+
 func TestParseFile_Queries_Fuzz(t *testing.T) {
 	tests := []struct {
 		src string
@@ -0,0 +1,162 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+	"github.com/leg100/pggen/internal/texts"
+)
+
+// Catalog caches the Postgres catalog for a single connection: the columns,
+// the tables with their constraints, and the types. Call Invalidate after
+// changing the schema, like with CREATE TABLE or ALTER TYPE, so the Catalog
+// doesn't return stale entries.
+type Catalog struct {
+	conn    *pgx.Conn
+	mu      *sync.Mutex
+	columns map[ColumnKey]Column
+	tables  map[string]Table // keyed by the table name as requested
+	types   *TypeFetcher
+	stats   FetchStats // stats for the column and table queries
+}
+
+// NewCatalog creates an empty Catalog for conn.
+func NewCatalog(conn *pgx.Conn) *Catalog {
+	return &Catalog{
+		conn:    conn,
+		mu:      &sync.Mutex{},
+		columns: make(map[ColumnKey]Column, 32),
+		tables:  make(map[string]Table),
+		types:   NewTypeFetcher(conn),
+	}
+}
+
+// FetchColumns returns the Postgres columns for keys in the order of keys,
+// fetching uncached columns from the catalog. A key for a column not directly
+// backed by a table, like a computed output column, has an empty Column.
+func (c *Catalog) FetchColumns(keys []ColumnKey) ([]Column, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	uncachedKeys := make([]ColumnKey, 0, len(keys))
+	for _, key := range keys {
+		if _, ok := c.columns[key]; !ok && key.TableOID > 0 {
+			uncachedKeys = append(uncachedKeys, key)
+		}
+	}
+	if len(uncachedKeys) > 0 {
+		cols, err := fetchColumnsByKey(c.conn, uncachedKeys, &c.stats)
+		if err != nil {
+			return nil, err
+		}
+		for key, col := range cols {
+			c.columns[key] = col
+		}
+	}
+	return orderColumns(c.columns, keys), nil
+}
+
+// FetchTables returns the tables with the given names in the order of names,
+// omitting a table already returned for an earlier name. Fetches uncached
+// tables from the catalog. See FetchTables.
+func (c *Catalog) FetchTables(names []string) ([]Table, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	uncachedNames := make([]string, 0, len(names))
+	for _, name := range names {
+		if _, ok := c.tables[name]; !ok {
+			uncachedNames = append(uncachedNames, name)
+		}
+	}
+	if len(uncachedNames) > 0 {
+		tables, err := fetchTablesByName(c.conn, uncachedNames, &c.stats)
+		if err != nil {
+			return nil, err
+		}
+		if len(tables) != len(uncachedNames) {
+			return nil, fmt.Errorf("fetch tables: got %d tables for %d names", len(tables), len(uncachedNames))
+		}
+		for i, table := range tables {
+			c.tables[uncachedNames[i]] = table
+		}
+	}
+	tables := make([]Table, len(names))
+	for i, name := range names {
+		tables[i] = c.tables[name]
+	}
+	return uniqueTables(tables), nil
+}
+
+// FindTypesByOIDs returns a map of a type OID to the Type description,
+// fetching uncached types from the catalog. See TypeFetcher.FindTypesByOIDs.
+func (c *Catalog) FindTypesByOIDs(oids ...uint32) (map[pgtype.OID]Type, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.types.FindTypesByOIDs(oids...)
+}
+
+// FetchSharedTypeNames returns the names of the types that exist in more than
+// one user schema, like status for billing.status and shipping.status. Doesn't
+// cache the names.
+func (c *Catalog) FetchSharedTypeNames() (map[string]struct{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	defer c.stats.Record(time.Now())
+	rows, err := c.conn.Query(ctx, texts.Dedent(`
+		SELECT typ.typname
+		FROM pg_type typ
+		  JOIN pg_namespace ns ON ns.oid = typ.typnamespace
+		WHERE ns.nspname NOT IN ('pg_catalog', 'information_schema')
+		  AND ns.nspname NOT LIKE 'pg\_toast%'
+		  AND ns.nspname NOT LIKE 'pg\_temp\_%'
+		GROUP BY typ.typname
+		HAVING count(*) > 1
+	`))
+	if err != nil {
+		return nil, fmt.Errorf("fetch shared type names: %w", err)
+	}
+	defer rows.Close()
+	names := make(map[string]struct{})
+	for rows.Next() {
+		name := ""
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scan shared type name: %w", err)
+		}
+		names[name] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("close shared type name rows: %w", err)
+	}
+	return names, nil
+}
+
+// Stats returns the stats for all catalog queries sent to fetch columns,
+// tables, types, and shared type names.
+func (c *Catalog) Stats() FetchStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats.Add(c.types.Stats())
+}
+
+// Invalidate removes all cached entries so that later calls fetch the current
+// catalog. Call after changing the schema.
+func (c *Catalog) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.columns = make(map[ColumnKey]Column, 32)
+	c.tables = make(map[string]Table)
+	stats := c.types.Stats()
+	c.types = NewTypeFetcher(c.conn)
+	c.types.stats = stats
+}
@@ -0,0 +1,94 @@
+package pg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/leg100/pggen/internal/pgtest"
+	"github.com/leg100/pggen/internal/texts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalog_Invalidate(t *testing.T) {
+	conn, cleanup := pgtest.NewPostgresSchemaString(t, texts.Dedent(`
+		CREATE TABLE author (
+			author_id  serial PRIMARY KEY,
+			first_name text NOT NULL
+		);
+	`))
+	defer cleanup()
+	oid := findTableOID(t, conn, "author")
+	keys := []ColumnKey{{TableOID: oid, Number: 2}}
+	catalog := NewCatalog(conn)
+
+	cols, err := catalog.FetchColumns(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "first_name", cols[0].Name)
+	tables, err := catalog.FetchTables([]string{"author", "author"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, tables, 1, "FetchTables() should omit duplicate tables")
+	assert.Equal(t, []string{"author_id"}, tables[0].PrimaryKey)
+	// One round-trip for the columns and three for the tables.
+	assert.Equal(t, 4, catalog.Stats().RoundTrips, "round-trips after fetch")
+
+	if _, err := conn.Exec(context.Background(), texts.Dedent(`
+		ALTER TABLE author RENAME COLUMN first_name TO given_name;
+		ALTER TABLE author ADD UNIQUE (given_name);
+	`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Stale until invalidated.
+	cols, err = catalog.FetchColumns(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "first_name", cols[0].Name, "cached column before Invalidate")
+	assert.Equal(t, 4, catalog.Stats().RoundTrips, "round-trips after cached fetch")
+
+	catalog.Invalidate()
+	cols, err = catalog.FetchColumns(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "given_name", cols[0].Name, "fetched column after Invalidate")
+	tables, err = catalog.FetchTables([]string{"author"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, [][]string{{"given_name"}}, tables[0].UniqueKeys)
+	assert.Equal(t, 8, catalog.Stats().RoundTrips, "round-trips after Invalidate")
+}
+
+func TestCatalog_FetchSharedTypeNames(t *testing.T) {
+	conn, cleanup := pgtest.NewPostgresSchemaString(t, texts.Dedent(`
+		CREATE TYPE shared_status AS ENUM ('paid');
+		CREATE TYPE unique_status AS ENUM ('sent');
+	`))
+	defer cleanup()
+	other := findCurrentSchema(t, conn) + "_other"
+	ctx := context.Background()
+	if _, err := conn.Exec(ctx, "CREATE SCHEMA "+other+"; CREATE TYPE "+other+".shared_status AS ENUM ('sent')"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if _, err := conn.Exec(ctx, "DROP SCHEMA "+other+" CASCADE"); err != nil {
+			t.Error(err)
+		}
+	}()
+	catalog := NewCatalog(conn)
+
+	names, err := catalog.FetchSharedTypeNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, names, "shared_status")
+	assert.Contains(t, names, "_shared_status", "array types share the name too")
+	assert.NotContains(t, names, "unique_status")
+	assert.NotContains(t, names, "text", "ignores pg_catalog types")
+	assert.Equal(t, 1, catalog.Stats().RoundTrips)
+}
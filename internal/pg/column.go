@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/jackc/pgtype"
@@ -19,9 +18,10 @@ type Column struct {
 	Name      string     // pg_attribute.attname: column name
 	TableOID  pgtype.OID // pg_attribute:attrelid: table the column belongs to
 	TableName string     // pg_class.relname: name of table that owns the column
+	Schema    string     // pg_namespace.nspname: schema of the table that owns the column
 	Number    uint16     // pg_attribute.attnum: the number of column starting from 1
-	Type      Type       // pg_attribute.atttypid: data type of the column
-	Null      bool       // pg_attribute.attnotnull: represents a not-null constraint
+	TypeOID   pgtype.OID // pg_attribute.atttypid: data type of the column
+	Null      bool       // pg_attribute.attnotnull or pg_type.typnotnull: represents a not-null constraint on the column or its domain
 }
 
 // ColumnKey is a composite key of a table OID and the number of the column
@@ -31,57 +31,66 @@ type ColumnKey struct {
 	Number   uint16 // the number of column starting from 1
 }
 
-var (
-	columnsMu    = &sync.Mutex{}
-	columnsCache = make(map[ColumnKey]Column, 32)
-)
-
-// FetchColumns fetches meta information about a Postgres column from the
-// pg_class and pg_attribute catalog tables.
+// FetchColumns fetches meta information about Postgres columns from the
+// pg_class and pg_attribute catalog tables. Returns the columns in the order of
+// keys. A key for a column not directly backed by a table, like a computed
+// output column, has an empty Column. Doesn't cache the columns; use
+// Catalog.FetchColumns to cache.
 func FetchColumns(conn *pgx.Conn, keys []ColumnKey) ([]Column, error) {
 	if len(keys) == 0 {
 		return nil, nil
 	}
-
-	// Try cache first.
-	uncachedKeys := make([]ColumnKey, 0, len(keys))
-	columnsMu.Lock()
-	for _, key := range keys {
-		if _, ok := columnsCache[key]; !ok && key.TableOID > 0 {
-			uncachedKeys = append(uncachedKeys, key)
-		}
-	}
-	columnsMu.Unlock()
-	if len(uncachedKeys) == 0 {
-		return fetchCachedColumns(keys)
+	cols, err := fetchColumnsByKey(conn, keys, &FetchStats{})
+	if err != nil {
+		return nil, err
 	}
+	return orderColumns(cols, keys), nil
+}
+
+// fetchColumnsByKey fetches the Postgres columns for keys with a table OID.
+// Records the round-trip in stats.
+func fetchColumnsByKey(conn *pgx.Conn, keys []ColumnKey, stats *FetchStats) (map[ColumnKey]Column, error) {
+	cols := make(map[ColumnKey]Column, len(keys))
 
 	// Build query predicate.
 	predicate := &strings.Builder{}
-	predicate.Grow(len(uncachedKeys) * 40)
-	for i, key := range uncachedKeys {
+	predicate.Grow(len(keys) * 40)
+	for _, key := range keys {
+		if key.TableOID == 0 {
+			continue
+		}
+		if predicate.Len() > 0 {
+			predicate.WriteString("\n    OR ")
+		}
 		predicate.WriteString("(cls.oid = ")
 		predicate.WriteString(strconv.Itoa(int(key.TableOID)))
 		predicate.WriteString(" AND attr.attnum = ")
 		predicate.WriteString(strconv.Itoa(int(key.Number)))
 		predicate.WriteString(")")
-		if i < len(uncachedKeys)-1 {
-			predicate.WriteString("\n    OR ")
-		}
+	}
+	if predicate.Len() == 0 {
+		return cols, nil
 	}
 
 	// Execute query.
+	// A column with a NOT NULL domain type can't be null even without a
+	// not-null constraint on the column.
 	q := texts.Dedent(`
-		SELECT cls.oid         AS table_oid,
-					 cls.relname     AS table_name,
-					 attr.attname    AS col_name,
-					 attr.attnum     AS col_num,
-					 attr.attnotnull AS col_null
+		SELECT cls.oid                            AS table_oid,
+					 cls.relname                        AS table_name,
+					 ns.nspname                         AS schema_name,
+					 attr.attname                       AS col_name,
+					 attr.attnum                        AS col_num,
+					 attr.attnotnull OR typ.typnotnull  AS col_null,
+					 attr.atttypid                      AS col_type_oid
 		FROM pg_class cls
+					 JOIN pg_namespace ns ON (ns.oid = cls.relnamespace)
 					 JOIN pg_attribute attr ON (attr.attrelid = cls.oid)
+					 JOIN pg_type typ ON (typ.oid = attr.atttypid)
 	`) + "\nWHERE " + predicate.String()
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
+	defer stats.Record(time.Now())
 	rows, err := conn.Query(ctx, q)
 	if err != nil {
 		return nil, fmt.Errorf("fetch column metadata: %w", err)
@@ -90,30 +99,24 @@ func FetchColumns(conn *pgx.Conn, keys []ColumnKey) ([]Column, error) {
 	for rows.Next() {
 		col := Column{}
 		notNull := false
-		if err := rows.Scan(&col.TableOID, &col.TableName, &col.Name, &col.Number, &notNull); err != nil {
+		if err := rows.Scan(&col.TableOID, &col.TableName, &col.Schema, &col.Name, &col.Number, &notNull, &col.TypeOID); err != nil {
 			return nil, fmt.Errorf("scan fetch column row: %w", err)
 		}
 		col.Null = !notNull
-		columnsCache[ColumnKey{col.TableOID, col.Number}] = col
+		cols[ColumnKey{col.TableOID, col.Number}] = col
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("close fetch column rows: %w", err)
 	}
-
-	return fetchCachedColumns(keys)
+	return cols, nil
 }
 
-func fetchCachedColumns(keys []ColumnKey) ([]Column, error) {
-	cols := make([]Column, 0, len(keys))
-	columnsMu.Lock()
-	defer columnsMu.Unlock()
-	for _, key := range keys {
-		col, ok := columnsCache[key]
-		// Ignore columns not directly backed by a table.
-		if !ok && col.TableOID > 0 {
-			return nil, fmt.Errorf("missing column in fetch cache table_oid=%d Number=%d", key.TableOID, key.Number)
-		}
-		cols = append(cols, col)
+// orderColumns returns the column for each key in cols in the order of keys.
+// The column is empty for a key missing from cols.
+func orderColumns(cols map[ColumnKey]Column, keys []ColumnKey) []Column {
+	ordered := make([]Column, len(keys))
+	for i, key := range keys {
+		ordered[i] = cols[key]
 	}
-	return cols, nil
+	return ordered
 }
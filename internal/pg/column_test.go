@@ -17,30 +17,44 @@ func TestFetchColumns(t *testing.T) {
 		name    string
 		schema  string
 		colNums []uint16
+		colType string // type of all columns in want
 		want    []Column
 	}{
-		{"empty", "", nil, nil},
+		{"empty", "", nil, "", nil},
 		{
 			"one col null",
 			"CREATE TABLE author ( first_name text );",
 			[]uint16{1},
+			"text",
 			[]Column{{Name: "first_name", TableName: "author", Number: 1, Null: true}},
 		},
 		{
 			"one col not null",
 			"CREATE TABLE author ( first_name text NOT NULL);",
 			[]uint16{1},
+			"text",
 			[]Column{{Name: "first_name", TableName: "author", Number: 1, Null: false}},
 		},
 		{
 			"two col mixed",
 			"CREATE TABLE author ( first_name text NOT NULL, last_name text);",
 			[]uint16{2, 1},
+			"text",
 			[]Column{
 				{Name: "last_name", TableName: "author", Number: 2, Null: true},
 				{Name: "first_name", TableName: "author", Number: 1, Null: false},
 			},
 		},
+		{
+			"one col not null domain",
+			texts.Dedent(`
+				CREATE DOMAIN name_text AS text NOT NULL;
+				CREATE TABLE author ( first_name name_text );
+			`),
+			[]uint16{1},
+			"name_text",
+			[]Column{{Name: "first_name", TableName: "author", Number: 1, Null: false}},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -55,21 +69,30 @@ func TestFetchColumns(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			// Add table OID to each key.
+			// Add table OID, schema, and type OID to each key.
+			schema := findCurrentSchema(t, conn)
 			for i, col := range tt.want {
 				col.TableOID = oid
+				col.Schema = schema
+				col.TypeOID = findOIDVal(t, tt.colType, NewQuerier(conn))
 				tt.want[i] = col
 			}
 			if diff := cmp.Diff(tt.want, cols); diff != "" {
 				t.Errorf("FetchColumns() query mismatch (-want +got):\n%s", diff)
 			}
 
-			// Test cache.
-			cols2, err := FetchColumns(conn, keys)
+			// Test catalog cache.
+			catalog := NewCatalog(conn)
+			cols2, err := catalog.FetchColumns(keys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, cols, cols2, "same catalog columns as fetch columns")
+			cols3, err := catalog.FetchColumns(keys)
 			if err != nil {
 				t.Fatal(err)
 			}
-			assert.Equal(t, cols, cols2, "same fetch columns in succession")
+			assert.Equal(t, cols, cols3, "same catalog columns in succession")
 		})
 	}
 }
@@ -91,3 +114,13 @@ func findTableOID(t *testing.T, conn *pgx.Conn, table string) pgtype.OID {
 	}
 	return oid
 }
+
+func findCurrentSchema(t *testing.T, conn *pgx.Conn) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	var schema string
+	if err := conn.QueryRow(ctx, "SELECT current_schema()").Scan(&schema); err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
@@ -23,6 +23,8 @@ SELECT
   typ.oid           AS oid,
   -- typename: Data type name.
   typ.typname::text AS type_name,
+  -- nspname: Name of the schema that contains the type.
+  nsp.nspname::text AS schema_name,
   enum.enum_oids    AS child_oids,
   enum.enum_orders  AS orders,
   enum.enum_labels  AS labels,
@@ -39,6 +41,7 @@ SELECT
   COALESCE(typ.typdefault, '')    AS default_expr
 FROM pg_type typ
   JOIN enums enum ON typ.oid = enum.enum_type
+  JOIN pg_namespace nsp ON typ.typnamespace = nsp.oid
 WHERE typ.typisdefined
   AND typ.typtype = 'e'
   AND typ.oid = ANY (pggen.arg('OIDs')::oid[]);
@@ -48,6 +51,8 @@ SELECT
   arr_typ.oid           AS oid,
   -- typename: Data type name.
   arr_typ.typname::text AS type_name,
+  -- nspname: Name of the schema that contains the type.
+  nsp.nspname::text     AS schema_name,
   elem_typ.oid          AS elem_oid,
   -- typtype: b for a base type, c for a composite type (e.g., a table's
   -- row type), d for a domain, e for an enum type, p for a pseudo-type,
@@ -55,6 +60,7 @@ SELECT
   arr_typ.typtype       AS type_kind
 FROM pg_type arr_typ
   JOIN pg_type elem_typ ON arr_typ.typelem = elem_typ.oid
+  JOIN pg_namespace nsp ON arr_typ.typnamespace = nsp.oid
 WHERE arr_typ.typisdefined
   AND arr_typ.typtype = 'b' -- Array types are base types
   -- If typelem is not 0 then it identifies another row in pg_type. The current
@@ -74,6 +80,77 @@ WHERE arr_typ.typisdefined
   AND arr_typ.oid = ANY (pggen.arg('OIDs')::oid[]);
 
 
+-- A domain is a type based on another type with optional constraints, like
+-- NOT NULL or CHECK (VALUE > 0).
+-- https://www.postgresql.org/docs/13/domains.html
+-- name: FindDomainTypes :many
+SELECT
+  typ.oid                    AS oid,
+  -- typename: Data type name.
+  typ.typname::text          AS type_name,
+  -- nspname: Name of the schema that contains the type.
+  nsp.nspname::text          AS schema_name,
+  -- typnotnull represents a not-null constraint on a domain.
+  typ.typnotnull             AS is_not_null,
+  typ.typdefault IS NOT NULL AS has_default,
+  -- typbasetype identifies the type a domain is based on.
+  typ.typbasetype            AS base_type_oid,
+  -- typndims is the number of array dimensions for a domain over an array, or
+  -- 0 otherwise.
+  typ.typndims               AS dimensions,
+  -- The CHECK constraints of the domain in name order, like
+  -- CHECK (VALUE > 0).
+  ARRAY(
+    SELECT pg_get_constraintdef(con.oid)
+    FROM pg_constraint con
+    WHERE con.contypid = typ.oid
+      AND con.contype = 'c'
+    ORDER BY con.conname
+  )                          AS check_exprs
+FROM pg_type typ
+  JOIN pg_namespace nsp ON typ.typnamespace = nsp.oid
+WHERE typ.typisdefined
+  AND typ.typtype = 'd'
+  AND typ.oid = ANY (pggen.arg('oids')::oid[]);
+
+-- A range type is a range of values of a subtype, like int4range over int4.
+-- https://www.postgresql.org/docs/13/rangetypes.html
+-- name: FindRangeTypes :many
+SELECT
+  typ.oid           AS oid,
+  -- typename: Data type name.
+  typ.typname::text AS type_name,
+  -- nspname: Name of the schema that contains the type.
+  nsp.nspname::text AS schema_name,
+  -- rngsubtype is the element type of the range.
+  rng.rngsubtype    AS elem_oid
+FROM pg_type typ
+  JOIN pg_range rng ON typ.oid = rng.rngtypid
+  JOIN pg_namespace nsp ON typ.typnamespace = nsp.oid
+WHERE typ.typisdefined
+  AND typ.typtype = 'r'
+  AND typ.oid = ANY (pggen.arg('oids')::oid[]);
+
+-- A multirange type is an ordered list of non-overlapping ranges, added in
+-- Postgres 14. Postgres creates a multirange type for every range type.
+-- name: FindMultirangeTypes :many
+SELECT
+  typ.oid           AS oid,
+  -- typename: Data type name.
+  typ.typname::text AS type_name,
+  -- nspname: Name of the schema that contains the type.
+  nsp.nspname::text AS schema_name,
+  -- rngtypid is the range type of the multirange elements.
+  rng.rngtypid      AS range_oid
+FROM pg_type typ
+  -- Read rngmultitypid through jsonb because the column only exists in
+  -- Postgres 14 and later.
+  JOIN pg_range rng ON typ.oid = (to_jsonb(rng) ->> 'rngmultitypid')::oid
+  JOIN pg_namespace nsp ON typ.typnamespace = nsp.oid
+WHERE typ.typisdefined
+  AND typ.typtype = 'm'
+  AND typ.oid = ANY (pggen.arg('oids')::oid[]);
+
 -- A composite type represents a row or record, defined implicitly for each
 -- table, or explicitly with CREATE TYPE.
 -- https://www.postgresql.org/docs/13/rowtypes.html
@@ -97,6 +174,8 @@ WITH table_cols AS (
 SELECT
   typ.typname::text AS table_type_name,
   typ.oid           AS table_type_oid,
+  -- nspname: Name of the schema that contains the type.
+  nsp.nspname::text AS schema_name,
   table_name,
   col_names,
   col_oids,
@@ -105,11 +184,12 @@ SELECT
   col_type_names
 FROM pg_type typ
   JOIN table_cols cols ON typ.typrelid = cols.table_oid
+  JOIN pg_namespace nsp ON typ.typnamespace = nsp.oid
 WHERE typ.oid = ANY (pggen.arg('oids')::oid[])
   AND typ.typtype = 'c';
 
--- Recursively expands all given OIDs to all descendants through composite
--- types.
+-- Recursively expands all given OIDs to all descendants through composite,
+-- array, domain, range, and multirange types.
 -- name: FindDescendantOIDs :many
 WITH RECURSIVE oid_descs(oid) AS (
   -- Base case.
@@ -134,6 +214,22 @@ WITH RECURSIVE oid_descs(oid) AS (
     FROM pg_type arr_typ
       JOIN pg_type elem_typ ON arr_typ.typelem = elem_typ.oid
       JOIN all_oids od ON arr_typ.oid = od.oid
+    UNION
+    -- All domain base types.
+    SELECT dom_typ.typbasetype
+    FROM pg_type dom_typ
+      JOIN all_oids od ON dom_typ.oid = od.oid
+    WHERE dom_typ.typtype = 'd'
+    UNION
+    -- All range subtypes.
+    SELECT rng.rngsubtype
+    FROM pg_range rng
+      JOIN all_oids od ON rng.rngtypid = od.oid
+    UNION
+    -- All multirange range types.
+    SELECT rng.rngtypid
+    FROM pg_range rng
+      JOIN all_oids od ON (to_jsonb(rng) ->> 'rngmultitypid')::oid = od.oid
   ) t
 )
 SELECT oid
@@ -152,6 +248,11 @@ FROM pg_type
 WHERE oid = pggen.arg('oid');
 
 -- name: FindOIDNames :many
-SELECT oid, typname AS name, typtype AS kind
-FROM pg_type
-WHERE oid = ANY (pggen.arg('oid')::oid[]);
+SELECT
+  typ.oid,
+  typ.typname       AS name,
+  nsp.nspname::text AS schema_name,
+  typ.typtype       AS kind
+FROM pg_type typ
+  JOIN pg_namespace nsp ON typ.typnamespace = nsp.oid
+WHERE typ.oid = ANY (pggen.arg('oid')::oid[]);
@@ -4,10 +4,12 @@ package pg
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v4"
+	"time"
 )
 
 // Querier is a typesafe Go interface backed by SQL queries.
@@ -30,6 +32,34 @@ type Querier interface {
 	// FindArrayTypesScan scans the result of an executed FindArrayTypesBatch query.
 	FindArrayTypesScan(results pgx.BatchResults) ([]FindArrayTypesRow, error)
 
+	// A domain is a type based on another type with optional constraints, like
+	// NOT NULL or CHECK (VALUE > 0).
+	// https://www.postgresql.org/docs/13/domains.html
+	FindDomainTypes(ctx context.Context, oids []uint32) ([]FindDomainTypesRow, error)
+	// FindDomainTypesBatch enqueues a FindDomainTypes query into batch to be executed
+	// later by the batch.
+	FindDomainTypesBatch(batch genericBatch, oids []uint32)
+	// FindDomainTypesScan scans the result of an executed FindDomainTypesBatch query.
+	FindDomainTypesScan(results pgx.BatchResults) ([]FindDomainTypesRow, error)
+
+	// A range type is a range of values of a subtype, like int4range over int4.
+	// https://www.postgresql.org/docs/13/rangetypes.html
+	FindRangeTypes(ctx context.Context, oids []uint32) ([]FindRangeTypesRow, error)
+	// FindRangeTypesBatch enqueues a FindRangeTypes query into batch to be executed
+	// later by the batch.
+	FindRangeTypesBatch(batch genericBatch, oids []uint32)
+	// FindRangeTypesScan scans the result of an executed FindRangeTypesBatch query.
+	FindRangeTypesScan(results pgx.BatchResults) ([]FindRangeTypesRow, error)
+
+	// A multirange type is an ordered list of non-overlapping ranges, added in
+	// Postgres 14. Postgres creates a multirange type for every range type.
+	FindMultirangeTypes(ctx context.Context, oids []uint32) ([]FindMultirangeTypesRow, error)
+	// FindMultirangeTypesBatch enqueues a FindMultirangeTypes query into batch to be executed
+	// later by the batch.
+	FindMultirangeTypesBatch(batch genericBatch, oids []uint32)
+	// FindMultirangeTypesScan scans the result of an executed FindMultirangeTypesBatch query.
+	FindMultirangeTypesScan(results pgx.BatchResults) ([]FindMultirangeTypesRow, error)
+
 	// A composite type represents a row or record, defined implicitly for each
 	// table, or explicitly with CREATE TYPE.
 	// https://www.postgresql.org/docs/13/rowtypes.html
@@ -40,8 +70,8 @@ type Querier interface {
 	// FindCompositeTypesScan scans the result of an executed FindCompositeTypesBatch query.
 	FindCompositeTypesScan(results pgx.BatchResults) ([]FindCompositeTypesRow, error)
 
-	// Recursively expands all given OIDs to all descendants through composite
-	// types.
+	// Recursively expands all given OIDs to all descendants through composite,
+	// array, domain, range, and multirange types.
 	FindDescendantOIDs(ctx context.Context, oids []uint32) ([]pgtype.OID, error)
 	// FindDescendantOIDsBatch enqueues a FindDescendantOIDs query into batch to be executed
 	// later by the batch.
@@ -69,11 +99,17 @@ type Querier interface {
 	FindOIDNamesBatch(batch genericBatch, oid []uint32)
 	// FindOIDNamesScan scans the result of an executed FindOIDNamesBatch query.
 	FindOIDNamesScan(results pgx.BatchResults) ([]FindOIDNamesRow, error)
+
+	// RunInTx runs fn in a transaction using a Querier bound to the
+	// transaction. See DBQuerier.RunInTx.
+	RunInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error
 }
 
 type DBQuerier struct {
-	conn  genericConn   // underlying Postgres transport to use
-	types *typeResolver // resolve types by name
+	conn    genericConn   // underlying Postgres transport to use
+	replica genericConn   // transport for read-only queries; nil unless routing
+	types   *typeResolver // resolve types by name
+	cfg     QuerierConfig // config used to create the querier
 }
 
 var _ Querier = &DBQuerier{}
@@ -119,17 +155,362 @@ type QuerierConfig struct {
 	// transitive dependencies, pggen will use the binary encoding format for
 	// the input parameter.
 	DataTypes []pgtype.DataType
+
+	// UsePreparedStatements runs queries by the statement names prepared by
+	// PrepareAllQueries instead of by SQL text. Every connection used by the
+	// querier must run PrepareAllQueries first, typically in the AfterConnect
+	// callback of pgxpool.Config.
+	//
+	// If false, the default, queries run by SQL text, which works whether or
+	// not the connection prepared the statements.
+	UsePreparedStatements bool
+
+	// PgBouncerCompat enables compatibility with PgBouncer in transaction
+	// pooling mode, which doesn't support prepared statements. Each query runs
+	// with pgx.QuerySimpleProtocol, pggen-generated transcoders always encode
+	// in the text format, UsePreparedStatements is ignored, and
+	// PrepareAllQueriesConfig doesn't prepare any statements.
+	//
+	// pgx only sends a pgx.Batch with the simple protocol if
+	// PreferSimpleProtocol is set on pgx.ConnConfig, so Batch.Send runs the
+	// queued queries one at a time with the simple protocol instead of in a
+	// single network round-trip.
+	PgBouncerCompat bool
 }
 
 // NewQuerierConfig creates a DBQuerier that implements Querier with the given
 // config. conn is typically *pgx.Conn, pgx.Tx, or *pgxpool.Pool.
 func NewQuerierConfig(conn genericConn, cfg QuerierConfig) *DBQuerier {
-	return &DBQuerier{conn: conn, types: newTypeResolver(cfg.DataTypes)}
+	if cfg.PgBouncerCompat {
+		conn = simpleProtocolConn{conn}
+	}
+	return &DBQuerier{conn: conn, types: newTypeResolver(cfg.DataTypes, cfg.PgBouncerCompat), cfg: cfg}
 }
 
 // WithTx creates a new DBQuerier that uses the transaction to run all queries.
+// The returned DBQuerier keeps the config of q. If q is a routing querier, the
+// returned DBQuerier runs read-only queries in the transaction too.
 func (q *DBQuerier) WithTx(tx pgx.Tx) (*DBQuerier, error) {
-	return &DBQuerier{conn: tx}, nil
+	var conn genericConn = tx
+	if q.cfg.PgBouncerCompat {
+		conn = simpleProtocolConn{conn}
+	}
+	return &DBQuerier{conn: conn, types: q.types, cfg: q.cfg}, nil
+}
+
+// NewRoutingQuerier creates a DBQuerier that runs read-only queries on replica
+// and all other queries on primary. pggen classifies a query as read-only if
+// the query plan doesn't modify a table or lock rows. Queries with the
+// route=primary pragma, batches, and transactions always use primary.
+func NewRoutingQuerier(primary, replica genericConn) *DBQuerier {
+	return NewRoutingQuerierConfig(primary, replica, QuerierConfig{})
+}
+
+// NewRoutingQuerierConfig creates a DBQuerier like NewRoutingQuerier with the
+// given config. The config applies to both primary and replica.
+func NewRoutingQuerierConfig(primary, replica genericConn, cfg QuerierConfig) *DBQuerier {
+	q := NewQuerierConfig(primary, cfg)
+	if cfg.PgBouncerCompat {
+		replica = simpleProtocolConn{replica}
+	}
+	q.replica = replica
+	return q
+}
+
+// readConn returns the Postgres transport for read-only queries.
+func (q *DBQuerier) readConn() genericConn {
+	if q.replica == nil {
+		return q.conn
+	}
+	return q.replica
+}
+
+// simpleProtocolConn is a genericConn that runs all queries with the simple
+// protocol, overriding pgx.ConnConfig.PreferSimpleProtocol.
+type simpleProtocolConn struct {
+	genericConn
+}
+
+func (c simpleProtocolConn) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return c.genericConn.Query(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, args...)...)
+}
+
+func (c simpleProtocolConn) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return c.genericConn.QueryRow(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, args...)...)
+}
+
+func (c simpleProtocolConn) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	return c.genericConn.Exec(ctx, sql, append([]interface{}{pgx.QuerySimpleProtocol(true)}, arguments...)...)
+}
+
+// transport returns the underlying Postgres transport of q, unwrapping any
+// wrapper added by the config.
+func (q *DBQuerier) transport() genericConn {
+	if c, ok := q.conn.(simpleProtocolConn); ok {
+		return c.genericConn
+	}
+	return q.conn
+}
+
+// txBeginner is any Postgres connection transport that can begin a
+// transaction, most commonly *pgx.Conn or *pgxpool.Pool.
+type txBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// TxOptions controls how RunInTx begins and retries a transaction.
+type TxOptions struct {
+	// TxOptions are the options used to begin a top-level transaction. Ignored
+	// for nested transactions, which use a savepoint.
+	pgx.TxOptions
+
+	// MaxRetries is the maximum number of times to retry a top-level
+	// transaction that failed with a serialization failure (SQLSTATE 40001) or
+	// a deadlock (SQLSTATE 40P01). Zero disables retries.
+	MaxRetries int
+
+	// Backoff returns how long to wait before the nth retry, starting at 1. If
+	// nil, retries immediately.
+	Backoff func(retry int) time.Duration
+}
+
+// RunInTx runs fn in a transaction. fn receives a Querier bound to the
+// transaction with the same config as q. If fn returns nil, RunInTx commits
+// the transaction; otherwise, RunInTx rolls back the transaction and returns
+// the error from fn.
+//
+// If q is already bound to a transaction, like the Querier passed to fn,
+// RunInTx creates a nested transaction using a savepoint. Only the top-level
+// transaction is retried because a serialization failure aborts the entire
+// transaction.
+func (q *DBQuerier) RunInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error {
+	_, isNested := q.transport().(pgx.Tx)
+	for retry := 1; ; retry++ {
+		err := q.runInTx(ctx, opts, fn)
+		if err == nil || isNested || retry > opts.MaxRetries || !isRetryableTxErr(err) {
+			return err
+		}
+		if opts.Backoff == nil {
+			continue
+		}
+		timer := time.NewTimer(opts.Backoff(retry))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("retry tx: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// runInTx runs a single attempt of fn in a transaction.
+func (q *DBQuerier) runInTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error {
+	var tx pgx.Tx
+	var err error
+	switch conn := q.transport().(type) {
+	case pgx.Tx:
+		tx, err = conn.Begin(ctx) // savepoint
+	case txBeginner:
+		tx, err = conn.BeginTx(ctx, opts.TxOptions)
+	default:
+		return fmt.Errorf("begin tx: conn of type %T cannot begin a transaction", conn)
+	}
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	txq, _ := q.WithTx(tx)
+	if err := fn(txq); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			return fmt.Errorf("rollback tx: %v; original error: %w", rbErr, err)
+		}
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// isRetryableTxErr returns true if err is a serialization failure or deadlock
+// such that retrying the entire transaction might succeed.
+func isRetryableTxErr(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+// batchSender is any Postgres connection transport that can send a batch of
+// queries, most commonly *pgx.Conn, pgx.Tx, or *pgxpool.Pool.
+type batchSender interface {
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// Batch is a typed builder for a pgx.Batch. Each query method on Batch queues
+// the query and returns a handle. After calling Send, use Result on the handle
+// to get the typed result of the query.
+type Batch struct {
+	q       *DBQuerier
+	batch   queueBatch    // *pgx.Batch, or *simpleBatch with PgBouncerCompat
+	handles []batchHandle // handles in the same order as the queued queries
+	sent    bool
+}
+
+// batchHandle scans the result of a single query queued in a Batch.
+type batchHandle interface {
+	scan(results pgx.BatchResults) error
+}
+
+var errBatchNotSent = errors.New("batch not sent")
+
+// queueBatch is a genericBatch that counts the queued queries.
+type queueBatch interface {
+	genericBatch
+	Len() int
+}
+
+// NewBatch creates a typed Batch that sends queries using the conn of q.
+func (q *DBQuerier) NewBatch() *Batch {
+	if q.cfg.PgBouncerCompat {
+		return &Batch{q: q, batch: &simpleBatch{}}
+	}
+	return &Batch{q: q, batch: &pgx.Batch{}}
+}
+
+// Send sends all queued queries with SendBatch and scans the result of each
+// query into its handle in queue order. If any query fails, Send returns a
+// BatchError with the errors of all failed queries.
+func (b *Batch) Send(ctx context.Context) error {
+	if b.sent {
+		return errors.New("send batch: batch already sent")
+	}
+	if b.batch.Len() != len(b.handles) {
+		return fmt.Errorf("send batch: %d queued queries but %d handles", b.batch.Len(), len(b.handles))
+	}
+	var results pgx.BatchResults
+	switch batch := b.batch.(type) {
+	case *simpleBatch:
+		b.sent = true
+		results = &simpleBatchResults{ctx: ctx, conn: b.q.conn, queries: batch.queries}
+	case *pgx.Batch:
+		sender, ok := b.q.transport().(batchSender)
+		if !ok {
+			return fmt.Errorf("send batch: conn of type %T cannot send a batch", b.q.transport())
+		}
+		b.sent = true
+		results = sender.SendBatch(ctx, batch)
+	default:
+		return fmt.Errorf("send batch: unknown batch type %T", b.batch)
+	}
+	var errs []error
+	for _, h := range b.handles {
+		if err := h.scan(results); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := results.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("close batch results: %w", err))
+	}
+	if len(errs) > 0 {
+		return BatchError{Errs: errs}
+	}
+	return nil
+}
+
+// BatchError is the error returned by Batch.Send with the errors of all failed
+// queries in queue order.
+type BatchError struct {
+	Errs []error
+}
+
+func (e BatchError) Error() string {
+	msg := fmt.Sprintf("send batch: %d errors", len(e.Errs))
+	for _, err := range e.Errs {
+		msg += "; " + err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the first error.
+func (e BatchError) Unwrap() error { return e.Errs[0] }
+
+// simpleBatch is a genericBatch that records the queued queries so that
+// simpleBatchResults can run them with the simple protocol.
+type simpleBatch struct {
+	queries []simpleQuery
+}
+
+type simpleQuery struct {
+	sql  string
+	args []interface{}
+}
+
+func (b *simpleBatch) Queue(query string, arguments ...interface{}) {
+	b.queries = append(b.queries, simpleQuery{sql: query, args: arguments})
+}
+
+func (b *simpleBatch) Len() int { return len(b.queries) }
+
+// simpleBatchResults is a pgx.BatchResults that runs the next query of a
+// simpleBatch on conn when reading its result. conn is a simpleProtocolConn,
+// so each query runs with the simple protocol.
+type simpleBatchResults struct {
+	ctx     context.Context
+	conn    genericConn
+	queries []simpleQuery
+	idx     int // index of the next query
+}
+
+func (r *simpleBatchResults) next() (simpleQuery, error) {
+	if r.idx == len(r.queries) {
+		return simpleQuery{}, fmt.Errorf("no result for batch query %d", r.idx)
+	}
+	q := r.queries[r.idx]
+	r.idx++
+	return q, nil
+}
+
+func (r *simpleBatchResults) Exec() (pgconn.CommandTag, error) {
+	q, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	return r.conn.Exec(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) Query() (pgx.Rows, error) {
+	q, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	return r.conn.Query(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) QueryRow() pgx.Row {
+	q, err := r.next()
+	if err != nil {
+		return errRow{err: err}
+	}
+	return r.conn.QueryRow(r.ctx, q.sql, q.args...)
+}
+
+func (r *simpleBatchResults) Close() error { return nil }
+
+// errRow is a pgx.Row that returns err from Scan.
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(...interface{}) error { return r.err }
+
+// chooseSQL returns the name of the prepared statement if the querier uses
+// prepared statements. Otherwise, returns the SQL text.
+func (q *DBQuerier) chooseSQL(sql, stmtName string) string {
+	if q.cfg.UsePreparedStatements && !q.cfg.PgBouncerCompat {
+		return stmtName
+	}
+	return sql
 }
 
 // preparer is any Postgres connection transport that provides a way to prepare
@@ -142,48 +523,72 @@ type preparer interface {
 // queries in querier files. Typical usage is as the AfterConnect callback
 // for pgxpool.Config
 //
-// pgx will use the prepared statement if available. Calling PrepareAllQueries
-// is an optional optimization to avoid a network round-trip the first time pgx
-// runs a query if pgx statement caching is enabled.
+// Each statement is prepared under a deterministic name derived from the query
+// name and SQL text. A querier created with
+// QuerierConfig.UsePreparedStatements runs queries by those names, avoiding a
+// network round-trip to prepare the statement the first time pgx runs a query.
+//
+// Use PrepareAllQueriesConfig with the config of the querier to skip preparing
+// statements with QuerierConfig.PgBouncerCompat.
 func PrepareAllQueries(ctx context.Context, p preparer) error {
-	if _, err := p.Prepare(ctx, findEnumTypesSQL, findEnumTypesSQL); err != nil {
+	if _, err := p.Prepare(ctx, findEnumTypesStmt, findEnumTypesSQL); err != nil {
 		return fmt.Errorf("prepare query 'FindEnumTypes': %w", err)
 	}
-	if _, err := p.Prepare(ctx, findArrayTypesSQL, findArrayTypesSQL); err != nil {
+	if _, err := p.Prepare(ctx, findArrayTypesStmt, findArrayTypesSQL); err != nil {
 		return fmt.Errorf("prepare query 'FindArrayTypes': %w", err)
 	}
-	if _, err := p.Prepare(ctx, findCompositeTypesSQL, findCompositeTypesSQL); err != nil {
+	if _, err := p.Prepare(ctx, findDomainTypesStmt, findDomainTypesSQL); err != nil {
+		return fmt.Errorf("prepare query 'FindDomainTypes': %w", err)
+	}
+	if _, err := p.Prepare(ctx, findRangeTypesStmt, findRangeTypesSQL); err != nil {
+		return fmt.Errorf("prepare query 'FindRangeTypes': %w", err)
+	}
+	if _, err := p.Prepare(ctx, findMultirangeTypesStmt, findMultirangeTypesSQL); err != nil {
+		return fmt.Errorf("prepare query 'FindMultirangeTypes': %w", err)
+	}
+	if _, err := p.Prepare(ctx, findCompositeTypesStmt, findCompositeTypesSQL); err != nil {
 		return fmt.Errorf("prepare query 'FindCompositeTypes': %w", err)
 	}
-	if _, err := p.Prepare(ctx, findDescendantOIDsSQL, findDescendantOIDsSQL); err != nil {
+	if _, err := p.Prepare(ctx, findDescendantOIDsStmt, findDescendantOIDsSQL); err != nil {
 		return fmt.Errorf("prepare query 'FindDescendantOIDs': %w", err)
 	}
-	if _, err := p.Prepare(ctx, findOIDByNameSQL, findOIDByNameSQL); err != nil {
+	if _, err := p.Prepare(ctx, findOIDByNameStmt, findOIDByNameSQL); err != nil {
 		return fmt.Errorf("prepare query 'FindOIDByName': %w", err)
 	}
-	if _, err := p.Prepare(ctx, findOIDNameSQL, findOIDNameSQL); err != nil {
+	if _, err := p.Prepare(ctx, findOIDNameStmt, findOIDNameSQL); err != nil {
 		return fmt.Errorf("prepare query 'FindOIDName': %w", err)
 	}
-	if _, err := p.Prepare(ctx, findOIDNamesSQL, findOIDNamesSQL); err != nil {
+	if _, err := p.Prepare(ctx, findOIDNamesStmt, findOIDNamesSQL); err != nil {
 		return fmt.Errorf("prepare query 'FindOIDNames': %w", err)
 	}
 	return nil
 }
 
+// PrepareAllQueriesConfig is like PrepareAllQueries but doesn't prepare any
+// statements if cfg.PgBouncerCompat is set, because PgBouncer in transaction
+// pooling mode doesn't support prepared statements.
+func PrepareAllQueriesConfig(ctx context.Context, p preparer, cfg QuerierConfig) error {
+	if cfg.PgBouncerCompat {
+		return nil
+	}
+	return PrepareAllQueries(ctx, p)
+}
+
 // typeResolver looks up the pgtype.ValueTranscoder by Postgres type name.
 type typeResolver struct {
-	connInfo *pgtype.ConnInfo // types by Postgres type name
+	connInfo   *pgtype.ConnInfo // types by Postgres type name
+	preferText bool             // if true, always encode in the text format
 }
 
-func newTypeResolver(types []pgtype.DataType) *typeResolver {
+func newTypeResolver(types []pgtype.DataType, preferText bool) *typeResolver {
 	ci := pgtype.NewConnInfo()
 	for _, typ := range types {
-		if txt, ok := typ.Value.(textPreferrer); ok && typ.OID != unknownOID {
+		if txt, ok := typ.Value.(textPreferrer); ok && typ.OID != unknownOID && !preferText {
 			typ.Value = txt.ValueTranscoder
 		}
 		ci.RegisterDataType(typ)
 	}
-	return &typeResolver{connInfo: ci}
+	return &typeResolver{connInfo: ci, preferText: preferText}
 }
 
 // findValue find the OID, and pgtype.ValueTranscoder for a Postgres type name.
@@ -192,8 +597,11 @@ func (tr *typeResolver) findValue(name string) (uint32, pgtype.ValueTranscoder,
 	if !ok {
 		return 0, nil, false
 	}
-	v := pgtype.NewValue(typ.Value)
-	return typ.OID, v.(pgtype.ValueTranscoder), true
+	v := pgtype.NewValue(typ.Value).(pgtype.ValueTranscoder)
+	if _, isText := v.(textPreferrer); tr.preferText && !isText {
+		v = textPreferrer{v, name}
+	}
+	return typ.OID, v, true
 }
 
 // setValue sets the value of a ValueTranscoder to a value that should always
@@ -229,6 +637,8 @@ SELECT
   typ.oid           AS oid,
   -- typename: Data type name.
   typ.typname::text AS type_name,
+  -- nspname: Name of the schema that contains the type.
+  nsp.nspname::text AS schema_name,
   enum.enum_oids    AS child_oids,
   enum.enum_orders  AS orders,
   enum.enum_labels  AS labels,
@@ -245,13 +655,17 @@ SELECT
   COALESCE(typ.typdefault, '')    AS default_expr
 FROM pg_type typ
   JOIN enums enum ON typ.oid = enum.enum_type
+  JOIN pg_namespace nsp ON typ.typnamespace = nsp.oid
 WHERE typ.typisdefined
   AND typ.typtype = 'e'
   AND typ.oid = ANY ($1::oid[]);`
 
+const findEnumTypesStmt = "pggen_FindEnumTypes_cd21562937b1131e"
+
 type FindEnumTypesRow struct {
 	OID         pgtype.OID   `json:"oid"`
 	TypeName    string       `json:"type_name"`
+	SchemaName  string       `json:"schema_name"`
 	ChildOIDs   []int        `json:"child_oids"`
 	Orders      []float32    `json:"orders"`
 	Labels      []string     `json:"labels"`
@@ -262,7 +676,7 @@ type FindEnumTypesRow struct {
 // FindEnumTypes implements Querier.FindEnumTypes.
 func (q *DBQuerier) FindEnumTypes(ctx context.Context, oids []uint32) ([]FindEnumTypesRow, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "FindEnumTypes")
-	rows, err := q.conn.Query(ctx, findEnumTypesSQL, oids)
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(findEnumTypesSQL, findEnumTypesStmt), oids)
 	if err != nil {
 		return nil, fmt.Errorf("query FindEnumTypes: %w", err)
 	}
@@ -270,7 +684,7 @@ func (q *DBQuerier) FindEnumTypes(ctx context.Context, oids []uint32) ([]FindEnu
 	items := []FindEnumTypesRow{}
 	for rows.Next() {
 		var item FindEnumTypesRow
-		if err := rows.Scan(&item.OID, &item.TypeName, &item.ChildOIDs, &item.Orders, &item.Labels, &item.TypeKind, &item.DefaultExpr); err != nil {
+		if err := rows.Scan(&item.OID, &item.TypeName, &item.SchemaName, &item.ChildOIDs, &item.Orders, &item.Labels, &item.TypeKind, &item.DefaultExpr); err != nil {
 			return nil, fmt.Errorf("scan FindEnumTypes row: %w", err)
 		}
 		items = append(items, item)
@@ -283,7 +697,7 @@ func (q *DBQuerier) FindEnumTypes(ctx context.Context, oids []uint32) ([]FindEnu
 
 // FindEnumTypesBatch implements Querier.FindEnumTypesBatch.
 func (q *DBQuerier) FindEnumTypesBatch(batch genericBatch, oids []uint32) {
-	batch.Queue(findEnumTypesSQL, oids)
+	batch.Queue(q.chooseSQL(findEnumTypesSQL, findEnumTypesStmt), oids)
 }
 
 // FindEnumTypesScan implements Querier.FindEnumTypesScan.
@@ -296,7 +710,7 @@ func (q *DBQuerier) FindEnumTypesScan(results pgx.BatchResults) ([]FindEnumTypes
 	items := []FindEnumTypesRow{}
 	for rows.Next() {
 		var item FindEnumTypesRow
-		if err := rows.Scan(&item.OID, &item.TypeName, &item.ChildOIDs, &item.Orders, &item.Labels, &item.TypeKind, &item.DefaultExpr); err != nil {
+		if err := rows.Scan(&item.OID, &item.TypeName, &item.SchemaName, &item.ChildOIDs, &item.Orders, &item.Labels, &item.TypeKind, &item.DefaultExpr); err != nil {
 			return nil, fmt.Errorf("scan FindEnumTypesBatch row: %w", err)
 		}
 		items = append(items, item)
@@ -307,10 +721,42 @@ func (q *DBQuerier) FindEnumTypesScan(results pgx.BatchResults) ([]FindEnumTypes
 	return items, err
 }
 
+// FindEnumTypes queues a FindEnumTypes query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) FindEnumTypes(oids []uint32) *FindEnumTypesHandle {
+	b.q.FindEnumTypesBatch(b.batch, oids)
+	h := &FindEnumTypesHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// FindEnumTypesHandle is the result of a FindEnumTypes query queued in a Batch.
+type FindEnumTypesHandle struct {
+	b   *Batch
+	res []FindEnumTypesRow
+	err error
+}
+
+func (h *FindEnumTypesHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.FindEnumTypesScan(results)
+	return h.err
+}
+
+// Result returns the result of the FindEnumTypes query. Returns an error if the
+// batch wasn't sent.
+func (h *FindEnumTypesHandle) Result() ([]FindEnumTypesRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("FindEnumTypes result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const findArrayTypesSQL = `SELECT
   arr_typ.oid           AS oid,
   -- typename: Data type name.
   arr_typ.typname::text AS type_name,
+  -- nspname: Name of the schema that contains the type.
+  nsp.nspname::text     AS schema_name,
   elem_typ.oid          AS elem_oid,
   -- typtype: b for a base type, c for a composite type (e.g., a table's
   -- row type), d for a domain, e for an enum type, p for a pseudo-type,
@@ -318,6 +764,7 @@ const findArrayTypesSQL = `SELECT
   arr_typ.typtype       AS type_kind
 FROM pg_type arr_typ
   JOIN pg_type elem_typ ON arr_typ.typelem = elem_typ.oid
+  JOIN pg_namespace nsp ON arr_typ.typnamespace = nsp.oid
 WHERE arr_typ.typisdefined
   AND arr_typ.typtype = 'b' -- Array types are base types
   -- If typelem is not 0 then it identifies another row in pg_type. The current
@@ -336,17 +783,20 @@ WHERE arr_typ.typisdefined
   AND arr_typ.typlen = -1
   AND arr_typ.oid = ANY ($1::oid[]);`
 
+const findArrayTypesStmt = "pggen_FindArrayTypes_8429a15e49574e1a"
+
 type FindArrayTypesRow struct {
-	OID      pgtype.OID   `json:"oid"`
-	TypeName string       `json:"type_name"`
-	ElemOID  pgtype.OID   `json:"elem_oid"`
-	TypeKind pgtype.QChar `json:"type_kind"`
+	OID        pgtype.OID   `json:"oid"`
+	TypeName   string       `json:"type_name"`
+	SchemaName string       `json:"schema_name"`
+	ElemOID    pgtype.OID   `json:"elem_oid"`
+	TypeKind   pgtype.QChar `json:"type_kind"`
 }
 
 // FindArrayTypes implements Querier.FindArrayTypes.
 func (q *DBQuerier) FindArrayTypes(ctx context.Context, oids []uint32) ([]FindArrayTypesRow, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "FindArrayTypes")
-	rows, err := q.conn.Query(ctx, findArrayTypesSQL, oids)
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(findArrayTypesSQL, findArrayTypesStmt), oids)
 	if err != nil {
 		return nil, fmt.Errorf("query FindArrayTypes: %w", err)
 	}
@@ -354,7 +804,7 @@ func (q *DBQuerier) FindArrayTypes(ctx context.Context, oids []uint32) ([]FindAr
 	items := []FindArrayTypesRow{}
 	for rows.Next() {
 		var item FindArrayTypesRow
-		if err := rows.Scan(&item.OID, &item.TypeName, &item.ElemOID, &item.TypeKind); err != nil {
+		if err := rows.Scan(&item.OID, &item.TypeName, &item.SchemaName, &item.ElemOID, &item.TypeKind); err != nil {
 			return nil, fmt.Errorf("scan FindArrayTypes row: %w", err)
 		}
 		items = append(items, item)
@@ -367,7 +817,7 @@ func (q *DBQuerier) FindArrayTypes(ctx context.Context, oids []uint32) ([]FindAr
 
 // FindArrayTypesBatch implements Querier.FindArrayTypesBatch.
 func (q *DBQuerier) FindArrayTypesBatch(batch genericBatch, oids []uint32) {
-	batch.Queue(findArrayTypesSQL, oids)
+	batch.Queue(q.chooseSQL(findArrayTypesSQL, findArrayTypesStmt), oids)
 }
 
 // FindArrayTypesScan implements Querier.FindArrayTypesScan.
@@ -380,7 +830,7 @@ func (q *DBQuerier) FindArrayTypesScan(results pgx.BatchResults) ([]FindArrayTyp
 	items := []FindArrayTypesRow{}
 	for rows.Next() {
 		var item FindArrayTypesRow
-		if err := rows.Scan(&item.OID, &item.TypeName, &item.ElemOID, &item.TypeKind); err != nil {
+		if err := rows.Scan(&item.OID, &item.TypeName, &item.SchemaName, &item.ElemOID, &item.TypeKind); err != nil {
 			return nil, fmt.Errorf("scan FindArrayTypesBatch row: %w", err)
 		}
 		items = append(items, item)
@@ -391,6 +841,362 @@ func (q *DBQuerier) FindArrayTypesScan(results pgx.BatchResults) ([]FindArrayTyp
 	return items, err
 }
 
+// FindArrayTypes queues a FindArrayTypes query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) FindArrayTypes(oids []uint32) *FindArrayTypesHandle {
+	b.q.FindArrayTypesBatch(b.batch, oids)
+	h := &FindArrayTypesHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// FindArrayTypesHandle is the result of a FindArrayTypes query queued in a Batch.
+type FindArrayTypesHandle struct {
+	b   *Batch
+	res []FindArrayTypesRow
+	err error
+}
+
+func (h *FindArrayTypesHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.FindArrayTypesScan(results)
+	return h.err
+}
+
+// Result returns the result of the FindArrayTypes query. Returns an error if the
+// batch wasn't sent.
+func (h *FindArrayTypesHandle) Result() ([]FindArrayTypesRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("FindArrayTypes result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
+const findDomainTypesSQL = `SELECT
+  typ.oid                    AS oid,
+  -- typename: Data type name.
+  typ.typname::text          AS type_name,
+  -- nspname: Name of the schema that contains the type.
+  nsp.nspname::text          AS schema_name,
+  -- typnotnull represents a not-null constraint on a domain.
+  typ.typnotnull             AS is_not_null,
+  typ.typdefault IS NOT NULL AS has_default,
+  -- typbasetype identifies the type a domain is based on.
+  typ.typbasetype            AS base_type_oid,
+  -- typndims is the number of array dimensions for a domain over an array, or
+  -- 0 otherwise.
+  typ.typndims               AS dimensions,
+  -- The CHECK constraints of the domain in name order, like
+  -- CHECK (VALUE > 0).
+  ARRAY(
+    SELECT pg_get_constraintdef(con.oid)
+    FROM pg_constraint con
+    WHERE con.contypid = typ.oid
+      AND con.contype = 'c'
+    ORDER BY con.conname
+  )                          AS check_exprs
+FROM pg_type typ
+  JOIN pg_namespace nsp ON typ.typnamespace = nsp.oid
+WHERE typ.typisdefined
+  AND typ.typtype = 'd'
+  AND typ.oid = ANY ($1::oid[]);`
+
+const findDomainTypesStmt = "pggen_FindDomainTypes_8b884840208069c6"
+
+type FindDomainTypesRow struct {
+	OID         pgtype.OID `json:"oid"`
+	TypeName    string     `json:"type_name"`
+	SchemaName  string     `json:"schema_name"`
+	IsNotNull   bool       `json:"is_not_null"`
+	HasDefault  bool       `json:"has_default"`
+	BaseTypeOID pgtype.OID `json:"base_type_oid"`
+	Dimensions  int32      `json:"dimensions"`
+	CheckExprs  []string   `json:"check_exprs"`
+}
+
+// FindDomainTypes implements Querier.FindDomainTypes.
+func (q *DBQuerier) FindDomainTypes(ctx context.Context, oids []uint32) ([]FindDomainTypesRow, error) {
+	ctx = context.WithValue(ctx, "pggen_query_name", "FindDomainTypes")
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(findDomainTypesSQL, findDomainTypesStmt), oids)
+	if err != nil {
+		return nil, fmt.Errorf("query FindDomainTypes: %w", err)
+	}
+	defer rows.Close()
+	items := []FindDomainTypesRow{}
+	for rows.Next() {
+		var item FindDomainTypesRow
+		if err := rows.Scan(&item.OID, &item.TypeName, &item.SchemaName, &item.IsNotNull, &item.HasDefault, &item.BaseTypeOID, &item.Dimensions, &item.CheckExprs); err != nil {
+			return nil, fmt.Errorf("scan FindDomainTypes row: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("close FindDomainTypes rows: %w", err)
+	}
+	return items, err
+}
+
+// FindDomainTypesBatch implements Querier.FindDomainTypesBatch.
+func (q *DBQuerier) FindDomainTypesBatch(batch genericBatch, oids []uint32) {
+	batch.Queue(q.chooseSQL(findDomainTypesSQL, findDomainTypesStmt), oids)
+}
+
+// FindDomainTypesScan implements Querier.FindDomainTypesScan.
+func (q *DBQuerier) FindDomainTypesScan(results pgx.BatchResults) ([]FindDomainTypesRow, error) {
+	rows, err := results.Query()
+	if err != nil {
+		return nil, fmt.Errorf("query FindDomainTypesBatch: %w", err)
+	}
+	defer rows.Close()
+	items := []FindDomainTypesRow{}
+	for rows.Next() {
+		var item FindDomainTypesRow
+		if err := rows.Scan(&item.OID, &item.TypeName, &item.SchemaName, &item.IsNotNull, &item.HasDefault, &item.BaseTypeOID, &item.Dimensions, &item.CheckExprs); err != nil {
+			return nil, fmt.Errorf("scan FindDomainTypesBatch row: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("close FindDomainTypesBatch rows: %w", err)
+	}
+	return items, err
+}
+
+// FindDomainTypes queues a FindDomainTypes query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) FindDomainTypes(oids []uint32) *FindDomainTypesHandle {
+	b.q.FindDomainTypesBatch(b.batch, oids)
+	h := &FindDomainTypesHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// FindDomainTypesHandle is the result of a FindDomainTypes query queued in a Batch.
+type FindDomainTypesHandle struct {
+	b   *Batch
+	res []FindDomainTypesRow
+	err error
+}
+
+func (h *FindDomainTypesHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.FindDomainTypesScan(results)
+	return h.err
+}
+
+// Result returns the result of the FindDomainTypes query. Returns an error if the
+// batch wasn't sent.
+func (h *FindDomainTypesHandle) Result() ([]FindDomainTypesRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("FindDomainTypes result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
+const findRangeTypesSQL = `SELECT
+  typ.oid           AS oid,
+  -- typename: Data type name.
+  typ.typname::text AS type_name,
+  -- nspname: Name of the schema that contains the type.
+  nsp.nspname::text AS schema_name,
+  -- rngsubtype is the element type of the range.
+  rng.rngsubtype    AS elem_oid
+FROM pg_type typ
+  JOIN pg_range rng ON typ.oid = rng.rngtypid
+  JOIN pg_namespace nsp ON typ.typnamespace = nsp.oid
+WHERE typ.typisdefined
+  AND typ.typtype = 'r'
+  AND typ.oid = ANY ($1::oid[]);`
+
+const findRangeTypesStmt = "pggen_FindRangeTypes_825a6fc4670b7c6c"
+
+type FindRangeTypesRow struct {
+	OID        pgtype.OID `json:"oid"`
+	TypeName   string     `json:"type_name"`
+	SchemaName string     `json:"schema_name"`
+	ElemOID    pgtype.OID `json:"elem_oid"`
+}
+
+// FindRangeTypes implements Querier.FindRangeTypes.
+func (q *DBQuerier) FindRangeTypes(ctx context.Context, oids []uint32) ([]FindRangeTypesRow, error) {
+	ctx = context.WithValue(ctx, "pggen_query_name", "FindRangeTypes")
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(findRangeTypesSQL, findRangeTypesStmt), oids)
+	if err != nil {
+		return nil, fmt.Errorf("query FindRangeTypes: %w", err)
+	}
+	defer rows.Close()
+	items := []FindRangeTypesRow{}
+	for rows.Next() {
+		var item FindRangeTypesRow
+		if err := rows.Scan(&item.OID, &item.TypeName, &item.SchemaName, &item.ElemOID); err != nil {
+			return nil, fmt.Errorf("scan FindRangeTypes row: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("close FindRangeTypes rows: %w", err)
+	}
+	return items, err
+}
+
+// FindRangeTypesBatch implements Querier.FindRangeTypesBatch.
+func (q *DBQuerier) FindRangeTypesBatch(batch genericBatch, oids []uint32) {
+	batch.Queue(q.chooseSQL(findRangeTypesSQL, findRangeTypesStmt), oids)
+}
+
+// FindRangeTypesScan implements Querier.FindRangeTypesScan.
+func (q *DBQuerier) FindRangeTypesScan(results pgx.BatchResults) ([]FindRangeTypesRow, error) {
+	rows, err := results.Query()
+	if err != nil {
+		return nil, fmt.Errorf("query FindRangeTypesBatch: %w", err)
+	}
+	defer rows.Close()
+	items := []FindRangeTypesRow{}
+	for rows.Next() {
+		var item FindRangeTypesRow
+		if err := rows.Scan(&item.OID, &item.TypeName, &item.SchemaName, &item.ElemOID); err != nil {
+			return nil, fmt.Errorf("scan FindRangeTypesBatch row: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("close FindRangeTypesBatch rows: %w", err)
+	}
+	return items, err
+}
+
+// FindRangeTypes queues a FindRangeTypes query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) FindRangeTypes(oids []uint32) *FindRangeTypesHandle {
+	b.q.FindRangeTypesBatch(b.batch, oids)
+	h := &FindRangeTypesHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// FindRangeTypesHandle is the result of a FindRangeTypes query queued in a Batch.
+type FindRangeTypesHandle struct {
+	b   *Batch
+	res []FindRangeTypesRow
+	err error
+}
+
+func (h *FindRangeTypesHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.FindRangeTypesScan(results)
+	return h.err
+}
+
+// Result returns the result of the FindRangeTypes query. Returns an error if the
+// batch wasn't sent.
+func (h *FindRangeTypesHandle) Result() ([]FindRangeTypesRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("FindRangeTypes result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
+const findMultirangeTypesSQL = `SELECT
+  typ.oid           AS oid,
+  -- typename: Data type name.
+  typ.typname::text AS type_name,
+  -- nspname: Name of the schema that contains the type.
+  nsp.nspname::text AS schema_name,
+  -- rngtypid is the range type of the multirange elements.
+  rng.rngtypid      AS range_oid
+FROM pg_type typ
+  -- Read rngmultitypid through jsonb because the column only exists in
+  -- Postgres 14 and later.
+  JOIN pg_range rng ON typ.oid = (to_jsonb(rng) ->> 'rngmultitypid')::oid
+  JOIN pg_namespace nsp ON typ.typnamespace = nsp.oid
+WHERE typ.typisdefined
+  AND typ.typtype = 'm'
+  AND typ.oid = ANY ($1::oid[]);`
+
+const findMultirangeTypesStmt = "pggen_FindMultirangeTypes_55ee45ec39ce2c86"
+
+type FindMultirangeTypesRow struct {
+	OID        pgtype.OID `json:"oid"`
+	TypeName   string     `json:"type_name"`
+	SchemaName string     `json:"schema_name"`
+	RangeOID   pgtype.OID `json:"range_oid"`
+}
+
+// FindMultirangeTypes implements Querier.FindMultirangeTypes.
+func (q *DBQuerier) FindMultirangeTypes(ctx context.Context, oids []uint32) ([]FindMultirangeTypesRow, error) {
+	ctx = context.WithValue(ctx, "pggen_query_name", "FindMultirangeTypes")
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(findMultirangeTypesSQL, findMultirangeTypesStmt), oids)
+	if err != nil {
+		return nil, fmt.Errorf("query FindMultirangeTypes: %w", err)
+	}
+	defer rows.Close()
+	items := []FindMultirangeTypesRow{}
+	for rows.Next() {
+		var item FindMultirangeTypesRow
+		if err := rows.Scan(&item.OID, &item.TypeName, &item.SchemaName, &item.RangeOID); err != nil {
+			return nil, fmt.Errorf("scan FindMultirangeTypes row: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("close FindMultirangeTypes rows: %w", err)
+	}
+	return items, err
+}
+
+// FindMultirangeTypesBatch implements Querier.FindMultirangeTypesBatch.
+func (q *DBQuerier) FindMultirangeTypesBatch(batch genericBatch, oids []uint32) {
+	batch.Queue(q.chooseSQL(findMultirangeTypesSQL, findMultirangeTypesStmt), oids)
+}
+
+// FindMultirangeTypesScan implements Querier.FindMultirangeTypesScan.
+func (q *DBQuerier) FindMultirangeTypesScan(results pgx.BatchResults) ([]FindMultirangeTypesRow, error) {
+	rows, err := results.Query()
+	if err != nil {
+		return nil, fmt.Errorf("query FindMultirangeTypesBatch: %w", err)
+	}
+	defer rows.Close()
+	items := []FindMultirangeTypesRow{}
+	for rows.Next() {
+		var item FindMultirangeTypesRow
+		if err := rows.Scan(&item.OID, &item.TypeName, &item.SchemaName, &item.RangeOID); err != nil {
+			return nil, fmt.Errorf("scan FindMultirangeTypesBatch row: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("close FindMultirangeTypesBatch rows: %w", err)
+	}
+	return items, err
+}
+
+// FindMultirangeTypes queues a FindMultirangeTypes query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) FindMultirangeTypes(oids []uint32) *FindMultirangeTypesHandle {
+	b.q.FindMultirangeTypesBatch(b.batch, oids)
+	h := &FindMultirangeTypesHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// FindMultirangeTypesHandle is the result of a FindMultirangeTypes query queued in a Batch.
+type FindMultirangeTypesHandle struct {
+	b   *Batch
+	res []FindMultirangeTypesRow
+	err error
+}
+
+func (h *FindMultirangeTypesHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.FindMultirangeTypesScan(results)
+	return h.err
+}
+
+// Result returns the result of the FindMultirangeTypes query. Returns an error if the
+// batch wasn't sent.
+func (h *FindMultirangeTypesHandle) Result() ([]FindMultirangeTypesRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("FindMultirangeTypes result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const findCompositeTypesSQL = `WITH table_cols AS (
   SELECT
     cls.relname                                         AS table_name,
@@ -410,6 +1216,8 @@ const findCompositeTypesSQL = `WITH table_cols AS (
 SELECT
   typ.typname::text AS table_type_name,
   typ.oid           AS table_type_oid,
+  -- nspname: Name of the schema that contains the type.
+  nsp.nspname::text AS schema_name,
   table_name,
   col_names,
   col_oids,
@@ -418,12 +1226,16 @@ SELECT
   col_type_names
 FROM pg_type typ
   JOIN table_cols cols ON typ.typrelid = cols.table_oid
+  JOIN pg_namespace nsp ON typ.typnamespace = nsp.oid
 WHERE typ.oid = ANY ($1::oid[])
   AND typ.typtype = 'c';`
 
+const findCompositeTypesStmt = "pggen_FindCompositeTypes_ba5fa8a131a4cd90"
+
 type FindCompositeTypesRow struct {
 	TableTypeName string           `json:"table_type_name"`
 	TableTypeOID  pgtype.OID       `json:"table_type_oid"`
+	SchemaName    string           `json:"schema_name"`
 	TableName     pgtype.Name      `json:"table_name"`
 	ColNames      []string         `json:"col_names"`
 	ColOIDs       []int            `json:"col_oids"`
@@ -435,7 +1247,7 @@ type FindCompositeTypesRow struct {
 // FindCompositeTypes implements Querier.FindCompositeTypes.
 func (q *DBQuerier) FindCompositeTypes(ctx context.Context, oids []uint32) ([]FindCompositeTypesRow, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "FindCompositeTypes")
-	rows, err := q.conn.Query(ctx, findCompositeTypesSQL, oids)
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(findCompositeTypesSQL, findCompositeTypesStmt), oids)
 	if err != nil {
 		return nil, fmt.Errorf("query FindCompositeTypes: %w", err)
 	}
@@ -443,7 +1255,7 @@ func (q *DBQuerier) FindCompositeTypes(ctx context.Context, oids []uint32) ([]Fi
 	items := []FindCompositeTypesRow{}
 	for rows.Next() {
 		var item FindCompositeTypesRow
-		if err := rows.Scan(&item.TableTypeName, &item.TableTypeOID, &item.TableName, &item.ColNames, &item.ColOIDs, &item.ColOrders, &item.ColNotNulls, &item.ColTypeNames); err != nil {
+		if err := rows.Scan(&item.TableTypeName, &item.TableTypeOID, &item.SchemaName, &item.TableName, &item.ColNames, &item.ColOIDs, &item.ColOrders, &item.ColNotNulls, &item.ColTypeNames); err != nil {
 			return nil, fmt.Errorf("scan FindCompositeTypes row: %w", err)
 		}
 		items = append(items, item)
@@ -456,7 +1268,7 @@ func (q *DBQuerier) FindCompositeTypes(ctx context.Context, oids []uint32) ([]Fi
 
 // FindCompositeTypesBatch implements Querier.FindCompositeTypesBatch.
 func (q *DBQuerier) FindCompositeTypesBatch(batch genericBatch, oids []uint32) {
-	batch.Queue(findCompositeTypesSQL, oids)
+	batch.Queue(q.chooseSQL(findCompositeTypesSQL, findCompositeTypesStmt), oids)
 }
 
 // FindCompositeTypesScan implements Querier.FindCompositeTypesScan.
@@ -469,7 +1281,7 @@ func (q *DBQuerier) FindCompositeTypesScan(results pgx.BatchResults) ([]FindComp
 	items := []FindCompositeTypesRow{}
 	for rows.Next() {
 		var item FindCompositeTypesRow
-		if err := rows.Scan(&item.TableTypeName, &item.TableTypeOID, &item.TableName, &item.ColNames, &item.ColOIDs, &item.ColOrders, &item.ColNotNulls, &item.ColTypeNames); err != nil {
+		if err := rows.Scan(&item.TableTypeName, &item.TableTypeOID, &item.SchemaName, &item.TableName, &item.ColNames, &item.ColOIDs, &item.ColOrders, &item.ColNotNulls, &item.ColTypeNames); err != nil {
 			return nil, fmt.Errorf("scan FindCompositeTypesBatch row: %w", err)
 		}
 		items = append(items, item)
@@ -480,6 +1292,36 @@ func (q *DBQuerier) FindCompositeTypesScan(results pgx.BatchResults) ([]FindComp
 	return items, err
 }
 
+// FindCompositeTypes queues a FindCompositeTypes query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) FindCompositeTypes(oids []uint32) *FindCompositeTypesHandle {
+	b.q.FindCompositeTypesBatch(b.batch, oids)
+	h := &FindCompositeTypesHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// FindCompositeTypesHandle is the result of a FindCompositeTypes query queued in a Batch.
+type FindCompositeTypesHandle struct {
+	b   *Batch
+	res []FindCompositeTypesRow
+	err error
+}
+
+func (h *FindCompositeTypesHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.FindCompositeTypesScan(results)
+	return h.err
+}
+
+// Result returns the result of the FindCompositeTypes query. Returns an error if the
+// batch wasn't sent.
+func (h *FindCompositeTypesHandle) Result() ([]FindCompositeTypesRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("FindCompositeTypes result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const findDescendantOIDsSQL = `WITH RECURSIVE oid_descs(oid) AS (
   -- Base case.
   SELECT oid
@@ -503,15 +1345,33 @@ const findDescendantOIDsSQL = `WITH RECURSIVE oid_descs(oid) AS (
     FROM pg_type arr_typ
       JOIN pg_type elem_typ ON arr_typ.typelem = elem_typ.oid
       JOIN all_oids od ON arr_typ.oid = od.oid
+    UNION
+    -- All domain base types.
+    SELECT dom_typ.typbasetype
+    FROM pg_type dom_typ
+      JOIN all_oids od ON dom_typ.oid = od.oid
+    WHERE dom_typ.typtype = 'd'
+    UNION
+    -- All range subtypes.
+    SELECT rng.rngsubtype
+    FROM pg_range rng
+      JOIN all_oids od ON rng.rngtypid = od.oid
+    UNION
+    -- All multirange range types.
+    SELECT rng.rngtypid
+    FROM pg_range rng
+      JOIN all_oids od ON (to_jsonb(rng) ->> 'rngmultitypid')::oid = od.oid
   ) t
 )
 SELECT oid
 FROM oid_descs;`
 
+const findDescendantOIDsStmt = "pggen_FindDescendantOIDs_fb80b9fa00cfb0d4"
+
 // FindDescendantOIDs implements Querier.FindDescendantOIDs.
 func (q *DBQuerier) FindDescendantOIDs(ctx context.Context, oids []uint32) ([]pgtype.OID, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "FindDescendantOIDs")
-	rows, err := q.conn.Query(ctx, findDescendantOIDsSQL, oids)
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(findDescendantOIDsSQL, findDescendantOIDsStmt), oids)
 	if err != nil {
 		return nil, fmt.Errorf("query FindDescendantOIDs: %w", err)
 	}
@@ -532,7 +1392,7 @@ func (q *DBQuerier) FindDescendantOIDs(ctx context.Context, oids []uint32) ([]pg
 
 // FindDescendantOIDsBatch implements Querier.FindDescendantOIDsBatch.
 func (q *DBQuerier) FindDescendantOIDsBatch(batch genericBatch, oids []uint32) {
-	batch.Queue(findDescendantOIDsSQL, oids)
+	batch.Queue(q.chooseSQL(findDescendantOIDsSQL, findDescendantOIDsStmt), oids)
 }
 
 // FindDescendantOIDsScan implements Querier.FindDescendantOIDsScan.
@@ -556,16 +1416,48 @@ func (q *DBQuerier) FindDescendantOIDsScan(results pgx.BatchResults) ([]pgtype.O
 	return items, err
 }
 
+// FindDescendantOIDs queues a FindDescendantOIDs query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) FindDescendantOIDs(oids []uint32) *FindDescendantOIDsHandle {
+	b.q.FindDescendantOIDsBatch(b.batch, oids)
+	h := &FindDescendantOIDsHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// FindDescendantOIDsHandle is the result of a FindDescendantOIDs query queued in a Batch.
+type FindDescendantOIDsHandle struct {
+	b   *Batch
+	res []pgtype.OID
+	err error
+}
+
+func (h *FindDescendantOIDsHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.FindDescendantOIDsScan(results)
+	return h.err
+}
+
+// Result returns the result of the FindDescendantOIDs query. Returns an error if the
+// batch wasn't sent.
+func (h *FindDescendantOIDsHandle) Result() ([]pgtype.OID, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("FindDescendantOIDs result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const findOIDByNameSQL = `SELECT oid
 FROM pg_type
 WHERE typname::text = $1
 ORDER BY oid DESC
 LIMIT 1;`
 
+const findOIDByNameStmt = "pggen_FindOIDByName_d1951d3ff26451a4"
+
 // FindOIDByName implements Querier.FindOIDByName.
 func (q *DBQuerier) FindOIDByName(ctx context.Context, name string) (pgtype.OID, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "FindOIDByName")
-	row := q.conn.QueryRow(ctx, findOIDByNameSQL, name)
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(findOIDByNameSQL, findOIDByNameStmt), name)
 	var item pgtype.OID
 	if err := row.Scan(&item); err != nil {
 		return item, fmt.Errorf("query FindOIDByName: %w", err)
@@ -575,7 +1467,7 @@ func (q *DBQuerier) FindOIDByName(ctx context.Context, name string) (pgtype.OID,
 
 // FindOIDByNameBatch implements Querier.FindOIDByNameBatch.
 func (q *DBQuerier) FindOIDByNameBatch(batch genericBatch, name string) {
-	batch.Queue(findOIDByNameSQL, name)
+	batch.Queue(q.chooseSQL(findOIDByNameSQL, findOIDByNameStmt), name)
 }
 
 // FindOIDByNameScan implements Querier.FindOIDByNameScan.
@@ -588,14 +1480,46 @@ func (q *DBQuerier) FindOIDByNameScan(results pgx.BatchResults) (pgtype.OID, err
 	return item, nil
 }
 
+// FindOIDByName queues a FindOIDByName query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) FindOIDByName(name string) *FindOIDByNameHandle {
+	b.q.FindOIDByNameBatch(b.batch, name)
+	h := &FindOIDByNameHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// FindOIDByNameHandle is the result of a FindOIDByName query queued in a Batch.
+type FindOIDByNameHandle struct {
+	b   *Batch
+	res pgtype.OID
+	err error
+}
+
+func (h *FindOIDByNameHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.FindOIDByNameScan(results)
+	return h.err
+}
+
+// Result returns the result of the FindOIDByName query. Returns an error if the
+// batch wasn't sent.
+func (h *FindOIDByNameHandle) Result() (pgtype.OID, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("FindOIDByName result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 const findOIDNameSQL = `SELECT typname AS name
 FROM pg_type
 WHERE oid = $1;`
 
+const findOIDNameStmt = "pggen_FindOIDName_2c905613f31ead52"
+
 // FindOIDName implements Querier.FindOIDName.
 func (q *DBQuerier) FindOIDName(ctx context.Context, oid pgtype.OID) (pgtype.Name, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "FindOIDName")
-	row := q.conn.QueryRow(ctx, findOIDNameSQL, oid)
+	row := q.readConn().QueryRow(ctx, q.chooseSQL(findOIDNameSQL, findOIDNameStmt), oid)
 	var item pgtype.Name
 	if err := row.Scan(&item); err != nil {
 		return item, fmt.Errorf("query FindOIDName: %w", err)
@@ -605,7 +1529,7 @@ func (q *DBQuerier) FindOIDName(ctx context.Context, oid pgtype.OID) (pgtype.Nam
 
 // FindOIDNameBatch implements Querier.FindOIDNameBatch.
 func (q *DBQuerier) FindOIDNameBatch(batch genericBatch, oid pgtype.OID) {
-	batch.Queue(findOIDNameSQL, oid)
+	batch.Queue(q.chooseSQL(findOIDNameSQL, findOIDNameStmt), oid)
 }
 
 // FindOIDNameScan implements Querier.FindOIDNameScan.
@@ -618,20 +1542,58 @@ func (q *DBQuerier) FindOIDNameScan(results pgx.BatchResults) (pgtype.Name, erro
 	return item, nil
 }
 
-const findOIDNamesSQL = `SELECT oid, typname AS name, typtype AS kind
-FROM pg_type
-WHERE oid = ANY ($1::oid[]);`
+// FindOIDName queues a FindOIDName query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) FindOIDName(oid pgtype.OID) *FindOIDNameHandle {
+	b.q.FindOIDNameBatch(b.batch, oid)
+	h := &FindOIDNameHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// FindOIDNameHandle is the result of a FindOIDName query queued in a Batch.
+type FindOIDNameHandle struct {
+	b   *Batch
+	res pgtype.Name
+	err error
+}
+
+func (h *FindOIDNameHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.FindOIDNameScan(results)
+	return h.err
+}
+
+// Result returns the result of the FindOIDName query. Returns an error if the
+// batch wasn't sent.
+func (h *FindOIDNameHandle) Result() (pgtype.Name, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("FindOIDName result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
+const findOIDNamesSQL = `SELECT
+  typ.oid,
+  typ.typname       AS name,
+  nsp.nspname::text AS schema_name,
+  typ.typtype       AS kind
+FROM pg_type typ
+  JOIN pg_namespace nsp ON typ.typnamespace = nsp.oid
+WHERE typ.oid = ANY ($1::oid[]);`
+
+const findOIDNamesStmt = "pggen_FindOIDNames_4fe3a0f1aa6fa818"
 
 type FindOIDNamesRow struct {
-	OID  pgtype.OID   `json:"oid"`
-	Name pgtype.Name  `json:"name"`
-	Kind pgtype.QChar `json:"kind"`
+	OID        pgtype.OID   `json:"oid"`
+	Name       pgtype.Name  `json:"name"`
+	SchemaName string       `json:"schema_name"`
+	Kind       pgtype.QChar `json:"kind"`
 }
 
 // FindOIDNames implements Querier.FindOIDNames.
 func (q *DBQuerier) FindOIDNames(ctx context.Context, oid []uint32) ([]FindOIDNamesRow, error) {
 	ctx = context.WithValue(ctx, "pggen_query_name", "FindOIDNames")
-	rows, err := q.conn.Query(ctx, findOIDNamesSQL, oid)
+	rows, err := q.readConn().Query(ctx, q.chooseSQL(findOIDNamesSQL, findOIDNamesStmt), oid)
 	if err != nil {
 		return nil, fmt.Errorf("query FindOIDNames: %w", err)
 	}
@@ -639,7 +1601,7 @@ func (q *DBQuerier) FindOIDNames(ctx context.Context, oid []uint32) ([]FindOIDNa
 	items := []FindOIDNamesRow{}
 	for rows.Next() {
 		var item FindOIDNamesRow
-		if err := rows.Scan(&item.OID, &item.Name, &item.Kind); err != nil {
+		if err := rows.Scan(&item.OID, &item.Name, &item.SchemaName, &item.Kind); err != nil {
 			return nil, fmt.Errorf("scan FindOIDNames row: %w", err)
 		}
 		items = append(items, item)
@@ -652,7 +1614,7 @@ func (q *DBQuerier) FindOIDNames(ctx context.Context, oid []uint32) ([]FindOIDNa
 
 // FindOIDNamesBatch implements Querier.FindOIDNamesBatch.
 func (q *DBQuerier) FindOIDNamesBatch(batch genericBatch, oid []uint32) {
-	batch.Queue(findOIDNamesSQL, oid)
+	batch.Queue(q.chooseSQL(findOIDNamesSQL, findOIDNamesStmt), oid)
 }
 
 // FindOIDNamesScan implements Querier.FindOIDNamesScan.
@@ -665,7 +1627,7 @@ func (q *DBQuerier) FindOIDNamesScan(results pgx.BatchResults) ([]FindOIDNamesRo
 	items := []FindOIDNamesRow{}
 	for rows.Next() {
 		var item FindOIDNamesRow
-		if err := rows.Scan(&item.OID, &item.Name, &item.Kind); err != nil {
+		if err := rows.Scan(&item.OID, &item.Name, &item.SchemaName, &item.Kind); err != nil {
 			return nil, fmt.Errorf("scan FindOIDNamesBatch row: %w", err)
 		}
 		items = append(items, item)
@@ -676,6 +1638,36 @@ func (q *DBQuerier) FindOIDNamesScan(results pgx.BatchResults) ([]FindOIDNamesRo
 	return items, err
 }
 
+// FindOIDNames queues a FindOIDNames query in the batch. Call Result on the
+// returned handle after calling Send.
+func (b *Batch) FindOIDNames(oid []uint32) *FindOIDNamesHandle {
+	b.q.FindOIDNamesBatch(b.batch, oid)
+	h := &FindOIDNamesHandle{b: b}
+	b.handles = append(b.handles, h)
+	return h
+}
+
+// FindOIDNamesHandle is the result of a FindOIDNames query queued in a Batch.
+type FindOIDNamesHandle struct {
+	b   *Batch
+	res []FindOIDNamesRow
+	err error
+}
+
+func (h *FindOIDNamesHandle) scan(results pgx.BatchResults) error {
+	h.res, h.err = h.b.q.FindOIDNamesScan(results)
+	return h.err
+}
+
+// Result returns the result of the FindOIDNames query. Returns an error if the
+// batch wasn't sent.
+func (h *FindOIDNamesHandle) Result() ([]FindOIDNamesRow, error) {
+	if !h.b.sent {
+		return h.res, fmt.Errorf("FindOIDNames result: %w", errBatchNotSent)
+	}
+	return h.res, h.err
+}
+
 // textPreferrer wraps a pgtype.ValueTranscoder and sets the preferred encoding
 // format to text instead binary (the default). pggen uses the text format
 // when the OID is unknownOID because the binary format requires the OID.
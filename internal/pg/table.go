@@ -0,0 +1,191 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+	"github.com/leg100/pggen/internal/texts"
+)
+
+// Table stores information about a table from the pg_class, pg_attribute,
+// and pg_constraint catalog tables.
+type Table struct {
+	OID    pgtype.OID // pg_class.oid: row identifier
+	Name   string     // pg_class.relname: name of the table
+	Schema string     // pg_namespace.nspname: schema of the table
+	// The name of the table as regclass text, quoted if necessary and
+	// qualified with the schema if the schema isn't on the search_path.
+	QualName string
+	Columns  []TableColumn // columns of the table in order, excluding dropped columns
+	// Names of the primary key columns in key order; empty if the table has no
+	// primary key.
+	PrimaryKey []string
+	// Names of the columns of each unique constraint, excluding the primary
+	// key, in key order.
+	UniqueKeys [][]string
+}
+
+// TableColumn is a column of a Table.
+// https://www.postgresql.org/docs/13/catalog-pg-attribute.html
+type TableColumn struct {
+	Name             string // pg_attribute.attname: column name
+	Number           uint16 // pg_attribute.attnum: the number of column starting from 1
+	NotNull          bool   // pg_attribute.attnotnull: represents a not-null constraint
+	HasDefault       bool   // pg_attribute.atthasdef: column has a default expression
+	IsIdentity       bool   // pg_attribute.attidentity: column is an identity column
+	IsIdentityAlways bool   // pg_attribute.attidentity: column is a GENERATED ALWAYS identity column
+	IsGenerated      bool   // pg_attribute.attgenerated: column is a generated column
+}
+
+// FetchTables fetches the tables with the given names from the catalog
+// tables. Names resolve like a regclass, so a name may be qualified by
+// schema. Returns the tables in the order of names, omitting a table already
+// returned for an earlier name. Doesn't cache the tables; use
+// Catalog.FetchTables to cache.
+func FetchTables(conn *pgx.Conn, names []string) ([]Table, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	tables, err := fetchTablesByName(conn, names, &FetchStats{})
+	if err != nil {
+		return nil, err
+	}
+	return uniqueTables(tables), nil
+}
+
+// fetchTablesByName fetches the table for each name in names. Returns a table
+// for each name in the order of names, even if two names resolve to the same
+// table. Records the round-trips in stats.
+func fetchTablesByName(conn *pgx.Conn, names []string, stats *FetchStats) ([]Table, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Tables.
+	tables := make([]Table, 0, len(names))
+	tableIdxs := make(map[pgtype.OID][]int, len(names))
+	start := time.Now()
+	tableRows, err := conn.Query(ctx, texts.Dedent(`
+		SELECT cls.oid, cls.relname, ns.nspname, cls.oid::regclass::text, cls.relkind::text
+		FROM unnest($1::text[]::regclass[]) WITH ORDINALITY AS t(oid, ord)
+		  JOIN pg_class cls ON cls.oid = t.oid
+		  JOIN pg_namespace ns ON ns.oid = cls.relnamespace
+		ORDER BY t.ord
+	`), names)
+	if err != nil {
+		return nil, fmt.Errorf("fetch tables: %w", err)
+	}
+	defer tableRows.Close()
+	for tableRows.Next() {
+		table := Table{}
+		kind := ""
+		if err := tableRows.Scan(&table.OID, &table.Name, &table.Schema, &table.QualName, &kind); err != nil {
+			return nil, fmt.Errorf("scan fetch tables row: %w", err)
+		}
+		if kind != "r" && kind != "p" {
+			return nil, fmt.Errorf("relation %s is not a table; got relkind %q", table.QualName, kind)
+		}
+		tableIdxs[table.OID] = append(tableIdxs[table.OID], len(tables))
+		tables = append(tables, table)
+	}
+	stats.Record(start)
+	if err := tableRows.Err(); err != nil {
+		return nil, fmt.Errorf("close fetch tables rows: %w", err)
+	}
+
+	// Columns.
+	start = time.Now()
+	colRows, err := conn.Query(ctx, texts.Dedent(`
+		SELECT attr.attrelid,
+		       attr.attname,
+		       attr.attnum,
+		       attr.attnotnull,
+		       attr.atthasdef,
+		       attr.attidentity <> '',
+		       attr.attidentity = 'a',
+		       attr.attgenerated <> ''
+		FROM pg_attribute attr
+		WHERE attr.attrelid = ANY ($1::text[]::regclass[])
+		  AND attr.attnum > 0
+		  AND NOT attr.attisdropped
+		ORDER BY attr.attrelid, attr.attnum
+	`), names)
+	if err != nil {
+		return nil, fmt.Errorf("fetch table columns: %w", err)
+	}
+	defer colRows.Close()
+	for colRows.Next() {
+		var tableOID pgtype.OID
+		col := TableColumn{}
+		if err := colRows.Scan(&tableOID, &col.Name, &col.Number, &col.NotNull,
+			&col.HasDefault, &col.IsIdentity, &col.IsIdentityAlways, &col.IsGenerated); err != nil {
+			return nil, fmt.Errorf("scan fetch table columns row: %w", err)
+		}
+		for _, idx := range tableIdxs[tableOID] {
+			tables[idx].Columns = append(tables[idx].Columns, col)
+		}
+	}
+	stats.Record(start)
+	if err := colRows.Err(); err != nil {
+		return nil, fmt.Errorf("close fetch table columns rows: %w", err)
+	}
+
+	// Primary and unique keys.
+	start = time.Now()
+	keyRows, err := conn.Query(ctx, texts.Dedent(`
+		SELECT con.conrelid,
+		       con.contype::text,
+		       array(
+		         SELECT attr.attname
+		         FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+		           JOIN pg_attribute attr ON attr.attrelid = con.conrelid AND attr.attnum = k.attnum
+		         ORDER BY k.ord
+		       )::text[]
+		FROM pg_constraint con
+		WHERE con.conrelid = ANY ($1::text[]::regclass[])
+		  AND con.contype IN ('p', 'u')
+		ORDER BY con.conrelid, con.conname
+	`), names)
+	if err != nil {
+		return nil, fmt.Errorf("fetch table keys: %w", err)
+	}
+	defer keyRows.Close()
+	for keyRows.Next() {
+		var tableOID pgtype.OID
+		kind := ""
+		var cols []string
+		if err := keyRows.Scan(&tableOID, &kind, &cols); err != nil {
+			return nil, fmt.Errorf("scan fetch table keys row: %w", err)
+		}
+		for _, idx := range tableIdxs[tableOID] {
+			if kind == "p" {
+				tables[idx].PrimaryKey = cols
+			} else {
+				tables[idx].UniqueKeys = append(tables[idx].UniqueKeys, cols)
+			}
+		}
+	}
+	stats.Record(start)
+	if err := keyRows.Err(); err != nil {
+		return nil, fmt.Errorf("close fetch table keys rows: %w", err)
+	}
+
+	return tables, nil
+}
+
+// uniqueTables returns tables without a table already present earlier in
+// tables.
+func uniqueTables(tables []Table) []Table {
+	unique := make([]Table, 0, len(tables))
+	seen := make(map[pgtype.OID]struct{}, len(tables))
+	for _, table := range tables {
+		if _, ok := seen[table.OID]; ok {
+			continue
+		}
+		seen[table.OID] = struct{}{}
+		unique = append(unique, table)
+	}
+	return unique
+}
@@ -0,0 +1,71 @@
+package pg
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/leg100/pggen/internal/pgtest"
+	"github.com/leg100/pggen/internal/texts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchTables(t *testing.T) {
+	conn, cleanup := pgtest.NewPostgresSchemaString(t, texts.Dedent(`
+		CREATE TABLE author (
+			author_id  serial PRIMARY KEY,
+			first_name text NOT NULL,
+			email      text UNIQUE,
+			full_name  text GENERATED ALWAYS AS (first_name || '!') STORED
+		);
+
+		CREATE SCHEMA other;
+		CREATE TABLE other.tag (
+			id   int GENERATED ALWAYS AS IDENTITY,
+			name text,
+			rank int GENERATED BY DEFAULT AS IDENTITY
+		);
+		CREATE VIEW author_view AS SELECT * FROM author;
+	`))
+	defer cleanup()
+
+	tables, err := FetchTables(conn, []string{"other.tag", "author"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range tables {
+		tables[i].OID = 0 // not deterministic
+	}
+	want := []Table{
+		{
+			Name:     "tag",
+			Schema:   "other",
+			QualName: "other.tag",
+			Columns: []TableColumn{
+				{Name: "id", Number: 1, NotNull: true, IsIdentity: true, IsIdentityAlways: true},
+				{Name: "name", Number: 2},
+				{Name: "rank", Number: 3, NotNull: true, IsIdentity: true},
+			},
+		},
+		{
+			Name:     "author",
+			Schema:   findCurrentSchema(t, conn),
+			QualName: "author",
+			Columns: []TableColumn{
+				{Name: "author_id", Number: 1, NotNull: true, HasDefault: true},
+				{Name: "first_name", Number: 2, NotNull: true},
+				{Name: "email", Number: 3},
+				{Name: "full_name", Number: 4, HasDefault: true, IsGenerated: true},
+			},
+			PrimaryKey: []string{"author_id"},
+			UniqueKeys: [][]string{{"email"}},
+		},
+	}
+	if diff := cmp.Diff(want, tables); diff != "" {
+		t.Errorf("FetchTables() mismatch (-want +got):\n%s", diff)
+	}
+
+	_, err = FetchTables(conn, []string{"author_view"})
+	assert.Error(t, err, "FetchTables() should error for a view")
+	_, err = FetchTables(conn, []string{"missing_table"})
+	assert.Error(t, err, "FetchTables() should error for a missing table")
+}
@@ -12,6 +12,28 @@ import (
 type TypeFetcher struct {
 	cache   *typeCache
 	querier *DBQuerier
+	stats   FetchStats
+}
+
+// FetchStats describes the catalog queries sent to Postgres, like by a
+// TypeFetcher or Catalog.
+type FetchStats struct {
+	RoundTrips int           // number of round-trips to Postgres
+	Duration   time.Duration // total time spent waiting on Postgres
+}
+
+// Record records a round-trip to Postgres that started at start.
+func (s *FetchStats) Record(start time.Time) {
+	s.RoundTrips++
+	s.Duration += time.Since(start)
+}
+
+// Add returns the sum of the stats s and other.
+func (s FetchStats) Add(other FetchStats) FetchStats {
+	return FetchStats{
+		RoundTrips: s.RoundTrips + other.RoundTrips,
+		Duration:   s.Duration + other.Duration,
+	}
 }
 
 func NewTypeFetcher(conn *pgx.Conn) *TypeFetcher {
@@ -25,49 +47,145 @@ func NewTypeFetcher(conn *pgx.Conn) *TypeFetcher {
 // returned map contains every unique OID in oids (oids may contain duplicates)
 // unless there's an error.
 func (tf *TypeFetcher) FindTypesByOIDs(oids ...uint32) (map[pgtype.OID]Type, error) {
-	if types, uncached := tf.cache.getOIDs(oids...); len(uncached) == 0 {
+	types, uncached := tf.cache.getOIDs(oids...)
+	if len(uncached) == 0 {
 		return types, nil
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// First, recursively find all descendant OIDs from composite or array types.
-	// Composite types are the only type that can be nested. Array types have an
-	// element type that might be a composite type.
-	descOIDs, err := tf.querier.FindDescendantOIDs(ctx, oids)
+	// Fetch the uncached types and, in the same round-trip, recursively find
+	// all descendant OIDs from composite, array, domain, and range types.
+	batch := tf.querier.NewBatch()
+	descsH := batch.FindDescendantOIDs(oidKeys(uncached))
+	if err := tf.fetchTypes(ctx, batch, types, uncached); err != nil {
+		return nil, err
+	}
+	descOIDs, err := descsH.Result()
 	if err != nil {
 		return nil, fmt.Errorf("find descendant oids: %w", err)
 	}
+
+	// Fetch uncached descendants, like the column types of a new composite
+	// type, in a second round-trip. FindDescendantOIDs is recursive, so the
+	// descendants don't have uncached descendants of their own.
 	allOIDs := make([]uint32, len(descOIDs))
 	for i, d := range descOIDs {
 		allOIDs[i] = uint32(d)
 	}
-	types, uncached := tf.cache.getOIDs(allOIDs...)
+	descTypes, descUncached := tf.cache.getOIDs(allOIDs...)
+	for oid, typ := range descTypes {
+		types[oid] = typ
+	}
+	if len(descUncached) > 0 {
+		if err := tf.fetchTypes(ctx, tf.querier.NewBatch(), types, descUncached); err != nil {
+			return nil, err
+		}
+	}
+
+	// Resolve all placeholder types now that we know all types.
+	if err := tf.resolvePlaceholderTypes(types); err != nil {
+		return nil, err
+	}
+	// The cache has the types with placeholders, so replace them.
+	for _, typ := range types {
+		tf.cache.addType(typ)
+	}
+
+	for oid := range descUncached {
+		uncached[oid] = struct{}{}
+	}
+	if len(uncached) > 0 {
+		return nil, fmt.Errorf("had %d unclassified types: %v", len(uncached), uncached)
+	}
+	return types, nil
+}
+
+// fetchTypes queues the queries for the uncached OIDs in batch, sends the
+// batch, and adds the found types to types and the cache. Deletes each found
+// OID from uncached. A found type references a type that isn't fetched yet
+// with a placeholderType.
+func (tf *TypeFetcher) fetchTypes(ctx context.Context, batch *Batch, types map[pgtype.OID]Type, uncached map[pgtype.OID]struct{}) error {
+	// Fetch every kind of type in a single round-trip. Each query only returns
+	// types of its kind, except FindOIDNames which returns all types.
+	uncachedOIDs := oidKeys(uncached)
+	enumsH := batch.FindEnumTypes(uncachedOIDs)
+	compsH := batch.FindCompositeTypes(uncachedOIDs)
+	domainsH := batch.FindDomainTypes(uncachedOIDs)
+	rangesH := batch.FindRangeTypes(uncachedOIDs)
+	multirangesH := batch.FindMultirangeTypes(uncachedOIDs)
+	arrsH := batch.FindArrayTypes(uncachedOIDs)
+	unknownsH := batch.FindOIDNames(uncachedOIDs)
+	if err := tf.sendBatch(ctx, batch); err != nil {
+		return fmt.Errorf("fetch types: %w", err)
+	}
 
-	enums, err := tf.findEnumTypes(ctx, uncached)
+	// Build types in dependency order so that a type can reference the types
+	// built before it, like an array with a domain element type.
+	enumRows, err := enumsH.Result()
 	if err != nil {
-		return nil, fmt.Errorf("find enum types: %w", err)
+		return fmt.Errorf("find enum types: %w", err)
 	}
-	for _, enum := range enums {
+	for _, enum := range newEnumTypes(enumRows) {
 		types[enum.ID] = enum
 		tf.cache.addType(enum)
 		delete(uncached, enum.ID)
 	}
 
-	comps, err := tf.findCompositeTypes(ctx, uncached)
+	compRows, err := compsH.Result()
 	if err != nil {
-		return nil, fmt.Errorf("find composite types: %w", err)
+		return fmt.Errorf("find composite types: %w", err)
 	}
-	for _, comp := range comps {
+	for _, comp := range tf.newCompositeTypes(compRows) {
 		types[comp.ID] = comp
 		tf.cache.addType(comp)
 		delete(uncached, comp.ID)
 	}
 
-	arrs, err := tf.findArrayTypes(ctx, uncached)
+	// Build domains before arrays because an array element might be a domain.
+	domainRows, err := domainsH.Result()
+	if err != nil {
+		return fmt.Errorf("find domain types: %w", err)
+	}
+	for _, domain := range tf.newDomainTypes(domainRows) {
+		types[domain.ID] = domain
+		tf.cache.addType(domain)
+		delete(uncached, domain.ID)
+	}
+
+	// Build ranges before arrays because an array element might be a range.
+	rangeRows, err := rangesH.Result()
+	if err != nil {
+		return fmt.Errorf("find range types: %w", err)
+	}
+	for _, rng := range tf.newRangeTypes(rangeRows) {
+		types[rng.ID] = rng
+		tf.cache.addType(rng)
+		delete(uncached, rng.ID)
+	}
+
+	multirangeRows, err := multirangesH.Result()
 	if err != nil {
-		return nil, fmt.Errorf("find array types: %w", err)
+		return fmt.Errorf("find multirange types: %w", err)
+	}
+	multiranges, err := tf.newMultirangeTypes(multirangeRows)
+	if err != nil {
+		return fmt.Errorf("build multirange types: %w", err)
+	}
+	for _, multi := range multiranges {
+		types[multi.ID] = multi
+		tf.cache.addType(multi)
+		delete(uncached, multi.ID)
+	}
+
+	arrRows, err := arrsH.Result()
+	if err != nil {
+		return fmt.Errorf("find array types: %w", err)
+	}
+	arrs, err := tf.newArrayTypes(arrRows)
+	if err != nil {
+		return fmt.Errorf("build array types: %w", err)
 	}
 	for _, arr := range arrs {
 		types[arr.ID] = arr
@@ -75,33 +193,19 @@ func (tf *TypeFetcher) FindTypesByOIDs(oids ...uint32) (map[pgtype.OID]Type, err
 		delete(uncached, arr.ID)
 	}
 
-	unknowns, err := tf.findUnknownTypes(ctx, uncached)
+	unknownRows, err := unknownsH.Result()
 	if err != nil {
-		return nil, fmt.Errorf("find unknown types: %w", err)
+		return fmt.Errorf("find unknown types: %w", err)
 	}
-	for _, unk := range unknowns {
+	for _, unk := range newUnknownTypes(unknownRows, uncached) {
 		types[unk.ID] = unk
 		tf.cache.addType(unk)
 		delete(uncached, unk.ID)
 	}
-
-	// Resolve all placeholder types now that we know all types.
-	if err := tf.resolvePlaceholderTypes(types); err != nil {
-		return nil, err
-	}
-
-	if len(uncached) > 0 {
-		return nil, fmt.Errorf("had %d unclassified types: %v", len(uncached), uncached)
-	}
-	return types, nil
+	return nil
 }
 
-func (tf *TypeFetcher) findEnumTypes(ctx context.Context, uncached map[pgtype.OID]struct{}) ([]EnumType, error) {
-	oids := oidKeys(uncached)
-	rows, err := tf.querier.FindEnumTypes(ctx, oids)
-	if err != nil {
-		return nil, fmt.Errorf("find enum oid types: %w", err)
-	}
+func newEnumTypes(rows []FindEnumTypesRow) []EnumType {
 	types := make([]EnumType, len(rows))
 	for i, enum := range rows {
 		childOIDs := make([]pgtype.OID, len(enum.ChildOIDs))
@@ -111,20 +215,16 @@ func (tf *TypeFetcher) findEnumTypes(ctx context.Context, uncached map[pgtype.OI
 		types[i] = EnumType{
 			ID:        enum.OID,
 			Name:      enum.TypeName,
+			Schema:    enum.SchemaName,
 			Labels:    enum.Labels,
 			Orders:    enum.Orders,
 			ChildOIDs: childOIDs,
 		}
 	}
-	return types, nil
+	return types
 }
 
-func (tf *TypeFetcher) findCompositeTypes(ctx context.Context, uncached map[pgtype.OID]struct{}) ([]CompositeType, error) {
-	oids := oidKeys(uncached)
-	rows, err := tf.querier.FindCompositeTypes(ctx, oids)
-	if err != nil {
-		return nil, fmt.Errorf("find composite types: %w", err)
-	}
+func (tf *TypeFetcher) newCompositeTypes(rows []FindCompositeTypesRow) []CompositeType {
 	// Record all composite types to fake a topological sort by repeated iteration.
 	allComposites := make(map[pgtype.OID]struct{}, len(rows))
 	for _, row := range rows {
@@ -155,53 +255,120 @@ func (tf *TypeFetcher) findCompositeTypes(ctx context.Context, uncached map[pgty
 		typ := CompositeType{
 			ID:          row.TableTypeOID,
 			Name:        row.TableName.String,
+			Schema:      row.SchemaName,
 			ColumnNames: colNames,
 			ColumnTypes: colTypes,
 		}
 		tf.cache.addType(typ)
 		types = append(types, typ)
 	}
-	return types, nil
+	return types
 }
 
-func (tf *TypeFetcher) findUnknownTypes(ctx context.Context, uncached map[pgtype.OID]struct{}) ([]UnknownType, error) {
-	oids := oidKeys(uncached)
-	rows, err := tf.querier.FindOIDNames(ctx, oids)
-	if err != nil {
-		return nil, fmt.Errorf("find OID names for unknown OIDs: %w", err)
+func (tf *TypeFetcher) newDomainTypes(rows []FindDomainTypesRow) []DomainType {
+	types := make([]DomainType, len(rows))
+	for i, row := range rows {
+		baseType, ok := tf.cache.getOID(uint32(row.BaseTypeOID))
+		if !ok {
+			// We might resolve the base type in a future pass like findArrayTypes.
+			baseType = placeholderType{ID: row.BaseTypeOID}
+		}
+		types[i] = DomainType{
+			ID:         row.OID,
+			Name:       row.TypeName,
+			Schema:     row.SchemaName,
+			IsNotNull:  row.IsNotNull,
+			HasDefault: row.HasDefault,
+			BaseType:   baseType,
+			Dimensions: int(row.Dimensions),
+			CheckExprs: row.CheckExprs,
+		}
 	}
-	types := make([]UnknownType, len(rows))
+	return types
+}
+
+func (tf *TypeFetcher) newRangeTypes(rows []FindRangeTypesRow) []RangeType {
+	types := make([]RangeType, len(rows))
 	for i, row := range rows {
-		types[i] = UnknownType{
-			ID:     row.OID,
-			Name:   row.Name.String,
-			PgKind: TypeKind(row.Kind.Int),
+		elemType, ok := tf.cache.getOID(uint32(row.ElemOID))
+		if !ok {
+			// We might resolve the subtype in a future pass like findArrayTypes.
+			elemType = placeholderType{ID: row.ElemOID}
+		}
+		types[i] = RangeType{
+			ID:       row.OID,
+			Name:     row.TypeName,
+			Schema:   row.SchemaName,
+			ElemType: elemType,
+		}
+	}
+	return types
+}
+
+func (tf *TypeFetcher) newMultirangeTypes(rows []FindMultirangeTypesRow) ([]MultirangeType, error) {
+	types := make([]MultirangeType, len(rows))
+	for i, row := range rows {
+		rangeType, ok := tf.cache.getOID(uint32(row.RangeOID))
+		if !ok {
+			// We might fetch the range type in a later round-trip.
+			rangeType = placeholderType{ID: row.RangeOID}
+		}
+		types[i] = MultirangeType{
+			ID:        row.OID,
+			Name:      row.TypeName,
+			Schema:    row.SchemaName,
+			RangeType: rangeType,
 		}
 	}
 	return types, nil
 }
 
-func (tf *TypeFetcher) findArrayTypes(ctx context.Context, uncached map[pgtype.OID]struct{}) ([]ArrayType, error) {
-	oids := oidKeys(uncached)
-	rows, err := tf.querier.FindArrayTypes(ctx, oids)
-	if err != nil {
-		return nil, fmt.Errorf("find OID names for array OIDs: %w", err)
+// newUnknownTypes creates an UnknownType for each row with an OID in uncached.
+func newUnknownTypes(rows []FindOIDNamesRow, uncached map[pgtype.OID]struct{}) []UnknownType {
+	types := make([]UnknownType, 0, len(uncached))
+	for _, row := range rows {
+		if _, ok := uncached[row.OID]; !ok {
+			continue // already found as a more specific type
+		}
+		types = append(types, UnknownType{
+			ID:     row.OID,
+			Name:   row.Name.String,
+			Schema: row.SchemaName,
+			PgKind: TypeKind(row.Kind.Int),
+		})
 	}
+	return types
+}
+
+func (tf *TypeFetcher) newArrayTypes(rows []FindArrayTypesRow) ([]ArrayType, error) {
 	types := make([]ArrayType, len(rows))
 	for i, row := range rows {
 		elemType, ok := tf.cache.getOID(uint32(row.ElemOID))
 		if !ok {
-			return nil, fmt.Errorf("find type for array elem %s oid=%d", row.TypeName, row.OID)
+			// We might fetch the element type in a later round-trip.
+			elemType = placeholderType{ID: row.ElemOID}
 		}
 		types[i] = ArrayType{
 			ID:       row.OID,
 			Name:     row.TypeName,
+			Schema:   row.SchemaName,
 			ElemType: elemType,
 		}
 	}
 	return types, nil
 }
 
+// Stats returns the stats for all catalog queries sent by the TypeFetcher.
+func (tf *TypeFetcher) Stats() FetchStats {
+	return tf.stats
+}
+
+// sendBatch sends the batch of catalog queries in a single round-trip.
+func (tf *TypeFetcher) sendBatch(ctx context.Context, batch *Batch) error {
+	defer tf.stats.Record(time.Now())
+	return batch.Send(ctx)
+}
+
 // resolvePlaceholderTypes resolves all placeholder types or errors if we can't
 // resolve a placeholderType using all known types.
 func (tf *TypeFetcher) resolvePlaceholderTypes(knownTypes map[pgtype.OID]Type) error {
@@ -225,12 +392,34 @@ func (tf *TypeFetcher) resolvePlaceholderTypes(knownTypes map[pgtype.OID]Type) e
 			}
 			typ.ElemType = newType
 			return typ, nil
+		case DomainType:
+			newType, err := resolveType(typ.BaseType)
+			if err != nil {
+				return nil, fmt.Errorf("domain %q base type: %w", typ.Name, err)
+			}
+			typ.BaseType = newType
+			return typ, nil
+		case RangeType:
+			newType, err := resolveType(typ.ElemType)
+			if err != nil {
+				return nil, fmt.Errorf("range %q subtype: %w", typ.Name, err)
+			}
+			typ.ElemType = newType
+			return typ, nil
+		case MultirangeType:
+			newType, err := resolveType(typ.RangeType)
+			if err != nil {
+				return nil, fmt.Errorf("multirange %q range: %w", typ.Name, err)
+			}
+			typ.RangeType = newType
+			return typ, nil
 		case placeholderType:
 			newType, ok := knownTypes[typ.ID]
 			if !ok {
 				return nil, fmt.Errorf("unresolved placeholder type oid=%d", typ.ID)
 			}
-			return newType, nil
+			// The known type might have placeholders if we haven't resolved it yet.
+			return resolveType(newType)
 		default:
 			return typ, nil
 		}
@@ -150,6 +150,59 @@ func TestNewTypeFetcher(t *testing.T) {
 				);
 			`),
 		},
+		{
+			name: "domain",
+			schema: texts.Dedent(`
+				CREATE DOMAIN us_postal_code AS text NOT NULL
+					CHECK (VALUE ~ '^\d{5}$')
+					CHECK (length(VALUE) = 5);
+			`),
+			fetchOID: "us_postal_code",
+			wants: []Type{
+				DomainType{
+					Name:      "us_postal_code",
+					IsNotNull: true,
+					BaseType:  Text,
+					CheckExprs: []string{
+						"CHECK ((VALUE ~ '^\\d{5}$'::text))",
+						"CHECK ((length(VALUE) = 5))",
+					},
+				},
+				Text,
+			},
+		},
+		{
+			name:     "domain array",
+			schema:   `CREATE DOMAIN positive_int AS int4 DEFAULT 1 CHECK (VALUE > 0);`,
+			fetchOID: "_positive_int",
+			wants: []Type{
+				ArrayType{
+					Name: "_positive_int",
+					ElemType: DomainType{
+						Name:       "positive_int",
+						HasDefault: true,
+						BaseType:   Int4,
+						CheckExprs: []string{"CHECK ((VALUE > 0))"},
+					},
+				},
+				DomainType{
+					Name:       "positive_int",
+					HasDefault: true,
+					BaseType:   Int4,
+					CheckExprs: []string{"CHECK ((VALUE > 0))"},
+				},
+				Int4,
+			},
+		},
+		{
+			name:     "range",
+			schema:   `CREATE TYPE floatrange AS RANGE (subtype = float8);`,
+			fetchOID: "floatrange",
+			wants: []Type{
+				RangeType{Name: "floatrange", ElemType: Float8},
+				Float8,
+			},
+		},
 		{
 			name: "custom base type",
 			schema: texts.Dedent(`
@@ -222,6 +275,9 @@ func TestNewTypeFetcher(t *testing.T) {
 				case DomainType:
 					typ.ID = findOIDVal(t, typ.Name, querier)
 					wantTypes[i] = typ
+				case RangeType:
+					typ.ID = findOIDVal(t, typ.Name, querier)
+					wantTypes[i] = typ
 				case CompositeType:
 					typ.ID = findOIDVal(t, typ.Name, querier)
 					wantTypes[i] = typ
@@ -233,10 +289,14 @@ func TestNewTypeFetcher(t *testing.T) {
 				}
 			}
 
+			// Ignore the schema because each test uses a randomly named schema.
 			opts := cmp.Options{
-				cmpopts.IgnoreFields(EnumType{}, "ChildOIDs", "ID"),
-				cmpopts.IgnoreFields(CompositeType{}, "ID"),
-				cmpopts.IgnoreFields(ArrayType{}, "ID"),
+				cmpopts.IgnoreFields(EnumType{}, "ChildOIDs", "ID", "Schema"),
+				cmpopts.IgnoreFields(CompositeType{}, "ID", "Schema"),
+				cmpopts.IgnoreFields(ArrayType{}, "ID", "Schema"),
+				cmpopts.IgnoreFields(DomainType{}, "ID", "Schema"),
+				cmpopts.IgnoreFields(RangeType{}, "ID", "Schema"),
+				cmpopts.IgnoreFields(UnknownType{}, "Schema"),
 			}
 			sortTypes(wantTypes)
 			sortTypes(gotTypes)
@@ -247,6 +307,89 @@ func TestNewTypeFetcher(t *testing.T) {
 	}
 }
 
+func TestTypeFetcher_Schema(t *testing.T) {
+	conn, cleanup := pgtest.NewPostgresSchemaString(t, texts.Dedent(`
+		CREATE SCHEMA billing;
+		CREATE SCHEMA shipping;
+		CREATE TYPE billing.status AS ENUM ('paid', 'refunded');
+		CREATE TYPE shipping.status AS ENUM ('sent', 'delivered');
+		CREATE TABLE billing.invoice (status billing.status, statuses shipping.status[]);
+	`))
+	defer cleanup()
+	defer func() {
+		if _, err := conn.Exec(context.Background(), "DROP SCHEMA billing, shipping CASCADE"); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	var invoiceOID uint32
+	if err := conn.QueryRow(context.Background(), "SELECT 'billing.invoice'::regtype::oid").Scan(&invoiceOID); err != nil {
+		t.Fatal(err)
+	}
+	fetcher := NewTypeFetcher(conn)
+	types, err := fetcher.FindTypesByOIDs(invoiceOID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	invoice, ok := types[pgtype.OID(invoiceOID)].(CompositeType)
+	if !ok {
+		t.Fatalf("want composite type for billing.invoice; got %T", types[pgtype.OID(invoiceOID)])
+	}
+	want := []string{"billing.invoice", "billing.status", "shipping._status", "shipping.status"}
+	got := []string{
+		QualifiedName(invoice),
+		QualifiedName(invoice.ColumnTypes[0]),
+		QualifiedName(invoice.ColumnTypes[1]),
+		QualifiedName(invoice.ColumnTypes[1].(ArrayType).ElemType),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("QualifiedName() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTypeFetcher_Stats(t *testing.T) {
+	conn, cleanup := pgtest.NewPostgresSchemaString(t, texts.Dedent(`
+		CREATE TYPE device_type AS ENUM ('phone', 'laptop');
+		CREATE DOMAIN us_zip AS text CHECK (VALUE <> '');
+		CREATE TABLE device (type device_type, zips us_zip[], created tstzrange);
+	`))
+	defer cleanup()
+
+	var deviceOID uint32
+	if err := conn.QueryRow(context.Background(), "SELECT 'device'::regtype::oid").Scan(&deviceOID); err != nil {
+		t.Fatal(err)
+	}
+	var deviceTypeOID uint32
+	if err := conn.QueryRow(context.Background(), "SELECT 'device_type'::regtype::oid").Scan(&deviceTypeOID); err != nil {
+		t.Fatal(err)
+	}
+	enumFetcher := NewTypeFetcher(conn)
+	if _, err := enumFetcher.FindTypesByOIDs(deviceTypeOID); err != nil {
+		t.Fatal(err)
+	}
+	// A type without uncached descendants needs a single round-trip.
+	if got := enumFetcher.Stats().RoundTrips; got != 1 {
+		t.Errorf("Stats().RoundTrips for enum = %d; want 1", got)
+	}
+
+	fetcher := NewTypeFetcher(conn)
+	if _, err := fetcher.FindTypesByOIDs(deviceOID); err != nil {
+		t.Fatal(err)
+	}
+	// One round-trip for the composite type with the descendant OIDs and one
+	// for the uncached column types.
+	if got := fetcher.Stats().RoundTrips; got != 2 {
+		t.Errorf("Stats().RoundTrips = %d; want 2", got)
+	}
+	// Cached types don't need a round-trip.
+	if _, err := fetcher.FindTypesByOIDs(deviceOID); err != nil {
+		t.Fatal(err)
+	}
+	if got := fetcher.Stats().RoundTrips; got != 2 {
+		t.Errorf("Stats().RoundTrips after cached fetch = %d; want 2", got)
+	}
+}
+
 // Get the OID by name if fetchOID was a string, or just return the OID.
 func findOIDVal(t *testing.T, fetchOID interface{}, querier *DBQuerier) pgtype.OID {
 	switch rawOID := fetchOID.(type) {
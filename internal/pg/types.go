@@ -23,6 +23,7 @@ const (
 	KindEnumType        TypeKind = 'e'
 	KindPseudoType      TypeKind = 'p'
 	KindRangeType       TypeKind = 'r'
+	KindMultirangeType  TypeKind = 'm'
 	kindPlaceholderType TypeKind = '?' // pggen only, not part of postgres
 )
 
@@ -40,6 +41,8 @@ func (k TypeKind) String() string {
 		return "PseudoType"
 	case KindRangeType:
 		return "RangeType"
+	case KindMultirangeType:
+		return "MultirangeType"
 	default:
 		panic("unhandled TypeKind: " + string(k))
 	}
@@ -63,7 +66,8 @@ type (
 		ID pgtype.OID // pg_type.oid: row identifier
 		// The name of the type, like _int4. Array types in Postgres typically
 		// begin with an underscore. From pg_type.typname.
-		Name string
+		Name   string
+		Schema string // pg_namespace.nspname: schema that contains the type
 		// pg_type.typelem: the element type of the array
 		ElemType Type
 	}
@@ -73,7 +77,8 @@ type (
 		// The name of the enum, like 'device_type' in:
 		//     CREATE TYPE device_type AS ENUM ('foo');
 		// From pg_type.typname.
-		Name string
+		Name   string
+		Schema string // pg_namespace.nspname: schema that contains the type
 		// All textual labels for this enum in sort order.
 		Labels []string
 		// When an enum type is created, its members are assigned sort-order
@@ -89,10 +94,34 @@ type (
 	DomainType struct {
 		ID         pgtype.OID // pg_type.oid: row identifier
 		Name       string     // pg_type.typname: data type name
+		Schema     string     // pg_namespace.nspname: schema that contains the type
 		IsNotNull  bool       // pg_type.typnotnull: domains only, not null constraint for domains
 		HasDefault bool       // pg_type.typdefault: domains only, if there's a default value
-		BaseType   BaseType   // pg_type.typbasetype: domains only, the base type
+		BaseType   Type       // pg_type.typbasetype: domains only, the base type
 		Dimensions int        // pg_type.typndims: domains on array type only, 0 otherwise, number of array dimensions
+		// pg_constraint: the CHECK constraints of the domain in name order, like
+		// "CHECK (VALUE > 0)".
+		CheckExprs []string
+	}
+
+	// RangeType is a user-defined range type, like floatrange in:
+	//     CREATE TYPE floatrange AS RANGE (subtype = float8);
+	// Built-in range types, like int4range, are BaseTypes.
+	RangeType struct {
+		ID       pgtype.OID // pg_type.oid: row identifier
+		Name     string     // pg_type.typname: data type name
+		Schema   string     // pg_namespace.nspname: schema that contains the type
+		ElemType Type       // pg_range.rngsubtype: the subtype of the range
+	}
+
+	// MultirangeType is an ordered list of non-overlapping ranges, like
+	// int4multirange. Postgres 14 creates a multirange type for every range
+	// type.
+	MultirangeType struct {
+		ID        pgtype.OID // pg_type.oid: row identifier
+		Name      string     // pg_type.typname: data type name
+		Schema    string     // pg_namespace.nspname: schema that contains the type
+		RangeType Type       // pg_range.rngtypid: the range type of each element
 	}
 
 	// CompositeType is a type containing multiple columns and is represented as
@@ -100,6 +129,7 @@ type (
 	CompositeType struct {
 		ID          pgtype.OID // pg_class.oid: row identifier
 		Name        string     // pg_class.relname: name of the composite type
+		Schema      string     // pg_namespace.nspname: schema that contains the type
 		ColumnNames []string   // pg_attribute.attname: names of the column, in order
 		ColumnTypes []Type     // pg_attribute JOIN pg_type: information about columns of the composite type
 	}
@@ -111,6 +141,7 @@ type (
 	UnknownType struct {
 		ID     pgtype.OID // pg_type.oid: row identifier
 		Name   string     // pg_type.typname: data type name
+		Schema string     // pg_namespace.nspname: schema that contains the type
 		PgKind TypeKind
 	}
 
@@ -124,6 +155,39 @@ type (
 	}
 )
 
+// QualifiedName returns the schema-qualified name of typ, like
+// billing.status, or the type name if the schema is unknown, like for
+// built-in types.
+func QualifiedName(typ Type) string {
+	if schema := TypeSchema(typ); schema != "" {
+		return schema + "." + typ.String()
+	}
+	return typ.String()
+}
+
+// TypeSchema returns the schema that contains typ, or an empty string if
+// unknown.
+func TypeSchema(typ Type) string {
+	switch typ := typ.(type) {
+	case ArrayType:
+		return typ.Schema
+	case EnumType:
+		return typ.Schema
+	case DomainType:
+		return typ.Schema
+	case RangeType:
+		return typ.Schema
+	case MultirangeType:
+		return typ.Schema
+	case CompositeType:
+		return typ.Schema
+	case UnknownType:
+		return typ.Schema
+	default:
+		return ""
+	}
+}
+
 func (b BaseType) OID() pgtype.OID { return b.ID }
 func (b BaseType) String() string  { return b.Name }
 func (b BaseType) Kind() TypeKind  { return KindBaseType }
@@ -144,6 +208,14 @@ func (e DomainType) OID() pgtype.OID { return e.ID }
 func (e DomainType) String() string  { return e.Name }
 func (e DomainType) Kind() TypeKind  { return KindDomainType }
 
+func (r RangeType) OID() pgtype.OID { return r.ID }
+func (r RangeType) String() string  { return r.Name }
+func (r RangeType) Kind() TypeKind  { return KindRangeType }
+
+func (m MultirangeType) OID() pgtype.OID { return m.ID }
+func (m MultirangeType) String() string  { return m.Name }
+func (m MultirangeType) Kind() TypeKind  { return KindMultirangeType }
+
 func (e CompositeType) OID() pgtype.OID { return e.ID }
 func (e CompositeType) String() string  { return e.Name }
 func (e CompositeType) Kind() TypeKind  { return KindCompositeType }
@@ -0,0 +1,38 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// ServerVersion is the version of a Postgres server.
+type ServerVersion struct {
+	Num  int    // server_version_num setting, like 130004 for 13.4
+	Name string // server_version setting, like "13.4 (Debian 13.4-1.pgdg100+1)"
+}
+
+// Major returns the major version, like 13 for 13.4.
+func (v ServerVersion) Major() int {
+	return v.Num / 10000
+}
+
+// String returns the version number, like "13.4", without the build details.
+func (v ServerVersion) String() string {
+	if i := strings.IndexByte(v.Name, ' '); i >= 0 {
+		return v.Name[:i]
+	}
+	return v.Name
+}
+
+// FetchServerVersion fetches the version of the Postgres server for conn.
+func FetchServerVersion(ctx context.Context, conn *pgx.Conn) (ServerVersion, error) {
+	v := ServerVersion{}
+	row := conn.QueryRow(ctx, `SELECT current_setting('server_version_num')::int, current_setting('server_version')`)
+	if err := row.Scan(&v.Num, &v.Name); err != nil {
+		return ServerVersion{}, fmt.Errorf("fetch server version: %w", err)
+	}
+	return v, nil
+}
@@ -0,0 +1,27 @@
+package pg
+
+import (
+	"testing"
+)
+
+func TestServerVersion(t *testing.T) {
+	tests := []struct {
+		version   ServerVersion
+		wantMajor int
+		wantStr   string
+	}{
+		{ServerVersion{Num: 130004, Name: "13.4 (Debian 13.4-1.pgdg100+1)"}, 13, "13.4"},
+		{ServerVersion{Num: 150002, Name: "15.2"}, 15, "15.2"},
+		{ServerVersion{Num: 160000, Name: "16beta1"}, 16, "16beta1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version.Name, func(t *testing.T) {
+			if got := tt.version.Major(); got != tt.wantMajor {
+				t.Errorf("Major() = %d; want %d", got, tt.wantMajor)
+			}
+			if got := tt.version.String(); got != tt.wantStr {
+				t.Errorf("String() = %q; want %q", got, tt.wantStr)
+			}
+		})
+	}
+}
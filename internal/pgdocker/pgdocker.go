@@ -13,6 +13,7 @@ import (
 	"github.com/docker/go-connections/nat"
 	"github.com/jackc/pgx/v4"
 	"github.com/leg100/pggen/internal/errs"
+	"github.com/leg100/pggen/internal/pg"
 	"github.com/leg100/pggen/internal/ports"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
@@ -32,17 +33,24 @@ type Client struct {
 	containerID string // container ID if started, empty otherwise
 	l           *zap.SugaredLogger
 	connString  string
+	version     pg.ServerVersion // set once Postgres is ready
 }
 
-// Start builds a Docker image and runs the image in a container.
-func Start(ctx context.Context, initScripts []string, l *zap.SugaredLogger) (client *Client, mErr error) {
+// Start builds a Docker image from the base Postgres image and runs the image
+// in a container. An empty image uses DefaultImage. The image can be any
+// image based on the official Postgres image, like "postgres:15" or
+// "postgis/postgis:15-3.3".
+func Start(ctx context.Context, image string, initScripts []string, l *zap.SugaredLogger) (client *Client, mErr error) {
 	now := time.Now()
 	dockerCl, err := dockerClient.NewClientWithOpts(dockerClient.FromEnv)
 	if err != nil {
 		return nil, fmt.Errorf("create client: %w", err)
 	}
+	if image == "" {
+		image = DefaultImage
+	}
 	c := &Client{docker: dockerCl, l: l}
-	imageID, err := c.buildImage(ctx, initScripts)
+	imageID, err := c.buildImage(ctx, image, initScripts)
 	l.Debugf("build image ID: %s", imageID)
 	if err != nil {
 		return nil, fmt.Errorf("build image: %w", err)
@@ -54,7 +62,7 @@ func Start(ctx context.Context, initScripts []string, l *zap.SugaredLogger) (cli
 	// Enrich logs with Docker container logs.
 	defer func() {
 		if mErr != nil {
-			logs, err := c.GetContainerLogs()
+			logs, err := c.Logs()
 			if err != nil {
 				mErr = multierr.Append(mErr, err)
 			} else {
@@ -78,14 +86,14 @@ func Start(ctx context.Context, initScripts []string, l *zap.SugaredLogger) (cli
 	if err := c.waitIsReady(ctx); err != nil {
 		return nil, fmt.Errorf("wait for postgres to be ready: %w", err)
 	}
-	c.l.Debugf("started docker postgres in %d ms", time.Since(now).Milliseconds())
+	c.l.Debugf("started docker postgres %s from image %s in %d ms", c.version, image, time.Since(now).Milliseconds())
 	return c, nil
 }
 
-// GetContainerLogs returns a string of all stderr and stdout logs for a
+// Logs returns a string of all stderr and stdout logs for a
 // container. Useful to enrich output when pggen fails to start the Docker
 // container.
-func (c *Client) GetContainerLogs() (logs string, mErr error) {
+func (c *Client) Logs() (logs string, mErr error) {
 	if c.containerID == "" {
 		return "", nil
 	}
@@ -106,9 +114,9 @@ func (c *Client) GetContainerLogs() (logs string, mErr error) {
 	return string(bs), nil
 }
 
-// buildImage creates a new Postgres Docker image with the given init scripts
-// copied into the Postgres entry point.
-func (c *Client) buildImage(ctx context.Context, initScripts []string) (id string, mErr error) {
+// buildImage creates a new Postgres Docker image from the base image with the
+// given init scripts copied into the Postgres entry point.
+func (c *Client) buildImage(ctx context.Context, image string, initScripts []string) (id string, mErr error) {
 	// Make each init script run in the order it was given using a numeric prefix.
 	initTarNames := make([]string, len(initScripts))
 	for i, script := range initScripts {
@@ -122,6 +130,7 @@ func (c *Client) buildImage(ctx context.Context, initScripts []string) (id strin
 		return "", fmt.Errorf("parse template: %w", err)
 	}
 	if err := tmpl.ExecuteTemplate(dockerfileBuf, "dockerfile", pgTemplate{
+		Image:       image,
 		InitScripts: initTarNames,
 	}); err != nil {
 		return "", fmt.Errorf("execute template: %w", err)
@@ -233,7 +242,8 @@ func (c *Client) runContainer(ctx context.Context, imageID string) (string, port
 	return containerID, port, nil
 }
 
-// waitIsReady waits until we can connect to the database.
+// waitIsReady waits until we can connect to the database, then detects the
+// server version.
 func (c *Client) waitIsReady(ctx context.Context) error {
 	connString, _ := c.ConnString()
 	cfg, err := pgx.ParseConfig(connString + " connect_timeout=1")
@@ -254,9 +264,14 @@ func (c *Client) waitIsReady(ctx context.Context) error {
 		debounce := time.After(200 * time.Millisecond)
 		conn, err := pgx.ConnectConfig(ctx, cfg)
 		if err == nil {
-			if err := conn.Close(ctx); err != nil {
-				c.l.Errorf("close postgres connection: %s", err)
+			version, err := pg.FetchServerVersion(ctx, conn)
+			if closeErr := conn.Close(ctx); closeErr != nil {
+				c.l.Errorf("close postgres connection: %s", closeErr)
+			}
+			if err != nil {
+				return fmt.Errorf("detect postgres version: %w", err)
 			}
+			c.version = version
 			return nil
 		}
 		c.l.Debugf("attempted connection: %s", err)
@@ -273,6 +288,11 @@ func (c *Client) ConnString() (string, error) {
 	return c.connString, nil
 }
 
+// ServerVersion returns the version of the started Postgres server.
+func (c *Client) ServerVersion() pg.ServerVersion {
+	return c.version
+}
+
 // Stop stops the running container, if any.
 func (c *Client) Stop(ctx context.Context) error {
 	if c.containerID == "" {
@@ -1,6 +1,10 @@
 package pgdocker
 
+// DefaultImage is the Postgres Docker image to use if no image is given.
+const DefaultImage = "postgres:13"
+
 type pgTemplate struct {
+	Image       string // base Docker image, like "postgres:15"
 	PGPass      string
 	InitScripts []string
 }
@@ -8,7 +12,7 @@ type pgTemplate struct {
 const dockerfileTemplate = `
 {{- /*gotype: github.com/leg100/pggen/internal/pgdocker.pgTemplate*/ -}}
 {{- define "dockerfile" -}}
-FROM postgres:13
+FROM {{.Image}}
 {{ range .InitScripts }}
 COPY {{.}} /docker-entrypoint-initdb.d/
 {{ end }}
@@ -0,0 +1,145 @@
+package pginfer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leg100/pggen/internal/pg"
+)
+
+// DiffTypedQueries returns a description of each difference in the inferred
+// information between want and got, like the type or nullability of an output
+// column. Returns nil if the queries infer the same information. Useful to
+// compare the same query inferred on different Postgres versions.
+//
+// Types compare by schema-qualified name, kind, and nested types, not OID,
+// since OIDs differ between databases.
+func DiffTypedQueries(want, got TypedQuery) []string {
+	var diffs []string
+	if len(want.Inputs) != len(got.Inputs) {
+		diffs = append(diffs, fmt.Sprintf("param count: %d vs %d", len(want.Inputs), len(got.Inputs)))
+	} else {
+		for i, w := range want.Inputs {
+			g := got.Inputs[i]
+			if d := diffType(w.PgType, g.PgType); d != "" {
+				diffs = append(diffs, fmt.Sprintf("param %q type: %s", w.PgName, d))
+			}
+			if w.TableColumn != g.TableColumn {
+				diffs = append(diffs, fmt.Sprintf("param %q table column: %q vs %q", w.PgName, w.TableColumn, g.TableColumn))
+			}
+		}
+	}
+	if len(want.Outputs) != len(got.Outputs) {
+		diffs = append(diffs, fmt.Sprintf("output column count: %d vs %d", len(want.Outputs), len(got.Outputs)))
+	} else {
+		for i, w := range want.Outputs {
+			g := got.Outputs[i]
+			if w.PgName != g.PgName {
+				diffs = append(diffs, fmt.Sprintf("output column %d name: %q vs %q", i, w.PgName, g.PgName))
+				continue
+			}
+			if d := diffType(w.PgType, g.PgType); d != "" {
+				diffs = append(diffs, fmt.Sprintf("output column %q type: %s", w.PgName, d))
+			}
+			if w.Nullable != g.Nullable {
+				diffs = append(diffs, fmt.Sprintf("output column %q nullable: %t vs %t", w.PgName, w.Nullable, g.Nullable))
+			}
+			if w.TableColumn != g.TableColumn {
+				diffs = append(diffs, fmt.Sprintf("output column %q table column: %q vs %q", w.PgName, w.TableColumn, g.TableColumn))
+			}
+		}
+	}
+	if want.ReadOnly != got.ReadOnly {
+		diffs = append(diffs, fmt.Sprintf("read-only: %t vs %t", want.ReadOnly, got.ReadOnly))
+	}
+	return diffs
+}
+
+// diffType returns a description of the differences between types want and
+// got, or an empty string if the types are the same. Compares nested types
+// recursively: array and range elements, composite fields, domain base types,
+// and enum labels.
+func diffType(want, got pg.Type) string {
+	wantName, gotName := typeName(want), typeName(got)
+	switch {
+	case wantName != gotName:
+		return wantName + " vs " + gotName
+	case want == nil || got == nil:
+		return ""
+	case want.Kind() != got.Kind():
+		return fmt.Sprintf("%s kind %s vs %s", wantName, want.Kind(), got.Kind())
+	}
+	var diffs []string
+	addDiff := func(format string, args ...interface{}) {
+		diffs = append(diffs, wantName+" "+fmt.Sprintf(format, args...))
+	}
+	switch w := want.(type) {
+	case pg.ArrayType:
+		if g, ok := got.(pg.ArrayType); ok {
+			if d := diffType(w.ElemType, g.ElemType); d != "" {
+				addDiff("element: %s", d)
+			}
+		}
+	case pg.EnumType:
+		if g, ok := got.(pg.EnumType); ok && !equalStrings(w.Labels, g.Labels) {
+			addDiff("labels: %q vs %q", w.Labels, g.Labels)
+		}
+	case pg.CompositeType:
+		g, ok := got.(pg.CompositeType)
+		if !ok {
+			break
+		}
+		if !equalStrings(w.ColumnNames, g.ColumnNames) || len(w.ColumnTypes) != len(g.ColumnTypes) {
+			addDiff("fields: %q vs %q", w.ColumnNames, g.ColumnNames)
+			break
+		}
+		for i, name := range w.ColumnNames {
+			if d := diffType(w.ColumnTypes[i], g.ColumnTypes[i]); d != "" {
+				addDiff("field %q: %s", name, d)
+			}
+		}
+	case pg.DomainType:
+		g, ok := got.(pg.DomainType)
+		if !ok {
+			break
+		}
+		if d := diffType(w.BaseType, g.BaseType); d != "" {
+			addDiff("base type: %s", d)
+		}
+		if w.IsNotNull != g.IsNotNull {
+			addDiff("not null: %t vs %t", w.IsNotNull, g.IsNotNull)
+		}
+	case pg.RangeType:
+		if g, ok := got.(pg.RangeType); ok {
+			if d := diffType(w.ElemType, g.ElemType); d != "" {
+				addDiff("element: %s", d)
+			}
+		}
+	case pg.MultirangeType:
+		if g, ok := got.(pg.MultirangeType); ok {
+			if d := diffType(w.RangeType, g.RangeType); d != "" {
+				addDiff("range: %s", d)
+			}
+		}
+	}
+	return strings.Join(diffs, "; ")
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func typeName(typ pg.Type) string {
+	if typ == nil {
+		return "<nil>"
+	}
+	return pg.QualifiedName(typ)
+}
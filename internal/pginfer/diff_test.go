@@ -0,0 +1,139 @@
+package pginfer
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/leg100/pggen/internal/pg"
+)
+
+func TestDiffTypedQueries(t *testing.T) {
+	status := pg.EnumType{ID: 16384, Name: "status", Schema: "billing", Labels: []string{"paid", "void"}}
+	statuses := pg.ArrayType{ID: 16385, Name: "_status", Schema: "billing", ElemType: status}
+	invoice := pg.CompositeType{
+		ID:          16386,
+		Name:        "invoice",
+		Schema:      "billing",
+		ColumnNames: []string{"id", "statuses"},
+		ColumnTypes: []pg.Type{pg.Int4, statuses},
+	}
+	email := pg.DomainType{ID: 16387, Name: "email", Schema: "public", BaseType: pg.Text}
+	base := TypedQuery{
+		Name: "FindAuthor",
+		Inputs: []InputParam{
+			{PgName: "id", PgType: pg.Int4, TableColumn: "author.id"},
+		},
+		Outputs: []OutputColumn{
+			{PgName: "id", PgType: pg.Int4, TableColumn: "author.id"},
+			{PgName: "status", PgType: status, Nullable: true},
+			{PgName: "invoice", PgType: invoice, Nullable: true},
+			{PgName: "email", PgType: email},
+		},
+		ReadOnly: true,
+	}
+	tests := []struct {
+		name   string
+		modify func(q *TypedQuery)
+		want   []string
+	}{
+		{
+			name:   "same",
+			modify: func(q *TypedQuery) {},
+			want:   nil,
+		},
+		{
+			name: "different OID",
+			modify: func(q *TypedQuery) {
+				q.Outputs[1].PgType = pg.EnumType{ID: 16390, Name: "status", Schema: "billing", Labels: []string{"paid", "void"}}
+			},
+			want: nil,
+		},
+		{
+			name: "param type",
+			modify: func(q *TypedQuery) {
+				q.Inputs[0].PgType = pg.Int8
+			},
+			want: []string{`param "id" type: int4 vs int8`},
+		},
+		{
+			name: "output nullable and table column",
+			modify: func(q *TypedQuery) {
+				q.Outputs[0].Nullable = true
+				q.Outputs[0].TableColumn = ""
+			},
+			want: []string{
+				`output column "id" nullable: false vs true`,
+				`output column "id" table column: "author.id" vs ""`,
+			},
+		},
+		{
+			name: "output count",
+			modify: func(q *TypedQuery) {
+				q.Outputs = q.Outputs[:1]
+			},
+			want: []string{"output column count: 4 vs 1"},
+		},
+		{
+			name: "enum labels",
+			modify: func(q *TypedQuery) {
+				q.Outputs[1].PgType = pg.EnumType{ID: 16384, Name: "status", Schema: "billing", Labels: []string{"paid"}}
+			},
+			want: []string{`output column "status" type: billing.status labels: ["paid" "void"] vs ["paid"]`},
+		},
+		{
+			name: "composite field type",
+			modify: func(q *TypedQuery) {
+				inv := invoice
+				inv.ColumnTypes = []pg.Type{pg.Int8, statuses}
+				q.Outputs[2].PgType = inv
+			},
+			want: []string{`output column "invoice" type: billing.invoice field "id": int4 vs int8`},
+		},
+		{
+			name: "composite fields",
+			modify: func(q *TypedQuery) {
+				inv := invoice
+				inv.ColumnNames = []string{"id"}
+				inv.ColumnTypes = []pg.Type{pg.Int4}
+				q.Outputs[2].PgType = inv
+			},
+			want: []string{`output column "invoice" type: billing.invoice fields: ["id" "statuses"] vs ["id"]`},
+		},
+		{
+			name: "nested array element",
+			modify: func(q *TypedQuery) {
+				arr := statuses
+				arr.ElemType = pg.EnumType{ID: 16384, Name: "status", Schema: "billing", Labels: []string{"void", "paid"}}
+				inv := invoice
+				inv.ColumnTypes = []pg.Type{pg.Int4, arr}
+				q.Outputs[2].PgType = inv
+			},
+			want: []string{`output column "invoice" type: billing.invoice field "statuses": billing._status element: billing.status labels: ["paid" "void"] vs ["void" "paid"]`},
+		},
+		{
+			name: "domain base type and not null",
+			modify: func(q *TypedQuery) {
+				q.Outputs[3].PgType = pg.DomainType{ID: 16387, Name: "email", Schema: "public", BaseType: pg.Varchar, IsNotNull: true}
+			},
+			want: []string{`output column "email" type: public.email base type: text vs varchar; public.email not null: false vs true`},
+		},
+		{
+			name: "read-only",
+			modify: func(q *TypedQuery) {
+				q.ReadOnly = false
+			},
+			want: []string{"read-only: true vs false"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := base
+			got.Inputs = append([]InputParam(nil), base.Inputs...)
+			got.Outputs = append([]OutputColumn(nil), base.Outputs...)
+			tt.modify(&got)
+			if diff := cmp.Diff(tt.want, DiffTypedQueries(base, got)); diff != "" {
+				t.Errorf("DiffTypedQueries() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
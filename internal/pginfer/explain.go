@@ -2,8 +2,13 @@ package pginfer
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/leg100/pggen/internal/ast"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 // PlanType is the top-level node plan type that Postgres plans for executing
@@ -21,6 +26,13 @@ type Plan struct {
 	Type     PlanType
 	Relation string   // target relation if any
 	Outputs  []string // the output expressions if any
+	// True if any node in the plan modifies a table or locks rows, meaning a
+	// ModifyTable or LockRows node.
+	Modifies bool
+	// Names of the functions the plan calls, like "nextval", without the
+	// schema. Might include names that aren't functions, like a type modifier
+	// in "numeric(10,2)".
+	Funcs []string
 }
 
 // explainQuery executes explain plan to get the node plan type and the format
@@ -71,5 +83,191 @@ func (inf *Inferrer) explainQuery(query *ast.SourceQuery) (Plan, error) {
 		Type:     PlanType(strNode),
 		Relation: relationStr,
 		Outputs:  strOuts,
+		Modifies: planModifies(plan),
+		Funcs:    planFuncs(plan),
 	}, nil
 }
+
+// planModifies returns true if the plan node or any of its children is a
+// ModifyTable or LockRows node. A ModifyTable node might be nested, like in a
+// data-modifying statement in a WITH clause.
+func planModifies(node map[string]interface{}) bool {
+	switch node["Node Type"] {
+	case string(PlanModifyTable), "LockRows":
+		return true
+	}
+	children, _ := node["Plans"].([]interface{})
+	for _, child := range children {
+		if child, ok := child.(map[string]interface{}); ok && planModifies(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// funcCallRegexp matches the name of a function call in a plan expression,
+// like "nextval" in "nextval('author_id_seq'::regclass)".
+var funcCallRegexp = regexp.MustCompile(`(?:("(?:[^"]|"")+")|\b([A-Za-z_][\w$]*))\(`)
+
+// planFuncs returns the unique names of the functions called by any
+// expression in the plan node or its children, in order of appearance.
+func planFuncs(node map[string]interface{}) []string {
+	var funcs []string
+	seen := make(map[string]struct{})
+	var walk func(val interface{})
+	walk = func(val interface{}) {
+		switch val := val.(type) {
+		case string:
+			for _, m := range funcCallRegexp.FindAllStringSubmatch(val, -1) {
+				name := m[2]
+				if m[1] != "" {
+					name = unquoteIdent(m[1])
+				}
+				if _, ok := seen[name]; !ok {
+					seen[name] = struct{}{}
+					funcs = append(funcs, name)
+				}
+			}
+		case []interface{}:
+			for _, v := range val {
+				walk(v)
+			}
+		case map[string]interface{}:
+			// Walk keys in sorted order so that the order is deterministic.
+			keys := make([]string, 0, len(val))
+			for k := range val {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(val[k])
+			}
+		}
+	}
+	walk(node)
+	return funcs
+}
+
+// inferParamColumns finds the schema-qualified table columns, like
+// "public.author.author_id", that the prepared query compares to its params,
+// keyed by the param number starting at 1. Uses a generic plan so that the plan
+// references the params instead of their values. Returns no columns if Postgres can't produce a generic plan,
+// like before Postgres 12 which doesn't support plan_cache_mode.
+func (inf *Inferrer) inferParamColumns(prepareName string, numParams int) map[int]string {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+	args := make([]string, numParams)
+	for i := range args {
+		args[i] = "NULL"
+	}
+	// The simple protocol runs both statements in a single round-trip and in
+	// one implicit transaction, so the transaction-local plan_cache_mode only
+	// applies to the EXPLAIN.
+	explainQuery := "SELECT set_config('plan_cache_mode', 'force_generic_plan', true);\n" +
+		fmt.Sprintf(`EXPLAIN (VERBOSE, FORMAT JSON) EXECUTE %s(%s)`, prepareName, strings.Join(args, ", "))
+	results, err := inf.conn.PgConn().Exec(ctx, explainQuery).ReadAll()
+	if err != nil {
+		// Not all statements can be explained, like utility statements. The
+		// param columns are optional so ignore the error.
+		return nil
+	}
+	if len(results) != 2 || len(results[1].Rows) == 0 || len(results[1].Rows[0]) == 0 {
+		return nil
+	}
+	explain := make([]map[string]map[string]interface{}, 0, 1)
+	if err := json.Unmarshal(results[1].Rows[0][0], &explain); err != nil {
+		return nil
+	}
+	if len(explain) == 0 || explain[0]["Plan"] == nil {
+		return nil
+	}
+	return planParamColumns(explain[0]["Plan"])
+}
+
+// paramCondKeys are the plan node fields with a condition that might compare a
+// table column to a query param.
+var paramCondKeys = []string{"Filter", "Index Cond", "Recheck Cond", "Join Filter"}
+
+const identPattern = `"(?:[^"]|"")+"|[A-Za-z_][\w$]*`
+
+var (
+	// colEqParamRegexp matches a condition comparing a table column to a query
+	// param, like "(author.author_id = $1)" or "((a.name)::text = $1)". The
+	// column must not be a function argument, like "lower(a.name) = $1".
+	colEqParamRegexp = regexp.MustCompile(`(?:^|[^\w$"(])\(*(` + identPattern + `)\.(` + identPattern + `)\)*(?:::[\w" ]+(?:\[\])?\)*)?\s*=\s*\$(\d+)`)
+	// paramEqColRegexp matches a condition comparing a query param to a table
+	// column, like "($1 = author.author_id)".
+	paramEqColRegexp = regexp.MustCompile(`\$(\d+)(?:::[\w" ]+(?:\[\])?)?\s*=\s*\(*(` + identPattern + `)\.(` + identPattern + `)`)
+)
+
+// planParamColumns returns the schema-qualified table columns, like
+// "public.author.author_id", that the plan compares for equality to a query
+// param, keyed by the param number starting at 1. Omits a param compared to
+// more than one column or to a table without a schema in the plan.
+func planParamColumns(node map[string]interface{}) map[int]string {
+	relations := make(map[string]string) // table alias to qualified table name
+	var conds []string
+	var walk func(node map[string]interface{})
+	walk = func(node map[string]interface{}) {
+		rel, _ := node["Relation Name"].(string)
+		// A verbose plan includes the schema of each relation.
+		if schema, _ := node["Schema"].(string); rel != "" && schema != "" {
+			alias, _ := node["Alias"].(string)
+			if alias == "" {
+				alias = rel
+			}
+			relations[alias] = schema + "." + rel
+		}
+		for _, key := range paramCondKeys {
+			if cond, ok := node[key].(string); ok {
+				conds = append(conds, cond)
+			}
+		}
+		children, _ := node["Plans"].([]interface{})
+		for _, child := range children {
+			if child, ok := child.(map[string]interface{}); ok {
+				walk(child)
+			}
+		}
+	}
+	walk(node)
+
+	cols := make(map[int]string)
+	conflicts := make(map[int]struct{})
+	addCol := func(alias, col, param string) {
+		rel, ok := relations[unquoteIdent(alias)]
+		if !ok {
+			return // not a table, like a subquery
+		}
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return
+		}
+		tableCol := rel + "." + unquoteIdent(col)
+		if prev, ok := cols[n]; ok && prev != tableCol {
+			conflicts[n] = struct{}{}
+		}
+		cols[n] = tableCol
+	}
+	for _, cond := range conds {
+		for _, m := range colEqParamRegexp.FindAllStringSubmatch(cond, -1) {
+			addCol(m[1], m[2], m[3])
+		}
+		for _, m := range paramEqColRegexp.FindAllStringSubmatch(cond, -1) {
+			addCol(m[2], m[3], m[1])
+		}
+	}
+	for n := range conflicts {
+		delete(cols, n)
+	}
+	return cols
+}
+
+// unquoteIdent removes the double quotes from a quoted identifier, like
+// "Author".
+func unquoteIdent(ident string) string {
+	if len(ident) < 2 || ident[0] != '"' {
+		return ident
+	}
+	return strings.ReplaceAll(ident[1:len(ident)-1], `""`, `"`)
+}
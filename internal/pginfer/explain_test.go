@@ -0,0 +1,145 @@
+package pginfer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPlanModifies(t *testing.T) {
+	tests := []struct {
+		name string
+		plan string
+		want bool
+	}{
+		{"select", `{"Node Type": "Seq Scan"}`, false},
+		{"insert", `{"Node Type": "ModifyTable", "Operation": "Insert"}`, true},
+		{"select for update", `{"Node Type": "LockRows", "Plans": [{"Node Type": "Seq Scan"}]}`, true},
+		{
+			"data-modifying CTE",
+			`{"Node Type": "CTE Scan", "Plans": [{"Node Type": "ModifyTable", "Operation": "Delete"}]}`,
+			true,
+		},
+		{
+			"nested select",
+			`{"Node Type": "Hash Join", "Plans": [{"Node Type": "Seq Scan"}, {"Node Type": "Hash", "Plans": [{"Node Type": "Seq Scan"}]}]}`,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := make(map[string]interface{})
+			if err := json.Unmarshal([]byte(tt.plan), &node); err != nil {
+				t.Fatal(err)
+			}
+			if got := planModifies(node); got != tt.want {
+				t.Errorf("planModifies() = %t; want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanFuncs(t *testing.T) {
+	tests := []struct {
+		name string
+		plan string
+		want []string
+	}{
+		{"no funcs", `{"Node Type": "Seq Scan", "Output": ["author.first_name"], "Filter": "(author.author_id = $1)"}`, nil},
+		{"output", `{"Node Type": "Result", "Output": ["nextval('author_id_seq'::regclass)"]}`, []string{"nextval"}},
+		{"nested call", `{"Node Type": "Result", "Output": ["lower(gen_random_uuid()::text)"]}`, []string{"lower", "gen_random_uuid"}},
+		{"qualified", `{"Node Type": "Result", "Output": ["billing.next_invoice_id()"]}`, []string{"next_invoice_id"}},
+		{"quoted", `{"Node Type": "Result", "Output": ["\"NextID\"()"]}`, []string{"NextID"}},
+		{
+			"child filter",
+			`{"Node Type": "Limit", "Plans": [{"Node Type": "Seq Scan", "Filter": "(random() < '0.5'::double precision)"}]}`,
+			[]string{"random"},
+		},
+		{
+			"function scan",
+			`{"Node Type": "Function Scan", "Function Call": "generate_series(1, 10)", "Output": ["generate_series.generate_series"]}`,
+			[]string{"generate_series"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := make(map[string]interface{})
+			if err := json.Unmarshal([]byte(tt.plan), &node); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, planFuncs(node)); diff != "" {
+				t.Errorf("planFuncs() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPlanParamColumns(t *testing.T) {
+	tests := []struct {
+		name string
+		plan string
+		want map[int]string
+	}{
+		{
+			"filter",
+			`{"Node Type": "Seq Scan", "Relation Name": "author", "Schema": "public", "Alias": "author", "Filter": "(author.first_name = $1)"}`,
+			map[int]string{1: "public.author.first_name"},
+		},
+		{
+			"index cond cast",
+			`{"Node Type": "Index Scan", "Relation Name": "author", "Schema": "public", "Alias": "a", "Index Cond": "((a.first_name)::text = $2)"}`,
+			map[int]string{2: "public.author.first_name"},
+		},
+		{
+			"param first",
+			`{"Node Type": "Seq Scan", "Relation Name": "author", "Schema": "public", "Alias": "author", "Filter": "($1 = author.author_id)"}`,
+			map[int]string{1: "public.author.author_id"},
+		},
+		{
+			"quoted identifiers",
+			`{"Node Type": "Seq Scan", "Relation Name": "Author", "Schema": "public", "Alias": "Author", "Filter": "(\"Author\".\"firstName\" = $1)"}`,
+			map[int]string{1: "public.Author.firstName"},
+		},
+		{
+			"function argument",
+			`{"Node Type": "Seq Scan", "Relation Name": "author", "Schema": "public", "Alias": "author", "Filter": "(lower(author.first_name) = $1)"}`,
+			map[int]string{},
+		},
+		{
+			"nested join",
+			`{"Node Type": "Hash Join", "Plans": [
+				{"Node Type": "Seq Scan", "Relation Name": "users", "Schema": "public", "Alias": "u", "Filter": "(u.id = $1)"},
+				{"Node Type": "Hash", "Plans": [{"Node Type": "Seq Scan", "Relation Name": "orders", "Schema": "billing", "Alias": "o", "Filter": "((o.id = $2) AND (o.status = $3))"}]}
+			]}`,
+			map[int]string{1: "public.users.id", 2: "billing.orders.id", 3: "billing.orders.status"},
+		},
+		{
+			"no schema",
+			`{"Node Type": "Seq Scan", "Relation Name": "author", "Alias": "author", "Filter": "(author.first_name = $1)"}`,
+			map[int]string{},
+		},
+		{
+			"conflicting columns",
+			`{"Node Type": "Seq Scan", "Relation Name": "users", "Schema": "public", "Alias": "users", "Filter": "((users.id = $1) OR (users.parent_id = $1))"}`,
+			map[int]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := make(map[string]interface{})
+			if err := json.Unmarshal([]byte(tt.plan), &node); err != nil {
+				t.Fatal(err)
+			}
+			got := planParamColumns(node)
+			if len(got) != len(tt.want) {
+				t.Fatalf("planParamColumns() = %v; want %v", got, tt.want)
+			}
+			for n, col := range tt.want {
+				if got[n] != col {
+					t.Errorf("planParamColumns()[%d] = %q; want %q", n, got[n], col)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package pginfer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leg100/pggen/internal/ast"
+	"github.com/leg100/pggen/internal/pgplan"
+)
+
+// KeysetPagination describes the keyset pagination for a :many query with
+// the paginate=keyset pragma.
+//
+// Keyset pagination wraps the query in a subquery that filters out rows up to
+// and including the cursor row and limits the number of rows. The last
+// len(SortColumns)+2 TypedQuery.Inputs are the pagination params, in order:
+//
+//   - a bool that's true if the cursor is set, false for the first page
+//   - the value of each sort column from the last row of the previous page
+//   - the maximum number of rows to return
+type KeysetPagination struct {
+	// The indexes into TypedQuery.Outputs of the ORDER BY columns in sort
+	// order.
+	SortColumns []int
+	// True if the ORDER BY columns sort in descending order. All columns must
+	// sort in the same direction.
+	Descending bool
+}
+
+// inferKeysetPagination finds the sort columns of query using the query plan
+// and rewrites the query to paginate by the sort columns. Returns the
+// rewritten query with extra params for the cursor and limit.
+func (inf *Inferrer) inferKeysetPagination(query *ast.SourceQuery, outputs []OutputColumn) (*ast.SourceQuery, *KeysetPagination, error) {
+	sortKeys, planOuts, err := inf.explainSortKeys(query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyset := &KeysetPagination{SortColumns: make([]int, len(sortKeys))}
+	orders := make([]string, len(sortKeys)) // like " DESC NULLS LAST"
+	tableCols := make([]string, 0, len(sortKeys))
+	for i, key := range sortKeys {
+		expr, isDesc := parseSortKey(key)
+		orders[i] = key[len(expr):]
+		if i == 0 {
+			keyset.Descending = isDesc
+		} else if isDesc != keyset.Descending {
+			return nil, nil, fmt.Errorf("keyset pagination requires all ORDER BY columns to sort in the same direction; got sort key %q", key)
+		}
+		idx := -1
+		for j, out := range planOuts {
+			if out == expr && j < len(outputs) {
+				idx = j
+				break
+			}
+		}
+		if idx == -1 {
+			return nil, nil, fmt.Errorf("keyset pagination requires ORDER BY to use output columns; sort key %q is not an output column", key)
+		}
+		for j, out := range outputs {
+			if j != idx && out.PgName == outputs[idx].PgName {
+				return nil, nil, fmt.Errorf("keyset pagination requires unique names for ORDER BY columns; column %q is ambiguous", out.PgName)
+			}
+		}
+		// The row comparison against the cursor never matches a NULL sort
+		// value, so a nullable sort column would skip rows.
+		if outputs[idx].Nullable {
+			return nil, nil, fmt.Errorf("keyset pagination requires NOT NULL ORDER BY columns; column %q is nullable", outputs[idx].PgName)
+		}
+		if outputs[idx].TableColumn != "" {
+			tableCols = append(tableCols, outputs[idx].TableColumn)
+		}
+		keyset.SortColumns[i] = idx
+	}
+
+	// The row comparison against the cursor skips rows that tie with the
+	// cursor row, so the sort columns must be unique.
+	unique, err := inf.hasUniqueKey(tableCols)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !unique {
+		return nil, nil, fmt.Errorf("keyset pagination requires ORDER BY to include every column of a primary key or unique index so that rows don't tie; got sort keys %s", strings.Join(sortKeys, ", "))
+	}
+
+	// Build the paginated query. The first page uses a cursor that's not set.
+	n := len(query.ParamNames)
+	paramNames := make([]string, 0, n+len(sortKeys)+2)
+	paramNames = append(paramNames, query.ParamNames...)
+	paramNames = append(paramNames, "cursor_set")
+	cols := make([]string, len(keyset.SortColumns))
+	params := make([]string, len(keyset.SortColumns))
+	for i, idx := range keyset.SortColumns {
+		cols[i] = quoteIdent(outputs[idx].PgName)
+		params[i] = "$" + strconv.Itoa(n+2+i)
+		paramNames = append(paramNames, "cursor_"+outputs[idx].PgName)
+	}
+	paramNames = append(paramNames, "limit")
+	cmp := ">"
+	if keyset.Descending {
+		cmp = "<"
+	}
+	orderBy := make([]string, len(cols))
+	for i, col := range cols {
+		orderBy[i] = col + orders[i]
+	}
+	sql := &strings.Builder{}
+	sql.WriteString("SELECT * FROM (\n")
+	sql.WriteString(strings.TrimSuffix(strings.TrimSpace(query.PreparedSQL), ";"))
+	sql.WriteString("\n) pggen_keyset\n")
+	fmt.Fprintf(sql, "WHERE $%d::bool IS NOT TRUE OR (%s) %s (%s)\n",
+		n+1, strings.Join(cols, ", "), cmp, strings.Join(params, ", "))
+	sql.WriteString("ORDER BY " + strings.Join(orderBy, ", ") + "\n")
+	fmt.Fprintf(sql, "LIMIT $%d;", n+len(sortKeys)+2)
+
+	pageQuery := *query
+	pageQuery.PreparedSQL = sql.String()
+	pageQuery.ParamNames = paramNames
+	return &pageQuery, keyset, nil
+}
+
+// hasUniqueKey returns true if a table has a primary key or unique index,
+// without an expression or predicate, whose columns are all in tableCols.
+// Each table column is schema-qualified, like "public.author.author_id".
+func (inf *Inferrer) hasUniqueKey(tableCols []string) (bool, error) {
+	if len(tableCols) == 0 {
+		return false, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+	defer inf.stats.Record(time.Now())
+	row := inf.conn.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT FROM pg_index idx
+				JOIN pg_class cls ON cls.oid = idx.indrelid
+				JOIN pg_namespace ns ON ns.oid = cls.relnamespace
+			WHERE idx.indisunique
+				AND idx.indexprs IS NULL
+				AND idx.indpred IS NULL
+				AND NOT EXISTS (
+					SELECT FROM unnest(idx.indkey::int2[]) key (attnum)
+						JOIN pg_attribute attr ON attr.attrelid = idx.indrelid AND attr.attnum = key.attnum
+					WHERE ns.nspname || '.' || cls.relname || '.' || attr.attname <> ALL ($1::text[])
+				)
+		)`, tableCols)
+	unique := false
+	if err := row.Scan(&unique); err != nil {
+		return false, fmt.Errorf("find unique key for sort columns: %w", err)
+	}
+	return unique, nil
+}
+
+// explainSortKeys returns the sort keys of the Sort node that orders the
+// output of query, and the output expressions of the top-level plan node.
+func (inf *Inferrer) explainSortKeys(query *ast.SourceQuery) (keys []string, outs []string, mErr error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+	tx, err := inf.conn.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin explain sort keys tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	// Disable index scans so that Postgres orders rows with a Sort node instead
+	// of relying on the order of an index.
+	if _, err := tx.Exec(ctx, "SET LOCAL enable_indexscan = off; SET LOCAL enable_indexonlyscan = off"); err != nil {
+		return nil, nil, fmt.Errorf("disable index scans: %w", err)
+	}
+	plan, err := pgplan.ExplainQuery(tx, query.PreparedSQL, createParamArgs(query)...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("explain sort keys: %w", err)
+	}
+
+	// Walk down through nodes that preserve the order of their child.
+	node := plan
+	for {
+		switch n := node.(type) {
+		case pgplan.Sort:
+			return n.SortKey, plan.Output(), nil
+		case pgplan.MergeAppend:
+			return n.SortKey, plan.Output(), nil
+		case pgplan.Limit:
+			return nil, nil, fmt.Errorf("keyset pagination adds a LIMIT clause; remove the LIMIT clause from query %s", query.Name)
+		case pgplan.Result, pgplan.LockRows, pgplan.Unique:
+			if len(n.Children()) == 0 {
+				return nil, nil, fmt.Errorf("keyset pagination requires an ORDER BY clause; no sort in query plan for %s", query.Name)
+			}
+			node = n.Children()[0]
+		default:
+			return nil, nil, fmt.Errorf("keyset pagination requires an ORDER BY clause; no sort in query plan for %s", query.Name)
+		}
+	}
+}
+
+// parseSortKey splits a sort key from a Sort node, like "author.id DESC", into
+// the sort expression and whether the expression sorts in descending order.
+func parseSortKey(key string) (string, bool) {
+	expr := strings.TrimSuffix(strings.TrimSuffix(key, " NULLS FIRST"), " NULLS LAST")
+	if strings.HasSuffix(expr, " DESC") {
+		return strings.TrimSuffix(expr, " DESC"), true
+	}
+	return expr, false
+}
+
+// quoteIdent quotes a Postgres identifier.
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
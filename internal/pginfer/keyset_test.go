@@ -0,0 +1,213 @@
+package pginfer
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/leg100/pggen/internal/ast"
+	"github.com/leg100/pggen/internal/pg"
+	"github.com/leg100/pggen/internal/pgtest"
+	"github.com/leg100/pggen/internal/texts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInferrer_InferTypes_Keyset(t *testing.T) {
+	conn, cleanupFunc := pgtest.NewPostgresSchemaString(t, texts.Dedent(`
+		CREATE TABLE author (
+			author_id  serial PRIMARY KEY,
+			first_name text NOT NULL,
+			last_name  text NOT NULL
+		);
+	`))
+	defer cleanupFunc()
+
+	tests := []struct {
+		query *ast.SourceQuery
+		want  TypedQuery
+	}{
+		{
+			&ast.SourceQuery{
+				Name:        "FindAuthorsPage",
+				PreparedSQL: "SELECT author_id, first_name FROM author WHERE first_name = $1 ORDER BY author_id;",
+				ParamNames:  []string{"FirstName"},
+				ResultKind:  ast.ResultKindMany,
+				Pragmas:     ast.Pragmas{Paginate: ast.PaginateKeyset},
+			},
+			TypedQuery{
+				Name:       "FindAuthorsPage",
+				ResultKind: ast.ResultKindMany,
+				PreparedSQL: texts.Dedent(`
+					SELECT * FROM (
+					SELECT author_id, first_name FROM author WHERE first_name = $1 ORDER BY author_id
+					) pggen_keyset
+					WHERE $2::bool IS NOT TRUE OR ("author_id") > ($3)
+					ORDER BY "author_id"
+					LIMIT $4;`),
+				Inputs: []InputParam{
+					{PgName: "FirstName", PgType: pg.Text, TableColumn: "author.first_name"},
+					{PgName: "cursor_set", PgType: pg.Bool},
+					{PgName: "cursor_author_id", PgType: pg.Int4},
+					{PgName: "limit", PgType: pg.Int8},
+				},
+				Outputs: []OutputColumn{
+					{PgName: "author_id", PgType: pg.Int4, Nullable: false, TableColumn: "author.author_id"},
+					{PgName: "first_name", PgType: pg.Text, Nullable: false, TableColumn: "author.first_name"},
+				},
+				Keyset:   &KeysetPagination{SortColumns: []int{0}},
+				ReadOnly: true,
+			},
+		},
+		{
+			&ast.SourceQuery{
+				Name:        "FindAuthorsByNameDesc",
+				PreparedSQL: "SELECT author_id, last_name FROM author ORDER BY last_name DESC, author_id DESC;",
+				ResultKind:  ast.ResultKindMany,
+				Pragmas:     ast.Pragmas{Paginate: ast.PaginateKeyset},
+			},
+			TypedQuery{
+				Name:       "FindAuthorsByNameDesc",
+				ResultKind: ast.ResultKindMany,
+				PreparedSQL: texts.Dedent(`
+					SELECT * FROM (
+					SELECT author_id, last_name FROM author ORDER BY last_name DESC, author_id DESC
+					) pggen_keyset
+					WHERE $1::bool IS NOT TRUE OR ("last_name", "author_id") < ($2, $3)
+					ORDER BY "last_name" DESC, "author_id" DESC
+					LIMIT $4;`),
+				Inputs: []InputParam{
+					{PgName: "cursor_set", PgType: pg.Bool},
+					{PgName: "cursor_last_name", PgType: pg.Text},
+					{PgName: "cursor_author_id", PgType: pg.Int4},
+					{PgName: "limit", PgType: pg.Int8},
+				},
+				Outputs: []OutputColumn{
+					{PgName: "author_id", PgType: pg.Int4, Nullable: false, TableColumn: "author.author_id"},
+					{PgName: "last_name", PgType: pg.Text, Nullable: false, TableColumn: "author.last_name"},
+				},
+				Keyset:   &KeysetPagination{SortColumns: []int{1, 0}, Descending: true},
+				ReadOnly: true,
+			},
+		},
+		{
+			&ast.SourceQuery{
+				Name:        "FindAuthorsNullsFirst",
+				PreparedSQL: "SELECT author_id FROM author ORDER BY author_id NULLS FIRST;",
+				ResultKind:  ast.ResultKindMany,
+				Pragmas:     ast.Pragmas{Paginate: ast.PaginateKeyset},
+			},
+			TypedQuery{
+				Name:       "FindAuthorsNullsFirst",
+				ResultKind: ast.ResultKindMany,
+				PreparedSQL: texts.Dedent(`
+					SELECT * FROM (
+					SELECT author_id FROM author ORDER BY author_id NULLS FIRST
+					) pggen_keyset
+					WHERE $1::bool IS NOT TRUE OR ("author_id") > ($2)
+					ORDER BY "author_id" NULLS FIRST
+					LIMIT $3;`),
+				Inputs: []InputParam{
+					{PgName: "cursor_set", PgType: pg.Bool},
+					{PgName: "cursor_author_id", PgType: pg.Int4},
+					{PgName: "limit", PgType: pg.Int8},
+				},
+				Outputs: []OutputColumn{
+					{PgName: "author_id", PgType: pg.Int4, Nullable: false, TableColumn: "author.author_id"},
+				},
+				Keyset:   &KeysetPagination{SortColumns: []int{0}},
+				ReadOnly: true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query.Name, func(t *testing.T) {
+			inferrer := NewInferrer(conn)
+			got, err := inferrer.InferTypes(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = trimTableColumnSchema(t, conn, got)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestInferrer_InferTypes_KeysetError(t *testing.T) {
+	conn, cleanupFunc := pgtest.NewPostgresSchemaString(t, texts.Dedent(`
+		CREATE TABLE author (
+			author_id  serial PRIMARY KEY,
+			first_name text NOT NULL,
+			suffix     text
+		);
+	`))
+	defer cleanupFunc()
+
+	tests := []*ast.SourceQuery{
+		{
+			Name:        "NoOrderBy",
+			PreparedSQL: "SELECT author_id FROM author;",
+			ResultKind:  ast.ResultKindMany,
+			Pragmas:     ast.Pragmas{Paginate: ast.PaginateKeyset},
+		},
+		{
+			Name:        "OrderByNonOutput",
+			PreparedSQL: "SELECT author_id FROM author ORDER BY first_name;",
+			ResultKind:  ast.ResultKindMany,
+			Pragmas:     ast.Pragmas{Paginate: ast.PaginateKeyset},
+		},
+		{
+			Name:        "MixedDirection",
+			PreparedSQL: "SELECT author_id, first_name FROM author ORDER BY first_name, author_id DESC;",
+			ResultKind:  ast.ResultKindMany,
+			Pragmas:     ast.Pragmas{Paginate: ast.PaginateKeyset},
+		},
+		{
+			Name:        "NullableSortColumn",
+			PreparedSQL: "SELECT author_id, suffix FROM author ORDER BY suffix, author_id;",
+			ResultKind:  ast.ResultKindMany,
+			Pragmas:     ast.Pragmas{Paginate: ast.PaginateKeyset},
+		},
+		{
+			Name:        "NotUnique",
+			PreparedSQL: "SELECT author_id, first_name FROM author ORDER BY first_name;",
+			ResultKind:  ast.ResultKindMany,
+			Pragmas:     ast.Pragmas{Paginate: ast.PaginateKeyset},
+		},
+		{
+			Name:        "HasLimit",
+			PreparedSQL: "SELECT author_id FROM author ORDER BY author_id LIMIT 5;",
+			ResultKind:  ast.ResultKindMany,
+			Pragmas:     ast.Pragmas{Paginate: ast.PaginateKeyset},
+		},
+	}
+	for _, query := range tests {
+		t.Run(query.Name, func(t *testing.T) {
+			inferrer := NewInferrer(conn)
+			got, err := inferrer.InferTypes(query)
+			assert.Error(t, err)
+			assert.Equal(t, TypedQuery{}, got, "InferTypes should error and return empty TypedQuery struct")
+		})
+	}
+}
+
+func TestParseSortKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		wantExpr string
+		wantDesc bool
+	}{
+		{"author.author_id", "author.author_id", false},
+		{"author.author_id DESC", "author.author_id", true},
+		{"author.author_id NULLS FIRST", "author.author_id", false},
+		{"author.author_id DESC NULLS LAST", "author.author_id", true},
+		{"(lower(author.first_name))", "(lower(author.first_name))", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			expr, isDesc := parseSortKey(tt.key)
+			assert.Equal(t, tt.wantExpr, expr)
+			assert.Equal(t, tt.wantDesc, isDesc)
+		})
+	}
+}
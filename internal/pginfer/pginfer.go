@@ -38,6 +38,21 @@ type TypedQuery struct {
 	// Qualified protocol buffer message type to use for each output row, like
 	// "erp.api.Product". If empty, generate our own Row type.
 	ProtobufType string
+	// Keyset pagination for the query from the paginate=keyset pragma. Nil if
+	// the query isn't paginated.
+	Keyset *KeysetPagination
+	// True if the query plan doesn't modify a table or lock rows. False if
+	// Postgres can't explain the query, like for DDL statements.
+	ReadOnly bool
+	// Where a routing querier runs the query, from the route pragma.
+	Route ast.RouteKind
+	// Name of the Go struct for output rows, from the row-type pragma. Queries
+	// with the same row type share the struct. If empty, generate our own Row
+	// type.
+	RowType string
+	// Fully qualified Go types for json and jsonb params and output columns by
+	// name, from the json-type pragma.
+	JSONTypes map[string]string
 }
 
 // InputParam is an input parameter for a prepared query.
@@ -49,6 +64,10 @@ type InputParam struct {
 	DefaultVal string
 	// The postgres type of this param as reported by Postgres.
 	PgType pg.Type
+	// The schema-qualified table column, like "public.author.first_name", the
+	// query compares the param to, if Postgres reports one in the query plan.
+	// Empty otherwise.
+	TableColumn string
 }
 
 // OutputColumn is a single column output from a select query or returning
@@ -62,22 +81,40 @@ type OutputColumn struct {
 	// with a NOT NULL constraint can still be null in the output with a left
 	// join. Nullability is determined using rudimentary control-flow analysis.
 	Nullable bool
+	// The schema-qualified table column, like "public.author.first_name", the
+	// output column comes from, if Postgres reports one. Empty for computed
+	// columns.
+	TableColumn string
 }
 
 type Inferrer struct {
-	conn        *pgx.Conn
-	typeFetcher *pg.TypeFetcher
+	conn    *pgx.Conn
+	catalog *pg.Catalog
+	stats   pg.FetchStats // stats for catalog queries not sent by the catalog
 }
 
 // NewInferrer infers information about a query by running the query on
 // Postgres and extracting information from the catalog tables.
 func NewInferrer(conn *pgx.Conn) *Inferrer {
 	return &Inferrer{
-		conn:        conn,
-		typeFetcher: pg.NewTypeFetcher(conn),
+		conn:    conn,
+		catalog: pg.NewCatalog(conn),
 	}
 }
 
+// Catalog returns the cached Postgres catalog for the connection of the
+// Inferrer. Call Invalidate on the catalog after changing the schema.
+func (inf *Inferrer) Catalog() *pg.Catalog {
+	return inf.catalog
+}
+
+// CatalogStats returns the stats for all catalog queries sent to infer
+// queries, like the queries for the param types of a prepared statement, the
+// table columns, and the Postgres types.
+func (inf *Inferrer) CatalogStats() pg.FetchStats {
+	return inf.catalog.Stats().Add(inf.stats)
+}
+
 func (inf *Inferrer) InferTypes(query *ast.SourceQuery) (TypedQuery, error) {
 	inputs, err := inf.inferInputTypes(query)
 	if err != nil {
@@ -99,18 +136,69 @@ func (inf *Inferrer) InferTypes(query *ast.SourceQuery) (TypedQuery, error) {
 				"use :exec if query shouldn't return any columns",
 			query.Name, query.ResultKind)
 	}
+	preparedSQL := query.PreparedSQL
+	var keyset *KeysetPagination
+	if query.Pragmas.Paginate == ast.PaginateKeyset {
+		pageQuery, ks, err := inf.inferKeysetPagination(query, outputs)
+		if err != nil {
+			return TypedQuery{}, fmt.Errorf("infer keyset pagination for query %s: %w", query.Name, err)
+		}
+		inputs, err = inf.inferInputTypes(pageQuery)
+		if err != nil {
+			return TypedQuery{}, fmt.Errorf("infer input types for paginated query: %w", err)
+		}
+		preparedSQL = pageQuery.PreparedSQL
+		keyset = ks
+	}
+	readOnly := inf.inferReadOnly(query)
 	doc := extractDoc(query)
 	return TypedQuery{
 		Name:         query.Name,
 		ResultKind:   query.ResultKind,
 		Doc:          doc,
-		PreparedSQL:  query.PreparedSQL,
+		PreparedSQL:  preparedSQL,
 		Inputs:       inputs,
 		Outputs:      outputs,
 		ProtobufType: query.Pragmas.ProtobufType,
+		Keyset:       keyset,
+		ReadOnly:     readOnly,
+		Route:        query.Pragmas.Route,
+		RowType:      query.Pragmas.RowType,
+		JSONTypes:    query.Pragmas.JSONTypes,
 	}, nil
 }
 
+// inferReadOnly returns true if the query plan doesn't modify a table or lock
+// rows and doesn't call a volatile function, like nextval, which might write
+// to the database. Assumes the query isn't read-only if Postgres can't explain
+// it.
+func (inf *Inferrer) inferReadOnly(query *ast.SourceQuery) bool {
+	plan, err := inf.explainQuery(query)
+	if err != nil || plan.Modifies {
+		return false
+	}
+	volatile, err := inf.hasVolatileFunc(plan.Funcs)
+	return err == nil && !volatile
+}
+
+// hasVolatileFunc returns true if any function with one of the names is
+// volatile. Matches functions by name in any schema, so an overload or a
+// function with the same name in another schema counts too.
+func (inf *Inferrer) hasVolatileFunc(names []string) (bool, error) {
+	if len(names) == 0 {
+		return false, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+	defer inf.stats.Record(time.Now())
+	row := inf.conn.QueryRow(ctx, `SELECT EXISTS (SELECT FROM pg_proc WHERE proname = ANY ($1::text[]) AND provolatile = 'v')`, names)
+	volatile := false
+	if err := row.Scan(&volatile); err != nil {
+		return false, fmt.Errorf("find volatile functions: %w", err)
+	}
+	return volatile, nil
+}
+
 func (inf *Inferrer) inferInputTypes(query *ast.SourceQuery) (ps []InputParam, mErr error) {
 	if len(query.ParamNames) == 0 {
 		return nil, nil
@@ -131,28 +219,35 @@ func (inf *Inferrer) inferInputTypes(query *ast.SourceQuery) (ps []InputParam, m
 	ctx, cancel = context.WithTimeout(context.Background(), defaultTimeout)
 	defer cancel()
 	catalogQuery := `SELECT parameter_types::int[] FROM pg_prepared_statements WHERE lower(name) = lower($1)`
+	start := time.Now()
 	row := inf.conn.QueryRow(ctx, catalogQuery, prepareName)
 	oids := make([]uint32, 0, len(query.ParamNames))
-	if err := row.Scan(&oids); err != nil {
+	err = row.Scan(&oids)
+	inf.stats.Record(start)
+	if err != nil {
 		return nil, fmt.Errorf("scan prepared parameter types: %w", err)
 	}
 	if len(oids) != len(query.ParamNames) {
 		return nil, fmt.Errorf("expected %d parameter types for query; got %d",
 			len(query.ParamNames), len(oids))
 	}
-	types, err := inf.typeFetcher.FindTypesByOIDs(oids...)
+	types, err := inf.catalog.FindTypesByOIDs(oids...)
 	if err != nil {
 		return nil, fmt.Errorf("fetch oid types: %w", err)
 	}
 
+	// Find the table columns compared to the params.
+	paramCols := inf.inferParamColumns(prepareName, len(oids))
+
 	// Build up the input params.
 	params := make([]InputParam, len(query.ParamNames))
 	for i := 0; i < len(params); i++ {
 		pgType := types[pgtype.OID(oids[i])]
 		params[i] = InputParam{
-			PgName:     query.ParamNames[i],
-			DefaultVal: "",
-			PgType:     pgType,
+			PgName:      query.ParamNames[i],
+			DefaultVal:  "",
+			PgType:      pgType,
+			TableColumn: paramCols[i+1],
 		}
 	}
 	return params, nil
@@ -224,17 +319,33 @@ func (inf *Inferrer) inferOutputTypes(query *ast.SourceQuery) ([]OutputColumn, e
 	for i, desc := range descriptions {
 		oids[i] = desc.DataTypeOID
 	}
-	types, err := inf.typeFetcher.FindTypesByOIDs(oids...)
+	types, err := inf.catalog.FindTypesByOIDs(oids...)
 	if err != nil {
 		return nil, fmt.Errorf("fetch oid types: %w", err)
 	}
 
+	// Table columns of the output columns, used for nullability, domain types,
+	// and table column names.
+	cols, err := inf.fetchOutputColumns(descriptions)
+	if err != nil {
+		return nil, fmt.Errorf("fetch output table columns: %w", err)
+	}
+
 	// Output nullability.
-	nullables, err := inf.inferOutputNullability(query, descriptions)
+	nullables, err := inf.inferOutputNullability(query, cols)
 	if err != nil {
 		return nil, fmt.Errorf("infer output type nullability: %w", err)
 	}
 
+	// Output domain types.
+	domains, err := inf.findOutputDomains(descriptions, cols)
+	if err != nil {
+		return nil, fmt.Errorf("find output domain types: %w", err)
+	}
+
+	// Output table columns.
+	tableCols := findOutputTableColumns(cols)
+
 	// Create output columns
 	var outs []OutputColumn
 	for i, desc := range descriptions {
@@ -242,27 +353,24 @@ func (inf *Inferrer) inferOutputTypes(query *ast.SourceQuery) ([]OutputColumn, e
 		if !ok {
 			return nil, fmt.Errorf("no type name found for oid %d", desc.DataTypeOID)
 		}
+		if domains != nil && domains[i] != nil {
+			pgType = domains[i]
+		}
 
 		outs = append(outs, OutputColumn{
-			PgName:   string(desc.Name),
-			PgType:   pgType,
-			Nullable: nullables[i],
+			PgName:      string(desc.Name),
+			PgType:      pgType,
+			Nullable:    nullables[i],
+			TableColumn: tableCols[i],
 		})
 	}
 	return outs, nil
 }
 
-// inferOutputNullability infers which of the output columns produced by the
-// query and described by descs can be null.
-func (inf *Inferrer) inferOutputNullability(query *ast.SourceQuery, descs []pgproto3.FieldDescription) ([]bool, error) {
-	if len(descs) == 0 {
-		return nil, nil
-	}
-	plan, err := inf.explainQuery(query)
-	if err != nil {
-		return nil, err
-	}
-
+// fetchOutputColumns fetches the table column of each output column described
+// by descs in a single catalog query. The nth entry is empty if the output
+// column described by descs[n] doesn't come directly from a table.
+func (inf *Inferrer) fetchOutputColumns(descs []pgproto3.FieldDescription) ([]pg.Column, error) {
 	columnKeys := make([]pg.ColumnKey, len(descs))
 	for i, desc := range descs {
 		if desc.TableOID > 0 {
@@ -272,15 +380,24 @@ func (inf *Inferrer) inferOutputNullability(query *ast.SourceQuery, descs []pgpr
 			}
 		}
 	}
-	cols, err := pg.FetchColumns(inf.conn, columnKeys)
+	return inf.catalog.FetchColumns(columnKeys)
+}
+
+// inferOutputNullability infers which of the output columns produced by the
+// query with the table columns cols can be null.
+func (inf *Inferrer) inferOutputNullability(query *ast.SourceQuery, cols []pg.Column) ([]bool, error) {
+	if len(cols) == 0 {
+		return nil, nil
+	}
+	plan, err := inf.explainQuery(query)
 	if err != nil {
-		return nil, fmt.Errorf("fetch column for nullability: %w", err)
+		return nil, err
 	}
 
-	// The nth entry determines if the output column described by descs[n] is
-	// nullable. plan.Outputs might contain more entries than cols because the
-	// plan output also contains information like sort columns.
-	nullables := make([]bool, len(descs))
+	// The nth entry determines if the nth output column is nullable.
+	// plan.Outputs might contain more entries than cols because the plan output
+	// also contains information like sort columns.
+	nullables := make([]bool, len(cols))
 	for i := range nullables {
 		nullables[i] = true // assume nullable until proven otherwise
 	}
@@ -295,6 +412,50 @@ func (inf *Inferrer) inferOutputNullability(query *ast.SourceQuery, descs []pgpr
 	return nullables, nil
 }
 
+// findOutputDomains finds the domain type of each output column that comes
+// directly from a table column with a domain type. Postgres describes output
+// columns using the base type of a domain, so use the type of the table column
+// to recover the domain. The nth entry is nil if the output column described
+// by descs[n] isn't a domain. Returns nil if no output column is a domain.
+// cols are the table columns of the output columns from fetchOutputColumns.
+func (inf *Inferrer) findOutputDomains(descs []pgproto3.FieldDescription, cols []pg.Column) ([]pg.Type, error) {
+	oids := make([]uint32, 0, len(cols))
+	for i, col := range cols {
+		if col.TypeOID != 0 && uint32(col.TypeOID) != descs[i].DataTypeOID {
+			oids = append(oids, uint32(col.TypeOID))
+		}
+	}
+	if len(oids) == 0 {
+		return nil, nil
+	}
+	types, err := inf.catalog.FindTypesByOIDs(oids...)
+	if err != nil {
+		return nil, fmt.Errorf("fetch column oid types: %w", err)
+	}
+	domains := make([]pg.Type, len(descs))
+	for i, col := range cols {
+		domain, ok := types[col.TypeOID].(pg.DomainType)
+		if ok && uint32(domain.BaseType.OID()) == descs[i].DataTypeOID {
+			domains[i] = domain
+		}
+	}
+	return domains, nil
+}
+
+// findOutputTableColumns finds the schema-qualified table column, like
+// "public.author.first_name", of each output column that comes directly from a
+// table column, using the table columns cols from fetchOutputColumns. The nth
+// entry is empty if the nth output column doesn't come from a table.
+func findOutputTableColumns(cols []pg.Column) []string {
+	tableCols := make([]string, len(cols))
+	for i, col := range cols {
+		if col.Schema != "" && col.TableName != "" && col.Name != "" {
+			tableCols[i] = col.Schema + "." + col.TableName + "." + col.Name
+		}
+	}
+	return tableCols
+}
+
 func createParamArgs(query *ast.SourceQuery) []interface{} {
 	args := make([]interface{}, len(query.ParamNames))
 	for i := range query.ParamNames {
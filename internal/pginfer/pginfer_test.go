@@ -5,12 +5,14 @@ import (
 	"errors"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jackc/pgx/v4"
 	"github.com/leg100/pggen/internal/ast"
 	"github.com/leg100/pggen/internal/pg"
 	"github.com/leg100/pggen/internal/pgtest"
 	"github.com/leg100/pggen/internal/texts"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"strings"
 	"testing"
 )
 
@@ -29,6 +31,9 @@ func TestInferrer_InferTypes(t *testing.T) {
 		);
 
 		CREATE DOMAIN us_postal_code AS TEXT;
+
+		CREATE DOMAIN us_zip AS text NOT NULL CHECK (VALUE <> '');
+		CREATE TABLE shipment (zip us_zip);
 	`))
 	defer cleanupFunc()
 	q := pg.NewQuerier(conn)
@@ -36,6 +41,8 @@ func TestInferrer_InferTypes(t *testing.T) {
 	require.NoError(t, err)
 	deviceTypeArrOID, err := q.FindOIDByName(context.Background(), "_device_type")
 	require.NoError(t, err)
+	usZipOID, err := q.FindOIDByName(context.Background(), "us_zip")
+	require.NoError(t, err)
 
 	tests := []struct {
 		query *ast.SourceQuery
@@ -55,6 +62,23 @@ func TestInferrer_InferTypes(t *testing.T) {
 					{PgName: "one", PgType: pg.Int4, Nullable: false},
 					{PgName: "two", PgType: pg.Text, Nullable: false},
 				},
+				ReadOnly: true,
+			},
+		},
+		{
+			&ast.SourceQuery{
+				Name:        "NextAuthorID",
+				PreparedSQL: "SELECT nextval('author_author_id_seq')",
+				ResultKind:  ast.ResultKindOne,
+			},
+			TypedQuery{
+				Name:        "NextAuthorID",
+				ResultKind:  ast.ResultKindOne,
+				PreparedSQL: "SELECT nextval('author_author_id_seq')",
+				Outputs: []OutputColumn{
+					{PgName: "nextval", PgType: pg.Int8, Nullable: false},
+				},
+				ReadOnly: false, // nextval is volatile
 			},
 		},
 		{
@@ -70,6 +94,7 @@ func TestInferrer_InferTypes(t *testing.T) {
 				Outputs: []OutputColumn{
 					{PgName: "num", PgType: pg.Int4, Nullable: true},
 				},
+				ReadOnly: true,
 			},
 		},
 		{
@@ -87,6 +112,32 @@ func TestInferrer_InferTypes(t *testing.T) {
 					PgType:   pg.Text,
 					Nullable: false,
 				}},
+				ReadOnly: true,
+			},
+		},
+		{
+			&ast.SourceQuery{
+				Name:        "DomainColumn",
+				PreparedSQL: "SELECT zip FROM shipment",
+				ResultKind:  ast.ResultKindMany,
+			},
+			TypedQuery{
+				Name:        "DomainColumn",
+				ResultKind:  ast.ResultKindMany,
+				PreparedSQL: "SELECT zip FROM shipment",
+				Outputs: []OutputColumn{{
+					PgName: "zip",
+					PgType: pg.DomainType{
+						ID:         usZipOID,
+						Name:       "us_zip",
+						IsNotNull:  true,
+						BaseType:   pg.Text,
+						CheckExprs: []string{"CHECK ((VALUE <> ''::text))"},
+					},
+					Nullable:    false,
+					TableColumn: "shipment.zip",
+				}},
+				ReadOnly: true,
 			},
 		},
 		{
@@ -123,6 +174,7 @@ func TestInferrer_InferTypes(t *testing.T) {
 						Nullable: true,
 					},
 				},
+				ReadOnly: true,
 			},
 		},
 		{
@@ -139,11 +191,12 @@ func TestInferrer_InferTypes(t *testing.T) {
 				Doc:         []string{"Hello"},
 				PreparedSQL: "SELECT first_name FROM author WHERE first_name = $1;",
 				Inputs: []InputParam{
-					{PgName: "FirstName", PgType: pg.Text},
+					{PgName: "FirstName", PgType: pg.Text, TableColumn: "author.first_name"},
 				},
 				Outputs: []OutputColumn{
-					{PgName: "first_name", PgType: pg.Text, Nullable: false},
+					{PgName: "first_name", PgType: pg.Text, Nullable: false, TableColumn: "author.first_name"},
 				},
+				ReadOnly: true,
 			},
 		},
 		{
@@ -160,11 +213,12 @@ func TestInferrer_InferTypes(t *testing.T) {
 				Doc:         []string{"Hello"},
 				PreparedSQL: "SELECT a1.first_name FROM author a1 JOIN author a2 USING (author_id) WHERE a1.first_name = $1;",
 				Inputs: []InputParam{
-					{PgName: "FirstName", PgType: pg.Text},
+					{PgName: "FirstName", PgType: pg.Text, TableColumn: "author.first_name"},
 				},
 				Outputs: []OutputColumn{
-					{PgName: "first_name", PgType: pg.Text, Nullable: true},
+					{PgName: "first_name", PgType: pg.Text, Nullable: true, TableColumn: "author.first_name"},
 				},
+				ReadOnly: true,
 			},
 		},
 		{
@@ -181,7 +235,7 @@ func TestInferrer_InferTypes(t *testing.T) {
 				Doc:         []string{"One", "- two"},
 				PreparedSQL: "DELETE FROM author WHERE author_id = $1;",
 				Inputs: []InputParam{
-					{PgName: "AuthorID", PgType: pg.Int4},
+					{PgName: "AuthorID", PgType: pg.Int4, TableColumn: "author.author_id"},
 				},
 				Outputs: nil,
 			},
@@ -198,11 +252,11 @@ func TestInferrer_InferTypes(t *testing.T) {
 				ResultKind:  ast.ResultKindMany,
 				PreparedSQL: "DELETE FROM author WHERE author_id = $1 RETURNING author_id, first_name;",
 				Inputs: []InputParam{
-					{PgName: "AuthorID", PgType: pg.Int4},
+					{PgName: "AuthorID", PgType: pg.Int4, TableColumn: "author.author_id"},
 				},
 				Outputs: []OutputColumn{
-					{PgName: "author_id", PgType: pg.Int4, Nullable: false},
-					{PgName: "first_name", PgType: pg.Text, Nullable: false},
+					{PgName: "author_id", PgType: pg.Int4, Nullable: false, TableColumn: "author.author_id"},
+					{PgName: "first_name", PgType: pg.Text, Nullable: false, TableColumn: "author.first_name"},
 				},
 			},
 		},
@@ -221,6 +275,7 @@ func TestInferrer_InferTypes(t *testing.T) {
 				Outputs: []OutputColumn{
 					{PgName: "void", PgType: pg.Void, Nullable: false},
 				},
+				ReadOnly: true,
 			},
 		},
 		{
@@ -239,6 +294,7 @@ func TestInferrer_InferTypes(t *testing.T) {
 					{PgName: "foo", PgType: pg.Text, Nullable: false},
 					{PgName: "void", PgType: pg.Void, Nullable: false},
 				},
+				ReadOnly: true,
 			},
 		},
 		{
@@ -257,6 +313,7 @@ func TestInferrer_InferTypes(t *testing.T) {
 					{PgName: "two", PgType: pg.Text, Nullable: false},
 				},
 				ProtobufType: "foo.Bar",
+				ReadOnly:     true,
 			},
 		},
 	}
@@ -267,8 +324,12 @@ func TestInferrer_InferTypes(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
+			got = trimTableColumnSchema(t, conn, got)
+			// Ignore the schema because the test uses a randomly named schema.
 			opts := cmp.Options{
-				cmpopts.IgnoreFields(pg.EnumType{}, "ChildOIDs"),
+				cmpopts.IgnoreFields(pg.EnumType{}, "ChildOIDs", "Schema"),
+				cmpopts.IgnoreFields(pg.ArrayType{}, "Schema"),
+				cmpopts.IgnoreFields(pg.DomainType{}, "Schema"),
 			}
 			if diff := cmp.Diff(tt.want, got, opts); diff != "" {
 				t.Errorf("mismatch (-want +got):\n%s", diff)
@@ -340,3 +401,30 @@ func newCommentGroup(lines ...string) *ast.CommentGroup {
 	}
 	return &ast.CommentGroup{List: cs}
 }
+
+// trimTableColumnSchema removes the randomly named test schema from the table
+// columns of the query, like "author.first_name" for
+// "pggen_test_123.author.first_name".
+func trimTableColumnSchema(t *testing.T, conn *pgx.Conn, query TypedQuery) TypedQuery {
+	t.Helper()
+	var schema string
+	if err := conn.QueryRow(context.Background(), "SELECT current_schema()").Scan(&schema); err != nil {
+		t.Fatal(err)
+	}
+	trim := func(col string) string {
+		if col == "" {
+			return ""
+		}
+		if !strings.HasPrefix(col, schema+".") {
+			t.Errorf("table column %q isn't qualified with test schema %q", col, schema)
+		}
+		return strings.TrimPrefix(col, schema+".")
+	}
+	for i := range query.Inputs {
+		query.Inputs[i].TableColumn = trim(query.Inputs[i].TableColumn)
+	}
+	for i := range query.Outputs {
+		query.Outputs[i].TableColumn = trim(query.Outputs[i].TableColumn)
+	}
+	return query
+}
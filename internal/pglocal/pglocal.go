@@ -0,0 +1,322 @@
+// Package pglocal runs a throwaway Postgres cluster using locally installed
+// Postgres binaries, initdb, pg_ctl, and psql. An alternative to pgdocker for
+// environments that can't run Docker.
+package pglocal
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/leg100/pggen/internal/errs"
+	"github.com/leg100/pggen/internal/pg"
+	"github.com/leg100/pggen/internal/ports"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// user is the Postgres superuser created by initdb. The same as the official
+// Postgres Docker image.
+const user = "postgres"
+
+// ErrRoot is the error from Start when the process runs as root.
+var ErrRoot = errors.New("local postgres can't run as root because initdb and pg_ctl " +
+	"refuse to run as root; run pggen as an unprivileged user or use the docker backend")
+
+// Client is a Postgres cluster in a temp dir.
+type Client struct {
+	binDir     string // directory with the Postgres binaries; empty to use PATH
+	dir        string // temp dir with the data dir and server log
+	port       ports.Port
+	l          *zap.SugaredLogger
+	connString string
+	version    pg.ServerVersion // set once Postgres is ready
+	started    bool             // true if pg_ctl started the server
+}
+
+// Start creates a new Postgres cluster in a temp dir with initdb and starts
+// the server with pg_ctl on an available port. Start then runs initScripts
+// in order like the entry point of the official Postgres Docker image: psql
+// runs *.sql and *.sql.gz files, and *.sh files run with the PG* environment
+// variables set to connect to the server. Executable *.sh files run directly
+// and other *.sh files run with sh.
+//
+// binDir is the directory with the initdb, pg_ctl, and psql binaries, like
+// "/usr/lib/postgresql/15/bin". If empty, finds the binaries on PATH.
+//
+// Returns ErrRoot if the process runs as root since initdb and pg_ctl refuse
+// to run as root.
+func Start(ctx context.Context, binDir string, initScripts []string, l *zap.SugaredLogger) (client *Client, mErr error) {
+	now := time.Now()
+	if os.Geteuid() == 0 {
+		return nil, ErrRoot
+	}
+	c := &Client{binDir: binDir, l: l}
+	for _, name := range []string{"initdb", "pg_ctl", "psql"} {
+		if _, err := exec.LookPath(c.bin(name)); err != nil {
+			return nil, fmt.Errorf("find postgres binary %s; set the postgres bin dir to the "+
+				"directory with the binaries, like /usr/lib/postgresql/15/bin: %w", name, err)
+		}
+	}
+	dir, err := ioutil.TempDir("", "pggen-postgres-")
+	if err != nil {
+		return nil, fmt.Errorf("create postgres temp dir: %w", err)
+	}
+	c.dir = dir
+	// Cleanup the cluster if we fail to start. Enrich errors with the server
+	// logs.
+	defer func() {
+		if mErr == nil {
+			return
+		}
+		if logs, err := c.Logs(); err != nil {
+			mErr = multierr.Append(mErr, err)
+		} else if logs != "" {
+			mErr = fmt.Errorf("%w\nPostgres server logs:\n\n%s", mErr, logs)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := c.Stop(ctx); err != nil {
+			c.l.Errorf("stop pglocal client: %s", err)
+		}
+	}()
+
+	if err := c.initDB(ctx); err != nil {
+		return nil, fmt.Errorf("init postgres cluster: %w", err)
+	}
+	port, err := ports.FindAvailable()
+	if err != nil {
+		return nil, fmt.Errorf("find available port: %w", err)
+	}
+	c.port = port
+	if err := c.startServer(ctx); err != nil {
+		return nil, fmt.Errorf("start postgres server: %w", err)
+	}
+	c.connString = fmt.Sprintf("host=localhost port=%d user=%s", port, user)
+	if err := c.detectVersion(ctx); err != nil {
+		return nil, err
+	}
+	for _, script := range initScripts {
+		if err := c.runInitScript(ctx, script); err != nil {
+			return nil, fmt.Errorf("run init script %s: %w", script, err)
+		}
+	}
+	c.l.Debugf("started local postgres %s in %d ms", c.version, time.Since(now).Milliseconds())
+	return c, nil
+}
+
+// initDB creates the Postgres cluster in the data dir.
+func (c *Client) initDB(ctx context.Context) error {
+	cmd := c.command(ctx, "initdb",
+		"--pgdata", c.dataDir(),
+		"--username", user,
+		"--auth", "trust",
+		"--encoding", "UTF8",
+		"--no-sync",
+	)
+	return c.run(cmd)
+}
+
+// startServer starts Postgres and waits until the server accepts
+// connections. Postgres only listens on localhost TCP to avoid needing a
+// writable Unix socket directory.
+func (c *Client) startServer(ctx context.Context) error {
+	serverOpts := strings.Join([]string{
+		"-p " + strconv.Itoa(c.port),
+		"-c listen_addresses=localhost",
+		"-c unix_socket_directories=''",
+		"-c fsync=off",
+		"-c full_page_writes=off",
+	}, " ")
+	cmd := c.command(ctx, "pg_ctl", "start",
+		"--pgdata", c.dataDir(),
+		"--log", c.logFile(),
+		"--options", serverOpts,
+		"--wait",
+		"--timeout", "10",
+	)
+	if err := c.run(cmd); err != nil {
+		return err
+	}
+	c.started = true
+	return nil
+}
+
+// detectVersion connects to the server to detect the server version.
+func (c *Client) detectVersion(ctx context.Context) (mErr error) {
+	conn, err := pgx.Connect(ctx, c.connString)
+	if err != nil {
+		return fmt.Errorf("connect to local postgres: %w", err)
+	}
+	defer errs.Capture(&mErr, func() error { return conn.Close(ctx) }, "close postgres connection")
+	version, err := pg.FetchServerVersion(ctx, conn)
+	if err != nil {
+		return fmt.Errorf("detect postgres version: %w", err)
+	}
+	c.version = version
+	return nil
+}
+
+// runInitScript runs a single init script on the server.
+func (c *Client) runInitScript(ctx context.Context, script string) (mErr error) {
+	switch {
+	case strings.HasSuffix(script, ".sql"):
+		return c.run(c.psql(ctx, "--file", script))
+
+	case strings.HasSuffix(script, ".sql.gz"):
+		f, err := os.Open(script)
+		if err != nil {
+			return fmt.Errorf("open init script: %w", err)
+		}
+		defer errs.Capture(&mErr, f.Close, "close init script")
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("read gzip init script: %w", err)
+		}
+		defer errs.Capture(&mErr, gz.Close, "close gzip init script")
+		cmd := c.psql(ctx)
+		cmd.Stdin = gz
+		return c.run(cmd)
+
+	case strings.HasSuffix(script, ".sh"):
+		stat, err := os.Stat(script)
+		if err != nil {
+			return fmt.Errorf("stat init script: %w", err)
+		}
+		path, err := filepath.Abs(script)
+		if err != nil {
+			return fmt.Errorf("resolve absolute path for init script: %w", err)
+		}
+		var cmd *exec.Cmd
+		if stat.Mode()&0111 != 0 {
+			cmd = exec.CommandContext(ctx, path)
+		} else {
+			cmd = exec.CommandContext(ctx, "sh", path)
+		}
+		cmd.Env = append(os.Environ(), c.env()...)
+		return c.run(cmd)
+
+	default:
+		return fmt.Errorf("unsupported init script; want *.sql, *.sql.gz, or *.sh file")
+	}
+}
+
+// psql returns a command to run psql on the server with args that stops on
+// the first error.
+func (c *Client) psql(ctx context.Context, args ...string) *exec.Cmd {
+	psqlArgs := []string{"--no-psqlrc", "--quiet", "--set", "ON_ERROR_STOP=1"}
+	return c.command(ctx, "psql", append(psqlArgs, args...)...)
+}
+
+// command returns a command to run the Postgres binary name with args.
+func (c *Client) command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, c.bin(name), args...)
+	cmd.Env = append(os.Environ(), c.env()...)
+	return cmd
+}
+
+// run runs cmd and returns an error with the combined output if cmd fails.
+func (c *Client) run(cmd *exec.Cmd) error {
+	out := &bytes.Buffer{}
+	cmd.Stdout = out
+	cmd.Stderr = out
+	start := time.Now()
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("run %s: %w\n%s", filepath.Base(cmd.Path), err, out.String())
+	}
+	c.l.Debugf("ran %s in %d ms", filepath.Base(cmd.Path), time.Since(start).Milliseconds())
+	return nil
+}
+
+// env returns the environment variables so that commands, like psql and init
+// scripts, connect to the server. Also sets the variables from the official
+// Postgres Docker image used by init scripts.
+func (c *Client) env() []string {
+	env := []string{
+		"PGUSER=" + user,
+		"PGDATABASE=" + user,
+		"POSTGRES_USER=" + user,
+		"POSTGRES_DB=" + user,
+		"PGDATA=" + c.dataDir(),
+	}
+	if c.port != 0 {
+		env = append(env, "PGHOST=localhost", "PGPORT="+strconv.Itoa(c.port))
+	}
+	return env
+}
+
+func (c *Client) bin(name string) string {
+	if c.binDir == "" {
+		return name
+	}
+	return filepath.Join(c.binDir, name)
+}
+
+func (c *Client) dataDir() string { return filepath.Join(c.dir, "data") }
+
+func (c *Client) logFile() string { return filepath.Join(c.dir, "postgres.log") }
+
+// Logs returns the Postgres server logs.
+func (c *Client) Logs() (string, error) {
+	if c.dir == "" {
+		return "", nil
+	}
+	bs, err := ioutil.ReadFile(c.logFile())
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("read postgres server logs: %w", err)
+	}
+	return string(bs), nil
+}
+
+// ConnString returns the connection string to connect to the started Postgres
+// server.
+func (c *Client) ConnString() (string, error) {
+	if c.connString == "" {
+		return "", fmt.Errorf("conn string not set; did postgres start correctly")
+	}
+	return c.connString, nil
+}
+
+// ServerVersion returns the version of the started Postgres server.
+func (c *Client) ServerVersion() pg.ServerVersion {
+	return c.version
+}
+
+// Stop stops the server, if running, and removes the cluster temp dir.
+func (c *Client) Stop(ctx context.Context) error {
+	if c.dir == "" {
+		return nil
+	}
+	var err error
+	if c.started {
+		cmd := c.command(ctx, "pg_ctl", "stop",
+			"--pgdata", c.dataDir(),
+			"--mode", "immediate",
+			"--wait",
+		)
+		if stopErr := c.run(cmd); stopErr != nil {
+			err = fmt.Errorf("stop postgres server: %w", stopErr)
+		} else {
+			c.started = false
+		}
+	}
+	if rmErr := os.RemoveAll(c.dir); rmErr != nil {
+		err = multierr.Append(err, fmt.Errorf("remove postgres temp dir: %w", rmErr))
+	} else {
+		c.dir = ""
+	}
+	return err
+}
@@ -0,0 +1,75 @@
+package pglocal
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestStart(t *testing.T) {
+	if _, err := exec.LookPath("initdb"); err != nil {
+		t.Skip("initdb not found on PATH")
+	}
+	if os.Geteuid() == 0 {
+		t.Skip("initdb can't run as root; see TestStart_Root")
+	}
+	dir := t.TempDir()
+	sqlFile := filepath.Join(dir, "01_schema.sql")
+	require.NoError(t, ioutil.WriteFile(sqlFile, []byte("CREATE TABLE author (id int);"), 0644))
+	gzFile := filepath.Join(dir, "02_schema.sql.gz")
+	gzBuf := &bytes.Buffer{}
+	gzW := gzip.NewWriter(gzBuf)
+	_, err := gzW.Write([]byte("CREATE TABLE book (id int);"))
+	require.NoError(t, err)
+	require.NoError(t, gzW.Close())
+	require.NoError(t, ioutil.WriteFile(gzFile, gzBuf.Bytes(), 0644))
+	shFile := filepath.Join(dir, "03_schema.sh")
+	require.NoError(t, ioutil.WriteFile(shFile, []byte(`psql -c "CREATE TABLE publisher (id int);"`), 0644))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	client, err := Start(ctx, "", []string{sqlFile, gzFile, shFile}, zaptest.NewLogger(t).Sugar())
+	require.NoError(t, err)
+	clusterDir := client.dir
+	assert.NotZero(t, client.ServerVersion().Num)
+
+	connString, err := client.ConnString()
+	require.NoError(t, err)
+	conn, err := pgx.Connect(ctx, connString)
+	require.NoError(t, err)
+	var count int
+	err = conn.QueryRow(ctx, `
+		SELECT count(*)
+		FROM pg_tables
+		WHERE tablename IN ('author', 'book', 'publisher')`).Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count, "tables created by init scripts")
+	require.NoError(t, conn.Close(ctx))
+
+	require.NoError(t, client.Stop(ctx))
+	if _, err := os.Stat(clusterDir); !os.IsNotExist(err) {
+		t.Errorf("cluster dir %s exists after stop; stat error: %v", clusterDir, err)
+	}
+}
+
+func TestStart_Root(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("not running as root")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := Start(ctx, "", nil, zaptest.NewLogger(t).Sugar())
+	assert.Nil(t, client)
+	assert.Equal(t, ErrRoot, err)
+}
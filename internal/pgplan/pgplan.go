@@ -7,13 +7,19 @@ import (
 	"time"
 )
 
-// ExplainQuery executes an explain query and parses the plan.
-func ExplainQuery(conn *pgx.Conn, sql string) (Node, error) {
+// queryRower runs a query that returns a single row, like *pgx.Conn or pgx.Tx.
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// ExplainQuery executes an explain query and parses the plan. args are the
+// values for the parameters in sql, like $1.
+func ExplainQuery(conn queryRower, sql string, args ...interface{}) (Node, error) {
 	explainQuery := `EXPLAIN (VERBOSE, FORMAT JSON) ` + sql
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	row := conn.QueryRow(ctx, explainQuery)
+	row := conn.QueryRow(ctx, explainQuery, args...)
 	explain := make([]map[string]map[string]interface{}, 0, 1)
 	if err := row.Scan(&explain); err != nil {
 		return BadNode{}, fmt.Errorf("execute explain query: %w", err)
@@ -47,3 +47,25 @@ func (sc *GuardedStmtCache) Get(ctx context.Context, sql string) (*pgconn.Statem
 	}
 	return sc.LRU.Get(ctx, sql)
 }
+
+// WithoutPreparedStatements is a functional option to initialize the pgtest
+// conn with a statement cache that fails if pgx attempts to prepare or
+// describe any statement. Mimics a connection behind PgBouncer in transaction
+// pooling mode, where only the simple protocol works reliably.
+func WithoutPreparedStatements() Option {
+	return func(config *pgx.ConnConfig) {
+		config.BuildStatementCache = func(conn *pgconn.PgConn) stmtcache.Cache {
+			return rejectStmtCache{NewGuardedStmtCache(conn)}
+		}
+	}
+}
+
+// rejectStmtCache errors if pgx attempts to get any cached statement.
+type rejectStmtCache struct {
+	*GuardedStmtCache
+}
+
+func (sc rejectStmtCache) Get(_ context.Context, sql string) (*pgconn.StatementDescription, error) {
+	return nil, fmt.Errorf("reject statement cache attempted to get %s;"+
+		" should use the simple protocol", sql)
+}
@@ -0,0 +1,98 @@
+package pggen
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/leg100/pggen/internal/codegen"
+	"github.com/leg100/pggen/internal/errs"
+	"github.com/leg100/pggen/internal/pg"
+	"github.com/leg100/pggen/internal/pginfer"
+	"go.uber.org/zap"
+)
+
+// checkMatrix infers every query in want on a Dockerized Postgres for each of
+// opts.PostgresImages after the first. Returns an error describing each query
+// that errors or infers differently than want, which was inferred on the
+// first image with the given version.
+func checkMatrix(
+	ctx context.Context,
+	opts GenerateOptions,
+	version pg.ServerVersion,
+	want []codegen.QueryFile,
+	l *zap.SugaredLogger,
+) error {
+	ref := fmt.Sprintf("%s (%s)", opts.PostgresImages[0], version)
+	var problems []string
+	for _, image := range opts.PostgresImages[1:] {
+		ps, err := checkMatrixImage(ctx, opts, image, ref, want, l)
+		if err != nil {
+			return fmt.Errorf("check postgres image %s: %w", image, err)
+		}
+		problems = append(problems, ps...)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("queries differ between postgres versions:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// checkMatrixImage infers every query in want on a Dockerized Postgres for
+// image. Returns a description of each query that errors or infers
+// differently than want, which was inferred on the ref Postgres.
+func checkMatrixImage(
+	ctx context.Context,
+	opts GenerateOptions,
+	image string,
+	ref string,
+	want []codegen.QueryFile,
+	l *zap.SugaredLogger,
+) (problems []string, mErr error) {
+	start := time.Now()
+	server, err := startPostgres(ctx, opts, image, l)
+	if err != nil {
+		return nil, err
+	}
+	defer errs.Capture(&mErr, func() error { return server.Stop(ctx) }, "stop postgres")
+	connStr, err := server.ConnString()
+	if err != nil {
+		return nil, fmt.Errorf("get postgres conn string: %w", err)
+	}
+	conn, err := pgx.Connect(ctx, connStr)
+	if err != nil {
+		return nil, fmt.Errorf("connect to pggen postgres database: %w", err)
+	}
+	defer errs.Capture(&mErr, func() error { return conn.Close(ctx) }, "close postgres connection")
+	if opts.SearchPath != "" {
+		if _, err := conn.Exec(ctx, "SELECT set_config('search_path', $1, false)", opts.SearchPath); err != nil {
+			return nil, fmt.Errorf("set search_path: %w", err)
+		}
+	}
+
+	name := fmt.Sprintf("%s (%s)", image, server.ServerVersion())
+	inferrer := pginfer.NewInferrer(conn)
+	numQueries := 0
+	for _, file := range want {
+		srcQueries, err := parseSourceQueries(file.SourcePath)
+		if err != nil {
+			return nil, err
+		}
+		for i, srcQuery := range srcQueries {
+			numQueries++
+			got, err := inferrer.InferTypes(srcQuery)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("query %s fails on %s: %s", srcQuery.Name, name, err))
+				continue
+			}
+			if diffs := pginfer.DiffTypedQueries(file.Queries[i], got); len(diffs) > 0 {
+				problems = append(problems, fmt.Sprintf("query %s differs on %s from %s:\n\t%s",
+					srcQuery.Name, name, ref, strings.Join(diffs, "\n\t")))
+			}
+		}
+	}
+	l.Debugf("checked %d queries on postgres %s in %d ms", numQueries, name, time.Since(start).Milliseconds())
+	return problems, nil
+}
@@ -0,0 +1,52 @@
+package pggen
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/leg100/pggen/internal/pg"
+	"github.com/leg100/pggen/internal/pgdocker"
+	"github.com/leg100/pggen/internal/pglocal"
+	"go.uber.org/zap"
+)
+
+// postgresProvider is a throwaway Postgres server started with the schema
+// files. Implemented by each PostgresBackend.
+type postgresProvider interface {
+	// ConnString returns the connection string to connect to the server.
+	ConnString() (string, error)
+	// ServerVersion returns the version of the server.
+	ServerVersion() pg.ServerVersion
+	// Logs returns the server logs to debug errors.
+	Logs() (string, error)
+	// Stop stops the server and removes all resources, like the Docker
+	// container or the data directory.
+	Stop(ctx context.Context) error
+}
+
+var (
+	_ postgresProvider = (*pgdocker.Client)(nil)
+	_ postgresProvider = (*pglocal.Client)(nil)
+)
+
+// startPostgres starts a Postgres server using opts.PostgresBackend and runs
+// opts.SchemaFiles on the server. image is the Docker image for
+// PostgresBackendDocker. If empty, uses the default image.
+func startPostgres(ctx context.Context, opts GenerateOptions, image string, l *zap.SugaredLogger) (postgresProvider, error) {
+	switch opts.PostgresBackend {
+	case "", PostgresBackendDocker:
+		client, err := pgdocker.Start(ctx, image, opts.SchemaFiles, l)
+		if err != nil {
+			return nil, fmt.Errorf("start dockerized postgres: %w", err)
+		}
+		return client, nil
+	case PostgresBackendLocal:
+		client, err := pglocal.Start(ctx, opts.PostgresBinDir, opts.SchemaFiles, l)
+		if err != nil {
+			return nil, fmt.Errorf("start local postgres: %w", err)
+		}
+		return client, nil
+	default:
+		return nil, fmt.Errorf("unknown postgres backend %q", opts.PostgresBackend)
+	}
+}